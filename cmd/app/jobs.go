@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/example/learngo/pkg/utils"
+)
+
+// Job фоновая задача. Run получает ключи, накопившиеся с прошлого запуска
+// (например, ID курсов); при плановом запуске по Interval keys == nil — значит «всё».
+type Job struct {
+	Name     string
+	Interval time.Duration // 0 — только по Trigger
+	Debounce time.Duration // задержка перед запуском по Trigger, чтобы схлопнуть серию событий
+	Run      func(ctx context.Context, keys []string) error
+}
+
+// Scheduler минимальный планировщик фоновых задач внутри процесса API.
+// Каждая задача работает в своей горутине, запуски одной задачи не пересекаются.
+type Scheduler struct {
+	logger *utils.Logger
+	mu     sync.Mutex
+	jobs   map[string]*jobState
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+type jobState struct {
+	job     Job
+	pending map[string]struct{}
+	wake    chan struct{}
+}
+
+func NewScheduler(logger *utils.Logger) *Scheduler {
+	return &Scheduler{logger: logger, jobs: make(map[string]*jobState)}
+}
+
+// Register добавляет задачу; вызывать до Start.
+func (s *Scheduler) Register(j Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[j.Name] = &jobState{job: j, pending: make(map[string]struct{}), wake: make(chan struct{}, 1)}
+}
+
+// Trigger ставит ключ в очередь задачи; повторные ключи до запуска схлопываются.
+func (s *Scheduler) Trigger(name, key string) {
+	s.mu.Lock()
+	st, ok := s.jobs[name]
+	if ok {
+		st.pending[key] = struct{}{}
+	}
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case st.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start запускает все зарегистрированные задачи.
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, st)
+	}
+}
+
+// Stop останавливает задачи и ждёт завершения текущих запусков.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, st *jobState) {
+	defer s.wg.Done()
+	var tick <-chan time.Time
+	if st.job.Interval > 0 {
+		t := time.NewTicker(st.job.Interval)
+		defer t.Stop()
+		tick = t.C
+		// первый полный пересчёт сразу после старта
+		s.run(ctx, st.job, nil)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			s.mu.Lock()
+			st.pending = make(map[string]struct{}) // полный пересчёт покрывает накопленные ключи
+			s.mu.Unlock()
+			s.run(ctx, st.job, nil)
+		case <-st.wake:
+			if st.job.Debounce > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(st.job.Debounce):
+				}
+			}
+			s.mu.Lock()
+			keys := make([]string, 0, len(st.pending))
+			for k := range st.pending {
+				keys = append(keys, k)
+			}
+			st.pending = make(map[string]struct{})
+			s.mu.Unlock()
+			if len(keys) > 0 {
+				s.run(ctx, st.job, keys)
+			}
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, j Job, keys []string) {
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return j.Run(ctx, keys)
+	}()
+	if err != nil {
+		s.logger.Error("background job failed", "job", j.Name, "keys", len(keys), "error", err)
+		return
+	}
+	s.logger.Info("background job done", "job", j.Name, "keys", len(keys), "duration_ms", time.Since(start).Milliseconds())
+}
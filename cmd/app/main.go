@@ -3,39 +3,73 @@ package main
 import (
 	"context"
 	"log"
+	"slices"
 	"time"
 
 	httpdelivery "github.com/example/learngo/internal/delivery/http"
 	achievementdomain "github.com/example/learngo/internal/domain/achievement"
 	assignmentdomain "github.com/example/learngo/internal/domain/assignment"
 	coursedomain "github.com/example/learngo/internal/domain/course"
+	discussiondomain "github.com/example/learngo/internal/domain/discussion"
 	enrollmentdomain "github.com/example/learngo/internal/domain/enrollment"
+	eventdomain "github.com/example/learngo/internal/domain/event"
 	lessondomain "github.com/example/learngo/internal/domain/lesson"
 	moduledomain "github.com/example/learngo/internal/domain/module"
+	notedomain "github.com/example/learngo/internal/domain/note"
+	plagiarismdomain "github.com/example/learngo/internal/domain/plagiarism"
 	progressdomain "github.com/example/learngo/internal/domain/progress"
+	quizdomain "github.com/example/learngo/internal/domain/quiz"
+	revisiondomain "github.com/example/learngo/internal/domain/revision"
 	sectiondomain "github.com/example/learngo/internal/domain/section"
+	submissiondomain "github.com/example/learngo/internal/domain/submission"
+	translationdomain "github.com/example/learngo/internal/domain/translation"
 	userdomain "github.com/example/learngo/internal/domain/user"
+	videodomain "github.com/example/learngo/internal/domain/video"
 	"github.com/example/learngo/internal/infrastructure/db"
+	"github.com/example/learngo/internal/infrastructure/eventbus"
+	"github.com/example/learngo/internal/infrastructure/queue"
 	memoryrepo "github.com/example/learngo/internal/infrastructure/repository/memory"
 	postgresrepo "github.com/example/learngo/internal/infrastructure/repository/postgres"
+	accessuc "github.com/example/learngo/internal/usecase/access"
 	achievementuc "github.com/example/learngo/internal/usecase/achievement"
 	aiuc "github.com/example/learngo/internal/usecase/ai"
 	assignuc "github.com/example/learngo/internal/usecase/assignment"
 	authuc "github.com/example/learngo/internal/usecase/auth"
 	codeexecuc "github.com/example/learngo/internal/usecase/codeexec"
+	codejobuc "github.com/example/learngo/internal/usecase/codejob"
 	courseuc "github.com/example/learngo/internal/usecase/course"
 	dashboarduc "github.com/example/learngo/internal/usecase/dashboard"
+	discussionuc "github.com/example/learngo/internal/usecase/discussion"
 	"github.com/example/learngo/internal/usecase/enrollment"
+	gradinguc "github.com/example/learngo/internal/usecase/grading"
+	i18nuc "github.com/example/learngo/internal/usecase/i18n"
 	lessonuc "github.com/example/learngo/internal/usecase/lesson"
 	moduleuc "github.com/example/learngo/internal/usecase/module"
+	noteuc "github.com/example/learngo/internal/usecase/note"
+	plagiarismuc "github.com/example/learngo/internal/usecase/plagiarism"
 	progressuc "github.com/example/learngo/internal/usecase/progress"
+	quizuc "github.com/example/learngo/internal/usecase/quiz"
+	revisionuc "github.com/example/learngo/internal/usecase/revision"
 	sectionsvc "github.com/example/learngo/internal/usecase/section"
+	submissionuc "github.com/example/learngo/internal/usecase/submission"
+	videouc "github.com/example/learngo/internal/usecase/video"
 	"github.com/example/learngo/pkg/ai"
 	"github.com/example/learngo/pkg/codeexec"
+	"github.com/example/learngo/pkg/codejob"
+	"github.com/example/learngo/pkg/gotest"
+	"github.com/example/learngo/pkg/lint"
+	"github.com/example/learngo/pkg/markdown"
+	"github.com/example/learngo/pkg/sandbox"
+	"github.com/example/learngo/pkg/storage"
 	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
+	// вспомогательный процесс песочницы (программы-чекеры): настраивает изоляцию и exec'ает программу
+	sandbox.Init()
+
 	cfg, err := utils.LoadConfig()
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
@@ -54,6 +88,14 @@ func main() {
 		progressRepo    progressdomain.Repository
 		enrollmentRepo  enrollmentdomain.Repository
 		achievementRepo achievementdomain.Repository
+		translationRepo translationdomain.Repository
+		quizRepo        quizdomain.Repository
+		revisionRepo    revisiondomain.Repository
+		videoRepo       videodomain.Repository
+		discussionRepo  discussiondomain.Repository
+		noteRepo        notedomain.Repository
+		submissionRepo  submissiondomain.Repository
+		plagiarismRepo  plagiarismdomain.Repository
 	)
 
 	var pdbOpened bool
@@ -87,6 +129,32 @@ func main() {
 			achr := postgresrepo.NewAchievementRepository(pdb)
 			_ = achr.AutoMigrate()
 			achievementRepo = achr
+			tr := postgresrepo.NewTranslationRepository(pdb)
+			_ = tr.AutoMigrate()
+			translationRepo = tr
+			qr := postgresrepo.NewQuizRepository(pdb)
+			_ = qr.AutoMigrate()
+			quizRepo = qr
+			rvr := postgresrepo.NewRevisionRepository(pdb)
+			_ = rvr.AutoMigrate()
+			revisionRepo = rvr
+			vr := postgresrepo.NewVideoRepository(pdb)
+			_ = vr.AutoMigrate()
+			videoRepo = vr
+			dr := postgresrepo.NewDiscussionRepository(pdb)
+			_ = dr.AutoMigrate()
+			discussionRepo = dr
+			nr := postgresrepo.NewNoteRepository(pdb)
+			if err := nr.AutoMigrate(); err != nil {
+				logger.Error("notes migration failed", "error", err)
+			}
+			noteRepo = nr
+			sbr := postgresrepo.NewSubmissionRepository(pdb)
+			_ = sbr.AutoMigrate()
+			submissionRepo = sbr
+			plr := postgresrepo.NewPlagiarismRepository(pdb)
+			_ = plr.AutoMigrate()
+			plagiarismRepo = plr
 
 			// AI Chat History repository
 			aiChatRepo := postgresrepo.NewAIChatHistoryRepository(pdb)
@@ -104,9 +172,13 @@ func main() {
 		enrollmentRepo = memoryrepo.NewInMemoryEnrollmentRepository()
 	}
 
+	// Доменные события и фоновые задачи
+	bus := eventbus.New()
+	scheduler := NewScheduler(logger)
+
 	// Use cases
 	courseService := courseuc.NewService(courseRepo, logger)
-	lessonService := lessonuc.NewService(lessonRepo, logger)
+	lessonService := lessonuc.NewService(lessonRepo, moduleRepo, logger, bus)
 	assignmentService := assignuc.NewService(assignmentRepo, logger)
 	jwtManager := utils.NewJWTManager(cfg.JWTSecret, cfg.JWTTTLMin, cfg.JWTRefreshSecret, cfg.JWTRefreshTTLDays)
 	authService := authuc.NewService(userRepo, jwtManager)
@@ -126,7 +198,9 @@ func main() {
 
 	// Передаём enrollmentRepo через контекст Router'у через добавление параметра — упростим: внедрим через package-level? Лучше расширить сигнатуру
 	// Enrollment use case
-	enrollService := enrollment.NewService(enrollmentRepo, logger)
+	enrollService := enrollment.NewService(enrollmentRepo, logger, bus)
+	// Политика доступа к платному контенту (запись на курс, бесплатные уроки)
+	accessService := accessuc.NewService(courseRepo, lessonRepo, enrollService)
 	// Section/Module use case (может быть nil)
 	var sectionService sectionsvc.Service
 	if sectionRepo != nil {
@@ -141,6 +215,64 @@ func main() {
 		achievementService = achievementuc.NewService(achievementRepo)
 	}
 
+	// i18n service (переводы хранятся только в Postgres)
+	var i18nService i18nuc.Service
+	if translationRepo != nil {
+		i18nService = i18nuc.NewService(translationRepo, courseRepo, lessonRepo, userRepo, logger, cfg.SupportedLocales, cfg.DefaultLocale)
+	}
+
+	// Quiz service (вопросы и попытки хранятся только в Postgres)
+	var quizService quizuc.Service
+	if quizRepo != nil {
+		quizService = quizuc.NewService(quizRepo, lessonRepo, progressService, logger)
+	}
+
+	// История изменений уроков и заданий (только Postgres)
+	var revisionService revisionuc.Service
+	if revisionRepo != nil {
+		revisionService = revisionuc.NewService(revisionRepo, lessonService, assignmentService, logger)
+	}
+
+	// Обсуждения уроков (только Postgres)
+	var discussionService discussionuc.Service
+	if discussionRepo != nil {
+		discussionService = discussionuc.NewService(discussionRepo, accessService, enrollService, logger)
+	}
+
+	// Заметки и закладки (только Postgres); рендерер Markdown общий с роутером
+	md := markdown.New(cfg.MarkdownCacheSize)
+	var noteService noteuc.Service
+	if noteRepo != nil {
+		noteService = noteuc.NewService(noteRepo, lessonRepo, courseRepo, accessService, md)
+	}
+
+	// Объектное хранилище: один клиент на процесс, бакет проверяется при старте
+	var objectStorage *storage.S3Client
+	if cfg.S3AccessKey != "" && cfg.S3Bucket != "" {
+		s3, err := storage.NewS3Client(storage.S3Config{Endpoint: cfg.S3Endpoint, AccessKey: cfg.S3AccessKey, SecretKey: cfg.S3SecretKey, Bucket: cfg.S3Bucket, UseSSL: true, BaseURL: cfg.S3BaseURL})
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			err = s3.EnsureBucket(ctx)
+			cancel()
+		}
+		if err != nil {
+			logger.Error("s3 init failed, uploads disabled", "error", err)
+		} else {
+			objectStorage = s3
+		}
+	}
+
+	// Видеоуроки (нужны Postgres и S3)
+	var videoService videouc.Service
+	if videoRepo != nil && objectStorage != nil {
+		videoService = videouc.NewService(videoRepo, objectStorage, lessonService, enrollService, logger, videouc.Options{
+			PartSize:     int64(cfg.VideoPartSizeMB) << 20,
+			MaxSize:      int64(cfg.VideoMaxSizeMB) << 20,
+			UploadURLTTL: time.Duration(cfg.VideoUploadURLTTLMin) * time.Minute,
+			PlaybackTTL:  time.Duration(cfg.VideoPlaybackTTLMin) * time.Minute,
+		})
+	}
+
 	// Dashboard service
 	var dashboardService dashboarduc.Service
 	if userRepo != nil && courseRepo != nil && lessonRepo != nil && progressRepo != nil && enrollmentRepo != nil {
@@ -171,18 +303,227 @@ func main() {
 		}
 	}
 
-	// Code execution service
-	var codeExecService codeexecuc.Service
-	if cfg.Judge0APIURL != "" {
-		judge0Client := codeexec.NewClient(cfg.Judge0APIURL, cfg.Judge0APIKey)
-		timeout := time.Duration(cfg.CodeExecutionTimeout) * time.Millisecond
-		memoryLimitKB := cfg.CodeExecutionMemoryLimit * 1024 // MB to KB
-		codeExecService = codeexecuc.NewService(judge0Client, logger, timeout, memoryLimitKB)
+	// Асинхронное выполнение кода: очередь RabbitMQ + результаты в Redis (воркер cmd/runner)
+	var codeJobService codejobuc.Service
+	rdb := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr, Password: cfg.RedisPassword})
+	defer rdb.Close()
+	pingCtx, cancelPing := context.WithTimeout(context.Background(), 3*time.Second)
+	redisErr := rdb.Ping(pingCtx).Err()
+	if redisErr != nil {
+		logger.Warn("redis unavailable, async code jobs disabled", "error", redisErr)
+	} else if publisher, err := queue.NewPublisher(cfg.RabbitURL, cfg.RunnerQueue); err != nil {
+		logger.Warn("rabbitmq unavailable, async code jobs disabled", "error", err)
 	} else {
-		logger.Warn("judge0 not configured, code execution will be limited")
+		defer publisher.Close()
+		store := codejob.NewStore(rdb, time.Duration(cfg.ResultTTLMin)*time.Minute)
+		codeJobService = codejobuc.NewService(publisher, store, logger)
+	}
+	cancelPing()
+
+	// Code execution service: бэкенды Judge0, локальный исполнитель и очередь раннера
+	// с проверками здоровья и переключением при сбоях
+	timeout := time.Duration(cfg.CodeExecutionTimeout) * time.Millisecond
+	memoryLimitKB := cfg.CodeExecutionMemoryLimit * 1024 // MB to KB
+	sb, sbErr := sandbox.New(sandbox.DefaultConfig())
+	var judge0Callbacks *codeexec.Callbacks
+	auto := len(cfg.CodeExecutors) == 0 || (len(cfg.CodeExecutors) == 1 && cfg.CodeExecutors[0] == "auto")
+	var executors []codeexec.Executor
+	var defaultChain []string
+	for _, name := range []string{"judge0", "queue", "local"} {
+		wanted := auto || slices.Contains(cfg.CodeExecutors, name)
+		switch {
+		case !wanted:
+		case name == "judge0" && cfg.Judge0APIURL != "":
+			client := codeexec.NewClient(cfg.Judge0APIURL, cfg.Judge0APIKey)
+			if cfg.Judge0CallbackURL != "" {
+				// секрет общий для всех реплик: уведомление может прийти не на ту, что отправила задачу
+				if cfg.Judge0CallbackSecret == "" {
+					log.Fatalf("JUDGE0_CALLBACK_URL is set without JUDGE0_CALLBACK_SECRET")
+				}
+				cb, err := codeexec.NewCallbacks(cfg.Judge0CallbackURL, cfg.Judge0CallbackSecret)
+				if err != nil {
+					log.Fatalf("JUDGE0_CALLBACK_URL: %v", err)
+				}
+				client.UseCallbacks(cb)
+				judge0Callbacks = cb
+			}
+			executors = append(executors, codeexec.NewJudge0Executor(client, timeout))
+		case name == "queue" && codeJobService != nil:
+			// у очереди своё время на ожидание воркера и сборку
+			executors = append(executors, codeexecuc.NewQueueExecutor(codeJobService, timeout+30*time.Second))
+		case name == "local":
+			// в auto без песочницы локальный исполнитель — только если больше выполнять нечем (машина разработчика)
+			if auto && sbErr != nil && len(executors) > 0 {
+				continue
+			}
+			if sbErr != nil && cfg.CodeExecSandboxRequired && !cfg.Development() {
+				log.Fatalf("sandbox unavailable, refusing to run code without isolation (set CODE_EXEC_SANDBOX_REQUIRED=false to allow): %v", sbErr)
+			}
+			opts := codeexec.DefaultLocalOptions()
+			opts.Run.WallTime = timeout
+			if sbErr != nil {
+				logger.Warn("sandbox unavailable, local code execution is NOT isolated; use only on a developer machine", "error", sbErr)
+			} else {
+				opts.Sandbox = sb
+			}
+			local := codeexec.NewLocalExecutor(opts)
+			logger.Info("local code executor", "languages", local.Languages(), "sandboxed", local.Sandboxed())
+			executors = append(executors, local)
+		default:
+			logger.Warn("code execution backend is not configured", "backend", name)
+		}
+	}
+	if !auto {
+		// порядок из CODE_EXECUTOR — цепочка по умолчанию
+		for _, name := range cfg.CodeExecutors {
+			if slices.ContainsFunc(executors, func(e codeexec.Executor) bool { return e.Name() == name }) {
+				defaultChain = append(defaultChain, name)
+			}
+		}
+	}
+	var execRouter *codeexec.Router
+	if len(executors) > 0 {
+		rules, err := codeexec.ParseRules(cfg.CodeExecutorRoutes)
+		if err != nil {
+			log.Fatalf("CODE_EXECUTOR_ROUTES: %v", err)
+		}
+		execRouter, err = codeexec.NewRouter(codeexec.RouterConfig{
+			Rules:            rules,
+			Default:          defaultChain,
+			ProbeInterval:    time.Duration(cfg.CodeExecutorProbeSec) * time.Second,
+			FailureThreshold: cfg.CodeExecutorFailureThreshold,
+			Cooldown:         time.Duration(cfg.CodeExecutorCooldownSec) * time.Second,
+		}, logger, executors...)
+		if err != nil {
+			log.Fatalf("code execution routes: %v", err)
+		}
+	} else {
+		logger.Warn("no code execution backends, code execution disabled")
+	}
+	var codeExecService codeexecuc.Service
+	if execRouter != nil {
+		// программы-чекеры преподавателей выполняются только в песочнице
+		var customChecker codeexecuc.CustomChecker
+		if sbErr != nil {
+			logger.Warn("sandbox unavailable, custom output checkers disabled", "error", sbErr)
+		} else if customChecker, err = codeexecuc.NewSandboxChecker(sb, ""); err != nil {
+			logger.Warn("custom output checkers disabled", "error", err)
+		}
+		// кеш результатов: в Redis, общий для экземпляров; при сбое Redis и без него — в памяти
+		var resultCache codeexec.ResultCache
+		if cfg.CodeExecCacheEnabled {
+			ttl := time.Duration(cfg.CodeExecCacheTTLMin) * time.Minute
+			memCache := codeexec.NewMemoryCache(cfg.CodeExecCacheSize, ttl)
+			resultCache = memCache
+			if redisErr == nil {
+				resultCache = codeexec.NewRedisCache(rdb, ttl, memCache)
+			}
+		}
+		var analyzer codeexecuc.StaticAnalyzer
+		if cfg.StaticAnalysisEnabled {
+			analyzer = codeexecuc.NewStaticAnalyzer(lint.New(time.Duration(cfg.StaticAnalysisVetTimeoutSec)*time.Second), courseRepo)
+		}
+		codeExecService = codeexecuc.NewService(execRouter, customChecker, resultCache, analyzer, logger, memoryLimitKB)
+	}
+
+	// История попыток (только Postgres)
+	var submissionService submissionuc.Service
+	if submissionRepo != nil {
+		submissionService = submissionuc.NewService(submissionRepo)
+	}
+
+	// Автопроверка заданий Go-тестами
+	var gradingService gradinguc.Service
+	switch {
+	case !cfg.GraderEnabled:
+	case sbErr != nil && cfg.GraderSandboxRequired:
+		// решения студентов без песочницы не запускаем
+		logger.Warn("sandbox unavailable, assignment grading disabled", "error", sbErr)
+	default:
+		var runnerSandbox *sandbox.Sandbox
+		if sbErr != nil {
+			logger.Warn("sandbox unavailable, assignment grading is NOT isolated; use only on a developer machine", "error", sbErr)
+		} else {
+			runnerSandbox = sb
+		}
+		runner := gotest.NewLocalRunner(runnerSandbox, time.Duration(cfg.GraderTimeoutSec)*time.Second)
+		gradingService = gradinguc.NewService(assignmentService, lessonService, accessService, progressService, submissionService, runner, logger,
+			gradinguc.Options{
+				MaxConcurrent: cfg.GraderMaxConcurrent,
+				Bench: gotest.BenchOptions{
+					Count:     cfg.GraderBenchCount,
+					Benchtime: cfg.GraderBenchTime,
+					Timeout:   time.Duration(cfg.GraderBenchTimeoutSec) * time.Second,
+				},
+			})
+	}
+
+	// Пересчёт счётчиков курсов: по расписанию и по событиям записи/изменения уроков
+	statsService := courseuc.NewStatsService(
+		courseRepo, lessonRepo, enrollmentRepo, logger,
+		time.Duration(cfg.TrendingWindowDays)*24*time.Hour,
+		time.Duration(cfg.TrendingHalfLifeHours*float64(time.Hour)),
+	)
+	scheduler.Register(Job{
+		Name:     "course-stats",
+		Interval: time.Duration(cfg.StatsRecalcIntervalMin) * time.Minute,
+		Debounce: time.Duration(cfg.StatsDebounceSec) * time.Second,
+		Run: func(ctx context.Context, keys []string) error {
+			if keys == nil {
+				return statsService.RecalculateAll(ctx)
+			}
+			for _, k := range keys {
+				id, err := uuid.Parse(k)
+				if err != nil {
+					continue
+				}
+				if _, err := statsService.Recalculate(ctx, id); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+	// Поиск заимствований: по расписанию — задания с новыми попытками
+	var plagiarismService plagiarismuc.Service
+	if plagiarismRepo != nil && submissionRepo != nil {
+		plagiarismService = plagiarismuc.NewService(plagiarismRepo, submissionRepo, assignmentRepo, logger,
+			plagiarismuc.Options{Threshold: cfg.PlagiarismThreshold})
+		scheduler.Register(Job{
+			Name:     "plagiarism",
+			Interval: time.Duration(cfg.PlagiarismIntervalMin) * time.Minute,
+			Run: func(ctx context.Context, keys []string) error {
+				if keys == nil {
+					return plagiarismService.AnalyzeUpdated(ctx)
+				}
+				for _, k := range keys {
+					id, err := uuid.Parse(k)
+					if err != nil {
+						continue
+					}
+					if _, err := plagiarismService.Analyze(ctx, id); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		})
+	}
+	bus.Subscribe(func(ctx context.Context, e eventdomain.Event) {
+		scheduler.Trigger("course-stats", e.CourseID.String())
+	}, eventdomain.EnrollmentCreated, eventdomain.LessonCreated, eventdomain.LessonUpdated, eventdomain.LessonDeleted)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	scheduler.Start(jobsCtx)
+	if execRouter != nil {
+		execRouter.Start(jobsCtx)
 	}
+	defer func() {
+		stopJobs()
+		scheduler.Stop()
+	}()
 
-	router := httpdelivery.NewRouter(logger, courseService, authService, jwtManager, cfg, lessonService, assignmentService, progressService, enrollService, sectionService, moduleService, achievementService, dashboardService, aiService, codeExecService)
+	router := httpdelivery.NewRouter(logger, courseService, authService, jwtManager, cfg, lessonService, assignmentService, progressService, enrollService, sectionService, moduleService, achievementService, dashboardService, aiService, codeExecService, i18nService, quizService, revisionService, objectStorage, videoService, accessService, discussionService, md, noteService, gradingService, submissionService, codeJobService, judge0Callbacks, plagiarismService)
 	logger.Info("starting http server", "port", cfg.HTTPPort)
 	if err := router.Run(cfg.HTTPPort); err != nil {
 		logger.Error("http server stopped with error", "error", err)
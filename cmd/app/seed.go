@@ -28,7 +28,7 @@ func seedDev(ctx context.Context, cfg *ConfigLike, repos *Repositories, auth Aut
 		if len(courses) == 0 {
 			c := cdom.Course{ID: uuid.New(), Title: "Демо курс Go", Description: "Быстрый старт"}
 			_, _ = repos.Course.Create(ctx, c)
-			l := ldom.Lesson{ID: uuid.New(), CourseID: c.ID, Title: "Введение", Content: []byte("[]"), Order: 1}
+			l := ldom.Lesson{ID: uuid.New(), CourseID: c.ID, Title: "Введение", Type: ldom.TypeTheory, Content: []byte(`{"schema_version":1,"theory":"Добро пожаловать в курс!"}`), Order: 1}
 			_, _ = repos.Lesson.Create(ctx, l)
 			a := adom.Assignment{ID: uuid.New(), LessonID: l.ID, Title: "Hello", Prompt: "Напечатайте Hello", StarterCode: "package main\nimport \"fmt\"\nfunc main(){}", Tests: "[]", Order: 1}
 			_, _ = repos.Assignment.Create(ctx, a)
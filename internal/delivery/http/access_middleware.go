@@ -0,0 +1,94 @@
+package httpdelivery
+
+import (
+	"errors"
+
+	accessuc "github.com/example/learngo/internal/usecase/access"
+	discussionuc "github.com/example/learngo/internal/usecase/discussion"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CtxAccess решение о доступе, принятое LessonAccessRequired или DiscussionAccessRequired.
+const CtxAccess = "access"
+
+// LessonAccessRequired пропускает к уроку из параметра :id только тех, кому он доступен:
+// персонал, записанные на курс, бесплатный курс или урок-превью. Иначе — 402 с ценой курса.
+// Ставится после AuthRequired или OptionalAuth.
+func LessonAccessRequired(svc accessuc.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lessonID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			BadRequestError(c, "invalid id", nil)
+			c.Abort()
+			return
+		}
+		if !checkLessonAccess(c, svc, lessonID) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// DiscussionAccessRequired то же для треда или ответа (targetType) из параметра :id:
+// доступ проверяется к уроку, к которому относится обсуждение.
+func DiscussionAccessRequired(svc accessuc.Service, discussions discussionuc.Service, targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			BadRequestError(c, "invalid id", nil)
+			c.Abort()
+			return
+		}
+		lessonID, err := discussions.LessonOf(c.Request.Context(), targetType, id, actorFromContext(c))
+		if err != nil {
+			if errors.Is(err, discussionuc.ErrNotFound) {
+				NotFoundError(c, "discussion")
+			} else {
+				InternalError(c, "Failed to check access", err)
+			}
+			c.Abort()
+			return
+		}
+		if !checkLessonAccess(c, svc, lessonID) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkLessonAccess проверяет доступ к уроку и при отказе пишет ответ (404, 402 с ценой курса).
+func checkLessonAccess(c *gin.Context, svc accessuc.Service, lessonID uuid.UUID) bool {
+	uid, _ := UserIDFromContext(c)
+	d, err := svc.CheckLesson(c.Request.Context(), lessonID, uid, c.GetString(CtxRole))
+	if err != nil {
+		if errors.Is(err, accessuc.ErrLessonNotFound) || errors.Is(err, accessuc.ErrCourseNotFound) {
+			NotFoundError(c, "lesson")
+		} else {
+			InternalError(c, "Failed to check access", err)
+		}
+		return false
+	}
+	if !d.Allowed {
+		writePaywall(c, d, uid)
+		return false
+	}
+	c.Set(CtxAccess, d)
+	return true
+}
+
+func writePaywall(c *gin.Context, d accessuc.Decision, uid uuid.UUID) {
+	pw := accessuc.NewPaywall(d.Course, uid)
+	PaymentRequiredError(c, "Enroll in the course to access this content", map[string]interface{}{
+		"reason":         d.Reason,
+		"course_id":      pw.CourseID,
+		"course_slug":    pw.CourseSlug,
+		"course_title":   pw.CourseTitle,
+		"price":          pw.Price,
+		"price_cents":    pw.PriceCents,
+		"currency":       pw.Currency,
+		"login_required": pw.LoginRequired,
+	})
+}
@@ -1,9 +1,14 @@
 package httpdelivery
 
 import (
+	"errors"
 	"net/http"
 
+	assigndom "github.com/example/learngo/internal/domain/assignment"
+	codedom "github.com/example/learngo/internal/domain/code"
+	revisiondom "github.com/example/learngo/internal/domain/revision"
 	assignuc "github.com/example/learngo/internal/usecase/assignment"
+	revisionuc "github.com/example/learngo/internal/usecase/revision"
 	"github.com/example/learngo/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -11,6 +16,7 @@ import (
 
 type AssignmentHandler struct {
 	svc    assignuc.Service
+	revSvc revisionuc.Service // история изменений; может быть nil
 	logger *utils.Logger
 }
 
@@ -18,6 +24,17 @@ func NewAssignmentHandler(s assignuc.Service, logger *utils.Logger) *AssignmentH
 	return &AssignmentHandler{svc: s, logger: logger}
 }
 
+// recordRevision сохраняет ревизию задания; сбой истории не должен ломать сохранение.
+func (h *AssignmentHandler) recordRevision(c *gin.Context, a assigndom.Assignment) {
+	if h.revSvc == nil {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	if _, err := h.revSvc.RecordAssignment(c.Request.Context(), a, uid); err != nil {
+		h.logger.Error("failed to record assignment revision", "assignment_id", a.ID, "error", err)
+	}
+}
+
 func (h *AssignmentHandler) ListByLesson(c *gin.Context) {
 	lid, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -29,28 +46,42 @@ func (h *AssignmentHandler) ListByLesson(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		return
 	}
+	// скрытые тесты и эталон задания на скорость видят только авторы курса
+	if role := c.GetString(CtxRole); role != "admin" && role != "teacher" {
+		for i := range list {
+			list[i].HiddenTests = ""
+			list[i].Performance = list[i].Performance.Public()
+		}
+	}
 	c.JSON(http.StatusOK, list)
 }
 
 func (h *AssignmentHandler) Create(c *gin.Context) {
-	lid, err := uuid.Parse(c.Param("lessonId"))
+	lid, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lessonId"})
 		return
 	}
 	var req struct {
-		Title, Prompt, StarterCode, Tests string
-		Order                             int
+		Title, Prompt, StarterCode, Tests, HiddenTests string
+		StarterFiles                                   map[string]string
+		Performance                                    *assigndom.Performance
+		Order                                          int
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	a, err := h.svc.Create(c.Request.Context(), lid, req.Title, req.Prompt, req.StarterCode, req.Tests, req.Order)
+	a, err := h.svc.Create(c.Request.Context(), lid, req.Title, req.Prompt, req.StarterCode, req.StarterFiles, req.Tests, req.HiddenTests, req.Performance, req.Order)
+	if errors.Is(err, codedom.ErrInvalidFiles) || errors.Is(err, assigndom.ErrInvalidPerformance) || errors.Is(err, assigndom.ErrInvalidTests) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		return
 	}
+	h.recordRevision(c, a)
 	c.JSON(http.StatusCreated, a)
 }
 
@@ -69,6 +100,9 @@ func (h *AssignmentHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	if role := c.GetString(CtxRole); role != "admin" && role != "teacher" {
+		a.Performance = a.Performance.Public()
+	}
 	c.JSON(http.StatusOK, a)
 }
 
@@ -79,14 +113,25 @@ func (h *AssignmentHandler) Update(c *gin.Context) {
 		return
 	}
 	var req struct {
-		Title, Prompt, StarterCode, Tests string
-		Order                             int
+		Title, Prompt, StarterCode, Tests, HiddenTests string
+		StarterFiles                                   map[string]string
+		Performance                                    *assigndom.Performance
+		Order                                          int
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	a, err := h.svc.Update(c.Request.Context(), id, req.Title, req.Prompt, req.StarterCode, req.Tests, req.Order)
+	if h.revSvc != nil {
+		if err := h.revSvc.EnsureBaseline(c.Request.Context(), revisiondom.KindAssignment, id); err != nil {
+			h.logger.Error("failed to record assignment baseline revision", "assignment_id", id, "error", err)
+		}
+	}
+	a, err := h.svc.Update(c.Request.Context(), id, req.Title, req.Prompt, req.StarterCode, req.StarterFiles, req.Tests, req.HiddenTests, req.Performance, req.Order)
+	if errors.Is(err, codedom.ErrInvalidFiles) || errors.Is(err, assigndom.ErrInvalidPerformance) || errors.Is(err, assigndom.ErrInvalidTests) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		return
@@ -95,6 +140,7 @@ func (h *AssignmentHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	h.recordRevision(c, a)
 	c.JSON(http.StatusOK, a)
 }
 
@@ -1,10 +1,16 @@
 package httpdelivery
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	codedom "github.com/example/learngo/internal/domain/code"
 	codeexecuc "github.com/example/learngo/internal/usecase/codeexec"
+	"github.com/example/learngo/pkg/codeexec"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,12 +24,76 @@ func NewCodeHandler(s codeexecuc.Service) *CodeHandler {
 
 // Execute обрабатывает POST /api/code/execute
 func (h *CodeHandler) Execute(c *gin.Context) {
+	req, ok := bindExecuteRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp, err := h.svc.Execute(ctx, req)
+	if err != nil {
+		writeExecuteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ExecuteStream обрабатывает POST /api/code/execute/stream — то же, что Execute, но ответ
+// идёт как SSE: событие test на каждый тест по готовности, затем result (или error).
+func (h *CodeHandler) ExecuteStream(c *gin.Context) {
+	req, ok := bindExecuteRequest(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), jobStreamTimeout)
+	defer cancel()
+	events, err := h.svc.ExecuteStream(ctx, req)
+	if err != nil {
+		writeExecuteError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(jobStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			switch {
+			case ev.Test != nil:
+				fmt.Fprintf(c.Writer, "event: test\ndata: %s\n\n", mustJSON(ev.Test))
+			case ev.Err != nil:
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", mustJSON(gin.H{"message": "Failed to execute code", "error": ev.Err.Error()}))
+			default:
+				fmt.Fprintf(c.Writer, "event: result\ndata: %s\n\n", mustJSON(ev.Response))
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func bindExecuteRequest(c *gin.Context) (codedom.ExecuteRequest, bool) {
 	var req codedom.ExecuteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		ValidationError(c, "Invalid request body", map[string]interface{}{
 			"validation_error": err.Error(),
 		})
-		return
+		return req, false
 	}
 
 	// Валидация языка
@@ -39,15 +109,48 @@ func (h *CodeHandler) Execute(c *gin.Context) {
 			"language":            req.Language,
 			"supported_languages": []string{"python", "javascript", "java", "go", "cpp"},
 		})
-		return
+		return req, false
 	}
+	return req, true
+}
 
-	ctx := c.Request.Context()
-	resp, err := h.svc.Execute(ctx, req)
-	if err != nil {
+func writeExecuteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, codedom.ErrInvalidFiles) || errors.Is(err, codedom.ErrInvalidChecker) || errors.Is(err, codeexec.ErrMultiFileUnsupported) ||
+		errors.Is(err, codeexec.ErrUnsupportedLanguage):
+		ValidationError(c, err.Error(), nil)
+	case errors.Is(err, codeexec.ErrNoBackend):
+		ServiceUnavailableError(c, "code execution is temporarily unavailable")
+	default:
 		InternalError(c, "Failed to execute code", err)
-		return
 	}
+}
 
-	c.JSON(http.StatusOK, resp)
+// Backends обрабатывает GET /api/code/backends — состояние бэкендов выполнения (для администраторов).
+func (h *CodeHandler) Backends(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"backends": h.svc.Backends()})
+}
+
+// judge0CallbackMaxBytes предел тела уведомления: вывод программы в base64 плюс служебные поля.
+const judge0CallbackMaxBytes = 16 << 20
+
+// Judge0Callback обрабатывает PUT /internal/judge0/callbacks — уведомления Judge0 о готовых
+// submissions (callback_url). Запрос подписан секретом в параметре secret.
+func Judge0Callback(cb *codeexec.Callbacks) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, judge0CallbackMaxBytes))
+		if err != nil {
+			BadRequestError(c, "Invalid callback body", nil)
+			return
+		}
+		if err := cb.Deliver(c.Query("secret"), body); err != nil {
+			if errors.Is(err, codeexec.ErrCallbackForbidden) {
+				ForbiddenError(c, "invalid callback secret")
+				return
+			}
+			BadRequestError(c, err.Error(), nil)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
 }
@@ -0,0 +1,120 @@
+package httpdelivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	codejobuc "github.com/example/learngo/internal/usecase/codejob"
+	"github.com/example/learngo/pkg/codejob"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	jobStreamTimeout   = 5 * time.Minute
+	jobStreamKeepAlive = 15 * time.Second
+)
+
+type CodeJobHandler struct {
+	svc    codejobuc.Service
+	logger *utils.Logger
+}
+
+func NewCodeJobHandler(s codejobuc.Service, logger *utils.Logger) *CodeJobHandler {
+	return &CodeJobHandler{svc: s, logger: logger}
+}
+
+func (h *CodeJobHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, codejobuc.ErrNotFound):
+		NotFoundError(c, "job")
+	case errors.Is(err, codejobuc.ErrInvalidCode):
+		ValidationError(c, err.Error(), nil)
+	default:
+		InternalError(c, "Code job request failed", err)
+	}
+}
+
+// Submit POST /api/code/jobs — ставит код (code или дерево files) в очередь раннера,
+// отвечает 202 с ID задачи
+func (h *CodeJobHandler) Submit(c *gin.Context) {
+	var req struct {
+		Code     string            `json:"code" binding:"required_without=Files"`
+		Files    map[string]string `json:"files"`
+		Language string            `json:"language" binding:"required"`
+		Stdin    string            `json:"stdin"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	j, err := h.svc.Submit(c.Request.Context(), uid, codejobuc.Request{Code: req.Code, Files: req.Files, Language: req.Language, Stdin: req.Stdin})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"id":         j.ID,
+		"status":     j.Status,
+		"created_at": j.CreatedAt,
+		"status_url": "/api/code/jobs/" + j.ID,
+		"events_url": "/api/code/jobs/" + j.ID + "/events",
+	})
+}
+
+// Get GET /api/code/jobs/:id — статус и результат (пока не истёк TTL)
+func (h *CodeJobHandler) Get(c *gin.Context) {
+	uid, _ := UserIDFromContext(c)
+	j, err := h.svc.Get(c.Request.Context(), c.Param("id"), uid)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, j)
+}
+
+// Events GET /api/code/jobs/:id/events — SSE: событие status на каждый переход статуса,
+// поток закрывается после done/failed
+func (h *CodeJobHandler) Events(c *gin.Context) {
+	uid, _ := UserIDFromContext(c)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), jobStreamTimeout)
+	defer cancel()
+
+	updates, err := h.svc.Watch(ctx, c.Param("id"), uid)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(jobStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case j, ok := <-updates:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(c.Writer, "event: status\ndata: %s\n\n", mustJSON(j))
+			c.Writer.Flush()
+			if codejob.Terminal(j.Status) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package httpdelivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	discussiondom "github.com/example/learngo/internal/domain/discussion"
+	discussionuc "github.com/example/learngo/internal/usecase/discussion"
+	"github.com/example/learngo/pkg/markdown"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DiscussionHandler struct {
+	svc    discussionuc.Service
+	md     *markdown.Renderer
+	logger *utils.Logger
+}
+
+func NewDiscussionHandler(s discussionuc.Service, md *markdown.Renderer, logger *utils.Logger) *DiscussionHandler {
+	return &DiscussionHandler{svc: s, md: md, logger: logger}
+}
+
+type discussionRequest struct {
+	Title string                        `json:"title"`
+	Body  string                        `json:"body"`
+	Code  *discussiondom.CodeAttachment `json:"code"`
+}
+
+func (r discussionRequest) input() discussionuc.Input {
+	return discussionuc.Input{Title: r.Title, Body: r.Body, Code: r.Code}
+}
+
+func (h *DiscussionHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, discussionuc.ErrNotFound):
+		NotFoundError(c, "discussion")
+	case errors.Is(err, discussionuc.ErrForbidden):
+		ForbiddenError(c, err.Error())
+	case errors.Is(err, discussionuc.ErrNotEnrolled), errors.Is(err, discussionuc.ErrNoAccess):
+		ForbiddenError(c, err.Error())
+	case errors.Is(err, discussionuc.ErrLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, discussiondom.ErrInvalid):
+		ValidationError(c, err.Error(), nil)
+	default:
+		InternalError(c, "Discussion request failed", err)
+	}
+}
+
+func actorFromContext(c *gin.Context) discussionuc.Actor {
+	uid, _ := UserIDFromContext(c)
+	return discussionuc.Actor{UserID: uid, Role: c.GetString(CtxRole)}
+}
+
+func pageParams(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	size, _ := strconv.Atoi(c.Query("page_size"))
+	return page, size
+}
+
+// render заполняет body_html; ошибка рендеринга не должна скрывать само сообщение.
+func (h *DiscussionHandler) render(body string) string {
+	if h.md == nil || body == "" {
+		return ""
+	}
+	res, err := h.md.Render(body)
+	if err != nil {
+		h.logger.Warn("failed to render discussion markdown", "error", err)
+		return ""
+	}
+	return res.HTML
+}
+
+func (h *DiscussionHandler) renderNodes(nodes []*discussiondom.PostNode) {
+	for _, n := range nodes {
+		n.BodyHTML = h.render(n.Body)
+		h.renderNodes(n.Replies)
+	}
+}
+
+// ListThreads GET /api/lessons/:id/threads?sort=active|new|top|unanswered&page=&page_size=
+func (h *DiscussionHandler) ListThreads(c *gin.Context) {
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	page, size := pageParams(c)
+	res, err := h.svc.ListThreads(c.Request.Context(), lessonID, actorFromContext(c), c.Query("sort"), page, size)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateThread POST /api/lessons/:id/threads
+func (h *DiscussionHandler) CreateThread(c *gin.Context) {
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req discussionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	t, err := h.svc.CreateThread(c.Request.Context(), lessonID, actorFromContext(c), req.input())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	t.BodyHTML = h.render(t.Body)
+	c.JSON(http.StatusCreated, t)
+}
+
+// GetThread GET /api/threads/:id?page=&page_size= — тред с деревом ответов
+func (h *DiscussionHandler) GetThread(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	page, size := pageParams(c)
+	view, err := h.svc.GetThread(c.Request.Context(), id, actorFromContext(c), page, size)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	view.Thread.BodyHTML = h.render(view.Thread.Body)
+	h.renderNodes(view.Posts)
+	c.JSON(http.StatusOK, view)
+}
+
+// UpdateThread PUT /api/threads/:id — автор или модератор
+func (h *DiscussionHandler) UpdateThread(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req discussionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	t, err := h.svc.UpdateThread(c.Request.Context(), id, actorFromContext(c), req.input())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	t.BodyHTML = h.render(t.Body)
+	c.JSON(http.StatusOK, t)
+}
+
+// DeleteThread DELETE /api/threads/:id
+func (h *DiscussionHandler) DeleteThread(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteThread(c.Request.Context(), id, actorFromContext(c)); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Reply POST /api/threads/:id/posts — body: {parent_id?, body, code?}
+func (h *DiscussionHandler) Reply(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		discussionRequest
+		ParentID *uuid.UUID `json:"parent_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	p, err := h.svc.Reply(c.Request.Context(), id, req.ParentID, actorFromContext(c), req.input())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	p.BodyHTML = h.render(p.Body)
+	c.JSON(http.StatusCreated, p)
+}
+
+// UpdatePost PUT /api/posts/:id
+func (h *DiscussionHandler) UpdatePost(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req discussionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	p, err := h.svc.UpdatePost(c.Request.Context(), id, actorFromContext(c), req.input())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	p.BodyHTML = h.render(p.Body)
+	c.JSON(http.StatusOK, p)
+}
+
+// DeletePost DELETE /api/posts/:id
+func (h *DiscussionHandler) DeletePost(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.svc.DeletePost(c.Request.Context(), id, actorFromContext(c)); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Vote возвращает обработчик POST (голос) / DELETE (снять голос) /api/{threads|posts}/:id/vote
+func (h *DiscussionHandler) Vote(targetType string, up bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseUUIDParam(c, "id")
+		if !ok {
+			return
+		}
+		n, err := h.svc.Vote(c.Request.Context(), targetType, id, actorFromContext(c), up)
+		if err != nil {
+			h.writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"upvotes": n, "voted": up})
+	}
+}
+
+// Accept PUT /api/threads/:id/accepted — body: {post_id} (null снимает отметку)
+func (h *DiscussionHandler) Accept(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		PostID *uuid.UUID `json:"post_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	t, err := h.svc.Accept(c.Request.Context(), id, req.PostID, actorFromContext(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Moderate возвращает обработчик POST /api/{threads|posts}/:id/moderation — body: {action}
+func (h *DiscussionHandler) Moderate(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseUUIDParam(c, "id")
+		if !ok {
+			return
+		}
+		var req struct {
+			Action string `json:"action" binding:"required,oneof=hide restore lock unlock"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+			return
+		}
+		if err := h.svc.Moderate(c.Request.Context(), targetType, id, actorFromContext(c), req.Action); err != nil {
+			h.writeError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
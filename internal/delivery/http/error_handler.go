@@ -108,6 +108,14 @@ func BadRequestError(c *gin.Context, message string, details map[string]interfac
 	ErrorResponse(c, http.StatusBadRequest, errors.ErrCodeBadRequest, message, details)
 }
 
+// PaymentRequiredError отправляет paywall: контент доступен после записи на курс (details — цена курса)
+func PaymentRequiredError(c *gin.Context, message string, details map[string]interface{}) {
+	if message == "" {
+		message = errors.MsgPaymentRequired
+	}
+	ErrorResponse(c, http.StatusPaymentRequired, errors.ErrCodePaymentRequired, message, details)
+}
+
 // ErrorHandlerMiddleware обрабатывает панику и возвращает стандартизированную ошибку
 func ErrorHandlerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,56 @@
+package httpdelivery
+
+import (
+	"errors"
+	"net/http"
+
+	gradinguc "github.com/example/learngo/internal/usecase/grading"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type GradingHandler struct {
+	svc    gradinguc.Service
+	logger *utils.Logger
+}
+
+func NewGradingHandler(s gradinguc.Service, logger *utils.Logger) *GradingHandler {
+	return &GradingHandler{svc: s, logger: logger}
+}
+
+func (h *GradingHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gradinguc.ErrNotFound):
+		NotFoundError(c, "assignment")
+	case errors.Is(err, gradinguc.ErrNoTests):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gradinguc.ErrNoAccess):
+		ForbiddenError(c, err.Error())
+	case errors.Is(err, gradinguc.ErrInvalidCode):
+		ValidationError(c, err.Error(), nil)
+	default:
+		InternalError(c, "Grading failed", err)
+	}
+}
+
+// Grade POST /api/assignments/:id/grade — body: {code}
+func (h *GradingHandler) Grade(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	v, err := h.svc.Grade(c.Request.Context(), id, uid, c.GetString(CtxRole), req.Code)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
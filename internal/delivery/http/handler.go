@@ -7,6 +7,7 @@ import (
 	coursedom "github.com/example/learngo/internal/domain/course"
 	courseuc "github.com/example/learngo/internal/usecase/course"
 	enrolluc "github.com/example/learngo/internal/usecase/enrollment"
+	i18nuc "github.com/example/learngo/internal/usecase/i18n"
 	lessonuc "github.com/example/learngo/internal/usecase/lesson"
 	moduleuc "github.com/example/learngo/internal/usecase/module"
 	"github.com/example/learngo/pkg/utils"
@@ -20,6 +21,7 @@ type CourseHandler struct {
 	enrollmentSvc enrolluc.Service
 	lessonSvc     lessonuc.Service
 	moduleSvc     moduleuc.Service
+	i18nSvc       i18nuc.Service
 	logger        *utils.Logger
 }
 
@@ -29,7 +31,7 @@ func NewCourseHandler(service courseuc.Service, logger *utils.Logger) *CourseHan
 }
 
 func (h *CourseHandler) List(c *gin.Context) {
-	// Параметры согласно документации: language, difficulty, page, limit
+	// Параметры согласно документации: language, difficulty, page, limit, sort
 	language := c.Query("language")
 	difficulty := c.Query("difficulty")
 	page := parseIntDefault(c.Query("page"), 1)
@@ -44,6 +46,7 @@ func (h *CourseHandler) List(c *gin.Context) {
 		Difficulty: difficulty,
 		Page:       page,
 		PageSize:   limit,
+		Sort:       c.Query("sort"), // title_asc|title_desc|popularity_desc|trending_desc|rating_desc|newest
 	}
 
 	res, err := h.service.SearchCourses(c.Request.Context(), filter)
@@ -60,8 +63,12 @@ func (h *CourseHandler) List(c *gin.Context) {
 	}
 
 	// Преобразуем курсы в формат API
-	courses := make([]gin.H, 0, len(res.Items))
-	for _, course := range res.Items {
+	items := res.Items
+	if h.i18nSvc != nil {
+		items = h.i18nSvc.LocalizeCourses(c.Request.Context(), items, LocaleFromContext(c))
+	}
+	courses := make([]gin.H, 0, len(items))
+	for _, course := range items {
 		courses = append(courses, h.courseToAPIResponse(course))
 	}
 
@@ -95,6 +102,8 @@ func (h *CourseHandler) courseToAPIResponse(course coursedom.Course) gin.H {
 		"duration_hours": course.DurationHours,
 		"lessons_count":  course.LessonsCount,
 		"students_count": course.StudentsCount,
+		"popularity":     course.Popularity,
+		"trending_score": course.TrendingScore,
 		"rating":         course.Rating,
 		"thumbnail_url":  course.ThumbnailURL,
 		"is_free":        course.IsFree,
@@ -114,6 +123,9 @@ type createCourseRequest struct {
 	Requirements  []string `json:"requirements"`
 	IsFree        bool     `json:"is_free"`
 	Price         *float64 `json:"price"`
+	SourceLocale  string   `json:"source_locale"`
+	// Analyzers проверки статического анализа решений; не задано — все, [] — анализ выключен
+	Analyzers []string `json:"analyzers" binding:"omitempty,dive,oneof=vet gofmt unusedresult shadow errcheck ineffassign"`
 }
 
 func (h *CourseHandler) Create(c *gin.Context) {
@@ -138,6 +150,8 @@ func (h *CourseHandler) Create(c *gin.Context) {
 			ca.Requirements = req.Requirements
 			ca.IsFree = req.IsFree
 			ca.Price = req.Price
+			ca.SourceLocale = req.SourceLocale
+			ca.Analyzers = req.Analyzers
 		},
 	)
 	if err != nil {
@@ -165,6 +179,9 @@ func (h *CourseHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		return
 	}
+	if h.i18nSvc != nil {
+		crs = h.i18nSvc.LocalizeCourse(c.Request.Context(), crs, LocaleFromContext(c))
+	}
 
 	// Получаем модули и уроки для детального ответа
 	ctx := c.Request.Context()
@@ -181,9 +198,9 @@ func (h *CourseHandler) Get(c *gin.Context) {
 				"id":               lesson.ID.String(),
 				"title":            lesson.Title,
 				"slug":             lesson.Slug,
-				"duration_minutes": 0, // TODO: добавить в модель урока
+				"duration_minutes": lesson.DurationMinutes,
 				"order":            lesson.Order,
-				"is_free":          false, // TODO: добавить в модель урока
+				"is_free":          lesson.IsFree,
 			})
 		}
 
@@ -207,12 +224,17 @@ func (h *CourseHandler) Get(c *gin.Context) {
 		"id":                crs.ID.String(),
 		"slug":              crs.Slug,
 		"title":             crs.Title,
+		"summary":           crs.Summary,
 		"description":       crs.Description,
+		"source_locale":     crs.SourceLocale,
 		"language":          crs.Language,
 		"difficulty":        crs.Difficulty,
 		"duration_hours":    crs.DurationHours,
+		"duration_minutes":  crs.DurationMin,
 		"lessons_count":     crs.LessonsCount,
 		"students_count":    crs.StudentsCount,
+		"popularity":        crs.Popularity,
+		"trending_score":    crs.TrendingScore,
 		"rating":            crs.Rating,
 		"requirements":      crs.Requirements,
 		"learning_outcomes": crs.Objectives,
@@ -239,6 +261,9 @@ func (h *CourseHandler) GetBySlug(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		return
 	}
+	if h.i18nSvc != nil {
+		crs = h.i18nSvc.LocalizeCourse(c.Request.Context(), crs, LocaleFromContext(c))
+	}
 	if uid, ok := UserIDFromContext(c); ok && h.enrollmentSvc != nil {
 		// найдём ID курса
 		id := crs.ID
@@ -273,6 +298,9 @@ type updateCourseRequest struct {
 	Requirements  []string `json:"requirements"`
 	IsFree        bool     `json:"is_free"`
 	Price         *float64 `json:"price"`
+	SourceLocale  string   `json:"source_locale"`
+	// Analyzers проверки статического анализа решений; не задано — все, [] — анализ выключен
+	Analyzers []string `json:"analyzers" binding:"omitempty,dive,oneof=vet gofmt unusedresult shadow errcheck ineffassign"`
 }
 
 func (h *CourseHandler) Update(c *gin.Context) {
@@ -299,6 +327,8 @@ func (h *CourseHandler) Update(c *gin.Context) {
 		Requirements:  req.Requirements,
 		IsFree:        req.IsFree,
 		Price:         req.Price,
+		SourceLocale:  req.SourceLocale,
+		Analyzers:     req.Analyzers,
 	})
 	if err != nil {
 		if err == courseuc.ErrNotFound {
@@ -336,3 +366,27 @@ func parseIntDefault(s string, def int) int {
 	}
 	return def
 }
+
+// parseUUIDParam читает UUID из параметра пути; при ошибке отвечает 400 и возвращает false.
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		BadRequestError(c, "invalid "+name, nil)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseUUIDQuery разбирает необязательный UUID из query; пустое значение — nil.
+func parseUUIDQuery(c *gin.Context, name string) (*uuid.UUID, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		BadRequestError(c, "invalid "+name, nil)
+		return nil, false
+	}
+	return &id, true
+}
@@ -2,24 +2,135 @@ package httpdelivery
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	codedom "github.com/example/learngo/internal/domain/code"
 	lessondom "github.com/example/learngo/internal/domain/lesson"
+	revisiondom "github.com/example/learngo/internal/domain/revision"
+	accessuc "github.com/example/learngo/internal/usecase/access"
+	codeexecuc "github.com/example/learngo/internal/usecase/codeexec"
+	i18nuc "github.com/example/learngo/internal/usecase/i18n"
 	lessonuc "github.com/example/learngo/internal/usecase/lesson"
+	revisionuc "github.com/example/learngo/internal/usecase/revision"
+	"github.com/example/learngo/pkg/codeexec"
+	"github.com/example/learngo/pkg/markdown"
 	"github.com/example/learngo/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type LessonHandler struct {
-	svc    lessonuc.Service
-	logger *utils.Logger
+	svc     lessonuc.Service
+	i18nSvc i18nuc.Service
+	codeSvc codeexecuc.Service // для запуска фрагментов из теории; может быть nil
+	revSvc  revisionuc.Service // история изменений; может быть nil
+	access  accessuc.Service   // без него контент уроков в списках не скрывается
+	md      *markdown.Renderer
+	logger  *utils.Logger
 }
 
 func NewLessonHandler(s lessonuc.Service, logger *utils.Logger) *LessonHandler {
 	return &LessonHandler{svc: s, logger: logger}
 }
 
+type lessonRequest struct {
+	Title     string          `json:"title"`
+	Type      string          `json:"type"` // theory|video|quiz|task
+	Content   json.RawMessage `json:"content"`
+	Order     int             `json:"order"`
+	SectionID string          `json:"sectionId"`
+}
+
+// content принимает контент и объектом, и строкой с JSON (как присылали старые клиенты).
+func (r lessonRequest) content() string {
+	var s string
+	if err := json.Unmarshal(r.Content, &s); err == nil {
+		return s
+	}
+	return string(r.Content)
+}
+
+// writeError отвечает 400 на невалидный контент или порядок, 404 на неизвестный модуль, иначе 500.
+func (h *LessonHandler) writeError(c *gin.Context, err error) {
+	var verr *lessondom.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		ValidationError(c, "Invalid lesson content", map[string]interface{}{"fields": verr.Errors})
+		return
+	case errors.Is(err, lessonuc.ErrInvalidOrder):
+		ValidationError(c, err.Error(), nil)
+		return
+	case errors.Is(err, lessonuc.ErrModuleNotFound):
+		NotFoundError(c, "module")
+		return
+	}
+	h.logger.Error("lesson request failed", "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+}
+
+// recordRevision сохраняет ревизию урока; сбой истории не должен ломать сохранение урока.
+func (h *LessonHandler) recordRevision(c *gin.Context, l lessondom.Lesson) {
+	if h.revSvc == nil {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	if _, err := h.revSvc.RecordLesson(c.Request.Context(), l, uid); err != nil {
+		h.logger.Error("failed to record lesson revision", "lesson_id", l.ID, "error", err)
+	}
+}
+
+// redactLocked убирает контент уроков, недоступных вызывающему; структура курса остаётся видна.
+// Из доступного контента студенту не отдаются ответы на тесты.
+func (h *LessonHandler) redactLocked(c *gin.Context, list []lessondom.Lesson) error {
+	uid, _ := UserIDFromContext(c)
+	role := c.GetString(CtxRole)
+	allowed := map[uuid.UUID]bool{}
+	for i := range list {
+		if h.access == nil {
+			continue
+		}
+		ok, seen := allowed[list[i].CourseID]
+		if !seen {
+			d, err := h.access.CheckCourse(c.Request.Context(), list[i].CourseID, uid, role)
+			if err != nil && !errors.Is(err, accessuc.ErrCourseNotFound) {
+				return err
+			}
+			ok = d.Allowed
+			allowed[list[i].CourseID] = ok
+		}
+		if !ok && !list[i].IsFree {
+			list[i].Content = nil
+		}
+	}
+	if isStaffRole(role) {
+		return nil
+	}
+	for i := range list {
+		if list[i].Content != nil {
+			list[i].Content = hideAnswers(list[i])
+		}
+	}
+	return nil
+}
+
+func isStaffRole(role string) bool { return role == "admin" || role == "teacher" }
+
+// hideAnswers контент урока без ожидаемого вывода и чекеров (lessondom.HideAnswers);
+// контент, который не декодируется, не отдаётся.
+func hideAnswers(l lessondom.Lesson) json.RawMessage {
+	typed, err := lessondom.DecodeContent(l.Type, l.Content)
+	if err != nil {
+		return nil
+	}
+	lessondom.HideAnswers(typed)
+	raw, err := json.Marshal(typed)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
 func (h *LessonHandler) ListByCourse(c *gin.Context) {
 	cid, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -31,6 +142,10 @@ func (h *LessonHandler) ListByCourse(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		return
 	}
+	if err := h.redactLocked(c, list); err != nil {
+		h.writeError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, list)
 }
 
@@ -45,6 +160,10 @@ func (h *LessonHandler) ListBySection(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 		return
 	}
+	if err := h.redactLocked(c, list); err != nil {
+		h.writeError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, list)
 }
 
@@ -54,31 +173,29 @@ func (h *LessonHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courseId"})
 		return
 	}
-	var req struct {
-		Title, Content string
-		Order          int
-		SectionID      string `json:"sectionId"`
-	}
+	var req lessonRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	if req.SectionID != "" {
 		if sid, e := uuid.Parse(req.SectionID); e == nil {
-			l, err := h.svc.CreateInSection(c.Request.Context(), cid, sid, req.Title, req.Content, req.Order)
+			l, err := h.svc.CreateInSection(c.Request.Context(), cid, sid, req.Title, req.Type, req.content(), req.Order)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+				h.writeError(c, err)
 				return
 			}
+			h.recordRevision(c, l)
 			c.JSON(http.StatusCreated, l)
 			return
 		}
 	}
-	l, err := h.svc.Create(c.Request.Context(), cid, req.Title, req.Content, req.Order)
+	l, err := h.svc.Create(c.Request.Context(), cid, req.Title, req.Type, req.content(), req.Order)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		h.writeError(c, err)
 		return
 	}
+	h.recordRevision(c, l)
 	c.JSON(http.StatusCreated, l)
 }
 
@@ -97,15 +214,19 @@ func (h *LessonHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	if h.i18nSvc != nil {
+		l = h.i18nSvc.LocalizeLesson(c.Request.Context(), l, LocaleFromContext(c))
+	}
 
-	// Парсим Content из JSON
-	var content lessondom.LessonContent
-	if len(l.Content) > 0 {
-		if err := json.Unmarshal(l.Content, &content); err != nil {
-			h.logger.Error("failed to parse lesson content", "error", err)
-			// Используем пустую структуру если не удалось распарсить
-			content = lessondom.LessonContent{}
+	// Парсим Content в структуру по типу урока; ответы на тесты студенту не отдаются
+	var content interface{} = lessondom.LessonContent{}
+	if typed, err := lessondom.DecodeContent(l.Type, l.Content); err == nil {
+		if !isStaffRole(c.GetString(CtxRole)) {
+			lessondom.HideAnswers(typed)
 		}
+		content = typed
+	} else {
+		h.logger.Error("failed to parse lesson content", "error", err)
 	}
 
 	// Формируем ответ согласно документации
@@ -115,6 +236,7 @@ func (h *LessonHandler) Get(c *gin.Context) {
 		"module_id":        l.ModuleID.String(),
 		"title":            l.Title,
 		"slug":             l.Slug,
+		"type":             lessondom.NormalizeType(l.Type),
 		"content":          content,
 		"duration_minutes": l.DurationMinutes,
 		"order":            l.Order,
@@ -126,21 +248,157 @@ func (h *LessonHandler) Get(c *gin.Context) {
 	if l.PreviousLessonID != nil {
 		response["previous_lesson_id"] = l.PreviousLessonID.String()
 	}
+	if typed, ok := content.(lessondom.Content); ok && h.md != nil && typed.Base().Theory != "" {
+		if rendered, err := h.md.Render(typed.Base().Theory); err == nil {
+			response["theory_html"] = rendered.HTML
+			response["snippets"] = rendered.Snippets
+		} else {
+			h.logger.Error("failed to render lesson theory", "lesson_id", l.ID, "error", err)
+		}
+	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// RunSnippet POST /api/lessons/:id/snippets/:snippetId/run — выполняет фрагмент ```go run из теории урока.
+// Код берётся с сервера по ID фрагмента, клиент может передать только stdin.
+func (h *LessonHandler) RunSnippet(c *gin.Context) {
+	if h.codeSvc == nil || h.md == nil {
+		ServiceUnavailableError(c, "code execution is not configured")
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Stdin string `json:"stdin"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+			return
+		}
+	}
+	l, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if l.ID == uuid.Nil {
+		NotFoundError(c, "lesson")
+		return
+	}
+	if h.i18nSvc != nil {
+		l = h.i18nSvc.LocalizeLesson(c.Request.Context(), l, LocaleFromContext(c))
+	}
+	typed, err := lessondom.DecodeContent(l.Type, l.Content)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	rendered, err := h.md.Render(typed.Base().Theory)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	snippet, found := rendered.Snippet(c.Param("snippetId"))
+	if !found {
+		NotFoundError(c, "snippet")
+		return
+	}
+	if snippet.Language != "go" {
+		BadRequestError(c, "only go snippets can be run", nil)
+		return
+	}
+	resp, err := h.codeSvc.Execute(c.Request.Context(), codedom.ExecuteRequest{Code: snippet.Code, Language: snippet.Language, Stdin: req.Stdin, CourseID: l.CourseID.String()})
+	if errors.Is(err, codeexec.ErrNoBackend) {
+		ServiceUnavailableError(c, "code execution is temporarily unavailable")
+		return
+	}
+	if err != nil {
+		InternalError(c, "Failed to execute code", err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RunTests POST /api/lessons/:id/tests/run — проверяет решение тестами урока. Ожидаемый вывод
+// и чекеры берутся из контента урока на сервере; клиент выбирает тесты по номерам (tests).
+// Студенту в результатах тестов не возвращаются ни ожидаемый вывод, ни чекер.
+func (h *LessonHandler) RunTests(c *gin.Context) {
+	if h.codeSvc == nil {
+		ServiceUnavailableError(c, "code execution is not configured")
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Code     string            `json:"code" binding:"required_without=Files"`
+		Files    map[string]string `json:"files,omitempty"`
+		Language string            `json:"language" binding:"required,oneof=python javascript java go cpp"`
+		Tests    []int             `json:"tests,omitempty"` // номера тестов урока с 0; пустой — все
+		NoCache  bool              `json:"no_cache,omitempty"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	l, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if l.ID == uuid.Nil {
+		NotFoundError(c, "lesson")
+		return
+	}
+	typed, err := lessondom.DecodeContent(l.Type, l.Content)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	tests := typed.Base().Tests()
+	if len(tests) == 0 {
+		BadRequestError(c, "lesson has no tests", nil)
+		return
+	}
+	if len(req.Tests) == 0 {
+		for i := range tests {
+			req.Tests = append(req.Tests, i)
+		}
+	}
+	exec := codedom.ExecuteRequest{Code: req.Code, Files: req.Files, Language: req.Language, CourseID: l.CourseID.String(), NoCache: req.NoCache, LessonTests: true}
+	for _, n := range req.Tests {
+		if n < 0 || n >= len(tests) {
+			ValidationError(c, "Unknown test", map[string]interface{}{"test": n, "tests": len(tests)})
+			return
+		}
+		tc := tests[n]
+		exec.TestCases = append(exec.TestCases, codedom.TestCase{Input: tc.Input, ExpectedOutput: tc.ExpectedOutput, Description: tc.Description, Checker: tc.Checker})
+	}
+	resp, err := h.codeSvc.Execute(c.Request.Context(), exec)
+	if err != nil {
+		writeExecuteError(c, err)
+		return
+	}
+	if !isStaffRole(c.GetString(CtxRole)) {
+		for i, tr := range resp.TestResults {
+			resp.TestResults[i].TestCase = codedom.TestCase{Input: tr.TestCase.Input, Description: tr.TestCase.Description}
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *LessonHandler) Update(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
-	var req struct {
-		Title, Content string
-		Order          int
-		SectionID      string `json:"sectionId"`
-	}
+	var req lessonRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -151,15 +409,21 @@ func (h *LessonHandler) Update(c *gin.Context) {
 			sid = parsed
 		}
 	}
-	l, err := h.svc.Update(c.Request.Context(), id, req.Title, req.Content, req.Order, sid)
+	if h.revSvc != nil {
+		if err := h.revSvc.EnsureBaseline(c.Request.Context(), revisiondom.KindLesson, id); err != nil {
+			h.logger.Error("failed to record lesson baseline revision", "lesson_id", id, "error", err)
+		}
+	}
+	l, err := h.svc.Update(c.Request.Context(), id, req.Title, req.Type, req.content(), req.Order, sid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		h.writeError(c, err)
 		return
 	}
 	if l.ID == uuid.Nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	h.recordRevision(c, l)
 	c.JSON(http.StatusOK, l)
 }
 
@@ -175,3 +439,61 @@ func (h *LessonHandler) Delete(c *gin.Context) {
 	}
 	c.Status(http.StatusNoContent)
 }
+
+// MigrateContents POST /api/courses/:id/lessons/migrate-content — поднимает контент уроков курса до текущей схемы.
+func (h *LessonHandler) MigrateContents(c *gin.Context) {
+	cid, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid courseId"})
+		return
+	}
+	n, err := h.svc.MigrateContents(c.Request.Context(), cid)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"migrated": n, "schema_version": lessondom.ContentSchemaVersion})
+}
+
+// ReorderLessons PUT /api/modules/:id/lessons/order — полный список уроков модуля в новом порядке.
+// Уроки из других модулей того же курса переносятся в этот модуль.
+func (h *LessonHandler) ReorderLessons(c *gin.Context) {
+	moduleID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		LessonIDs []uuid.UUID `json:"lesson_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	list, err := h.svc.ReorderLessons(c.Request.Context(), moduleID, req.LessonIDs)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// ReorderModules PUT /api/courses/:id/modules/order — все модули курса в новом порядке.
+func (h *LessonHandler) ReorderModules(c *gin.Context) {
+	courseID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		ModuleIDs []uuid.UUID `json:"module_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	mods, err := h.svc.ReorderModules(c.Request.Context(), courseID, req.ModuleIDs)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, mods)
+}
@@ -3,6 +3,7 @@ package httpdelivery
 import (
 	"strings"
 
+	i18nuc "github.com/example/learngo/internal/usecase/i18n"
 	"github.com/example/learngo/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -11,6 +12,7 @@ import (
 const (
 	CtxUserID = "userId"
 	CtxRole   = "role"
+	CtxLocale = "locale"
 )
 
 // AuthRequired валидирует Bearer-токен и кладёт userId/role в контекст.
@@ -35,6 +37,20 @@ func AuthRequired(jwt *utils.JWTManager) gin.HandlerFunc {
 	}
 }
 
+// OptionalAuth как AuthRequired, но без токена (или с невалидным) пропускает запрос анонимно.
+func OptionalAuth(jwt *utils.JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") {
+			if claims, err := jwt.Verify(parts[1]); err == nil {
+				c.Set(CtxUserID, claims.UserID)
+				c.Set(CtxRole, claims.Role)
+			}
+		}
+		c.Next()
+	}
+}
+
 // UserIDFromContext helper.
 func UserIDFromContext(c *gin.Context) (uuid.UUID, bool) {
 	v, ok := c.Get(CtxUserID)
@@ -61,3 +77,27 @@ func RequireRoles(roles ...string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// LocaleMiddleware определяет локаль запроса: ?lang -> профиль пользователя -> Accept-Language -> по умолчанию.
+// На публичных маршрутах токен необязателен: если он есть и валиден, учитываем язык из профиля.
+func LocaleMiddleware(jwt *utils.JWTManager, svc i18nuc.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var uid uuid.UUID
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") {
+			if claims, err := jwt.Verify(parts[1]); err == nil {
+				uid = claims.UserID
+			}
+		}
+		locale := svc.ResolveLocale(c.Request.Context(), c.Query("lang"), uid, c.GetHeader("Accept-Language"))
+		c.Set(CtxLocale, locale)
+		c.Header("Content-Language", locale)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// LocaleFromContext возвращает локаль, определённую LocaleMiddleware (пусто, если i18n выключен).
+func LocaleFromContext(c *gin.Context) string {
+	return c.GetString(CtxLocale)
+}
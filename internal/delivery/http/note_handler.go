@@ -0,0 +1,207 @@
+package httpdelivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	notedom "github.com/example/learngo/internal/domain/note"
+	noteuc "github.com/example/learngo/internal/usecase/note"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NoteHandler struct {
+	svc    noteuc.Service
+	logger *utils.Logger
+}
+
+func NewNoteHandler(s noteuc.Service, logger *utils.Logger) *NoteHandler {
+	return &NoteHandler{svc: s, logger: logger}
+}
+
+func (h *NoteHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, noteuc.ErrNotFound):
+		NotFoundError(c, "note")
+	case errors.Is(err, noteuc.ErrLessonNotFound):
+		NotFoundError(c, "lesson")
+	case errors.Is(err, noteuc.ErrCourseNotFound):
+		NotFoundError(c, "course")
+	case errors.Is(err, noteuc.ErrSnippetNotFound):
+		NotFoundError(c, "snippet")
+	case errors.Is(err, noteuc.ErrNoAccess):
+		ForbiddenError(c, err.Error())
+	case errors.Is(err, notedom.ErrInvalid):
+		ValidationError(c, err.Error(), nil)
+	default:
+		InternalError(c, "Notes request failed", err)
+	}
+}
+
+// ListNotes GET /api/users/me/notes?course_id=&lesson_id=
+func (h *NoteHandler) ListNotes(c *gin.Context) {
+	uid, _ := UserIDFromContext(c)
+	courseID, ok := parseUUIDQuery(c, "course_id")
+	if !ok {
+		return
+	}
+	lessonID, ok := parseUUIDQuery(c, "lesson_id")
+	if !ok {
+		return
+	}
+	notes, err := h.svc.ListNotes(c.Request.Context(), uid, courseID, lessonID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"notes": notes})
+}
+
+// CreateNote POST /api/users/me/notes — body: {lesson_id, snippet_id?, body}
+func (h *NoteHandler) CreateNote(c *gin.Context) {
+	var req struct {
+		LessonID  uuid.UUID `json:"lesson_id" binding:"required"`
+		SnippetID string    `json:"snippet_id"`
+		Body      string    `json:"body"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	n, err := h.svc.CreateNote(c.Request.Context(), uid, c.GetString(CtxRole), req.LessonID, req.SnippetID, req.Body)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, n)
+}
+
+// GetNote GET /api/users/me/notes/:id
+func (h *NoteHandler) GetNote(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	n, err := h.svc.GetNote(c.Request.Context(), uid, id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, n)
+}
+
+// UpdateNote PUT /api/users/me/notes/:id — body: {body}
+func (h *NoteHandler) UpdateNote(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	n, err := h.svc.UpdateNote(c.Request.Context(), uid, id, req.Body)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, n)
+}
+
+// DeleteNote DELETE /api/users/me/notes/:id
+func (h *NoteHandler) DeleteNote(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	if err := h.svc.DeleteNote(c.Request.Context(), uid, id); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SearchNotes GET /api/users/me/notes/search?q=&limit=
+func (h *NoteHandler) SearchNotes(c *gin.Context) {
+	uid, _ := UserIDFromContext(c)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	hits, err := h.svc.Search(c.Request.Context(), uid, c.Query("q"), limit)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"results": hits})
+}
+
+// ExportNotes GET /api/users/me/notes/export?course_id= — все заметки курса одним Markdown-файлом
+func (h *NoteHandler) ExportNotes(c *gin.Context) {
+	courseID, ok := parseUUIDQuery(c, "course_id")
+	if !ok {
+		return
+	}
+	if courseID == nil {
+		ValidationError(c, "course_id is required", nil)
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	exp, err := h.svc.ExportCourse(c.Request.Context(), uid, *courseID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+exp.Filename+`"`)
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(exp.Markdown))
+}
+
+// ListBookmarks GET /api/users/me/bookmarks?course_id=
+func (h *NoteHandler) ListBookmarks(c *gin.Context) {
+	courseID, ok := parseUUIDQuery(c, "course_id")
+	if !ok {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	list, err := h.svc.ListBookmarks(c.Request.Context(), uid, courseID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"bookmarks": list})
+}
+
+// AddBookmark PUT /api/users/me/bookmarks/:id — id урока; повторный вызов ничего не меняет
+func (h *NoteHandler) AddBookmark(c *gin.Context) {
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	b, err := h.svc.AddBookmark(c.Request.Context(), uid, lessonID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+// RemoveBookmark DELETE /api/users/me/bookmarks/:id
+func (h *NoteHandler) RemoveBookmark(c *gin.Context) {
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	if err := h.svc.RemoveBookmark(c.Request.Context(), uid, lessonID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
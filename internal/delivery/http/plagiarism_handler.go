@@ -0,0 +1,72 @@
+package httpdelivery
+
+import (
+	"errors"
+	"net/http"
+
+	plagiarismuc "github.com/example/learngo/internal/usecase/plagiarism"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PlagiarismHandler struct {
+	svc    plagiarismuc.Service
+	logger *utils.Logger
+}
+
+func NewPlagiarismHandler(s plagiarismuc.Service, logger *utils.Logger) *PlagiarismHandler {
+	return &PlagiarismHandler{svc: s, logger: logger}
+}
+
+func (h *PlagiarismHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, plagiarismuc.ErrNotFound):
+		NotFoundError(c, "plagiarism pair")
+	case errors.Is(err, plagiarismuc.ErrAssignmentNotFound):
+		NotFoundError(c, "assignment")
+	default:
+		InternalError(c, "Plagiarism request failed", err)
+	}
+}
+
+// Report GET /api/assignments/:id/plagiarism — последний отчёт: подозрительные пары от самых похожих.
+func (h *PlagiarismHandler) Report(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	rep, err := h.svc.Report(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// Analyze POST /api/assignments/:id/plagiarism — пересчитать отчёт, не дожидаясь фоновой задачи.
+func (h *PlagiarismHandler) Analyze(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	rep, err := h.svc.Analyze(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// Pair GET /api/plagiarism/pairs/:id — оба решения рядом, совпавшие фрагменты друг напротив друга.
+func (h *PlagiarismHandler) Pair(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	view, err := h.svc.Pair(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
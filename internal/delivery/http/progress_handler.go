@@ -46,7 +46,7 @@ func (h *ProgressHandler) GetCourseProgress(c *gin.Context) {
 	c.JSON(http.StatusOK, progress)
 }
 
-// UpsertLessonProgress обрабатывает POST /api/lessons/:lessonId/progress
+// UpsertLessonProgress обрабатывает POST /api/lessons/:id/progress
 func (h *ProgressHandler) UpsertLessonProgress(c *gin.Context) {
 	userID, ok := UserIDFromContext(c)
 	if !ok {
@@ -54,7 +54,7 @@ func (h *ProgressHandler) UpsertLessonProgress(c *gin.Context) {
 		return
 	}
 
-	lessonID, err := uuid.Parse(c.Param("lessonId"))
+	lessonID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lesson id"})
 		return
@@ -0,0 +1,212 @@
+package httpdelivery
+
+import (
+	"errors"
+	"net/http"
+
+	quizdom "github.com/example/learngo/internal/domain/quiz"
+	quizuc "github.com/example/learngo/internal/usecase/quiz"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type QuizHandler struct {
+	svc    quizuc.Service
+	logger *utils.Logger
+}
+
+func NewQuizHandler(s quizuc.Service, logger *utils.Logger) *QuizHandler {
+	return &QuizHandler{svc: s, logger: logger}
+}
+
+type quizQuestionRequest struct {
+	Kind          string           `json:"kind" binding:"required"`
+	Prompt        string           `json:"prompt" binding:"required"`
+	Code          string           `json:"code"`
+	Options       []quizdom.Option `json:"options"`
+	Correct       []string         `json:"correct"`
+	Accepted      []string         `json:"accepted"`
+	CaseSensitive bool             `json:"case_sensitive"`
+	Points        int              `json:"points"`
+	Order         int              `json:"order"`
+}
+
+func (r quizQuestionRequest) toDomain() quizdom.Question {
+	return quizdom.Question{
+		Kind:          r.Kind,
+		Prompt:        r.Prompt,
+		Code:          r.Code,
+		Options:       r.Options,
+		Correct:       r.Correct,
+		Accepted:      r.Accepted,
+		CaseSensitive: r.CaseSensitive,
+		Points:        r.Points,
+		Order:         r.Order,
+	}
+}
+
+// ListQuestions GET /api/lessons/:id/quiz/questions — банк вопросов с ответами (преподаватель)
+func (h *QuizHandler) ListQuestions(c *gin.Context) {
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	list, err := h.svc.ListQuestions(c.Request.Context(), lessonID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"questions": list, "total": len(list)})
+}
+
+// CreateQuestion POST /api/lessons/:id/quiz/questions
+func (h *QuizHandler) CreateQuestion(c *gin.Context) {
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req quizQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	q, err := h.svc.CreateQuestion(c.Request.Context(), lessonID, req.toDomain())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, q)
+}
+
+// UpdateQuestion PUT /api/quiz/questions/:id
+func (h *QuizHandler) UpdateQuestion(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req quizQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	q, err := h.svc.UpdateQuestion(c.Request.Context(), id, req.toDomain())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// DeleteQuestion DELETE /api/quiz/questions/:id
+func (h *QuizHandler) DeleteQuestion(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteQuestion(c.Request.Context(), id); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// StartAttempt POST /api/lessons/:id/quiz/attempts — новая попытка или продолжение незавершённой
+func (h *QuizHandler) StartAttempt(c *gin.Context) {
+	userID, ok := UserIDFromContext(c)
+	if !ok {
+		UnauthorizedError(c, "")
+		return
+	}
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	view, err := h.svc.StartAttempt(c.Request.Context(), userID, lessonID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, view)
+}
+
+// ListAttempts GET /api/lessons/:id/quiz/attempts — мои попытки и оставшийся лимит
+func (h *QuizHandler) ListAttempts(c *gin.Context) {
+	userID, ok := UserIDFromContext(c)
+	if !ok {
+		UnauthorizedError(c, "")
+		return
+	}
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	sum, err := h.svc.Summary(c.Request.Context(), userID, lessonID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sum)
+}
+
+// GetAttempt GET /api/quiz/attempts/:id
+func (h *QuizHandler) GetAttempt(c *gin.Context) {
+	userID, ok := UserIDFromContext(c)
+	if !ok {
+		UnauthorizedError(c, "")
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	view, err := h.svc.GetAttempt(c.Request.Context(), userID, id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// SubmitAttempt POST /api/quiz/attempts/:id/submit
+func (h *QuizHandler) SubmitAttempt(c *gin.Context) {
+	userID, ok := UserIDFromContext(c)
+	if !ok {
+		UnauthorizedError(c, "")
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Answers []quizdom.Answer `json:"answers"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	a, err := h.svc.SubmitAttempt(c.Request.Context(), userID, id, req.Answers)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+func (h *QuizHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, quizuc.ErrNotFound):
+		NotFoundError(c, "quiz")
+	case errors.Is(err, quizuc.ErrForbidden):
+		ForbiddenError(c, "")
+	case errors.Is(err, quizdom.ErrInvalidQuestion):
+		ValidationError(c, err.Error(), nil)
+	case errors.Is(err, quizuc.ErrNotQuiz), errors.Is(err, quizuc.ErrNoQuestions):
+		BadRequestError(c, err.Error(), nil)
+	case errors.Is(err, quizuc.ErrAttemptsExhausted), errors.Is(err, quizuc.ErrAttemptClosed), errors.Is(err, quizuc.ErrAttemptExpired):
+		ErrorResponse(c, http.StatusConflict, "QUIZ_ATTEMPT_UNAVAILABLE", err.Error(), nil)
+	default:
+		h.logger.Error("quiz request failed", "error", err)
+		InternalError(c, "", nil)
+	}
+}
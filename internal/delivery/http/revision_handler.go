@@ -0,0 +1,121 @@
+package httpdelivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	revisionuc "github.com/example/learngo/internal/usecase/revision"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RevisionHandler история изменений одного вида сущностей (уроки или задания).
+type RevisionHandler struct {
+	svc    revisionuc.Service
+	kind   string
+	logger *utils.Logger
+}
+
+func NewRevisionHandler(s revisionuc.Service, kind string, logger *utils.Logger) *RevisionHandler {
+	return &RevisionHandler{svc: s, kind: kind, logger: logger}
+}
+
+func (h *RevisionHandler) writeError(c *gin.Context, err error) {
+	var verr *lessondom.ValidationError
+	switch {
+	case errors.Is(err, revisionuc.ErrNotFound):
+		NotFoundError(c, "revision")
+	case errors.Is(err, revisionuc.ErrEntityNotFound):
+		NotFoundError(c, h.kind)
+	case errors.As(err, &verr):
+		ValidationError(c, "Revision content is no longer valid", map[string]interface{}{"fields": verr.Errors})
+	default:
+		InternalError(c, "Revision request failed", err)
+	}
+}
+
+func parseRevisionNumber(c *gin.Context, raw string) (int, bool) {
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		BadRequestError(c, "invalid revision number", nil)
+		return 0, false
+	}
+	return n, true
+}
+
+// List GET /api/{lessons|assignments}/:id/revisions — ревизии без снимков, от новых к старым
+func (h *RevisionHandler) List(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	list, err := h.svc.List(c.Request.Context(), h.kind, id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revisions": list, "total": len(list)})
+}
+
+// Get GET /api/{lessons|assignments}/:id/revisions/:rev — ревизия с полным снимком
+func (h *RevisionHandler) Get(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	n, ok := parseRevisionNumber(c, c.Param("rev"))
+	if !ok {
+		return
+	}
+	rev, err := h.svc.Get(c.Request.Context(), h.kind, id, n)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rev)
+}
+
+// Diff GET /api/{lessons|assignments}/:id/revisions/diff?from=1&to=3 — без to сравнивает с последней
+func (h *RevisionHandler) Diff(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	from, ok := parseRevisionNumber(c, c.Query("from"))
+	if !ok {
+		return
+	}
+	to := 0
+	if raw := c.Query("to"); raw != "" {
+		if to, ok = parseRevisionNumber(c, raw); !ok {
+			return
+		}
+	}
+	res, err := h.svc.Diff(c.Request.Context(), h.kind, id, from, to)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// Rollback POST /api/{lessons|assignments}/:id/revisions/:rev/rollback — создаёт новую ревизию из старой
+func (h *RevisionHandler) Rollback(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	n, ok := parseRevisionNumber(c, c.Param("rev"))
+	if !ok {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	rev, err := h.svc.Rollback(c.Request.Context(), h.kind, id, n, uid)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rev)
+}
@@ -4,18 +4,33 @@ import (
 	"net/http"
 	"os"
 
+	discussiondom "github.com/example/learngo/internal/domain/discussion"
+	revisiondom "github.com/example/learngo/internal/domain/revision"
+	accessuc "github.com/example/learngo/internal/usecase/access"
 	achievementuc "github.com/example/learngo/internal/usecase/achievement"
 	aiuc "github.com/example/learngo/internal/usecase/ai"
 	assignuc "github.com/example/learngo/internal/usecase/assignment"
 	authuc "github.com/example/learngo/internal/usecase/auth"
 	codeexecuc "github.com/example/learngo/internal/usecase/codeexec"
+	codejobuc "github.com/example/learngo/internal/usecase/codejob"
 	"github.com/example/learngo/internal/usecase/course"
 	dashboarduc "github.com/example/learngo/internal/usecase/dashboard"
+	discussionuc "github.com/example/learngo/internal/usecase/discussion"
 	enrolluc "github.com/example/learngo/internal/usecase/enrollment"
+	gradinguc "github.com/example/learngo/internal/usecase/grading"
+	i18nuc "github.com/example/learngo/internal/usecase/i18n"
 	lessonuc "github.com/example/learngo/internal/usecase/lesson"
 	moduleuc "github.com/example/learngo/internal/usecase/module"
+	noteuc "github.com/example/learngo/internal/usecase/note"
+	plagiarismuc "github.com/example/learngo/internal/usecase/plagiarism"
 	progressuc "github.com/example/learngo/internal/usecase/progress"
+	quizuc "github.com/example/learngo/internal/usecase/quiz"
+	revisionuc "github.com/example/learngo/internal/usecase/revision"
 	sectionuc "github.com/example/learngo/internal/usecase/section"
+	submissionuc "github.com/example/learngo/internal/usecase/submission"
+	videouc "github.com/example/learngo/internal/usecase/video"
+	"github.com/example/learngo/pkg/codeexec"
+	"github.com/example/learngo/pkg/markdown"
 	"github.com/example/learngo/pkg/observability"
 	"github.com/example/learngo/pkg/storage"
 	"github.com/example/learngo/pkg/utils"
@@ -28,7 +43,7 @@ import (
 type Router struct{ engine *gin.Engine }
 
 // NewRouter конструирует HTTP-роутер и регистрирует обработчики.
-func NewRouter(logger *utils.Logger, courseService course.Service, authService authuc.Service, jwt *utils.JWTManager, cfg *utils.Config, lessonService lessonuc.Service, assignmentService assignuc.Service, progressService progressuc.Service, enrollmentService enrolluc.Service, sectionService sectionuc.Service, moduleService moduleuc.Service, achievementService achievementuc.Service, dashboardService dashboarduc.Service, aiService aiuc.Service, codeExecService codeexecuc.Service) *Router {
+func NewRouter(logger *utils.Logger, courseService course.Service, authService authuc.Service, jwt *utils.JWTManager, cfg *utils.Config, lessonService lessonuc.Service, assignmentService assignuc.Service, progressService progressuc.Service, enrollmentService enrolluc.Service, sectionService sectionuc.Service, moduleService moduleuc.Service, achievementService achievementuc.Service, dashboardService dashboarduc.Service, aiService aiuc.Service, codeExecService codeexecuc.Service, i18nService i18nuc.Service, quizService quizuc.Service, revisionService revisionuc.Service, objectStorage *storage.S3Client, videoService videouc.Service, accessService accessuc.Service, discussionService discussionuc.Service, md *markdown.Renderer, noteService noteuc.Service, gradingService gradinguc.Service, submissionService submissionuc.Service, codeJobService codejobuc.Service, judge0Callbacks *codeexec.Callbacks, plagiarismService plagiarismuc.Service) *Router {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(RequestIDMiddleware())
@@ -41,8 +56,8 @@ func NewRouter(logger *utils.Logger, courseService course.Service, authService a
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.CORSOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept-Language"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Language"},
 		AllowCredentials: true,
 	}))
 
@@ -52,6 +67,10 @@ func NewRouter(logger *utils.Logger, courseService course.Service, authService a
 	})
 	// Prometheus metrics
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	// уведомления Judge0 о готовых submissions: вне /api, без JWT и rate limit — запрос подписан секретом
+	if judge0Callbacks != nil {
+		r.PUT("/internal/judge0/callbacks", Judge0Callback(judge0Callbacks))
+	}
 
 	// OpenAPI static docs (файл может быть рядом с бинарём в Docker)
 	r.GET("/api/docs/openapi.yaml", func(c *gin.Context) {
@@ -95,6 +114,24 @@ func NewRouter(logger *utils.Logger, courseService course.Service, authService a
 	h.moduleSvc = moduleService
 	authHandler := NewAuthHandler(authService, logger)
 	lh := NewLessonHandler(lessonService, logger)
+	// рендерер общий с сервисами (заметки ищут в нём фрагменты кода), иначе свой
+	lh.md = md
+	if lh.md == nil {
+		lh.md = markdown.New(cfg.MarkdownCacheSize)
+	}
+	lh.codeSvc = codeExecService
+	lh.access = accessService
+	// доступ к контенту урока: без политики доступа (нет сервиса) уроки остаются публичными
+	lessonAccess := func(c *gin.Context) { c.Next() }
+	if accessService != nil {
+		lessonAccess = LessonAccessRequired(accessService)
+	}
+	var th *TranslationHandler
+	if i18nService != nil {
+		h.i18nSvc = i18nService
+		lh.i18nSvc = i18nService
+		th = NewTranslationHandler(i18nService)
+	}
 	var sh *SectionHandler
 	if sectionService != nil {
 		sh = NewSectionHandler(sectionService)
@@ -123,11 +160,38 @@ func NewRouter(logger *utils.Logger, courseService course.Service, authService a
 	if codeExecService != nil {
 		codeHandler = NewCodeHandler(codeExecService)
 	}
+	var qh *QuizHandler
+	if quizService != nil {
+		qh = NewQuizHandler(quizService, logger)
+	}
+	var vh *VideoHandler
+	if videoService != nil {
+		vh = NewVideoHandler(videoService, logger)
+	}
+	var nh *NoteHandler
+	if noteService != nil {
+		nh = NewNoteHandler(noteService, logger)
+	}
+	var dh *DiscussionHandler
+	if discussionService != nil {
+		dh = NewDiscussionHandler(discussionService, lh.md, logger)
+	}
+	var lessonRevs, assignmentRevs *RevisionHandler
+	if revisionService != nil {
+		lh.revSvc = revisionService
+		ah.revSvc = revisionService
+		lessonRevs = NewRevisionHandler(revisionService, revisiondom.KindLesson, logger)
+		assignmentRevs = NewRevisionHandler(revisionService, revisiondom.KindAssignment, logger)
+	}
 
 	api := r.Group("/api")
 	{
 		// Глобальный rate limit
 		api.Use(globalRateLimiter(cfg))
+		// Локаль запроса для переводимого контента
+		if i18nService != nil {
+			api.Use(LocaleMiddleware(jwt, i18nService))
+		}
 
 		// Подключаем rate limits для auth
 		attachRateLimits(api, cfg)
@@ -151,19 +215,28 @@ func NewRouter(logger *utils.Logger, courseService course.Service, authService a
 			if mh != nil {
 				courses.GET(":id/modules", mh.ListByCourse)
 				courses.POST(":id/modules", AuthRequired(jwt), RequireRoles("admin", "teacher"), mh.Create)
+				courses.PUT(":id/modules/order", AuthRequired(jwt), RequireRoles("admin", "teacher"), lh.ReorderModules)
 			}
-			courses.GET(":id/lessons", lh.ListByCourse)
+			if th != nil {
+				courses.GET(":id/translations", AuthRequired(jwt), RequireRoles("admin", "teacher"), th.ListCourse)
+				courses.GET(":id/translations/stale", AuthRequired(jwt), RequireRoles("admin", "teacher"), th.ListStale)
+				courses.PUT(":id/translations/:locale", AuthRequired(jwt), RequireRoles("admin", "teacher"), th.UpsertCourse)
+				courses.DELETE(":id/translations/:locale", AuthRequired(jwt), RequireRoles("admin", "teacher"), th.DeleteCourse)
+			}
+			courses.GET(":id/lessons", OptionalAuth(jwt), lh.ListByCourse)
 			courses.POST(":id/lessons", AuthRequired(jwt), RequireRoles("admin", "teacher"), lh.Create)
+			courses.POST(":id/lessons/migrate-content", AuthRequired(jwt), RequireRoles("admin"), lh.MigrateContents)
 			// lessons by section
-			api.GET("/sections/:id/lessons", lh.ListBySection)
+			api.GET("/sections/:id/lessons", OptionalAuth(jwt), lh.ListBySection)
 			api.POST("/sections/:id/lessons", AuthRequired(jwt), RequireRoles("admin", "teacher"), lh.Create)
 			if mh != nil {
-				api.GET("/modules/:id/lessons", lh.ListBySection) // временно используем тот же метод (по id)
+				api.GET("/modules/:id/lessons", OptionalAuth(jwt), lh.ListBySection) // временно используем тот же метод (по id)
+				api.PUT("/modules/:id/lessons/order", AuthRequired(jwt), RequireRoles("admin", "teacher"), lh.ReorderLessons)
 			}
 		}
 		// Новые эндпоинты прогресса согласно документации
 		api.GET("/users/:userId/progress/:courseId", AuthRequired(jwt), ph.GetCourseProgress)
-		api.POST("/lessons/:lessonId/progress", AuthRequired(jwt), ph.UpsertLessonProgress)
+		api.POST("/lessons/:id/progress", AuthRequired(jwt), ph.UpsertLessonProgress)
 
 		// achievements
 		if achHandler != nil {
@@ -190,14 +263,37 @@ func NewRouter(logger *utils.Logger, courseService course.Service, authService a
 		// Code execution с отдельным rate limit
 		if codeHandler != nil {
 			api.POST("/code/execute", AuthRequired(jwt), codeExecRateLimiter(cfg), codeHandler.Execute)
+			api.POST("/code/execute/stream", AuthRequired(jwt), codeExecRateLimiter(cfg), codeHandler.ExecuteStream)
+			api.GET("/code/backends", AuthRequired(jwt), RequireRoles("admin"), codeHandler.Backends)
+			api.POST("/lessons/:id/snippets/:snippetId/run", AuthRequired(jwt), lessonAccess, codeExecRateLimiter(cfg), lh.RunSnippet)
+			api.POST("/lessons/:id/tests/run", AuthRequired(jwt), lessonAccess, codeExecRateLimiter(cfg), lh.RunTests)
+		}
+		// асинхронное выполнение через очередь раннера
+		if codeJobService != nil {
+			jh := NewCodeJobHandler(codeJobService, logger)
+			api.POST("/code/jobs", AuthRequired(jwt), codeExecRateLimiter(cfg), jh.Submit)
+			api.GET("/code/jobs/:id", AuthRequired(jwt), jh.Get)
+			api.GET("/code/jobs/:id/events", AuthRequired(jwt), jh.Events)
 		}
+		// CSS подсветки синтаксиса для theory_html
+		api.GET("/markdown/highlight.css", func(c *gin.Context) {
+			c.Header("Cache-Control", "public, max-age=86400")
+			c.Data(http.StatusOK, "text/css; charset=utf-8", []byte(lh.md.CSS()))
+		})
 
 		// enrollments
 		api.POST("/enrollments", AuthRequired(jwt), RequireRoles("user", "admin", "teacher"), eh.Enroll)
 		// lesson and assignments
-		api.GET("/lessons/:id", lh.Get)
+		api.GET("/lessons/:id", OptionalAuth(jwt), lessonAccess, lh.Get)
 		api.PUT("/lessons/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), lh.Update)
 		api.DELETE("/lessons/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), lh.Delete)
+		if th != nil {
+			api.GET("/lessons/:id/translations", AuthRequired(jwt), RequireRoles("admin", "teacher"), th.ListLesson)
+			api.PUT("/lessons/:id/translations/:locale", AuthRequired(jwt), RequireRoles("admin", "teacher"), th.UpsertLesson)
+			api.DELETE("/lessons/:id/translations/:locale", AuthRequired(jwt), RequireRoles("admin", "teacher"), th.DeleteLesson)
+			api.GET("/locales", th.Locales)
+			api.PUT("/users/me/locale", AuthRequired(jwt), th.SetMyLocale)
+		}
 		if sh != nil {
 			api.PUT("/section/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), sh.Update)
 			api.DELETE("/section/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), sh.Delete)
@@ -206,13 +302,55 @@ func NewRouter(logger *utils.Logger, courseService course.Service, authService a
 			api.PUT("/module/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), mh.Update)
 			api.DELETE("/module/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), mh.Delete)
 		}
-		api.GET("/lessons/:id/assignments", ah.ListByLesson)
+		if qh != nil {
+			api.GET("/lessons/:id/quiz/questions", AuthRequired(jwt), RequireRoles("admin", "teacher"), qh.ListQuestions)
+			api.POST("/lessons/:id/quiz/questions", AuthRequired(jwt), RequireRoles("admin", "teacher"), qh.CreateQuestion)
+			api.PUT("/quiz/questions/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), qh.UpdateQuestion)
+			api.DELETE("/quiz/questions/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), qh.DeleteQuestion)
+			api.GET("/lessons/:id/quiz/attempts", AuthRequired(jwt), lessonAccess, qh.ListAttempts)
+			api.POST("/lessons/:id/quiz/attempts", AuthRequired(jwt), lessonAccess, qh.StartAttempt)
+			api.GET("/quiz/attempts/:id", AuthRequired(jwt), qh.GetAttempt)
+			api.POST("/quiz/attempts/:id/submit", AuthRequired(jwt), qh.SubmitAttempt)
+		}
+		api.GET("/lessons/:id/assignments", OptionalAuth(jwt), lessonAccess, ah.ListByLesson)
 		api.POST("/lessons/:id/assignments", AuthRequired(jwt), RequireRoles("admin", "teacher"), ah.Create)
 		api.PUT("/assignments/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), ah.Update)
 		api.DELETE("/assignments/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), ah.Delete)
+		if gradingService != nil {
+			api.POST("/assignments/:id/grade", AuthRequired(jwt), codeExecRateLimiter(cfg), NewGradingHandler(gradingService, logger).Grade)
+		}
+		// история попыток
+		if submissionService != nil {
+			subh := NewSubmissionHandler(submissionService, logger)
+			api.GET("/submissions", AuthRequired(jwt), subh.List)
+			api.GET("/submissions/:id", AuthRequired(jwt), subh.Get)
+			api.GET("/assignments/:id/submissions/best", AuthRequired(jwt), subh.Best)
+		}
+		// отчёты о заимствованиях
+		if plagiarismService != nil {
+			plh := NewPlagiarismHandler(plagiarismService, logger)
+			api.GET("/assignments/:id/plagiarism", AuthRequired(jwt), RequireRoles("admin", "teacher"), plh.Report)
+			api.POST("/assignments/:id/plagiarism", AuthRequired(jwt), RequireRoles("admin", "teacher"), plh.Analyze)
+			api.GET("/plagiarism/pairs/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), plh.Pair)
+		}
+		// история изменений уроков и заданий
+		if lessonRevs != nil {
+			api.GET("/lessons/:id/revisions", AuthRequired(jwt), RequireRoles("admin", "teacher"), lessonRevs.List)
+			api.GET("/lessons/:id/revisions/diff", AuthRequired(jwt), RequireRoles("admin", "teacher"), lessonRevs.Diff)
+			api.GET("/lessons/:id/revisions/:rev", AuthRequired(jwt), RequireRoles("admin", "teacher"), lessonRevs.Get)
+			api.POST("/lessons/:id/revisions/:rev/rollback", AuthRequired(jwt), RequireRoles("admin", "teacher"), lessonRevs.Rollback)
+			api.GET("/assignments/:id/revisions", AuthRequired(jwt), RequireRoles("admin", "teacher"), assignmentRevs.List)
+			api.GET("/assignments/:id/revisions/diff", AuthRequired(jwt), RequireRoles("admin", "teacher"), assignmentRevs.Diff)
+			api.GET("/assignments/:id/revisions/:rev", AuthRequired(jwt), RequireRoles("admin", "teacher"), assignmentRevs.Get)
+			api.POST("/assignments/:id/revisions/:rev/rollback", AuthRequired(jwt), RequireRoles("admin", "teacher"), assignmentRevs.Rollback)
+		}
 
 		// S3 presign upload (для админки и загрузок обложек)
 		api.POST("/uploads/presign", AuthRequired(jwt), func(c *gin.Context) {
+			if objectStorage == nil {
+				ServiceUnavailableError(c, "object storage is not configured")
+				return
+			}
 			type req struct {
 				Prefix      string `json:"prefix"`
 				Ext         string `json:"ext"`
@@ -220,27 +358,65 @@ func NewRouter(logger *utils.Logger, courseService course.Service, authService a
 			}
 			var rbody req
 			_ = c.ShouldBindJSON(&rbody)
-			s3, err := storage.NewS3Client(storage.S3Config{Endpoint: cfg.S3Endpoint, AccessKey: cfg.S3AccessKey, SecretKey: cfg.S3SecretKey, Bucket: cfg.S3Bucket, UseSSL: true, BaseURL: cfg.S3BaseURL})
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "s3 init failed"})
-				return
-			}
-			if err := s3.EnsureBucket(c); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "bucket"})
-				return
-			}
-			key := s3.GenerateKey(rbody.Prefix, rbody.Ext)
-			url, err := s3.PresignPut(c, key, rbody.ContentType, 15*60*1e9)
+			key := objectStorage.GenerateKey(rbody.Prefix, rbody.Ext)
+			url, err := objectStorage.PresignPut(c, key, rbody.ContentType, 15*60*1e9)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "presign"})
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"uploadUrl": url, "objectKey": key, "publicUrl": s3.ObjectURL(key)})
+			c.JSON(http.StatusOK, gin.H{"uploadUrl": url, "objectKey": key, "publicUrl": objectStorage.ObjectURL(key)})
 		})
+		// заметки и закладки студента
+		if nh != nil {
+			api.GET("/users/me/notes", AuthRequired(jwt), nh.ListNotes)
+			api.POST("/users/me/notes", AuthRequired(jwt), nh.CreateNote)
+			api.GET("/users/me/notes/search", AuthRequired(jwt), nh.SearchNotes)
+			api.GET("/users/me/notes/export", AuthRequired(jwt), nh.ExportNotes)
+			api.GET("/users/me/notes/:id", AuthRequired(jwt), nh.GetNote)
+			api.PUT("/users/me/notes/:id", AuthRequired(jwt), nh.UpdateNote)
+			api.DELETE("/users/me/notes/:id", AuthRequired(jwt), nh.DeleteNote)
+			api.GET("/users/me/bookmarks", AuthRequired(jwt), nh.ListBookmarks)
+			api.PUT("/users/me/bookmarks/:id", AuthRequired(jwt), nh.AddBookmark)
+			api.DELETE("/users/me/bookmarks/:id", AuthRequired(jwt), nh.RemoveBookmark)
+		}
+		// обсуждения уроков
+		if dh != nil {
+			// тред и ответ открыты тем же, кому открыт их урок
+			threadAccess, postAccess := lessonAccess, lessonAccess
+			if accessService != nil {
+				threadAccess = DiscussionAccessRequired(accessService, discussionService, discussiondom.TargetThread)
+				postAccess = DiscussionAccessRequired(accessService, discussionService, discussiondom.TargetPost)
+			}
+			api.GET("/lessons/:id/threads", OptionalAuth(jwt), lessonAccess, dh.ListThreads)
+			api.POST("/lessons/:id/threads", AuthRequired(jwt), lessonAccess, dh.CreateThread)
+			api.GET("/threads/:id", OptionalAuth(jwt), threadAccess, dh.GetThread)
+			api.PUT("/threads/:id", AuthRequired(jwt), threadAccess, dh.UpdateThread)
+			api.DELETE("/threads/:id", AuthRequired(jwt), threadAccess, dh.DeleteThread)
+			api.POST("/threads/:id/posts", AuthRequired(jwt), threadAccess, dh.Reply)
+			api.POST("/threads/:id/vote", AuthRequired(jwt), threadAccess, dh.Vote(discussiondom.TargetThread, true))
+			api.DELETE("/threads/:id/vote", AuthRequired(jwt), threadAccess, dh.Vote(discussiondom.TargetThread, false))
+			api.PUT("/threads/:id/accepted", AuthRequired(jwt), RequireRoles("admin", "teacher"), dh.Accept)
+			api.POST("/threads/:id/moderation", AuthRequired(jwt), RequireRoles("admin", "teacher"), dh.Moderate(discussiondom.TargetThread))
+			api.PUT("/posts/:id", AuthRequired(jwt), postAccess, dh.UpdatePost)
+			api.DELETE("/posts/:id", AuthRequired(jwt), postAccess, dh.DeletePost)
+			api.POST("/posts/:id/vote", AuthRequired(jwt), postAccess, dh.Vote(discussiondom.TargetPost, true))
+			api.DELETE("/posts/:id/vote", AuthRequired(jwt), postAccess, dh.Vote(discussiondom.TargetPost, false))
+			api.POST("/posts/:id/moderation", AuthRequired(jwt), RequireRoles("admin", "teacher"), dh.Moderate(discussiondom.TargetPost))
+		}
+		// видеоуроки: multipart-загрузка, субтитры, подписанные ссылки на просмотр
+		if vh != nil {
+			api.POST("/lessons/:id/video/uploads", AuthRequired(jwt), RequireRoles("admin", "teacher"), vh.StartUpload)
+			api.GET("/lessons/:id/video/assets", AuthRequired(jwt), RequireRoles("admin", "teacher"), vh.ListByLesson)
+			api.GET("/lessons/:id/video/playback", AuthRequired(jwt), lessonAccess, vh.Playback)
+			api.GET("/videos/:id", AuthRequired(jwt), RequireRoles("admin", "teacher"), vh.Get)
+			api.POST("/videos/:id/complete", AuthRequired(jwt), RequireRoles("admin", "teacher"), vh.CompleteUpload)
+			api.DELETE("/videos/:id/upload", AuthRequired(jwt), RequireRoles("admin", "teacher"), vh.AbortUpload)
+			api.PUT("/videos/:id/captions/:locale", AuthRequired(jwt), RequireRoles("admin", "teacher"), vh.UploadCaptions)
+		}
 		api.GET("/me", AuthRequired(jwt), func(c *gin.Context) {
 			uid, _ := UserIDFromContext(c)
 			role := c.GetString(CtxRole)
-			c.JSON(http.StatusOK, gin.H{"userId": uid, "role": role})
+			c.JSON(http.StatusOK, gin.H{"userId": uid, "role": role, "locale": LocaleFromContext(c)})
 		})
 	}
 
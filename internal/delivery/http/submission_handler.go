@@ -0,0 +1,101 @@
+package httpdelivery
+
+import (
+	"errors"
+	"net/http"
+
+	subdom "github.com/example/learngo/internal/domain/submission"
+	submissionuc "github.com/example/learngo/internal/usecase/submission"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type SubmissionHandler struct {
+	svc    submissionuc.Service
+	logger *utils.Logger
+}
+
+func NewSubmissionHandler(s submissionuc.Service, logger *utils.Logger) *SubmissionHandler {
+	return &SubmissionHandler{svc: s, logger: logger}
+}
+
+func (h *SubmissionHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, submissionuc.ErrNotFound) {
+		NotFoundError(c, "submission")
+		return
+	}
+	InternalError(c, "Submissions request failed", err)
+}
+
+// List GET /api/submissions?user_id=&course_id=&lesson_id=&assignment_id=&verdict=&best=&page=&page_size=
+// Студенту всегда отдаются только его попытки; user_id учитывается для персонала.
+func (h *SubmissionHandler) List(c *gin.Context) {
+	var f subdom.Filter
+	var ok bool
+	if f.UserID, ok = parseUUIDQuery(c, "user_id"); !ok {
+		return
+	}
+	if f.CourseID, ok = parseUUIDQuery(c, "course_id"); !ok {
+		return
+	}
+	if f.LessonID, ok = parseUUIDQuery(c, "lesson_id"); !ok {
+		return
+	}
+	if f.AssignmentID, ok = parseUUIDQuery(c, "assignment_id"); !ok {
+		return
+	}
+	if v := c.Query("verdict"); v != "" {
+		if !subdom.IsKnownVerdict(v) {
+			ValidationError(c, "Invalid verdict", map[string]interface{}{"verdict": v})
+			return
+		}
+		f.Verdict = v
+	}
+	f.BestOnly = c.Query("best") == "true"
+	f.Page, f.PageSize = pageParams(c)
+
+	uid, _ := UserIDFromContext(c)
+	page, err := h.svc.List(c.Request.Context(), f, uid, c.GetString(CtxRole))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// Get GET /api/submissions/:id
+func (h *SubmissionHandler) Get(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	sub, err := h.svc.Get(c.Request.Context(), id, uid, c.GetString(CtxRole))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// Best GET /api/assignments/:id/submissions/best?user_id= — лучшая попытка (своя или, для персонала, студента)
+func (h *SubmissionHandler) Best(c *gin.Context) {
+	assignmentID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	owner, ok := parseUUIDQuery(c, "user_id")
+	if !ok {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	if owner == nil {
+		owner = &uid
+	}
+	sub, err := h.svc.Best(c.Request.Context(), *owner, assignmentID, uid, c.GetString(CtxRole))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
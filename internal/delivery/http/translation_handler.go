@@ -0,0 +1,135 @@
+package httpdelivery
+
+import (
+	"errors"
+	"net/http"
+
+	transdom "github.com/example/learngo/internal/domain/translation"
+	i18nuc "github.com/example/learngo/internal/usecase/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TranslationHandler авторинг переводов курсов и уроков.
+type TranslationHandler struct{ svc i18nuc.Service }
+
+func NewTranslationHandler(s i18nuc.Service) *TranslationHandler { return &TranslationHandler{svc: s} }
+
+// ListCourse GET /api/courses/:id/translations
+func (h *TranslationHandler) ListCourse(c *gin.Context) { h.list(c, transdom.EntityCourse) }
+
+// UpsertCourse PUT /api/courses/:id/translations/:locale
+func (h *TranslationHandler) UpsertCourse(c *gin.Context) { h.upsert(c, transdom.EntityCourse) }
+
+// DeleteCourse DELETE /api/courses/:id/translations/:locale
+func (h *TranslationHandler) DeleteCourse(c *gin.Context) { h.delete(c, transdom.EntityCourse) }
+
+// ListLesson GET /api/lessons/:id/translations
+func (h *TranslationHandler) ListLesson(c *gin.Context) { h.list(c, transdom.EntityLesson) }
+
+// UpsertLesson PUT /api/lessons/:id/translations/:locale
+func (h *TranslationHandler) UpsertLesson(c *gin.Context) { h.upsert(c, transdom.EntityLesson) }
+
+// DeleteLesson DELETE /api/lessons/:id/translations/:locale
+func (h *TranslationHandler) DeleteLesson(c *gin.Context) { h.delete(c, transdom.EntityLesson) }
+
+// ListStale GET /api/courses/:id/translations/stale?locale=en
+func (h *TranslationHandler) ListStale(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid course id", nil)
+		return
+	}
+	list, err := h.svc.ListStale(c.Request.Context(), courseID, c.Query("locale"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"translations": list, "total": len(list)})
+}
+
+// Locales GET /api/locales
+func (h *TranslationHandler) Locales(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"locales": h.svc.SupportedLocales(), "current": LocaleFromContext(c)})
+}
+
+// SetMyLocale PUT /api/users/me/locale
+func (h *TranslationHandler) SetMyLocale(c *gin.Context) {
+	uid, ok := UserIDFromContext(c)
+	if !ok {
+		UnauthorizedError(c, "")
+		return
+	}
+	var req struct {
+		Locale string `json:"locale" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	if err := h.svc.SetUserLocale(c.Request.Context(), uid, req.Locale); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"locale": i18nuc.NormalizeLocale(req.Locale)})
+}
+
+func (h *TranslationHandler) list(c *gin.Context, entityType string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid id", nil)
+		return
+	}
+	list, err := h.svc.List(c.Request.Context(), entityType, id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"translations": list, "locales": h.svc.SupportedLocales()})
+}
+
+func (h *TranslationHandler) upsert(c *gin.Context, entityType string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid id", nil)
+		return
+	}
+	var fields transdom.Fields
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	author, _ := UserIDFromContext(c)
+	t, err := h.svc.Upsert(c.Request.Context(), entityType, id, c.Param("locale"), fields, author)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *TranslationHandler) delete(c *gin.Context, entityType string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid id", nil)
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), entityType, id, c.Param("locale")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TranslationHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, i18nuc.ErrNotFound):
+		NotFoundError(c, "entity")
+	case errors.Is(err, i18nuc.ErrUnsupportedLocale):
+		ValidationError(c, err.Error(), map[string]interface{}{"supported_locales": h.svc.SupportedLocales()})
+	case errors.Is(err, i18nuc.ErrSourceLocale), errors.Is(err, i18nuc.ErrInvalidField), errors.Is(err, i18nuc.ErrUnknownEntity):
+		ValidationError(c, err.Error(), nil)
+	default:
+		InternalError(c, "", err)
+	}
+}
@@ -0,0 +1,162 @@
+package httpdelivery
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	videodom "github.com/example/learngo/internal/domain/video"
+	videouc "github.com/example/learngo/internal/usecase/video"
+	"github.com/example/learngo/pkg/storage"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type VideoHandler struct {
+	svc    videouc.Service
+	logger *utils.Logger
+}
+
+func NewVideoHandler(s videouc.Service, logger *utils.Logger) *VideoHandler {
+	return &VideoHandler{svc: s, logger: logger}
+}
+
+func (h *VideoHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, videouc.ErrNotFound):
+		NotFoundError(c, "video")
+	case errors.Is(err, videouc.ErrLessonNotFound):
+		NotFoundError(c, "lesson")
+	case errors.Is(err, videouc.ErrNotEnrolled):
+		ForbiddenError(c, "Enroll in the course to watch this lesson")
+	case errors.Is(err, videouc.ErrNotVideoLesson), errors.Is(err, videouc.ErrInvalidUpload), errors.Is(err, videodom.ErrInvalidCaptions):
+		ValidationError(c, err.Error(), nil)
+	case errors.Is(err, videouc.ErrUploadClosed), errors.Is(err, videouc.ErrNotReady):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		InternalError(c, "Video request failed", err)
+	}
+}
+
+// StartUpload POST /api/lessons/:id/video/uploads — начинает multipart-загрузку видео урока
+func (h *VideoHandler) StartUpload(c *gin.Context) {
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Filename    string `json:"filename" binding:"required"`
+		ContentType string `json:"content_type" binding:"required"`
+		Size        int64  `json:"size" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	session, err := h.svc.StartUpload(c.Request.Context(), lessonID, uid, req.Filename, req.ContentType, req.Size)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+// CompleteUpload POST /api/videos/:id/complete — собирает файл из загруженных частей
+func (h *VideoHandler) CompleteUpload(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Parts       []storage.CompletedPart `json:"parts" binding:"required"`
+		DurationSec int                     `json:"duration_sec"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ValidationError(c, "Invalid request body", map[string]interface{}{"validation_error": err.Error()})
+		return
+	}
+	a, err := h.svc.CompleteUpload(c.Request.Context(), id, req.Parts, req.DurationSec)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// AbortUpload DELETE /api/videos/:id/upload
+func (h *VideoHandler) AbortUpload(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.svc.AbortUpload(c.Request.Context(), id); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Get GET /api/videos/:id
+func (h *VideoHandler) Get(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	a, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// ListByLesson GET /api/lessons/:id/video/assets
+func (h *VideoHandler) ListByLesson(c *gin.Context) {
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	list, err := h.svc.ListByLesson(c.Request.Context(), lessonID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"assets": list, "total": len(list)})
+}
+
+// UploadCaptions PUT /api/videos/:id/captions/:locale?label=Русский — тело запроса: файл WebVTT
+func (h *VideoHandler) UploadCaptions(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, videodom.MaxCaptionBytes+1))
+	if err != nil {
+		BadRequestError(c, "failed to read captions", nil)
+		return
+	}
+	a, err := h.svc.UploadCaptions(c.Request.Context(), id, c.Param("locale"), c.Query("label"), data)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// Playback GET /api/lessons/:id/video/playback — подписанные ссылки на просмотр
+func (h *VideoHandler) Playback(c *gin.Context) {
+	lessonID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	uid, _ := UserIDFromContext(c)
+	role := c.GetString(CtxRole)
+	p, err := h.svc.Playback(c.Request.Context(), lessonID, uid, role == "admin" || role == "teacher")
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, p)
+}
@@ -1,15 +1,33 @@
 package assignment
 
-import "github.com/google/uuid"
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidTests тесты задания нельзя запустить раннером проверки.
+var ErrInvalidTests = errors.New("invalid tests")
 
 type Assignment struct {
-    ID          uuid.UUID `json:"id"`
-    LessonID    uuid.UUID `json:"lessonId"`
-    Title       string    `json:"title"`
-    Prompt      string    `json:"prompt"`
-    StarterCode string    `json:"starterCode"`
-    Tests       string    `json:"tests"` // JSON тестов (упрощённо)
-    Order       int       `json:"order"`
+	ID          uuid.UUID `json:"id"`
+	LessonID    uuid.UUID `json:"lessonId"`
+	Title       string    `json:"title"`
+	Prompt      string    `json:"prompt"`
+	StarterCode string    `json:"starterCode"`
+	// StarterFiles стартовый проект из нескольких файлов (путь → содержимое), например go.mod и подпакеты.
+	// Если задан, StarterCode не используется.
+	StarterFiles map[string]string `json:"starterFiles,omitempty"`
+	Tests        string            `json:"tests"`                 // Go-файл с тестами (*_test.go, package main), виден студенту
+	HiddenTests  string            `json:"hiddenTests,omitempty"` // скрытые тесты: запускаются при проверке, студенту не отдаются
+	Performance  *Performance      `json:"performance,omitempty"` // бенчмарки и эталон для заданий на скорость; nil — только тесты
+	Order        int               `json:"order"`
 }
 
-
+// Starter стартовый проект: StarterFiles, а для однофайловых заданий — StarterCode в main.go.
+func (a Assignment) Starter() map[string]string {
+	if len(a.StarterFiles) > 0 {
+		return a.StarterFiles
+	}
+	return map[string]string{"main.go": a.StarterCode}
+}
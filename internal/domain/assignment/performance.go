@@ -0,0 +1,46 @@
+package assignment
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidPerformance задание на скорость настроено неверно.
+var ErrInvalidPerformance = errors.New("invalid performance settings")
+
+// DefaultMaxNsRatio во сколько раз решение может быть медленнее эталона, если порог не задан.
+const DefaultMaxNsRatio = 1.5
+
+// Performance задание на скорость: бенчмарки запускаются на решении студента и на эталонном
+// решении в одной песочнице. Решение, прошедшее тесты, принимается, если каждый бенчмарк
+// не медленнее эталона больше чем в MaxNsRatio раз и выделяет память не больше чем
+// в MaxAllocsRatio раз (при нуле выделений у эталона решение тоже не должно выделять память).
+type Performance struct {
+	Benchmarks     string  `json:"benchmarks"`               // Go-файл с функциями Benchmark* (package main)
+	Reference      string  `json:"reference,omitempty"`      // эталонное решение (main.go); студенту не отдаётся
+	MaxNsRatio     float64 `json:"maxNsRatio,omitempty"`     // 0 — DefaultMaxNsRatio
+	MaxAllocsRatio float64 `json:"maxAllocsRatio,omitempty"` // 0 — allocs/op не сравниваются
+}
+
+// Enabled заданы бенчмарки и эталон.
+func (p *Performance) Enabled() bool {
+	return p != nil && strings.TrimSpace(p.Benchmarks) != "" && strings.TrimSpace(p.Reference) != ""
+}
+
+// NsRatio порог по ns/op с учётом значения по умолчанию.
+func (p *Performance) NsRatio() float64 {
+	if p.MaxNsRatio <= 0 {
+		return DefaultMaxNsRatio
+	}
+	return p.MaxNsRatio
+}
+
+// Public настройки без эталонного решения — для студентов.
+func (p *Performance) Public() *Performance {
+	if p == nil {
+		return nil
+	}
+	pub := *p
+	pub.Reference = ""
+	return &pub
+}
@@ -10,6 +10,6 @@ type Repository interface {
 	ListByLesson(ctx context.Context, lessonID uuid.UUID) ([]Assignment, error)
 	Create(ctx context.Context, a Assignment) (Assignment, error)
 	Get(ctx context.Context, id uuid.UUID) (Assignment, error)
-	Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode, tests string, order int) (Assignment, error)
+	Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode string, starterFiles map[string]string, tests, hiddenTests string, performance *Performance, order int) (Assignment, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,218 @@
+package code
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Режимы проверки вывода теста.
+const (
+	CheckExact      = "exact"      // байт в байт
+	CheckWhitespace = "whitespace" // совпадают последовательности слов; режим по умолчанию
+	CheckLineSet    = "line_set"   // те же непустые строки в любом порядке (с учётом повторов)
+	CheckFloat      = "float"      // как whitespace, но числа сравниваются с погрешностью Tolerance
+	CheckRegex      = "regex"      // весь вывод без пробелов по краям соответствует expected_output как регулярке
+	CheckJSON       = "json"       // вывод и expected_output — эквивалентные JSON-документы
+	CheckCustom     = "custom"     // вердикт выносит программа-чекер преподавателя (Program)
+)
+
+// DefaultTolerance погрешность режима float, если она не задана.
+const DefaultTolerance = 1e-6
+
+// MaxCheckerProgramBytes ограничение на исходник программы-чекера.
+const MaxCheckerProgramBytes = 64 << 10
+
+var (
+	// ErrInvalidChecker настройки чекера не прошли проверку.
+	ErrInvalidChecker = errors.New("invalid checker")
+	// ErrCustomChecker режим custom выполняет не CompareOutput, а раннер программы-чекера.
+	ErrCustomChecker = errors.New("custom checker must be run by the executor")
+)
+
+// Checker способ сравнения вывода с ожидаемым; nil — CheckWhitespace.
+//
+// Программа-чекер (Mode == CheckCustom) — Go-программа с пакетом main. Она получает
+// на stdin JSON CheckerInput и отвечает кодом выхода: 0 — ответ верный, 1 — неверный,
+// остальное — ошибка чекера. Первая строка stdout показывается студенту как комментарий.
+type Checker struct {
+	Mode      string  `json:"mode"`
+	Tolerance float64 `json:"tolerance,omitempty"`
+	Program   string  `json:"program,omitempty"`
+}
+
+// CheckerInput данные для программы-чекера.
+type CheckerInput struct {
+	Input    string `json:"input"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// CheckResult вердикт чекера.
+type CheckResult struct {
+	Passed  bool
+	Message string // почему ответ не принят (или комментарий программы-чекера)
+}
+
+// CheckerMode режим чекера с учётом значения по умолчанию.
+func CheckerMode(c *Checker) string {
+	if c == nil || c.Mode == "" {
+		return CheckWhitespace
+	}
+	return c.Mode
+}
+
+// ValidateChecker проверяет настройки чекера теста с ожидаемым выводом expected.
+// Ошибка оборачивает ErrInvalidChecker.
+func ValidateChecker(c *Checker, expected string) error {
+	if c == nil {
+		return nil
+	}
+	switch CheckerMode(c) {
+	case CheckExact, CheckWhitespace, CheckLineSet:
+	case CheckFloat:
+		if c.Tolerance < 0 || math.IsNaN(c.Tolerance) || math.IsInf(c.Tolerance, 0) {
+			return fmt.Errorf("%w: tolerance must be a non-negative number", ErrInvalidChecker)
+		}
+	case CheckRegex:
+		if _, err := compileExpected(expected); err != nil {
+			return fmt.Errorf("%w: expected_output is not a valid regular expression: %v", ErrInvalidChecker, err)
+		}
+	case CheckJSON:
+		var v interface{}
+		if err := json.Unmarshal([]byte(expected), &v); err != nil {
+			return fmt.Errorf("%w: expected_output is not valid JSON", ErrInvalidChecker)
+		}
+	case CheckCustom:
+		if strings.TrimSpace(c.Program) == "" {
+			return fmt.Errorf("%w: program is required for the custom checker", ErrInvalidChecker)
+		}
+		if len(c.Program) > MaxCheckerProgramBytes {
+			return fmt.Errorf("%w: program must be at most %d KB", ErrInvalidChecker, MaxCheckerProgramBytes>>10)
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidChecker, c.Mode)
+	}
+	return nil
+}
+
+// CompareOutput сравнивает вывод встроенным чекером. Для CheckCustom возвращает ErrCustomChecker.
+func CompareOutput(c *Checker, expected, actual string) (CheckResult, error) {
+	switch CheckerMode(c) {
+	case CheckExact:
+		if actual == expected {
+			return CheckResult{Passed: true}, nil
+		}
+		return CheckResult{Message: "output differs from the expected one"}, nil
+	case CheckWhitespace:
+		return compareTokens(expected, actual, nil), nil
+	case CheckFloat:
+		tol := c.Tolerance
+		if tol == 0 {
+			tol = DefaultTolerance
+		}
+		return compareTokens(expected, actual, func(e, a string) bool { return floatsEqual(e, a, tol) }), nil
+	case CheckLineSet:
+		return compareLineSets(expected, actual), nil
+	case CheckRegex:
+		re, err := compileExpected(expected)
+		if err != nil {
+			return CheckResult{}, fmt.Errorf("%w: %v", ErrInvalidChecker, err)
+		}
+		if re.MatchString(strings.TrimSpace(actual)) {
+			return CheckResult{Passed: true}, nil
+		}
+		return CheckResult{Message: "output does not match the expected pattern"}, nil
+	case CheckJSON:
+		var want, got interface{}
+		if err := json.Unmarshal([]byte(expected), &want); err != nil {
+			return CheckResult{}, fmt.Errorf("%w: expected_output is not valid JSON", ErrInvalidChecker)
+		}
+		if err := json.Unmarshal([]byte(actual), &got); err != nil {
+			return CheckResult{Message: "output is not valid JSON"}, nil
+		}
+		if reflect.DeepEqual(want, got) {
+			return CheckResult{Passed: true}, nil
+		}
+		return CheckResult{Message: "JSON differs from the expected one"}, nil
+	case CheckCustom:
+		return CheckResult{}, ErrCustomChecker
+	}
+	return CheckResult{}, fmt.Errorf("%w: unknown mode %q", ErrInvalidChecker, c.Mode)
+}
+
+func compileExpected(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + strings.TrimSpace(pattern) + `)$`)
+}
+
+// compareTokens сравнивает вывод по словам; eq == nil — точное совпадение слов.
+func compareTokens(expected, actual string, eq func(e, a string) bool) CheckResult {
+	want, got := strings.Fields(expected), strings.Fields(actual)
+	for i := 0; i < len(want) && i < len(got); i++ {
+		if want[i] == got[i] || (eq != nil && eq(want[i], got[i])) {
+			continue
+		}
+		return CheckResult{Message: fmt.Sprintf("token %d: expected %s, got %s", i+1, clip(want[i]), clip(got[i]))}
+	}
+	if len(want) != len(got) {
+		return CheckResult{Message: fmt.Sprintf("expected %d tokens, got %d", len(want), len(got))}
+	}
+	return CheckResult{Passed: true}
+}
+
+// floatsEqual числа равны с абсолютной или относительной погрешностью tol.
+func floatsEqual(e, a string, tol float64) bool {
+	x, err1 := strconv.ParseFloat(e, 64)
+	y, err2 := strconv.ParseFloat(a, 64)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return math.IsNaN(x) && math.IsNaN(y)
+	}
+	if x == y {
+		return true
+	}
+	diff := math.Abs(x - y)
+	return diff <= tol || diff <= tol*math.Abs(x)
+}
+
+func compareLineSets(expected, actual string) CheckResult {
+	want, got := nonEmptyLines(expected), nonEmptyLines(actual)
+	if len(want) != len(got) {
+		return CheckResult{Message: fmt.Sprintf("expected %d lines, got %d", len(want), len(got))}
+	}
+	sort.Strings(want)
+	sort.Strings(got)
+	for i := range want {
+		if want[i] != got[i] {
+			return CheckResult{Message: fmt.Sprintf("unexpected line %s", clip(got[i]))}
+		}
+	}
+	return CheckResult{Passed: true}
+}
+
+func nonEmptyLines(s string) []string {
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// clip обрезает значение для сообщения студенту.
+func clip(s string) string {
+	const max = 40
+	if r := []rune(s); len(r) > max {
+		s = string(r[:max]) + "…"
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,65 @@
+package code
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompareOutput(t *testing.T) {
+	cases := []struct {
+		name     string
+		checker  *Checker
+		expected string
+		actual   string
+		passed   bool
+	}{
+		{"default ignores whitespace", nil, "1 2\n3\n", "1  2 3  \n\n", true},
+		{"default compares tokens", nil, "1 2 3", "1 2 4", false},
+		{"exact", &Checker{Mode: CheckExact}, "a\n", "a", false},
+		{"exact match", &Checker{Mode: CheckExact}, "a\n", "a\n", true},
+		{"line set", &Checker{Mode: CheckLineSet}, "b\na\na\n", "a\n  b \na\n", true},
+		{"line set counts repeats", &Checker{Mode: CheckLineSet}, "a\na\nb", "a\nb\nb", false},
+		{"float tolerance", &Checker{Mode: CheckFloat, Tolerance: 1e-3}, "pi 3.1416", "pi 3.14159265", true},
+		{"float out of tolerance", &Checker{Mode: CheckFloat}, "0.3", "0.31", false},
+		{"float relative", &Checker{Mode: CheckFloat, Tolerance: 1e-9}, "1e20", "1.0000000001e20", true},
+		{"regex", &Checker{Mode: CheckRegex}, `id=\d+`, "id=42\n", true},
+		{"regex is anchored", &Checker{Mode: CheckRegex}, `id=\d+`, "id=42 extra", false},
+		{"json", &Checker{Mode: CheckJSON}, `{"a":[1,2],"b":true}`, "{\"b\": true, \"a\": [1, 2.0]}\n", true},
+		{"json differs", &Checker{Mode: CheckJSON}, `{"a":[1,2]}`, `{"a":[2,1]}`, false},
+		{"json invalid output", &Checker{Mode: CheckJSON}, `{}`, `{`, false},
+	}
+	for _, tc := range cases {
+		res, err := CompareOutput(tc.checker, tc.expected, tc.actual)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if res.Passed != tc.passed {
+			t.Errorf("%s: passed = %v, want %v (%s)", tc.name, res.Passed, tc.passed, res.Message)
+		}
+		if !res.Passed && res.Message == "" {
+			t.Errorf("%s: failed check must explain why", tc.name)
+		}
+	}
+}
+
+func TestValidateChecker(t *testing.T) {
+	invalid := []struct {
+		checker  *Checker
+		expected string
+	}{
+		{&Checker{Mode: "fuzzy"}, ""},
+		{&Checker{Mode: CheckFloat, Tolerance: -1}, ""},
+		{&Checker{Mode: CheckRegex}, "(unclosed"},
+		{&Checker{Mode: CheckJSON}, "{"},
+		{&Checker{Mode: CheckCustom}, ""},
+	}
+	for _, tc := range invalid {
+		if err := ValidateChecker(tc.checker, tc.expected); !errors.Is(err, ErrInvalidChecker) {
+			t.Errorf("%+v: expected ErrInvalidChecker, got %v", tc.checker, err)
+		}
+	}
+	if _, err := CompareOutput(&Checker{Mode: CheckCustom, Program: "package main"}, "", ""); !errors.Is(err, ErrCustomChecker) {
+		t.Errorf("custom mode must be delegated, got %v", err)
+	}
+}
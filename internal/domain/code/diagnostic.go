@@ -0,0 +1,12 @@
+package code
+
+// Diagnostic замечание статического анализа к строкам [Line, EndLine] решения: go vet,
+// отличие от gofmt или одна из проверок идиоматичности (см. Course.Analyzers).
+type Diagnostic struct {
+	Analyzer   string `json:"analyzer"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column,omitempty"`
+	EndLine    int    `json:"end_line,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"` // как записать строки Line..EndLine (для gofmt)
+}
@@ -0,0 +1,101 @@
+package code
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Ограничения на дерево файлов проекта.
+const (
+	MaxFiles      = 64
+	MaxFileBytes  = 64 << 10
+	MaxTotalBytes = 256 << 10
+	MaxPathLen    = 128
+	MaxPathDepth  = 4 // вложенность каталогов над файлом
+)
+
+// MainFile имя файла, в который пишется одиночный Code.
+const MainFile = "main.go"
+
+// ErrInvalidFiles дерево файлов не прошло проверку.
+var ErrInvalidFiles = errors.New("invalid files")
+
+// ValidateFiles проверяет дерево файлов: путь → содержимое, пути относительные, через "/".
+// Ошибка оборачивает ErrInvalidFiles.
+func ValidateFiles(files map[string]string) error {
+	if len(files) == 0 {
+		return fmt.Errorf("%w: at least one file is required", ErrInvalidFiles)
+	}
+	if len(files) > MaxFiles {
+		return fmt.Errorf("%w: at most %d files are allowed", ErrInvalidFiles, MaxFiles)
+	}
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	total := 0
+	folded := make(map[string]string, len(files))
+	for _, p := range paths {
+		if err := ValidatePath(p); err != nil {
+			return err
+		}
+		// на нечувствительных к регистру ФС такие файлы перезапишут друг друга
+		if prev, ok := folded[strings.ToLower(p)]; ok {
+			return fmt.Errorf("%w: %q conflicts with %q", ErrInvalidFiles, p, prev)
+		}
+		folded[strings.ToLower(p)] = p
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			if _, ok := files[dir]; ok {
+				return fmt.Errorf("%w: %q is both a file and a directory", ErrInvalidFiles, dir)
+			}
+		}
+		size := len(files[p])
+		if size > MaxFileBytes {
+			return fmt.Errorf("%w: %s must be at most %d KB", ErrInvalidFiles, p, MaxFileBytes>>10)
+		}
+		total += size
+	}
+	if total > MaxTotalBytes {
+		return fmt.Errorf("%w: total size must be at most %d KB", ErrInvalidFiles, MaxTotalBytes>>10)
+	}
+	return nil
+}
+
+// ValidatePath проверяет путь файла: относительный, канонический, без скрытых
+// сегментов и выхода из корня, только латиница, цифры, '.', '_' и '-'.
+func ValidatePath(p string) error {
+	if p == "" || len(p) > MaxPathLen {
+		return fmt.Errorf("%w: path must be 1..%d characters", ErrInvalidFiles, MaxPathLen)
+	}
+	if path.IsAbs(p) || path.Clean(p) != p {
+		return fmt.Errorf("%w: path %q must be relative and clean", ErrInvalidFiles, p)
+	}
+	segs := strings.Split(p, "/")
+	if len(segs)-1 > MaxPathDepth {
+		return fmt.Errorf("%w: path %q is nested deeper than %d directories", ErrInvalidFiles, p, MaxPathDepth)
+	}
+	for _, seg := range segs {
+		if strings.HasPrefix(seg, ".") {
+			return fmt.Errorf("%w: path %q must not contain hidden or parent segments", ErrInvalidFiles, p)
+		}
+		for _, r := range seg {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+				return fmt.Errorf("%w: path %q contains unsupported character %q", ErrInvalidFiles, p, r)
+			}
+		}
+	}
+	return nil
+}
+
+// Sources дерево файлов запроса: Files, а если их нет — Code в main.go.
+func (r ExecuteRequest) Sources() map[string]string {
+	if len(r.Files) > 0 {
+		return r.Files
+	}
+	return map[string]string{MainFile: r.Code}
+}
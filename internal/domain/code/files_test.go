@@ -0,0 +1,36 @@
+package code
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateFiles(t *testing.T) {
+	ok := map[string]string{
+		"go.mod":                  "module demo\n",
+		"main.go":                 "package main\n",
+		"internal/greet/greet.go": "package greet\n",
+	}
+	if err := ValidateFiles(ok); err != nil {
+		t.Fatalf("valid tree rejected: %v", err)
+	}
+
+	cases := map[string]map[string]string{
+		"empty":          {},
+		"absolute":       {"/etc/passwd": "x"},
+		"parent":         {"../main.go": "x"},
+		"unclean":        {"a//main.go": "x"},
+		"hidden":         {".git/config": "x"},
+		"backslash":      {`a\main.go`: "x"},
+		"too deep":       {"a/b/c/d/e/main.go": "x"},
+		"file and dir":   {"greet": "x", "greet/greet.go": "x"},
+		"case collision": {"Main.go": "x", "main.go": "x"},
+		"too large":      {"main.go": strings.Repeat("x", MaxFileBytes+1)},
+	}
+	for name, files := range cases {
+		if err := ValidateFiles(files); !errors.Is(err, ErrInvalidFiles) {
+			t.Errorf("%s: expected ErrInvalidFiles, got %v", name, err)
+		}
+	}
+}
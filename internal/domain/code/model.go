@@ -2,17 +2,23 @@ package code
 
 // ExecuteRequest запрос на выполнение кода
 type ExecuteRequest struct {
-	Code     string     `json:"code" binding:"required"`
-	Language string     `json:"language" binding:"required,oneof=python javascript java go cpp"`
-	Stdin    string     `json:"stdin,omitempty"`
-	TestCases []TestCase `json:"test_cases,omitempty"`
+	Code      string            `json:"code" binding:"required_without=Files"`
+	Files     map[string]string `json:"files,omitempty"` // проект из нескольких файлов (путь → содержимое); вместо Code
+	Language  string            `json:"language" binding:"required,oneof=python javascript java go cpp"`
+	Stdin     string            `json:"stdin,omitempty"`
+	TestCases []TestCase        `json:"test_cases,omitempty"`
+	CourseID  string            `json:"course_id,omitempty" binding:"omitempty,uuid"` // курс, в котором запускается код; влияет на выбор бэкенда
+	NoCache   bool              `json:"no_cache,omitempty"`                           // не брать результат из кеша и не сохранять: программа недетерминирована (случайные числа, время)
+	// LessonTests тесты взяты сервером из контента урока; только у них может быть Checker.
+	LessonTests bool `json:"-"`
 }
 
 // TestCase тестовый случай
 type TestCase struct {
-	Input          string `json:"input"`
-	ExpectedOutput string `json:"expected_output"`
-	Description    string `json:"description,omitempty"`
+	Input          string   `json:"input"`
+	ExpectedOutput string   `json:"expected_output"`
+	Description    string   `json:"description,omitempty"`
+	Checker        *Checker `json:"checker,omitempty"` // nil — сравнение по словам (CheckWhitespace)
 }
 
 // ExecuteResponse ответ выполнения кода
@@ -23,14 +29,26 @@ type ExecuteResponse struct {
 	TestResults     []TestResult `json:"test_results,omitempty"`
 	ExecutionTimeMs int64        `json:"execution_time_ms"`
 	ExitCode        int          `json:"exit_code,omitempty"`
+	Verdict         Verdict      `json:"verdict"`          // с тестами — вердикт первого непройденного теста
+	Status          string       `json:"status,omitempty"` // статус бэкенда в терминах Judge0, например «Runtime Error (SIGSEGV)»
+	ExitSignal      int          `json:"exit_signal,omitempty"`
+	TimeMs          int64        `json:"time_ms,omitempty"`     // время работы программы по данным бэкенда, без очереди
+	MemoryKB        int          `json:"memory_kb,omitempty"`   // пиковая память программы
+	Cached          bool         `json:"cached,omitempty"`      // результат взят из кеша, программа не запускалась
+	Diagnostics     []Diagnostic `json:"diagnostics,omitempty"` // замечания статического анализа (только Go)
 }
 
 // TestResult результат теста
 type TestResult struct {
-	TestCase      TestCase `json:"test_case"`
-	ActualOutput  string   `json:"actual_output"`
-	Passed        bool     `json:"passed"`
-	ErrorMessage  string   `json:"error_message,omitempty"`
-	ExecutionTimeMs int64   `json:"execution_time_ms,omitempty"`
+	TestCase        TestCase `json:"test_case"`
+	Index           int      `json:"index"` // номер теста в запросе; в потоке результаты идут по готовности
+	ActualOutput    string   `json:"actual_output"`
+	Passed          bool     `json:"passed"`
+	ErrorMessage    string   `json:"error_message,omitempty"`
+	CheckerMessage  string   `json:"checker_message,omitempty"` // почему чекер не принял ответ
+	ExecutionTimeMs int64    `json:"execution_time_ms,omitempty"`
+	Verdict         Verdict  `json:"verdict"`
+	Status          string   `json:"status,omitempty"`
+	MemoryKB        int      `json:"memory_kb,omitempty"`
+	Cached          bool     `json:"cached,omitempty"`
 }
-
@@ -0,0 +1,14 @@
+package code
+
+// Verdict итог выполнения программы или теста.
+type Verdict string
+
+const (
+	VerdictAccepted         Verdict = "accepted"
+	VerdictWrongAnswer      Verdict = "wrong_answer" // программа отработала, но чекер не принял вывод
+	VerdictTimeLimit        Verdict = "time_limit_exceeded"
+	VerdictMemoryLimit      Verdict = "memory_limit_exceeded"
+	VerdictCompilationError Verdict = "compilation_error"
+	VerdictRuntimeError     Verdict = "runtime_error"  // ненулевой код выхода или сигнал, см. Status и ExitSignal
+	VerdictInternalError    Verdict = "internal_error" // сбой бэкенда выполнения или чекера
+)
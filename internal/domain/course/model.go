@@ -2,6 +2,9 @@ package course
 
 import "github.com/google/uuid"
 
+// Currency валюта цен курсов: Price — в рублях, PriceCents — в копейках.
+const Currency = "RUB"
+
 // Course доменная модель курса.
 type Course struct {
 	ID            uuid.UUID `json:"id"`
@@ -9,6 +12,7 @@ type Course struct {
 	Title         string    `json:"title"`
 	Description   string    `json:"description"`
 	Summary       string    `json:"summary"`
+	SourceLocale  string    `json:"source_locale"`  // язык исходного контента курса (ru, en)
 	Language      string    `json:"language"`       // python, javascript, java, go, cpp
 	Difficulty    string    `json:"difficulty"`     // beginner, intermediate, advanced
 	DurationHours int       `json:"duration_hours"` // длительность курса в часах
@@ -24,5 +28,18 @@ type Course struct {
 	Price         *float64  `json:"price"`          // цена в рублях (null для бесплатных)
 	PriceCents    int       `json:"priceCents"`     // цена в копейках (для обратной совместимости)
 	Rating        float64   `json:"rating"`         // рейтинг курса
-	Popularity    int       `json:"popularity"`     // популярность
+	Popularity    int       `json:"popularity"`     // популярность (вычисляемое)
+	TrendingScore float64   `json:"trending_score"` // тренд по недавним записям (вычисляемое)
+	// Analyzers проверки статического анализа решений на Go (vet, gofmt, unusedresult, shadow,
+	// errcheck, ineffassign); nil — все, пустой список — анализ выключен.
+	Analyzers []string `json:"analyzers"`
+}
+
+// Stats вычисляемые счётчики курса, пересчитываются фоновой задачей.
+type Stats struct {
+	LessonsCount  int     `json:"lessons_count"`
+	StudentsCount int     `json:"students_count"`
+	DurationMin   int     `json:"duration_minutes"`
+	Popularity    int     `json:"popularity"`
+	TrendingScore float64 `json:"trending_score"`
 }
@@ -15,6 +15,8 @@ type Repository interface {
 	Update(ctx context.Context, id uuid.UUID, updated Course) (Course, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	Search(ctx context.Context, f ListFilter) (ListResult, error)
+	// UpdateStats обновляет только вычисляемые счётчики курса.
+	UpdateStats(ctx context.Context, id uuid.UUID, stats Stats) error
 }
 
 // ListFilter параметры фильтрации/пагинации списка курсов.
@@ -28,7 +30,7 @@ type ListFilter struct {
 	Page       int
 	PageSize   int
 	Limit      int    // альтернатива PageSize
-	Sort       string // e.g. "title_asc", "popularity_desc", "rating_desc", "newest"
+	Sort       string // e.g. "title_asc", "popularity_desc", "trending_desc", "rating_desc", "newest"
 }
 
 // ListResult результат поиска с пагинацией.
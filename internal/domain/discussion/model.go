@@ -0,0 +1,180 @@
+package discussion
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Статусы тредов и сообщений.
+const (
+	StatusVisible = "visible"
+	StatusHidden  = "hidden"  // скрыто модератором, видно только персоналу
+	StatusDeleted = "deleted" // удалено; остаётся заглушкой, если на него есть ответы
+)
+
+// Виды объектов для голосования и модерации.
+const (
+	TargetThread = "thread"
+	TargetPost   = "post"
+)
+
+// Ограничения на содержимое.
+const (
+	MaxTitleLen = 200
+	MaxBodyLen  = 20000
+	MaxCodeLen  = 20000
+	MaxDepth    = 5 // глубина вложенности ответов; глубже — ответ прикрепляется к предку
+)
+
+var ErrInvalid = errors.New("invalid discussion input")
+
+// CodeAttachment фрагмент кода, приложенный к вопросу или ответу.
+type CodeAttachment struct {
+	Language string `json:"language"`
+	Source   string `json:"source"`
+}
+
+// Thread вопрос-обсуждение к уроку.
+type Thread struct {
+	ID             uuid.UUID       `json:"id"`
+	LessonID       uuid.UUID       `json:"lesson_id"`
+	CourseID       uuid.UUID       `json:"course_id"`
+	AuthorID       uuid.UUID       `json:"author_id"`
+	Title          string          `json:"title"`
+	Body           string          `json:"body"` // Markdown
+	BodyHTML       string          `json:"body_html,omitempty"`
+	Code           *CodeAttachment `json:"code,omitempty"`
+	Upvotes        int             `json:"upvotes"`
+	RepliesCount   int             `json:"replies_count"`
+	AcceptedPostID *uuid.UUID      `json:"accepted_post_id,omitempty"`
+	Status         string          `json:"status"`
+	Locked         bool            `json:"locked"` // закрыт модератором для новых ответов
+	Voted          bool            `json:"voted"`  // голос текущего пользователя (не хранится)
+	LastActivityAt time.Time       `json:"last_activity_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	EditedAt       *time.Time      `json:"edited_at,omitempty"`
+}
+
+// Post ответ в треде; ParentID == nil — ответ на сам вопрос.
+type Post struct {
+	ID        uuid.UUID       `json:"id"`
+	ThreadID  uuid.UUID       `json:"thread_id"`
+	ParentID  *uuid.UUID      `json:"parent_id,omitempty"`
+	AuthorID  uuid.UUID       `json:"author_id"`
+	Body      string          `json:"body"`
+	BodyHTML  string          `json:"body_html,omitempty"`
+	Code      *CodeAttachment `json:"code,omitempty"`
+	Upvotes   int             `json:"upvotes"`
+	Accepted  bool            `json:"accepted"`
+	Voted     bool            `json:"voted"`
+	Status    string          `json:"status"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	EditedAt  *time.Time      `json:"edited_at,omitempty"`
+}
+
+// PostNode ответ с вложенными ответами.
+type PostNode struct {
+	Post
+	Replies []*PostNode `json:"replies"`
+}
+
+var codeLanguages = map[string]bool{"go": true, "python": true, "javascript": true, "java": true, "cpp": true, "text": true}
+
+// ValidateBody проверяет заголовок (если требуется), текст и вложение.
+func ValidateBody(title string, requireTitle bool, body string, code *CodeAttachment) error {
+	if requireTitle {
+		t := strings.TrimSpace(title)
+		if t == "" || utf8.RuneCountInString(t) > MaxTitleLen {
+			return fmt.Errorf("%w: title must be 1..%d characters", ErrInvalid, MaxTitleLen)
+		}
+	}
+	if strings.TrimSpace(body) == "" && (code == nil || strings.TrimSpace(code.Source) == "") {
+		return fmt.Errorf("%w: body or code is required", ErrInvalid)
+	}
+	if utf8.RuneCountInString(body) > MaxBodyLen {
+		return fmt.Errorf("%w: body is longer than %d characters", ErrInvalid, MaxBodyLen)
+	}
+	if code != nil {
+		if !codeLanguages[code.Language] {
+			return fmt.Errorf("%w: unsupported code language %q", ErrInvalid, code.Language)
+		}
+		if len(code.Source) > MaxCodeLen {
+			return fmt.Errorf("%w: code is longer than %d bytes", ErrInvalid, MaxCodeLen)
+		}
+	}
+	return nil
+}
+
+// Depth глубина ответа (ответ на вопрос — 1).
+func Depth(posts map[uuid.UUID]Post, p Post) int {
+	d := 1
+	for p.ParentID != nil {
+		parent, ok := posts[*p.ParentID]
+		if !ok {
+			break
+		}
+		p = parent
+		d++
+	}
+	return d
+}
+
+// BuildTree строит дерево ответов: корни — принятый ответ первым, затем по голосам и времени;
+// вложенные ответы — по времени. Сообщения с неизвестным родителем считаются корнями.
+func BuildTree(posts []Post) []*PostNode {
+	nodes := make(map[uuid.UUID]*PostNode, len(posts))
+	for i := range posts {
+		nodes[posts[i].ID] = &PostNode{Post: posts[i], Replies: []*PostNode{}}
+	}
+	roots := []*PostNode{}
+	for i := range posts {
+		n := nodes[posts[i].ID]
+		if p := posts[i].ParentID; p != nil {
+			if parent, ok := nodes[*p]; ok {
+				parent.Replies = append(parent.Replies, n)
+				continue
+			}
+		}
+		roots = append(roots, n)
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		a, b := roots[i], roots[j]
+		if a.Accepted != b.Accepted {
+			return a.Accepted
+		}
+		if a.Upvotes != b.Upvotes {
+			return a.Upvotes > b.Upvotes
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	var byTime func(ns []*PostNode)
+	byTime = func(ns []*PostNode) {
+		for _, n := range ns {
+			sort.SliceStable(n.Replies, func(i, j int) bool { return n.Replies[i].CreatedAt.Before(n.Replies[j].CreatedAt) })
+			byTime(n.Replies)
+		}
+	}
+	byTime(roots)
+	return roots
+}
+
+// Prune убирает удалённые сообщения без ответов (после удаления ответов заглушка не нужна).
+func Prune(nodes []*PostNode) []*PostNode {
+	out := nodes[:0]
+	for _, n := range nodes {
+		n.Replies = Prune(n.Replies)
+		if n.Status == StatusDeleted && len(n.Replies) == 0 {
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
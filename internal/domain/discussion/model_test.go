@@ -0,0 +1,52 @@
+package discussion
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildTree(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	mk := func(parent *uuid.UUID, minute, votes int, accepted bool, status string) Post {
+		return Post{ID: uuid.New(), ParentID: parent, Upvotes: votes, Accepted: accepted, Status: status, CreatedAt: base.Add(time.Duration(minute) * time.Minute)}
+	}
+	first := mk(nil, 1, 0, false, StatusVisible)
+	top := mk(nil, 2, 5, false, StatusVisible)
+	accepted := mk(nil, 3, 1, true, StatusVisible)
+	late := mk(&first.ID, 9, 0, false, StatusVisible)
+	early := mk(&first.ID, 4, 10, false, StatusVisible)
+	gone := mk(nil, 5, 0, false, StatusDeleted)
+
+	roots := Prune(BuildTree([]Post{late, first, top, accepted, early, gone}))
+	if len(roots) != 3 {
+		t.Fatalf("want 3 roots after prune, got %d", len(roots))
+	}
+	if roots[0].ID != accepted.ID || roots[1].ID != top.ID || roots[2].ID != first.ID {
+		t.Fatalf("unexpected root order")
+	}
+	replies := roots[2].Replies
+	if len(replies) != 2 || replies[0].ID != early.ID || replies[1].ID != late.ID {
+		t.Fatalf("replies must be ordered by time")
+	}
+}
+
+func TestValidateBody(t *testing.T) {
+	if err := ValidateBody("Почему range копирует?", true, "текст", &CodeAttachment{Language: "go", Source: "for _, v := range s {}"}); err != nil {
+		t.Fatalf("valid input rejected: %v", err)
+	}
+	bad := []error{
+		ValidateBody("", true, "body", nil),
+		ValidateBody("", false, "  ", nil),
+		ValidateBody("", false, "x", &CodeAttachment{Language: "brainfuck", Source: "+"}),
+		ValidateBody("", false, strings.Repeat("я", MaxBodyLen+1), nil),
+	}
+	for i, err := range bad {
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("case %d: want ErrInvalid, got %v", i, err)
+		}
+	}
+}
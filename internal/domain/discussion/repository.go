@@ -0,0 +1,42 @@
+package discussion
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Сортировки списка тредов.
+const (
+	SortActive     = "active" // по последней активности
+	SortNew        = "new"
+	SortTop        = "top"
+	SortUnanswered = "unanswered" // без принятого ответа, новые сверху
+)
+
+// ThreadFilter параметры списка тредов урока.
+type ThreadFilter struct {
+	LessonID      uuid.UUID
+	Sort          string
+	IncludeHidden bool
+	Page          int
+	PageSize      int
+}
+
+type Repository interface {
+	CreateThread(ctx context.Context, t Thread) (Thread, error)
+	GetThread(ctx context.Context, id uuid.UUID) (Thread, error)
+	UpdateThread(ctx context.Context, t Thread) (Thread, error)
+	ListThreads(ctx context.Context, f ThreadFilter) ([]Thread, int64, error)
+
+	// CreatePost сохраняет ответ и в той же транзакции увеличивает счётчик и активность треда.
+	CreatePost(ctx context.Context, p Post) (Post, error)
+	GetPost(ctx context.Context, id uuid.UUID) (Post, error)
+	UpdatePost(ctx context.Context, p Post) (Post, error)
+	ListPosts(ctx context.Context, threadID uuid.UUID) ([]Post, error)
+
+	// SetVote ставит (up) или снимает голос пользователя и возвращает новое число голосов цели.
+	SetVote(ctx context.Context, targetType string, targetID, userID uuid.UUID, up bool) (int, error)
+	// VotedBy возвращает, за какие из целей голосовал пользователь.
+	VotedBy(ctx context.Context, userID uuid.UUID, targetIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
@@ -18,4 +18,6 @@ type Repository interface {
 	Upsert(ctx context.Context, e Enrollment) error
 	IsEnrolled(ctx context.Context, userID, courseID uuid.UUID) (bool, error)
 	ListByUser(ctx context.Context, userID uuid.UUID) ([]Enrollment, error)
+	CountByCourse(ctx context.Context, courseID uuid.UUID) (int64, error)
+	ListByCourseSince(ctx context.Context, courseID uuid.UUID, since time.Time) ([]Enrollment, error)
 }
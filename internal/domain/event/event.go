@@ -0,0 +1,30 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы доменных событий.
+const (
+	EnrollmentCreated = "enrollment.created"
+	LessonCreated     = "lesson.created"
+	LessonUpdated     = "lesson.updated"
+	LessonDeleted     = "lesson.deleted"
+)
+
+// Event доменное событие. CourseID — курс, к которому относится изменение.
+type Event struct {
+	Type       string
+	CourseID   uuid.UUID
+	EntityID   uuid.UUID
+	UserID     uuid.UUID
+	OccurredAt time.Time
+}
+
+// Publisher публикует доменные события; реализация не должна блокировать вызывающего надолго.
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
@@ -0,0 +1,284 @@
+package lesson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/example/learngo/internal/domain/code"
+)
+
+// Типы уроков.
+const (
+	TypeTheory = "theory"
+	TypeVideo  = "video"
+	TypeQuiz   = "quiz"
+	TypeTask   = "task"
+
+	typeLegacyText = "text" // значение по умолчанию в старых строках БД
+)
+
+// NormalizeType приводит тип к каноничному: пустой и legacy "text" — это теория.
+func NormalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if t == "" || t == typeLegacyText {
+		return TypeTheory
+	}
+	return t
+}
+
+// IsKnownType сообщает, поддерживается ли тип урока.
+func IsKnownType(t string) bool {
+	switch NormalizeType(t) {
+	case TypeTheory, TypeVideo, TypeQuiz, TypeTask:
+		return true
+	}
+	return false
+}
+
+// TheoryContent контент текстового урока.
+type TheoryContent struct {
+	LessonContent
+}
+
+// VideoContent контент видеоурока: внешний URL или загруженный видеофайл.
+type VideoContent struct {
+	LessonContent
+	VideoURL     string `json:"video_url,omitempty"`
+	VideoAssetID string `json:"video_asset_id,omitempty"`
+	DurationSec  int    `json:"duration_sec,omitempty"`
+	Transcript   string `json:"transcript,omitempty"`
+}
+
+// QuizContent настройки квиза; сами вопросы хранятся отдельно.
+type QuizContent struct {
+	LessonContent
+	PassingScore int  `json:"passing_score"`            // проходной балл, % (0..100)
+	MaxAttempts  int  `json:"max_attempts"`             // 0 — без ограничений
+	PoolSize     int  `json:"pool_size"`                // сколько вопросов выдавать из банка; 0 — все
+	Shuffle      bool `json:"shuffle"`                  // перемешивать варианты ответов
+	TimeLimitSec int  `json:"time_limit_sec,omitempty"` // 0 — без ограничения
+}
+
+// TaskContent контент практического задания.
+type TaskContent struct {
+	LessonContent
+}
+
+// Content типизированный контент урока.
+type Content interface {
+	// Validate возвращает ошибки полей; пути указываются относительно content.
+	Validate() []FieldError
+	// Base общая часть контента (теория, подсказки, тесты).
+	Base() LessonContent
+	lessonContent() *LessonContent
+}
+
+// Base возвращает общую часть контента; промотируется во все типы уроков.
+func (c LessonContent) Base() LessonContent { return c }
+
+func (c *LessonContent) lessonContent() *LessonContent { return c }
+
+// Tests тесты, на которых проверяется решение: test_cases, а без них — ожидаемый вывод урока.
+func (c LessonContent) Tests() []TestCase {
+	if len(c.TestCases) > 0 || c.ExpectedOutput == "" {
+		return c.TestCases
+	}
+	return []TestCase{{ExpectedOutput: c.ExpectedOutput}}
+}
+
+// HideAnswers убирает из контента ожидаемый вывод и чекеры тестов: по ним сервер проверяет
+// решение, студенту остаются входные данные и описания тестов.
+func HideAnswers(c Content) {
+	base := c.lessonContent()
+	base.ExpectedOutput = ""
+	tests := make([]TestCase, len(base.TestCases))
+	for i, tc := range base.TestCases {
+		tests[i] = TestCase{Input: tc.Input, Description: tc.Description}
+	}
+	base.TestCases = tests
+}
+
+// FieldError ошибка конкретного поля.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError набор ошибок полей контента урока.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+	return "invalid lesson content: " + strings.Join(parts, "; ")
+}
+
+func newContent(typ string) (Content, error) {
+	switch NormalizeType(typ) {
+	case TypeTheory:
+		return &TheoryContent{}, nil
+	case TypeVideo:
+		return &VideoContent{}, nil
+	case TypeQuiz:
+		return &QuizContent{}, nil
+	case TypeTask:
+		return &TaskContent{}, nil
+	}
+	return nil, &ValidationError{Errors: []FieldError{{Field: "type", Message: fmt.Sprintf("unknown lesson type %q", typ)}}}
+}
+
+// ParseContent мигрирует контент до текущей версии, строго декодирует его в структуру
+// для типа урока (неизвестные поля запрещены) и валидирует. Ошибки — *ValidationError.
+func ParseContent(typ string, raw json.RawMessage) (Content, error) {
+	c, err := newContent(typ)
+	if err != nil {
+		return nil, err
+	}
+	migrated, _, err := MigrateContent(typ, raw)
+	if err != nil {
+		return nil, &ValidationError{Errors: []FieldError{{Field: "content", Message: err.Error()}}}
+	}
+	dec := json.NewDecoder(bytes.NewReader(migrated))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return nil, &ValidationError{Errors: []FieldError{decodeFieldError(err)}}
+	}
+	if errs := c.Validate(); len(errs) > 0 {
+		for i := range errs {
+			errs[i].Field = "content." + errs[i].Field
+		}
+		return nil, &ValidationError{Errors: errs}
+	}
+	return c, nil
+}
+
+// DecodeContent мигрирует и декодирует сохранённый контент без валидации — для чтения.
+func DecodeContent(typ string, raw json.RawMessage) (Content, error) {
+	c, err := newContent(typ)
+	if err != nil {
+		return nil, err
+	}
+	migrated, _, err := MigrateContent(typ, raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(migrated, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func decodeFieldError(err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return FieldError{Field: "content." + typeErr.Field, Message: "must be " + jsonTypeName(typeErr.Type.Kind().String())}
+	case errors.As(err, &syntaxErr):
+		return FieldError{Field: "content", Message: fmt.Sprintf("invalid JSON at offset %d", syntaxErr.Offset)}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		name := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return FieldError{Field: "content." + name, Message: "unknown field"}
+	}
+	return FieldError{Field: "content", Message: err.Error()}
+}
+
+func jsonTypeName(kind string) string {
+	switch kind {
+	case "slice", "array":
+		return "an array"
+	case "struct", "map":
+		return "an object"
+	case "string":
+		return "a string"
+	case "bool":
+		return "a boolean"
+	}
+	return "a number"
+}
+
+func (c LessonContent) validate() []FieldError {
+	var errs []FieldError
+	if c.SchemaVersion != ContentSchemaVersion {
+		errs = append(errs, FieldError{Field: "schema_version", Message: fmt.Sprintf("must be %d", ContentSchemaVersion)})
+	}
+	for i, o := range c.Objectives {
+		if strings.TrimSpace(o) == "" {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("objectives[%d]", i), Message: "must not be empty"})
+		}
+	}
+	for i, h := range c.Hints {
+		if strings.TrimSpace(h) == "" {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("hints[%d]", i), Message: "must not be empty"})
+		}
+	}
+	for i, tc := range c.TestCases {
+		if tc.Input == "" && tc.ExpectedOutput == "" && code.CheckerMode(tc.Checker) != code.CheckCustom {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("test_cases[%d]", i), Message: "input or expected_output is required"})
+		}
+		if err := code.ValidateChecker(tc.Checker, tc.ExpectedOutput); err != nil {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("test_cases[%d].checker", i), Message: strings.TrimPrefix(err.Error(), code.ErrInvalidChecker.Error()+": ")})
+		}
+	}
+	return errs
+}
+
+func (c *TheoryContent) Validate() []FieldError {
+	errs := c.LessonContent.validate()
+	if strings.TrimSpace(c.Theory) == "" {
+		errs = append(errs, FieldError{Field: "theory", Message: "is required"})
+	}
+	return errs
+}
+
+func (c *VideoContent) Validate() []FieldError {
+	errs := c.LessonContent.validate()
+	if c.VideoURL == "" && c.VideoAssetID == "" {
+		errs = append(errs, FieldError{Field: "video_url", Message: "video_url or video_asset_id is required"})
+	}
+	if c.VideoURL != "" {
+		if u, err := url.Parse(c.VideoURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, FieldError{Field: "video_url", Message: "must be an absolute http(s) URL"})
+		}
+	}
+	if c.DurationSec < 0 {
+		errs = append(errs, FieldError{Field: "duration_sec", Message: "must not be negative"})
+	}
+	return errs
+}
+
+func (c *QuizContent) Validate() []FieldError {
+	errs := c.LessonContent.validate()
+	if c.PassingScore < 0 || c.PassingScore > 100 {
+		errs = append(errs, FieldError{Field: "passing_score", Message: "must be between 0 and 100"})
+	}
+	if c.MaxAttempts < 0 {
+		errs = append(errs, FieldError{Field: "max_attempts", Message: "must not be negative"})
+	}
+	if c.PoolSize < 0 {
+		errs = append(errs, FieldError{Field: "pool_size", Message: "must not be negative"})
+	}
+	if c.TimeLimitSec < 0 {
+		errs = append(errs, FieldError{Field: "time_limit_sec", Message: "must not be negative"})
+	}
+	return errs
+}
+
+func (c *TaskContent) Validate() []FieldError {
+	errs := c.LessonContent.validate()
+	if strings.TrimSpace(c.CodeTemplate) == "" {
+		errs = append(errs, FieldError{Field: "code_template", Message: "is required"})
+	}
+	if c.ExpectedOutput == "" && len(c.TestCases) == 0 {
+		errs = append(errs, FieldError{Field: "test_cases", Message: "expected_output or at least one test case is required"})
+	}
+	return errs
+}
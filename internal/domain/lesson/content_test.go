@@ -0,0 +1,89 @@
+package lesson
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func fieldsOf(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	out := map[string]string{}
+	for _, fe := range verr.Errors {
+		out[fe.Field] = fe.Message
+	}
+	return out
+}
+
+func TestMigrateLegacyContent(t *testing.T) {
+	raw := json.RawMessage(`{"text":"Переменные","hints":"первая\nвторая","test_cases":{"input":"1","expected_output":"2"}}`)
+	migrated, changed, err := MigrateContent("text", raw)
+	if err != nil || !changed {
+		t.Fatalf("migrate: changed=%v err=%v", changed, err)
+	}
+	c, err := ParseContent("text", migrated)
+	if err != nil {
+		t.Fatalf("parse migrated: %v", err)
+	}
+	th := c.(*TheoryContent)
+	if th.Theory != "Переменные" || len(th.Hints) != 2 || len(th.TestCases) != 1 || th.SchemaVersion != ContentSchemaVersion {
+		t.Fatalf("unexpected migrated content: %+v", th)
+	}
+
+	// уже актуальная версия не меняется
+	if _, changed, _ := MigrateContent(TypeTheory, migrated); changed {
+		t.Fatalf("current version must not be migrated again")
+	}
+	if _, _, err := MigrateContent(TypeTheory, json.RawMessage(`{"schema_version":99}`)); err == nil {
+		t.Fatalf("expected error for newer schema version")
+	}
+}
+
+func TestParseContentFieldErrors(t *testing.T) {
+	_, err := ParseContent(TypeTask, json.RawMessage(`{"schema_version":1,"hints":["", "ok"]}`))
+	f := fieldsOf(t, err)
+	if f["content.code_template"] == "" || f["content.hints[0]"] == "" || f["content.test_cases"] == "" {
+		t.Fatalf("unexpected field errors: %v", f)
+	}
+
+	_, err = ParseContent(TypeQuiz, json.RawMessage(`{"schema_version":1,"passing_score":"high"}`))
+	if f := fieldsOf(t, err); f["content.passing_score"] != "must be a number" {
+		t.Fatalf("unexpected type error: %v", f)
+	}
+
+	_, err = ParseContent(TypeVideo, json.RawMessage(`{"schema_version":1,"video_ulr":"x"}`))
+	if f := fieldsOf(t, err); f["content.video_ulr"] != "unknown field" {
+		t.Fatalf("unexpected unknown field error: %v", f)
+	}
+
+	_, err = ParseContent("podcast", json.RawMessage(`{}`))
+	if f := fieldsOf(t, err); f["type"] == "" {
+		t.Fatalf("expected type error: %v", f)
+	}
+
+	if _, err := ParseContent(TypeVideo, json.RawMessage(`{"url":"https://example.com/v.mp4"}`)); err != nil {
+		t.Fatalf("legacy video content should migrate and validate: %v", err)
+	}
+}
+
+func TestHideAnswers(t *testing.T) {
+	c, err := DecodeContent(TypeTask, json.RawMessage(`{"schema_version":1,"code_template":"x","expected_output":"42","test_cases":[{"input":"1","expected_output":"2","description":"d","checker":{"mode":"custom","program":"package main"}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tests := c.Base().Tests(); len(tests) != 1 || tests[0].Checker == nil {
+		t.Fatalf("tests %+v", tests)
+	}
+	HideAnswers(c)
+	base := c.Base()
+	if base.ExpectedOutput != "" || len(base.TestCases) != 1 || base.TestCases[0] != (TestCase{Input: "1", Description: "d"}) {
+		t.Fatalf("answers left in content: %+v", base)
+	}
+	if tests := (LessonContent{ExpectedOutput: "42"}).Tests(); len(tests) != 1 || tests[0].ExpectedOutput != "42" {
+		t.Fatalf("expected_output as a test: %+v", tests)
+	}
+}
@@ -0,0 +1,120 @@
+package lesson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ContentSchemaVersion текущая версия схемы контента урока.
+// При изменении схемы: увеличить версию и добавить миграцию в contentMigrations.
+const ContentSchemaVersion = 1
+
+// contentMigration переводит документ контента с версии from на from+1.
+type contentMigration struct {
+	from  int
+	apply func(typ string, doc map[string]interface{})
+}
+
+var contentMigrations = []contentMigration{
+	{from: 0, apply: migrateV0toV1},
+}
+
+// MigrateContent поднимает JSON контента до ContentSchemaVersion.
+// Возвращает признак того, что документ изменился.
+func MigrateContent(typ string, raw json.RawMessage) (json.RawMessage, bool, error) {
+	doc := map[string]interface{}{}
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return nil, false, err
+		}
+	} else if len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) && !bytes.Equal(trimmed, []byte("[]")) {
+		// до версии 1 встречался контент-строка или массив; всё остальное — ошибка
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err != nil {
+			return nil, false, fmt.Errorf("must be a JSON object")
+		}
+		doc["theory"] = s
+	}
+
+	version, err := schemaVersion(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	if version > ContentSchemaVersion {
+		return nil, false, fmt.Errorf("schema_version %d is newer than supported %d", version, ContentSchemaVersion)
+	}
+	if version == ContentSchemaVersion && len(trimmed) > 0 && trimmed[0] == '{' {
+		return raw, false, nil
+	}
+	for _, m := range contentMigrations {
+		if m.from >= version {
+			m.apply(NormalizeType(typ), doc)
+		}
+	}
+	doc["schema_version"] = ContentSchemaVersion
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+func schemaVersion(doc map[string]interface{}) (int, error) {
+	v, ok := doc["schema_version"]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != float64(int(f)) {
+		return 0, fmt.Errorf("schema_version must be a non-negative integer")
+	}
+	return int(f), nil
+}
+
+// migrateV0toV1: контент до введения версий. Подсказки и цели бывали строкой,
+// test_cases — одиночным объектом, текст — в поле "text"/"content", у видео — "url".
+func migrateV0toV1(typ string, doc map[string]interface{}) {
+	for _, legacy := range []string{"text", "content"} {
+		if s, ok := doc[legacy].(string); ok {
+			if _, has := doc["theory"]; !has {
+				doc["theory"] = s
+			}
+			delete(doc, legacy)
+		}
+	}
+	for _, key := range []string{"hints", "objectives"} {
+		switch v := doc[key].(type) {
+		case nil:
+			delete(doc, key)
+		case string:
+			doc[key] = splitLines(v)
+		}
+	}
+	switch v := doc["test_cases"].(type) {
+	case nil:
+		delete(doc, "test_cases")
+	case map[string]interface{}:
+		doc["test_cases"] = []interface{}{v}
+	}
+	if typ == TypeVideo {
+		if u, ok := doc["url"].(string); ok {
+			if _, has := doc["video_url"]; !has {
+				doc["video_url"] = u
+			}
+			delete(doc, "url")
+		}
+	}
+}
+
+func splitLines(s string) []string {
+	out := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
@@ -3,11 +3,13 @@ package lesson
 import (
 	"encoding/json"
 
+	"github.com/example/learngo/internal/domain/code"
 	"github.com/google/uuid"
 )
 
 // LessonContent структура контента урока согласно документации
 type LessonContent struct {
+	SchemaVersion  int        `json:"schema_version"`  // версия схемы контента, см. ContentSchemaVersion
 	Theory         string     `json:"theory"`          // теория урока
 	Objectives     []string   `json:"objectives"`      // цели урока
 	CodeTemplate   string     `json:"code_template"`   // шаблон кода для выполнения
@@ -21,6 +23,8 @@ type TestCase struct {
 	Input          string `json:"input"`           // входные данные
 	ExpectedOutput string `json:"expected_output"` // ожидаемый вывод
 	Description    string `json:"description"`     // описание теста
+	// Checker способ сравнения вывода; nil — по словам, без учёта пробелов.
+	Checker *code.Checker `json:"checker,omitempty"`
 }
 
 type Lesson struct {
@@ -31,7 +35,7 @@ type Lesson struct {
 	Slug             string          `json:"slug"`
 	Title            string          `json:"title"`
 	Type             string          `json:"type"`             // theory|video|quiz|task
-	Content          json.RawMessage `json:"content"`          // JSON с LessonContent
+	Content          json.RawMessage `json:"content"`          // JSON с контентом по типу урока (TheoryContent, VideoContent, ...)
 	DurationMinutes  int             `json:"duration_minutes"` // длительность урока в минутах
 	Order            int             `json:"order"`
 	IsFree           bool            `json:"is_free"`                      // бесплатный урок
@@ -0,0 +1,101 @@
+package lesson
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Placement положение урока в курсе: модуль, сквозной порядковый номер и соседи.
+type Placement struct {
+	ID               uuid.UUID
+	ModuleID         uuid.UUID
+	Order            int
+	PreviousLessonID *uuid.UUID
+	NextLessonID     *uuid.UUID
+}
+
+// Outline структура курса, прочитанная Rearrange под блокировкой.
+type Outline struct {
+	ModuleIDs []uuid.UUID // модули курса в текущем порядке
+	Lessons   []Lesson    // уроки курса по Order
+}
+
+// ModuleOrder индекс модулей для PlanOutline.
+func (o Outline) ModuleOrder() map[uuid.UUID]int {
+	order := make(map[uuid.UUID]int, len(o.ModuleIDs))
+	for i, id := range o.ModuleIDs {
+		order[id] = i
+	}
+	return order
+}
+
+// Layout изменения, которые Rearrange применяет в той же транзакции: новый порядок модулей,
+// сохранение или удаление урока и положения уроков.
+type Layout struct {
+	ModuleIDs  []uuid.UUID // новый порядок модулей; nil — не менять
+	Save       *Lesson     // урок для создания или обновления
+	Remove     uuid.UUID   // урок для удаления
+	Placements []Placement
+}
+
+// Module возвращает модуль урока (ModuleID, а для старых данных — SectionID).
+func (l Lesson) Module() uuid.UUID {
+	if l.ModuleID != uuid.Nil {
+		return l.ModuleID
+	}
+	return l.SectionID
+}
+
+// PlanOutline выстраивает уроки курса в одну последовательность: по порядку модулей
+// (moduleOrder; уроки вне известных модулей — в конце), внутри модуля — по Order.
+// Order перенумеровывается сквозь весь курс с 1, ссылки next/prev пересекают границы модулей.
+// При равном Order первым идёт pinned (только что вставленный или перемещённый урок).
+// Возвращает только изменившиеся положения.
+func PlanOutline(lessons []Lesson, moduleOrder map[uuid.UUID]int, pinned uuid.UUID) []Placement {
+	seq := append([]Lesson(nil), lessons...)
+	rank := func(l Lesson) int {
+		if idx, ok := moduleOrder[l.Module()]; ok {
+			return idx
+		}
+		return len(moduleOrder) + 1
+	}
+	sort.SliceStable(seq, func(i, j int) bool {
+		a, b := seq[i], seq[j]
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra < rb
+		}
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		if a.ID == pinned || b.ID == pinned {
+			return a.ID == pinned
+		}
+		return bytes.Compare(a.ID[:], b.ID[:]) < 0
+	})
+
+	var out []Placement
+	for i, l := range seq {
+		p := Placement{ID: l.ID, ModuleID: l.Module(), Order: i + 1}
+		if i > 0 {
+			prev := seq[i-1].ID
+			p.PreviousLessonID = &prev
+		}
+		if i < len(seq)-1 {
+			next := seq[i+1].ID
+			p.NextLessonID = &next
+		}
+		if p.Order != l.Order || p.ModuleID != l.ModuleID || !sameRef(p.PreviousLessonID, l.PreviousLessonID) || !sameRef(p.NextLessonID, l.NextLessonID) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func sameRef(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
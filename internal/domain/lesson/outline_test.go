@@ -0,0 +1,45 @@
+package lesson
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPlanOutlineAcrossModules(t *testing.T) {
+	m1, m2 := uuid.New(), uuid.New()
+	a := Lesson{ID: uuid.New(), ModuleID: m2, Order: 1}
+	b := Lesson{ID: uuid.New(), ModuleID: m1, Order: 7}
+	c := Lesson{ID: uuid.New(), ModuleID: m1, Order: 3}
+	inserted := Lesson{ID: uuid.New(), ModuleID: m1, Order: 3}
+
+	plan := PlanOutline([]Lesson{a, b, c, inserted}, map[uuid.UUID]int{m1: 0, m2: 1}, inserted.ID)
+	byID := map[uuid.UUID]Placement{}
+	for _, p := range plan {
+		byID[p.ID] = p
+	}
+	want := []uuid.UUID{inserted.ID, c.ID, b.ID, a.ID}
+	for i, id := range want {
+		p, ok := byID[id]
+		if !ok || p.Order != i+1 {
+			t.Fatalf("lesson %d: unexpected placement %+v", i, p)
+		}
+		if (i == 0) != (p.PreviousLessonID == nil) || (i == len(want)-1) != (p.NextLessonID == nil) {
+			t.Fatalf("lesson %d: unexpected links %+v", i, p)
+		}
+		if i > 0 && *p.PreviousLessonID != want[i-1] {
+			t.Fatalf("lesson %d: wrong previous", i)
+		}
+	}
+
+	// применённый план стабилен
+	applied := make([]Lesson, 0, len(want))
+	for _, l := range []Lesson{a, b, c, inserted} {
+		p := byID[l.ID]
+		l.Order, l.PreviousLessonID, l.NextLessonID = p.Order, p.PreviousLessonID, p.NextLessonID
+		applied = append(applied, l)
+	}
+	if again := PlanOutline(applied, map[uuid.UUID]int{m1: 0, m2: 1}, uuid.Nil); len(again) != 0 {
+		t.Fatalf("expected no changes, got %+v", again)
+	}
+}
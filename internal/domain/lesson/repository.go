@@ -11,6 +11,10 @@ type Repository interface {
 	ListBySection(ctx context.Context, sectionID uuid.UUID) ([]Lesson, error)
 	Create(ctx context.Context, lesson Lesson) (Lesson, error)
 	Get(ctx context.Context, id uuid.UUID) (Lesson, error)
-	Update(ctx context.Context, id uuid.UUID, title, content string, order int, sectionID uuid.UUID) (Lesson, error)
+	// Update обновляет урок; пустой typ оставляет тип без изменений.
+	Update(ctx context.Context, id uuid.UUID, title, typ, content string, order int, sectionID uuid.UUID) (Lesson, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// Rearrange в одной транзакции читает модули и уроки курса под блокировкой, вызывает plan
+	// и применяет возвращённый Layout: порядок модулей, сохранение/удаление урока и положения уроков.
+	Rearrange(ctx context.Context, courseID uuid.UUID, plan func(o Outline) (Layout, error)) error
 }
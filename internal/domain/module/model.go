@@ -16,7 +16,10 @@ type Module struct {
 
 type Repository interface {
 	ListByCourse(ctx context.Context, courseID uuid.UUID) ([]Module, error)
+	Get(ctx context.Context, id uuid.UUID) (Module, error)
 	Create(ctx context.Context, m Module) (Module, error)
 	Update(ctx context.Context, id uuid.UUID, title string, orderIndex int) (Module, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// Reorder атомарно проставляет order_index модулей курса по порядку ids (с 1).
+	Reorder(ctx context.Context, courseID uuid.UUID, ids []uuid.UUID) error
 }
@@ -0,0 +1,88 @@
+package note
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportLesson урок курса в порядке прохождения.
+type ExportLesson struct {
+	ID    uuid.UUID
+	Title string
+}
+
+// ExportMarkdown собирает заметки курса в один Markdown-документ: разделы идут
+// в порядке уроков, заметки внутри урока — в порядке создания. Заметки к урокам,
+// которых нет в списке, попадают в раздел «Other notes».
+func ExportMarkdown(courseTitle string, lessons []ExportLesson, notes []Note, exportedAt time.Time) string {
+	byLesson := make(map[uuid.UUID][]Note)
+	for _, n := range notes {
+		byLesson[n.LessonID] = append(byLesson[n.LessonID], n)
+	}
+	for _, list := range byLesson {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Notes: %s\n\n", courseTitle)
+	fmt.Fprintf(&b, "_Exported %s_\n", exportedAt.UTC().Format("2006-01-02 15:04 UTC"))
+	if len(notes) == 0 {
+		b.WriteString("\nNo notes yet.\n")
+		return b.String()
+	}
+
+	for i, l := range lessons {
+		list := byLesson[l.ID]
+		if len(list) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %d. %s\n", i+1, l.Title)
+		writeNotes(&b, list)
+		delete(byLesson, l.ID)
+	}
+	if len(byLesson) > 0 {
+		var rest []Note
+		for _, list := range byLesson {
+			rest = append(rest, list...)
+		}
+		sort.SliceStable(rest, func(i, j int) bool { return rest[i].CreatedAt.Before(rest[j].CreatedAt) })
+		b.WriteString("\n## Other notes\n")
+		writeNotes(&b, rest)
+	}
+	return b.String()
+}
+
+func writeNotes(b *strings.Builder, notes []Note) {
+	for _, n := range notes {
+		b.WriteString("\n")
+		if n.SnippetCode != "" {
+			f := fence(n.SnippetCode)
+			fmt.Fprintf(b, "%s%s\n%s\n%s\n\n", f, n.SnippetLanguage, strings.TrimRight(n.SnippetCode, "\n"), f)
+		}
+		b.WriteString(strings.TrimSpace(n.Body))
+		b.WriteString("\n")
+	}
+}
+
+// fence подбирает ограничитель блока кода длиннее любой серии обратных кавычек внутри кода.
+func fence(code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+			continue
+		}
+		run = 0
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
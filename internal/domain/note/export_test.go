@@ -0,0 +1,34 @@
+package note
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestExportMarkdown(t *testing.T) {
+	l1, l2, gone := uuid.New(), uuid.New(), uuid.New()
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	notes := []Note{
+		{LessonID: l2, Body: "second lesson", CreatedAt: base},
+		{LessonID: l1, Body: "later", CreatedAt: base.Add(time.Hour)},
+		{LessonID: l1, Body: "earlier", SnippetLanguage: "go", SnippetCode: "s := \"````\"\n", CreatedAt: base},
+		{LessonID: gone, Body: "orphan", CreatedAt: base},
+	}
+	out := ExportMarkdown("Go basics", []ExportLesson{{ID: l1, Title: "Intro"}, {ID: uuid.New(), Title: "Empty"}, {ID: l2, Title: "Types"}}, notes, base)
+
+	order := []string{"# Notes: Go basics", "## 1. Intro", "`````go\ns := \"````\"\n`````", "earlier", "later", "## 3. Types", "second lesson", "## Other notes", "orphan"}
+	pos := 0
+	for _, want := range order {
+		i := strings.Index(out[pos:], want)
+		if i < 0 {
+			t.Fatalf("missing %q after offset %d in:\n%s", want, pos, out)
+		}
+		pos += i + len(want)
+	}
+	if strings.Contains(out, "Empty") {
+		t.Errorf("lessons without notes must be skipped:\n%s", out)
+	}
+}
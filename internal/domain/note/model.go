@@ -0,0 +1,64 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBodyLen ограничение длины заметки, в символах.
+const MaxBodyLen = 20000
+
+var ErrInvalid = errors.New("invalid note")
+
+// Note личная заметка студента к уроку или к конкретному фрагменту кода из теории.
+type Note struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	LessonID  uuid.UUID `json:"lesson_id"`
+	CourseID  uuid.UUID `json:"course_id"`
+	SnippetID string    `json:"snippet_id,omitempty"`
+	// копия фрагмента на момент создания: урок могут отредактировать, а заметка должна остаться понятной
+	SnippetLanguage string    `json:"snippet_language,omitempty"`
+	SnippetCode     string    `json:"snippet_code,omitempty"`
+	Body            string    `json:"body"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Bookmark закладка на урок; у пользователя не больше одной закладки на урок.
+type Bookmark struct {
+	UserID      uuid.UUID `json:"user_id"`
+	LessonID    uuid.UUID `json:"lesson_id"`
+	CourseID    uuid.UUID `json:"course_id"`
+	LessonTitle string    `json:"lesson_title,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SearchHit найденная заметка с подсвеченным фрагментом.
+type SearchHit struct {
+	Note
+	Headline string  `json:"headline"`
+	Rank     float64 `json:"rank"`
+}
+
+// Filter выборка заметок пользователя; пустые поля не ограничивают выборку.
+type Filter struct {
+	UserID   uuid.UUID
+	CourseID *uuid.UUID
+	LessonID *uuid.UUID
+}
+
+// ValidateBody проверяет текст заметки.
+func ValidateBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("%w: body is required", ErrInvalid)
+	}
+	if len([]rune(body)) > MaxBodyLen {
+		return fmt.Errorf("%w: body must be at most %d characters", ErrInvalid, MaxBodyLen)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package note
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	CreateNote(ctx context.Context, n Note) (Note, error)
+	GetNote(ctx context.Context, id uuid.UUID) (Note, error)
+	UpdateNote(ctx context.Context, n Note) (Note, error)
+	DeleteNote(ctx context.Context, id uuid.UUID) error
+	// ListNotes возвращает заметки в порядке создания.
+	ListNotes(ctx context.Context, f Filter) ([]Note, error)
+	// SearchNotes полнотекстовый поиск по заметкам пользователя, лучшие совпадения первыми.
+	SearchNotes(ctx context.Context, userID uuid.UUID, query string, limit int) ([]SearchHit, error)
+
+	// AddBookmark идемпотентна: повторная закладка возвращает существующую.
+	AddBookmark(ctx context.Context, b Bookmark) (Bookmark, error)
+	RemoveBookmark(ctx context.Context, userID, lessonID uuid.UUID) error
+	// ListBookmarks возвращает закладки от новых к старым; courseID == nil — по всем курсам.
+	ListBookmarks(ctx context.Context, userID uuid.UUID, courseID *uuid.UUID) ([]Bookmark, error)
+}
@@ -0,0 +1,68 @@
+package plagiarism
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Match совпадающие фрагменты пары: строки [AStart, AEnd] решения A и [BStart, BEnd] решения B.
+type Match struct {
+	AStart int `json:"a_start"`
+	AEnd   int `json:"a_end"`
+	BStart int `json:"b_start"`
+	BEnd   int `json:"b_end"`
+}
+
+// Pair подозрительно похожие лучшие решения двух студентов по заданию.
+type Pair struct {
+	ID           uuid.UUID `json:"id"`
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	SubmissionA  uuid.UUID `json:"submission_a"`
+	SubmissionB  uuid.UUID `json:"submission_b"`
+	UserA        uuid.UUID `json:"user_a"`
+	UserB        uuid.UUID `json:"user_b"`
+	// Similarity доля общего кода 0..1: большая из ScoreA (доля кода A, найденного в B) и ScoreB.
+	Similarity float64 `json:"similarity"`
+	ScoreA     float64 `json:"score_a"`
+	ScoreB     float64 `json:"score_b"`
+	Matches    []Match `json:"matches"`
+}
+
+// Report результат последнего анализа задания: пары от самых похожих.
+type Report struct {
+	AssignmentID uuid.UUID `json:"assignment_id"`
+	Submissions  int       `json:"submissions"` // сколько решений сравнивалось
+	Threshold    float64   `json:"threshold"`
+	Pairs        []Pair    `json:"pairs"`
+	ComputedAt   time.Time `json:"computed_at"`
+}
+
+// Row строка выровненного просмотра пары; номер строки 0 — с этой стороны пусто.
+type Row struct {
+	LeftLine  int    `json:"left_line,omitempty"`
+	Left      string `json:"left"`
+	RightLine int    `json:"right_line,omitempty"`
+	Right     string `json:"right"`
+	Match     int    `json:"match,omitempty"` // номер совпадения в Pair.Matches (с 1); 0 — строки не совпали
+}
+
+// PairView пара с кодом обоих решений, выровненным по совпадениям.
+type PairView struct {
+	Pair
+	CodeA string `json:"code_a"`
+	CodeB string `json:"code_b"`
+	Rows  []Row  `json:"rows"`
+}
+
+type Repository interface {
+	// SaveReport заменяет отчёт по заданию вместе с парами.
+	SaveReport(ctx context.Context, r Report) error
+	// Report отчёт по заданию; нулевое значение, если анализа ещё не было.
+	Report(ctx context.Context, assignmentID uuid.UUID) (Report, error)
+	// Pair пара по ID; нулевое значение, если её нет.
+	Pair(ctx context.Context, id uuid.UUID) (Pair, error)
+	// ComputedAt время последнего анализа по каждому заданию.
+	ComputedAt(ctx context.Context) (map[uuid.UUID]time.Time, error)
+}
@@ -0,0 +1,270 @@
+package quiz
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы вопросов.
+const (
+	KindSingle   = "single"   // один правильный вариант
+	KindMultiple = "multiple" // несколько правильных вариантов, засчитывается только точное совпадение
+	KindText     = "text"     // свободный ответ, сверяется со списком принятых
+	KindOutput   = "output"   // предсказать вывод фрагмента кода
+	KindOrdering = "ordering" // расставить варианты в правильном порядке
+)
+
+// Статусы попытки.
+const (
+	AttemptInProgress = "in_progress"
+	AttemptSubmitted  = "submitted"
+)
+
+// ErrInvalidQuestion оборачивает ошибки валидации вопроса.
+var ErrInvalidQuestion = errors.New("invalid question")
+
+// ErrAttemptsExhausted лимит попыток по квизу исчерпан.
+var ErrAttemptsExhausted = errors.New("no attempts left")
+
+// Option вариант ответа.
+type Option struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Question вопрос квиза. Correct и Accepted никогда не отдаются студенту.
+type Question struct {
+	ID            uuid.UUID `json:"id"`
+	LessonID      uuid.UUID `json:"lesson_id"`
+	Kind          string    `json:"kind"`
+	Prompt        string    `json:"prompt"`
+	Code          string    `json:"code,omitempty"`     // фрагмент кода для вопросов output
+	Options       []Option  `json:"options,omitempty"`  // single/multiple/ordering
+	Correct       []string  `json:"correct,omitempty"`  // ID верных вариантов; для ordering — верный порядок
+	Accepted      []string  `json:"accepted,omitempty"` // принятые ответы для text/output
+	CaseSensitive bool      `json:"case_sensitive"`
+	Points        int       `json:"points"`
+	Order         int       `json:"order"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// PublicQuestion представление вопроса для студента — без правильных ответов.
+type PublicQuestion struct {
+	ID      uuid.UUID `json:"id"`
+	Kind    string    `json:"kind"`
+	Prompt  string    `json:"prompt"`
+	Code    string    `json:"code,omitempty"`
+	Options []Option  `json:"options,omitempty"`
+	Points  int       `json:"points"`
+}
+
+// Answer ответ студента: выбранные/упорядоченные варианты или текст.
+type Answer struct {
+	QuestionID uuid.UUID `json:"question_id"`
+	Options    []string  `json:"options,omitempty"`
+	Text       string    `json:"text,omitempty"`
+}
+
+// QuestionResult результат проверки одного вопроса (без правильного ответа).
+type QuestionResult struct {
+	QuestionID uuid.UUID `json:"question_id"`
+	Correct    bool      `json:"correct"`
+	Points     int       `json:"points"`
+	MaxPoints  int       `json:"max_points"`
+}
+
+// Attempt попытка прохождения квиза.
+type Attempt struct {
+	ID          uuid.UUID        `json:"id"`
+	UserID      uuid.UUID        `json:"user_id"`
+	LessonID    uuid.UUID        `json:"lesson_id"`
+	CourseID    uuid.UUID        `json:"course_id"`
+	Seed        int64            `json:"-"` // зерно выборки вопросов и перемешивания вариантов
+	QuestionIDs []uuid.UUID      `json:"question_ids"`
+	Status      string           `json:"status"`
+	Answers     []Answer         `json:"answers,omitempty"`
+	Results     []QuestionResult `json:"results,omitempty"`
+	Score       int              `json:"score"`
+	MaxScore    int              `json:"max_score"`
+	Percent     int              `json:"percent"`
+	Passed      bool             `json:"passed"`
+	StartedAt   time.Time        `json:"started_at"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+	SubmittedAt *time.Time       `json:"submitted_at,omitempty"`
+}
+
+// Usage разбирает попытки пользователя по квизу: активная незавершённая попытка (если есть)
+// и число использованных. Просроченная незавершённая попытка считается использованной,
+// grace — запас на сетевую задержку при проверке лимита времени.
+func Usage(attempts []Attempt, now time.Time, grace time.Duration) (*Attempt, int) {
+	used := 0
+	for i, a := range attempts {
+		if a.Status == AttemptSubmitted {
+			used++
+			continue
+		}
+		if a.ExpiresAt == nil || now.Before(a.ExpiresAt.Add(grace)) {
+			return &attempts[i], used
+		}
+		used++
+	}
+	return nil, used
+}
+
+// Validate проверяет согласованность вопроса и нормализует его (ID вариантов, баллы).
+func (q *Question) Validate() error {
+	q.Prompt = strings.TrimSpace(q.Prompt)
+	if q.Prompt == "" {
+		return fmt.Errorf("%w: prompt is required", ErrInvalidQuestion)
+	}
+	if q.Points <= 0 {
+		q.Points = 1
+	}
+	switch q.Kind {
+	case KindSingle, KindMultiple, KindOrdering:
+		if len(q.Options) < 2 {
+			return fmt.Errorf("%w: at least two options are required", ErrInvalidQuestion)
+		}
+		ids := make(map[string]bool, len(q.Options))
+		for i := range q.Options {
+			if q.Options[i].ID == "" {
+				q.Options[i].ID = string(rune('a' + i))
+			}
+			if ids[q.Options[i].ID] {
+				return fmt.Errorf("%w: duplicate option id %q", ErrInvalidQuestion, q.Options[i].ID)
+			}
+			ids[q.Options[i].ID] = true
+		}
+		for _, c := range q.Correct {
+			if !ids[c] {
+				return fmt.Errorf("%w: correct references unknown option %q", ErrInvalidQuestion, c)
+			}
+		}
+		switch {
+		case q.Kind == KindSingle && len(q.Correct) != 1:
+			return fmt.Errorf("%w: single choice needs exactly one correct option", ErrInvalidQuestion)
+		case q.Kind == KindMultiple && len(q.Correct) == 0:
+			return fmt.Errorf("%w: multiple choice needs at least one correct option", ErrInvalidQuestion)
+		case q.Kind == KindOrdering && !sameSet(q.Correct, optionIDs(q.Options)):
+			return fmt.Errorf("%w: correct must list every option exactly once", ErrInvalidQuestion)
+		}
+		q.Accepted = nil
+	case KindText, KindOutput:
+		if len(q.Accepted) == 0 {
+			return fmt.Errorf("%w: at least one accepted answer is required", ErrInvalidQuestion)
+		}
+		if q.Kind == KindOutput && strings.TrimSpace(q.Code) == "" {
+			return fmt.Errorf("%w: code is required for output questions", ErrInvalidQuestion)
+		}
+		q.Options, q.Correct = nil, nil
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidQuestion, q.Kind)
+	}
+	return nil
+}
+
+// Public возвращает вопрос без ответов. Варианты перемешиваются через rnd при shuffle;
+// для ordering — всегда, иначе исходный порядок подсказывал бы ответ.
+func (q Question) Public(rnd *rand.Rand, shuffle bool) PublicQuestion {
+	opts := append([]Option(nil), q.Options...)
+	if shuffle || q.Kind == KindOrdering {
+		rnd.Shuffle(len(opts), func(i, j int) { opts[i], opts[j] = opts[j], opts[i] })
+	}
+	return PublicQuestion{ID: q.ID, Kind: q.Kind, Prompt: q.Prompt, Code: q.Code, Options: opts, Points: q.Points}
+}
+
+// Grade проверяет ответ на сервере.
+func (q Question) Grade(a Answer) QuestionResult {
+	res := QuestionResult{QuestionID: q.ID, MaxPoints: q.Points}
+	switch q.Kind {
+	case KindSingle:
+		res.Correct = len(a.Options) == 1 && len(q.Correct) == 1 && a.Options[0] == q.Correct[0]
+	case KindMultiple:
+		res.Correct = sameSet(a.Options, q.Correct)
+	case KindOrdering:
+		res.Correct = len(a.Options) == len(q.Correct)
+		for i := 0; res.Correct && i < len(a.Options); i++ {
+			res.Correct = a.Options[i] == q.Correct[i]
+		}
+	case KindText:
+		given := normalizeText(a.Text, q.CaseSensitive)
+		for _, acc := range q.Accepted {
+			if given != "" && given == normalizeText(acc, q.CaseSensitive) {
+				res.Correct = true
+				break
+			}
+		}
+	case KindOutput:
+		given := normalizeOutput(a.Text)
+		for _, acc := range q.Accepted {
+			if given == normalizeOutput(acc) {
+				res.Correct = true
+				break
+			}
+		}
+	}
+	if res.Correct {
+		res.Points = q.Points
+	}
+	return res
+}
+
+// SelectPool детерминированно (по seed) выбирает size вопросов из банка; size<=0 — все.
+func SelectPool(questions []Question, size int, seed int64) []Question {
+	pool := append([]Question(nil), questions...)
+	rnd := rand.New(rand.NewSource(seed))
+	rnd.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	if size > 0 && size < len(pool) {
+		pool = pool[:size]
+	}
+	return pool
+}
+
+// normalizeText: обрезка, схлопывание пробелов, регистр — по настройке вопроса.
+func normalizeText(s string, caseSensitive bool) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if !caseSensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// normalizeOutput: вывод сравнивается построчно без хвостовых пробелов и пустых строк в конце.
+func normalizeOutput(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func optionIDs(opts []Option) []string {
+	ids := make([]string, 0, len(opts))
+	for _, o := range opts {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]int, len(b))
+	for _, x := range b {
+		set[x]++
+	}
+	for _, x := range a {
+		if set[x] == 0 {
+			return false
+		}
+		set[x]--
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package quiz
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGradeQuestionKinds(t *testing.T) {
+	opts := []Option{{Text: "A"}, {Text: "B"}, {Text: "C"}}
+	cases := []struct {
+		name  string
+		q     Question
+		right Answer
+		wrong Answer
+	}{
+		{"single", Question{Kind: KindSingle, Prompt: "?", Options: opts, Correct: []string{"b"}},
+			Answer{Options: []string{"b"}}, Answer{Options: []string{"b", "c"}}},
+		{"multiple", Question{Kind: KindMultiple, Prompt: "?", Options: opts, Correct: []string{"a", "c"}},
+			Answer{Options: []string{"c", "a"}}, Answer{Options: []string{"a"}}},
+		{"ordering", Question{Kind: KindOrdering, Prompt: "?", Options: opts, Correct: []string{"c", "a", "b"}},
+			Answer{Options: []string{"c", "a", "b"}}, Answer{Options: []string{"a", "b", "c"}}},
+		{"text", Question{Kind: KindText, Prompt: "?", Accepted: []string{"Goroutine"}},
+			Answer{Text: "  goroutine "}, Answer{Text: ""}},
+		{"output", Question{Kind: KindOutput, Prompt: "?", Code: "fmt.Println(1)", Accepted: []string{"1\n2"}},
+			Answer{Text: "1  \r\n2\n\n"}, Answer{Text: "1 2"}},
+	}
+	for _, tc := range cases {
+		q := tc.q
+		if err := q.Validate(); err != nil {
+			t.Fatalf("%s: validate: %v", tc.name, err)
+		}
+		if r := q.Grade(tc.right); !r.Correct || r.Points != 1 {
+			t.Fatalf("%s: expected correct, got %+v", tc.name, r)
+		}
+		if r := q.Grade(tc.wrong); r.Correct || r.Points != 0 {
+			t.Fatalf("%s: expected wrong, got %+v", tc.name, r)
+		}
+	}
+}
+
+func TestValidateRejectsInconsistentQuestion(t *testing.T) {
+	q := Question{Kind: KindOrdering, Prompt: "?", Options: []Option{{Text: "x"}, {Text: "y"}}, Correct: []string{"a"}}
+	if err := q.Validate(); !errors.Is(err, ErrInvalidQuestion) {
+		t.Fatalf("expected ErrInvalidQuestion, got %v", err)
+	}
+}
+
+func TestSelectPoolAndPublic(t *testing.T) {
+	bank := make([]Question, 10)
+	for i := range bank {
+		bank[i] = Question{ID: uuid.New(), Kind: KindSingle, Options: []Option{{ID: "a"}, {ID: "b"}}, Correct: []string{"a"}}
+	}
+	p1, p2 := SelectPool(bank, 4, 42), SelectPool(bank, 4, 42)
+	if len(p1) != 4 {
+		t.Fatalf("pool size: %d", len(p1))
+	}
+	for i := range p1 {
+		if p1[i].ID != p2[i].ID {
+			t.Fatalf("pool must be deterministic for the same seed")
+		}
+	}
+	pub := bank[0].Public(rand.New(rand.NewSource(1)), true)
+	if len(pub.Options) != 2 {
+		t.Fatalf("options lost: %+v", pub)
+	}
+}
+
+func TestUsageCountsExpiredAndFindsActive(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	later := now.Add(time.Minute)
+	attempts := []Attempt{
+		{Status: AttemptSubmitted},
+		{Status: AttemptInProgress, ExpiresAt: &expired},
+		{Status: AttemptInProgress, ExpiresAt: &later},
+	}
+	active, used := Usage(attempts, now, 0)
+	if active != &attempts[2] || used != 2 {
+		t.Fatalf("Usage = %v, %d; want third attempt and 2 used", active, used)
+	}
+	if active, used = Usage(attempts[:2], now, 2*time.Minute); active != &attempts[1] || used != 1 {
+		t.Fatalf("grace: Usage = %v, %d; want second attempt and 1 used", active, used)
+	}
+}
@@ -0,0 +1,23 @@
+package quiz
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	ListQuestions(ctx context.Context, lessonID uuid.UUID) ([]Question, error)
+	GetQuestion(ctx context.Context, id uuid.UUID) (Question, error)
+	CreateQuestion(ctx context.Context, q Question) (Question, error)
+	UpdateQuestion(ctx context.Context, q Question) (Question, error)
+	DeleteQuestion(ctx context.Context, id uuid.UUID) error
+
+	// StartAttempt атомарно проверяет лимит maxAttempts (0 — без ограничений) и создаёт попытку.
+	// Если у пользователя уже есть активная попытка, возвращается она; при исчерпанном лимите — ErrAttemptsExhausted.
+	StartAttempt(ctx context.Context, a Attempt, maxAttempts int, grace time.Duration) (Attempt, error)
+	GetAttempt(ctx context.Context, id uuid.UUID) (Attempt, error)
+	UpdateAttempt(ctx context.Context, a Attempt) (Attempt, error)
+	ListAttempts(ctx context.Context, userID, lessonID uuid.UUID) ([]Attempt, error)
+}
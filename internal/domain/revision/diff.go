@@ -0,0 +1,184 @@
+package revision
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Операции структурного diff.
+const (
+	OpAdded   = "added"
+	OpRemoved = "removed"
+	OpChanged = "changed"
+)
+
+// maxLineDiff ограничение на размер построчного diff (строк в каждой версии).
+const maxLineDiff = 2000
+
+// Change изменение одного поля снимка. Path — путь вида content.hints[1].
+// Для многострочного текста (теория, шаблон кода) дополнительно заполняется Lines.
+type Change struct {
+	Path  string       `json:"path"`
+	Op    string       `json:"op"`
+	From  interface{}  `json:"from,omitempty"`
+	To    interface{}  `json:"to,omitempty"`
+	Lines []LineChange `json:"lines,omitempty"`
+}
+
+// LineChange строка построчного diff: Op "+", "-" или " " (без изменений).
+type LineChange struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Diff сравнивает два JSON-снимка и возвращает изменённые поля в порядке обхода.
+func Diff(from, to json.RawMessage) ([]Change, error) {
+	a, err := decode(from)
+	if err != nil {
+		return nil, fmt.Errorf("from: %w", err)
+	}
+	b, err := decode(to)
+	if err != nil {
+		return nil, fmt.Errorf("to: %w", err)
+	}
+	changes := []Change{}
+	walk("", a, b, &changes)
+	return changes, nil
+}
+
+func decode(raw json.RawMessage) (interface{}, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func walk(path string, a, b interface{}, out *[]Change) {
+	switch {
+	case a == nil && b == nil:
+		return
+	case a == nil:
+		*out = append(*out, Change{Path: path, Op: OpAdded, To: b})
+		return
+	case b == nil:
+		*out = append(*out, Change{Path: path, Op: OpRemoved, From: a})
+		return
+	}
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(av)+len(bv))
+			for k := range av {
+				keys = append(keys, k)
+			}
+			for k := range bv {
+				if _, ok := av[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(join(path, k), av[k], bv[k], out)
+			}
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			n := len(av)
+			if len(bv) > n {
+				n = len(bv)
+			}
+			for i := 0; i < n; i++ {
+				var x, y interface{}
+				if i < len(av) {
+					x = av[i]
+				}
+				if i < len(bv) {
+					y = bv[i]
+				}
+				walk(fmt.Sprintf("%s[%d]", path, i), x, y, out)
+			}
+			return
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	ch := Change{Path: path, Op: OpChanged, From: a, To: b}
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok && (strings.Contains(as, "\n") || strings.Contains(bs, "\n")) {
+			ch.Lines = DiffLines(as, bs)
+		}
+	}
+	*out = append(*out, ch)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// DiffLines построчный diff по наибольшей общей подпоследовательности.
+// Слишком большие тексты сравниваются целиком: все старые строки удалены, новые добавлены.
+func DiffLines(a, b string) []LineChange {
+	x, y := strings.Split(a, "\n"), strings.Split(b, "\n")
+	if len(x) > maxLineDiff || len(y) > maxLineDiff {
+		out := make([]LineChange, 0, len(x)+len(y))
+		for _, l := range x {
+			out = append(out, LineChange{Op: "-", Text: l})
+		}
+		for _, l := range y {
+			out = append(out, LineChange{Op: "+", Text: l})
+		}
+		return out
+	}
+	// lcs[i][j] — длина НОП для x[i:] и y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	out := make([]LineChange, 0, len(x)+len(y))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, LineChange{Op: " ", Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, LineChange{Op: "-", Text: x[i]})
+			i++
+		default:
+			out = append(out, LineChange{Op: "+", Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		out = append(out, LineChange{Op: "-", Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		out = append(out, LineChange{Op: "+", Text: y[j]})
+	}
+	return out
+}
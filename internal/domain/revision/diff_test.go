@@ -0,0 +1,53 @@
+package revision
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	from := json.RawMessage(`{"title":"Циклы","content":{"theory":"a\nb\nc","hints":["h1","h2"],"schema_version":1}}`)
+	to := json.RawMessage(`{"title":"Циклы for","content":{"theory":"a\nc\nd","hints":["h1"],"schema_version":1,"objectives":["x"]}}`)
+
+	changes, err := Diff(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Change{}
+	for _, c := range changes {
+		got[c.Path] = c
+	}
+	if len(got) != 4 {
+		t.Fatalf("want 4 changes, got %+v", changes)
+	}
+	if c := got["title"]; c.Op != OpChanged || c.To != "Циклы for" {
+		t.Errorf("title: %+v", c)
+	}
+	if c := got["content.hints[1]"]; c.Op != OpRemoved || c.From != "h2" {
+		t.Errorf("hints: %+v", c)
+	}
+	if c := got["content.objectives"]; c.Op != OpAdded {
+		t.Errorf("objectives: %+v", c)
+	}
+	want := []LineChange{{" ", "a"}, {"-", "b"}, {" ", "c"}, {"+", "d"}}
+	lines := got["content.theory"].Lines
+	if len(lines) != len(want) {
+		t.Fatalf("theory lines: %+v", lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d: got %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestDiffIdentical(t *testing.T) {
+	raw := json.RawMessage(`{"title":"x","content":{"hints":[]}}`)
+	changes, err := Diff(raw, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("want no changes, got %+v", changes)
+	}
+}
@@ -0,0 +1,28 @@
+package revision
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Виды сущностей, для которых ведётся история.
+const (
+	KindLesson     = "lesson"
+	KindAssignment = "assignment"
+)
+
+// Revision полный снимок сущности после изменения.
+// Номера идут подряд с 1 в пределах сущности; откат создаёт новую ревизию.
+type Revision struct {
+	ID           uuid.UUID       `json:"id"`
+	EntityType   string          `json:"entity_type"`
+	EntityID     uuid.UUID       `json:"entity_id"`
+	Number       int             `json:"number"`
+	AuthorID     uuid.UUID       `json:"author_id"` // uuid.Nil — состояние до включения истории
+	Title        string          `json:"title"`
+	Snapshot     json.RawMessage `json:"snapshot,omitempty"`
+	RestoredFrom int             `json:"restored_from,omitempty"` // номер ревизии, из которой сделан откат
+	CreatedAt    time.Time       `json:"created_at"`
+}
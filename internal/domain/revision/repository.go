@@ -0,0 +1,16 @@
+package revision
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// Append присваивает ревизии следующий номер в пределах сущности и сохраняет её.
+	Append(ctx context.Context, r Revision) (Revision, error)
+	// List возвращает ревизии без снимков, от новых к старым.
+	List(ctx context.Context, entityType string, entityID uuid.UUID) ([]Revision, error)
+	Get(ctx context.Context, entityType string, entityID uuid.UUID, number int) (Revision, error)
+	Latest(ctx context.Context, entityType string, entityID uuid.UUID) (Revision, error)
+}
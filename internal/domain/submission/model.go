@@ -0,0 +1,135 @@
+package submission
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Вердикты проверки решения.
+const (
+	VerdictAccepted      = "accepted"
+	VerdictWrongAnswer   = "wrong_answer"
+	VerdictCompileError  = "compile_error"
+	VerdictTimeLimit     = "time_limit_exceeded"
+	VerdictMemoryLimit   = "memory_limit_exceeded"
+	VerdictTooSlow       = "performance_limit_exceeded" // тесты пройдены, но бенчмарки хуже порога
+	VerdictRuntimeError  = "runtime_error"
+	VerdictInternalError = "internal_error"
+)
+
+// IsKnownVerdict сообщает, поддерживается ли вердикт (для фильтров в API).
+func IsKnownVerdict(v string) bool {
+	switch v {
+	case VerdictAccepted, VerdictWrongAnswer, VerdictCompileError, VerdictTimeLimit,
+		VerdictMemoryLimit, VerdictTooSlow, VerdictRuntimeError, VerdictInternalError:
+		return true
+	}
+	return false
+}
+
+// HiddenTestFile файл скрытых тестов задания; строки сборки с ним студенту не показываются.
+const HiddenTestFile = "hidden_test.go"
+
+// TestResult результат одного теста.
+type TestResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // pass|fail|skip|incomplete
+	Output     string `json:"output,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	MemoryKB   int    `json:"memory_kb,omitempty"`
+	Hidden     bool   `json:"hidden"`
+}
+
+// BenchmarkResult бенчмарк решения в сравнении с эталоном (задания на скорость).
+type BenchmarkResult struct {
+	Name                 string  `json:"name"`
+	NsPerOp              float64 `json:"ns_per_op"`
+	AllocsPerOp          int64   `json:"allocs_per_op"`
+	BytesPerOp           int64   `json:"bytes_per_op"`
+	ReferenceNsPerOp     float64 `json:"reference_ns_per_op"`
+	ReferenceAllocsPerOp int64   `json:"reference_allocs_per_op"`
+	NsRatio              float64 `json:"ns_ratio"` // во сколько раз решение медленнее эталона
+	Passed               bool    `json:"passed"`
+	Message              string  `json:"message,omitempty"` // какой порог превышен
+}
+
+// Submission попытка решения задания (или практического урока) с вердиктом.
+type Submission struct {
+	ID              uuid.UUID         `json:"id"`
+	UserID          uuid.UUID         `json:"user_id"`
+	CourseID        uuid.UUID         `json:"course_id"`
+	LessonID        uuid.UUID         `json:"lesson_id"`
+	AssignmentID    *uuid.UUID        `json:"assignment_id,omitempty"`
+	Language        string            `json:"language"`
+	Code            string            `json:"code,omitempty"`
+	Verdict         string            `json:"verdict"`
+	Score           int               `json:"score"` // доля пройденных тестов, %
+	PassedCount     int               `json:"passed_count"`
+	Total           int               `json:"total"`
+	Tests           []TestResult      `json:"tests,omitempty"`
+	Benchmarks      []BenchmarkResult `json:"benchmarks,omitempty"`
+	CompileOutput   string            `json:"compile_output,omitempty"`
+	ExecutionTimeMs int64             `json:"execution_time_ms"`
+	MemoryKB        int               `json:"memory_kb"`
+	IsBest          bool              `json:"is_best"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+// Accepted решение прошло все тесты.
+func (s Submission) Accepted() bool { return s.Verdict == VerdictAccepted }
+
+// Better сообщает, лучше ли a, чем b: принятое решение, затем больший балл,
+// меньшее время, меньшая память; при равенстве лучшим остаётся более раннее.
+func Better(a, b Submission) bool {
+	if a.Accepted() != b.Accepted() {
+		return a.Accepted()
+	}
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if a.ExecutionTimeMs != b.ExecutionTimeMs {
+		return a.ExecutionTimeMs < b.ExecutionTimeMs
+	}
+	if a.MemoryKB != b.MemoryKB {
+		return a.MemoryKB < b.MemoryKB
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+// Redacted копия для студента: без вывода скрытых тестов и строк сборки из их файла.
+func (s Submission) Redacted() Submission {
+	if len(s.Tests) > 0 {
+		tests := make([]TestResult, len(s.Tests))
+		copy(tests, s.Tests)
+		for i := range tests {
+			if tests[i].Hidden {
+				tests[i].Output = ""
+			}
+		}
+		s.Tests = tests
+	}
+	if strings.Contains(s.CompileOutput, HiddenTestFile) {
+		lines := strings.Split(s.CompileOutput, "\n")
+		for i, l := range lines {
+			if strings.Contains(l, HiddenTestFile) {
+				lines[i] = "(hidden test) error"
+			}
+		}
+		s.CompileOutput = strings.Join(lines, "\n")
+	}
+	return s
+}
+
+// Filter выборка попыток; nil-поля не ограничивают выборку.
+type Filter struct {
+	UserID       *uuid.UUID
+	CourseID     *uuid.UUID
+	LessonID     *uuid.UUID
+	AssignmentID *uuid.UUID
+	Verdict      string
+	BestOnly     bool
+	Page         int
+	PageSize     int
+}
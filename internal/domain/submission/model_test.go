@@ -0,0 +1,45 @@
+package submission
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBetter(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	accepted := Submission{Verdict: VerdictAccepted, Score: 100, ExecutionTimeMs: 900, CreatedAt: t0}
+	cases := []struct {
+		name string
+		a, b Submission
+		want bool
+	}{
+		{"accepted beats higher-scored failure", accepted, Submission{Verdict: VerdictWrongAnswer, Score: 100, CreatedAt: t0}, true},
+		{"higher score", Submission{Verdict: VerdictWrongAnswer, Score: 60}, Submission{Verdict: VerdictWrongAnswer, Score: 40}, true},
+		{"faster", Submission{Verdict: VerdictAccepted, Score: 100, ExecutionTimeMs: 100, CreatedAt: t0.Add(time.Hour)}, accepted, true},
+		{"less memory", Submission{Verdict: VerdictAccepted, Score: 100, ExecutionTimeMs: 900, MemoryKB: 10}, Submission{Verdict: VerdictAccepted, Score: 100, ExecutionTimeMs: 900, MemoryKB: 20}, true},
+		{"tie keeps earlier", Submission{Verdict: VerdictAccepted, Score: 100, ExecutionTimeMs: 900, CreatedAt: t0.Add(time.Minute)}, accepted, false},
+	}
+	for _, c := range cases {
+		if got := Better(c.a, c.b); got != c.want {
+			t.Errorf("%s: Better = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	s := Submission{
+		Tests:         []TestResult{{Name: "TestOpen", Output: "visible"}, {Name: "TestSecret", Output: "expected 42", Hidden: true}},
+		CompileOutput: "./main.go:3: undefined: x\n./" + HiddenTestFile + ":7: want 42\n",
+	}
+	r := s.Redacted()
+	if r.Tests[0].Output != "visible" || r.Tests[1].Output != "" {
+		t.Errorf("tests not redacted: %+v", r.Tests)
+	}
+	if strings.Contains(r.CompileOutput, "want 42") || !strings.Contains(r.CompileOutput, "undefined: x") {
+		t.Errorf("compile output: %q", r.CompileOutput)
+	}
+	if s.Tests[1].Output == "" {
+		t.Error("original submission must stay intact")
+	}
+}
@@ -0,0 +1,23 @@
+package submission
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	// Create сохраняет попытку и атомично пересчитывает лучшую попытку пользователя
+	// по заданию (или уроку, если задания нет) с помощью Better.
+	Create(ctx context.Context, s Submission) (Submission, error)
+	Get(ctx context.Context, id uuid.UUID) (Submission, error)
+	// List возвращает попытки от новых к старым без кода и результатов тестов.
+	List(ctx context.Context, f Filter) ([]Submission, int64, error)
+	// Best лучшая попытка пользователя по заданию; нулевое значение, если попыток нет.
+	Best(ctx context.Context, userID, assignmentID uuid.UUID) (Submission, error)
+	// BestByAssignment лучшие попытки всех пользователей по заданию вместе с кодом.
+	BestByAssignment(ctx context.Context, assignmentID uuid.UUID) ([]Submission, error)
+	// LatestByAssignment время последней попытки по каждому заданию.
+	LatestByAssignment(ctx context.Context) (map[uuid.UUID]time.Time, error)
+}
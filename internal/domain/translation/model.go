@@ -0,0 +1,50 @@
+package translation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы переводимых сущностей.
+const (
+	EntityCourse = "course"
+	EntityLesson = "lesson"
+)
+
+// Переводимые поля. Для курса: title/summary/description/objectives,
+// для урока: theory/hints из LessonContent.
+const (
+	FieldTitle       = "title"
+	FieldSummary     = "summary"
+	FieldDescription = "description"
+	FieldObjectives  = "objectives"
+	FieldTheory      = "theory"
+	FieldHints       = "hints"
+)
+
+// Fields значения переводимых полей (пустое значение = нет перевода поля).
+type Fields struct {
+	Title       string   `json:"title,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Objectives  []string `json:"objectives,omitempty"`
+	Theory      string   `json:"theory,omitempty"`
+	Hints       []string `json:"hints,omitempty"`
+}
+
+// Translation перевод сущности на конкретную локаль.
+type Translation struct {
+	ID         uuid.UUID `json:"id"`
+	EntityType string    `json:"entity_type"` // course|lesson
+	EntityID   uuid.UUID `json:"entity_id"`
+	Locale     string    `json:"locale"`
+	Fields     Fields    `json:"fields"`
+	// SourceHashes хэши исходных полей на момент перевода (поле -> sha256)
+	SourceHashes map[string]string `json:"-"`
+	UpdatedBy    uuid.UUID         `json:"updated_by"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	// StaleFields поля, исходник которых изменился после перевода (вычисляемое)
+	StaleFields []string `json:"stale_fields,omitempty"`
+	Stale       bool     `json:"stale"`
+}
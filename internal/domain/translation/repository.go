@@ -0,0 +1,16 @@
+package translation
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository контракт хранилища переводов.
+type Repository interface {
+	Get(ctx context.Context, entityType string, entityID uuid.UUID, locale string) (Translation, error)
+	ListByEntity(ctx context.Context, entityType string, entityID uuid.UUID) ([]Translation, error)
+	ListByEntities(ctx context.Context, entityType string, entityIDs []uuid.UUID) ([]Translation, error)
+	Upsert(ctx context.Context, t Translation) (Translation, error)
+	Delete(ctx context.Context, entityType string, entityID uuid.UUID, locale string) error
+}
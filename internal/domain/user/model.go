@@ -20,6 +20,7 @@ type User struct {
 	PasswordHash string     `json:"-"`
 	Name         string     `json:"name"`
 	AvatarURL    string     `json:"avatar_url,omitempty"`
+	Locale       string     `json:"locale,omitempty"` // предпочитаемый язык интерфейса/контента (ru, en)
 	Role         Role       `json:"role"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
@@ -0,0 +1,51 @@
+package video
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Статусы видеофайла.
+const (
+	StatusUploading = "uploading" // multipart-загрузка начата, части грузятся клиентом напрямую в S3
+	StatusReady     = "ready"
+	StatusAborted   = "aborted"
+	StatusFailed    = "failed" // собранный файл не прошёл проверку и удалён из хранилища
+)
+
+// Asset видеофайл урока в объектном хранилище. Объект приватный:
+// смотреть его можно только по кратковременной подписанной ссылке.
+type Asset struct {
+	ID          uuid.UUID `json:"id"`
+	LessonID    uuid.UUID `json:"lesson_id"`
+	CourseID    uuid.UUID `json:"course_id"`
+	ObjectKey   string    `json:"-"`
+	UploadID    string    `json:"-"` // uploadId multipart-загрузки, пока статус uploading
+	Status      string    `json:"status"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	DurationSec int       `json:"duration_sec"`
+	Captions    []Caption `json:"captions"`
+	CreatedBy   uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Caption дорожка субтитров WebVTT.
+type Caption struct {
+	Locale    string `json:"locale"`
+	Label     string `json:"label"`
+	ObjectKey string `json:"-"`
+}
+
+// SetCaption добавляет или заменяет субтитры для локали.
+func (a *Asset) SetCaption(c Caption) {
+	for i := range a.Captions {
+		if a.Captions[i].Locale == c.Locale {
+			a.Captions[i] = c
+			return
+		}
+	}
+	a.Captions = append(a.Captions, c)
+}
@@ -0,0 +1,14 @@
+package video
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Create(ctx context.Context, a Asset) (Asset, error)
+	Get(ctx context.Context, id uuid.UUID) (Asset, error)
+	Update(ctx context.Context, a Asset) (Asset, error)
+	ListByLesson(ctx context.Context, lessonID uuid.UUID) ([]Asset, error)
+}
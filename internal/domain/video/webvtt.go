@@ -0,0 +1,101 @@
+package video
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxCaptionBytes предельный размер файла субтитров.
+const MaxCaptionBytes = 1 << 20
+
+var ErrInvalidCaptions = errors.New("invalid WebVTT")
+
+// timing строка тайминга: [hh:]mm:ss.ttt --> [hh:]mm:ss.ttt [настройки]
+var timingRe = regexp.MustCompile(`^((?:\d{2,}:)?\d{2}:\d{2}\.\d{3})[ \t]+-->[ \t]+((?:\d{2,}:)?\d{2}:\d{2}\.\d{3})(?:[ \t]+.*)?$`)
+
+// ValidateWebVTT проверяет файл субтитров: заголовок WEBVTT, корректные тайминги
+// (конец позже начала, начала не убывают) и хотя бы одну реплику. Возвращает число реплик.
+func ValidateWebVTT(data []byte) (int, error) {
+	if len(data) > MaxCaptionBytes {
+		return 0, fmt.Errorf("%w: file is larger than %d bytes", ErrInvalidCaptions, MaxCaptionBytes)
+	}
+	if !utf8.Valid(data) {
+		return 0, fmt.Errorf("%w: file must be UTF-8", ErrInvalidCaptions)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	text := strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(text, "\n")
+	if first := lines[0]; first != "WEBVTT" && !strings.HasPrefix(first, "WEBVTT ") && !strings.HasPrefix(first, "WEBVTT\t") {
+		return 0, fmt.Errorf("%w: line 1: missing WEBVTT header", ErrInvalidCaptions)
+	}
+
+	cues := 0
+	var prevStart time.Duration
+	for i := 1; i < len(lines); i++ {
+		line := lines[i]
+		if !strings.Contains(line, "-->") {
+			continue
+		}
+		m := timingRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			return 0, fmt.Errorf("%w: line %d: malformed cue timing", ErrInvalidCaptions, i+1)
+		}
+		start, err := parseTimestamp(m[1])
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: %v", ErrInvalidCaptions, i+1, err)
+		}
+		end, err := parseTimestamp(m[2])
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: %v", ErrInvalidCaptions, i+1, err)
+		}
+		if end <= start {
+			return 0, fmt.Errorf("%w: line %d: cue ends before it starts", ErrInvalidCaptions, i+1)
+		}
+		if start < prevStart {
+			return 0, fmt.Errorf("%w: line %d: cues must be ordered by start time", ErrInvalidCaptions, i+1)
+		}
+		if i+1 >= len(lines) || strings.TrimSpace(lines[i+1]) == "" {
+			return 0, fmt.Errorf("%w: line %d: cue has no text", ErrInvalidCaptions, i+1)
+		}
+		prevStart = start
+		cues++
+	}
+	if cues == 0 {
+		return 0, fmt.Errorf("%w: no cues", ErrInvalidCaptions)
+	}
+	return cues, nil
+}
+
+func parseTimestamp(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	var h, m int
+	var err error
+	if len(parts) == 3 {
+		if h, err = strconv.Atoi(parts[0]); err != nil {
+			return 0, err
+		}
+		parts = parts[1:]
+	}
+	if m, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, err
+	}
+	secParts := strings.SplitN(parts[1], ".", 2)
+	sec, err := strconv.Atoi(secParts[0])
+	if err != nil {
+		return 0, err
+	}
+	ms, err := strconv.Atoi(secParts[1])
+	if err != nil {
+		return 0, err
+	}
+	if m > 59 || sec > 59 {
+		return 0, fmt.Errorf("timestamp %s out of range", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second + time.Duration(ms)*time.Millisecond, nil
+}
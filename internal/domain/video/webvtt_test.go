@@ -0,0 +1,32 @@
+package video
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateWebVTT(t *testing.T) {
+	valid := "\xef\xbb\xbfWEBVTT - урок 1\r\n\r\n1\r\n00:00.000 --> 00:02.500\r\nПривет\r\n\r\n00:01:02.000 --> 00:01:04.000 align:start\r\nfor i := range n\r\n"
+	n, err := ValidateWebVTT([]byte(valid))
+	if err != nil {
+		t.Fatalf("valid file rejected: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("want 2 cues, got %d", n)
+	}
+
+	cases := map[string]string{
+		"no header":    "00:00.000 --> 00:01.000\ntext\n",
+		"no cues":      "WEBVTT\n\nNOTE пусто\n",
+		"bad timing":   "WEBVTT\n\n00:00 --> 00:01.000\ntext\n",
+		"end <= start": "WEBVTT\n\n00:05.000 --> 00:04.000\ntext\n",
+		"unordered":    "WEBVTT\n\n00:05.000 --> 00:06.000\na\n\n00:01.000 --> 00:02.000\nb\n",
+		"empty cue":    "WEBVTT\n\n00:01.000 --> 00:02.000\n\n",
+		"seconds > 59": "WEBVTT\n\n00:61.000 --> 01:02.000\ntext\n",
+	}
+	for name, src := range cases {
+		if _, err := ValidateWebVTT([]byte(src)); !errors.Is(err, ErrInvalidCaptions) {
+			t.Errorf("%s: want ErrInvalidCaptions, got %v", name, err)
+		}
+	}
+}
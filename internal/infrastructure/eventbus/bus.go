@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/event"
+)
+
+// Handler обработчик события.
+type Handler func(ctx context.Context, e dom.Event)
+
+// Bus простая in-process шина событий: обработчики вызываются синхронно в Publish,
+// поэтому тяжёлую работу они должны откладывать (например, через планировщик задач).
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func New() *Bus { return &Bus{handlers: make(map[string][]Handler)} }
+
+// Subscribe подписывает обработчик на события указанных типов.
+func (b *Bus) Subscribe(h Handler, types ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, t := range types {
+		b.handlers[t] = append(b.handlers[t], h)
+	}
+}
+
+// Publish рассылает событие подписчикам.
+func (b *Bus) Publish(ctx context.Context, e dom.Event) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now().UTC()
+	}
+	b.mu.RLock()
+	hs := b.handlers[e.Type]
+	b.mu.RUnlock()
+	for _, h := range hs {
+		h(ctx, e)
+	}
+}
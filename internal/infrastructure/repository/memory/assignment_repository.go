@@ -50,7 +50,7 @@ func (r *InMemoryAssignmentRepository) Get(ctx context.Context, id uuid.UUID) (d
 	return dom.Assignment{}, nil
 }
 
-func (r *InMemoryAssignmentRepository) Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode, tests string, order int) (dom.Assignment, error) {
+func (r *InMemoryAssignmentRepository) Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode string, starterFiles map[string]string, tests, hiddenTests string, performance *dom.Performance, order int) (dom.Assignment, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	a, ok := r.byID[id]
@@ -60,7 +60,10 @@ func (r *InMemoryAssignmentRepository) Update(ctx context.Context, id uuid.UUID,
 	a.Title = title
 	a.Prompt = prompt
 	a.StarterCode = starterCode
+	a.StarterFiles = starterFiles
 	a.Tests = tests
+	a.HiddenTests = hiddenTests
+	a.Performance = performance
 	a.Order = order
 	r.byID[id] = a
 	return a, nil
@@ -79,6 +79,8 @@ func (r *InMemoryCourseRepository) Search(ctx context.Context, f dom.ListFilter)
 		sort.Slice(items, func(i, j int) bool { return items[i].Title > items[j].Title })
 	case "popularity_desc":
 		sort.Slice(items, func(i, j int) bool { return items[i].Popularity > items[j].Popularity })
+	case "trending_desc":
+		sort.Slice(items, func(i, j int) bool { return items[i].TrendingScore > items[j].TrendingScore })
 	case "rating_desc":
 		sort.Slice(items, func(i, j int) bool { return items[i].Rating > items[j].Rating })
 	default:
@@ -151,19 +153,42 @@ func (r *InMemoryCourseRepository) Update(ctx context.Context, id uuid.UUID, upd
 	c.Title = updated.Title
 	c.Description = updated.Description
 	c.Summary = updated.Summary
+	if updated.SourceLocale != "" {
+		c.SourceLocale = updated.SourceLocale
+	}
 	if updated.Language != "" {
 		c.Language = updated.Language
 	}
 	c.Difficulty = updated.Difficulty
-	c.DurationMin = updated.DurationMin
-	c.DurationHours = updated.DurationHours
 	c.Tags = updated.Tags
 	c.ImageURL = updated.ImageURL
 	c.Objectives = updated.Objectives
+	c.Analyzers = updated.Analyzers
 	r.storage[id] = c
 	return c, nil
 }
 
+func (r *InMemoryCourseRepository) UpdateStats(ctx context.Context, id uuid.UUID, stats dom.Stats) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.storage[id]
+	if !ok {
+		return nil
+	}
+	applyStats(&c, stats)
+	r.storage[id] = c
+	return nil
+}
+
+func applyStats(c *dom.Course, stats dom.Stats) {
+	c.LessonsCount = stats.LessonsCount
+	c.StudentsCount = stats.StudentsCount
+	c.DurationMin = stats.DurationMin
+	c.DurationHours = (stats.DurationMin + 59) / 60
+	c.Popularity = stats.Popularity
+	c.TrendingScore = stats.TrendingScore
+}
+
 func (r *InMemoryCourseRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"sync"
+	"time"
 
 	dom "github.com/example/learngo/internal/domain/enrollment"
 	"github.com/google/uuid"
@@ -45,3 +46,27 @@ func (r *InMemoryEnrollmentRepository) ListByUser(ctx context.Context, userID uu
 	}
 	return res, nil
 }
+
+func (r *InMemoryEnrollmentRepository) CountByCourse(ctx context.Context, courseID uuid.UUID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var n int64
+	for _, v := range r.m {
+		if v.CourseID == courseID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *InMemoryEnrollmentRepository) ListByCourseSince(ctx context.Context, courseID uuid.UUID, since time.Time) ([]dom.Enrollment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]dom.Enrollment, 0)
+	for _, v := range r.m {
+		if v.CourseID == courseID && !v.CreatedAt.Before(since) {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
@@ -63,7 +63,7 @@ func (r *InMemoryLessonRepository) Get(ctx context.Context, id uuid.UUID) (dom.L
 	return dom.Lesson{}, nil
 }
 
-func (r *InMemoryLessonRepository) Update(ctx context.Context, id uuid.UUID, title, content string, order int, sectionID uuid.UUID) (dom.Lesson, error) {
+func (r *InMemoryLessonRepository) Update(ctx context.Context, id uuid.UUID, title, typ, content string, order int, sectionID uuid.UUID) (dom.Lesson, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	l, ok := r.byID[id]
@@ -71,6 +71,9 @@ func (r *InMemoryLessonRepository) Update(ctx context.Context, id uuid.UUID, tit
 		return dom.Lesson{}, nil
 	}
 	l.Title = title
+	if typ != "" {
+		l.Type = typ
+	}
 	l.Content = []byte(content)
 	l.Order = order
 	if sectionID != uuid.Nil {
@@ -86,3 +89,38 @@ func (r *InMemoryLessonRepository) Delete(ctx context.Context, id uuid.UUID) err
 	delete(r.byID, id)
 	return nil
 }
+
+func (r *InMemoryLessonRepository) Rearrange(ctx context.Context, courseID uuid.UUID, plan func(o dom.Outline) (dom.Layout, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var o dom.Outline
+	for _, l := range r.byID {
+		if l.CourseID == courseID {
+			o.Lessons = append(o.Lessons, l)
+		}
+	}
+	sort.Slice(o.Lessons, func(i, j int) bool { return o.Lessons[i].Order < o.Lessons[j].Order })
+	layout, err := plan(o)
+	if err != nil {
+		return err
+	}
+	if layout.Remove != uuid.Nil {
+		delete(r.byID, layout.Remove)
+	}
+	if layout.Save != nil {
+		l := *layout.Save
+		l.CourseID = courseID
+		r.byID[l.ID] = l
+	}
+	for _, p := range layout.Placements {
+		l, ok := r.byID[p.ID]
+		if !ok || l.CourseID != courseID {
+			continue
+		}
+		l.ModuleID, l.SectionID = p.ModuleID, p.ModuleID
+		l.Order = p.Order
+		l.PreviousLessonID, l.NextLessonID = p.PreviousLessonID, p.NextLessonID
+		r.byID[p.ID] = l
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 
 	dom "github.com/example/learngo/internal/domain/assignment"
 	"github.com/google/uuid"
@@ -9,22 +10,50 @@ import (
 )
 
 type AssignmentModel struct {
-	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
-	LessonID    uuid.UUID `gorm:"type:uuid;index;not null"`
-	Title       string    `gorm:"size:255;not null"`
-	Prompt      string    `gorm:"type:text;not null"`
-	StarterCode string    `gorm:"type:text;not null"`
-	Tests       string    `gorm:"type:text;not null"`
-	Order       int       `gorm:"not null;column:sort_order"`
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LessonID     uuid.UUID `gorm:"type:uuid;index;not null"`
+	Title        string    `gorm:"size:255;not null"`
+	Prompt       string    `gorm:"type:text;not null"`
+	StarterCode  string    `gorm:"type:text;not null"`
+	StarterFiles string    `gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+	Tests        string    `gorm:"type:text;not null"`
+	HiddenTests  string    `gorm:"type:text;not null;default:''"`
+	Performance  *string   `gorm:"type:jsonb"`
+	Order        int       `gorm:"not null;column:sort_order"`
 }
 
 func (AssignmentModel) TableName() string { return "assignments" }
 
 func assignmentToModel(a dom.Assignment) AssignmentModel {
-	return AssignmentModel{ID: a.ID, LessonID: a.LessonID, Title: a.Title, Prompt: a.Prompt, StarterCode: a.StarterCode, Tests: a.Tests, Order: a.Order}
+	return AssignmentModel{ID: a.ID, LessonID: a.LessonID, Title: a.Title, Prompt: a.Prompt, StarterCode: a.StarterCode, StarterFiles: starterFilesJSON(a.StarterFiles), Tests: a.Tests, HiddenTests: a.HiddenTests, Performance: performanceJSON(a.Performance), Order: a.Order}
 }
 func assignmentToDomain(m AssignmentModel) dom.Assignment {
-	return dom.Assignment{ID: m.ID, LessonID: m.LessonID, Title: m.Title, Prompt: m.Prompt, StarterCode: m.StarterCode, Tests: m.Tests, Order: m.Order}
+	a := dom.Assignment{ID: m.ID, LessonID: m.LessonID, Title: m.Title, Prompt: m.Prompt, StarterCode: m.StarterCode, Tests: m.Tests, HiddenTests: m.HiddenTests, Order: m.Order}
+	_ = json.Unmarshal([]byte(m.StarterFiles), &a.StarterFiles)
+	if len(a.StarterFiles) == 0 {
+		a.StarterFiles = nil
+	}
+	if m.Performance != nil {
+		a.Performance = &dom.Performance{}
+		_ = json.Unmarshal([]byte(*m.Performance), a.Performance)
+	}
+	return a
+}
+
+func starterFilesJSON(files map[string]string) string {
+	if len(files) == 0 {
+		return "{}"
+	}
+	return jsonString(files)
+}
+
+func performanceJSON(p *dom.Performance) *string {
+	if p == nil {
+		return nil
+	}
+	b, _ := json.Marshal(p)
+	s := string(b)
+	return &s
 }
 
 type AssignmentRepository struct{ db *gorm.DB }
@@ -66,7 +95,7 @@ func (r *AssignmentRepository) Get(ctx context.Context, id uuid.UUID) (dom.Assig
 	return assignmentToDomain(m), nil
 }
 
-func (r *AssignmentRepository) Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode, tests string, order int) (dom.Assignment, error) {
+func (r *AssignmentRepository) Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode string, starterFiles map[string]string, tests, hiddenTests string, performance *dom.Performance, order int) (dom.Assignment, error) {
 	var m AssignmentModel
 	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -77,7 +106,10 @@ func (r *AssignmentRepository) Update(ctx context.Context, id uuid.UUID, title,
 	m.Title = title
 	m.Prompt = prompt
 	m.StarterCode = starterCode
+	m.StarterFiles = starterFilesJSON(starterFiles)
 	m.Tests = tests
+	m.HiddenTests = hiddenTests
+	m.Performance = performanceJSON(performance)
 	m.Order = order
 	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
 		return dom.Assignment{}, err
@@ -15,6 +15,7 @@ type CourseModel struct {
 	Title            string    `gorm:"size:255;not null"`
 	Description      string    `gorm:"type:text;not null"`
 	Summary          string    `gorm:"type:text;not null;default:''"`
+	SourceLocale     string    `gorm:"size:16;not null;default:'ru'"`
 	Language         string    `gorm:"size:32;not null;default:'go'"`
 	Difficulty       string    `gorm:"size:32;not null;default:'beginner'"` // beginner, intermediate, advanced
 	DurationHours    int       `gorm:"not null;default:0"`                  // длительность в часах
@@ -29,6 +30,10 @@ type CourseModel struct {
 	PriceCents       int       `gorm:"not null;default:0"`    // цена в копейках (для обратной совместимости)
 	Rating           float64   `gorm:"not null;default:0"`
 	Popularity       int       `gorm:"not null;default:0"`
+	LessonsCount     int       `gorm:"not null;default:0"`
+	StudentsCount    int       `gorm:"not null;default:0"`
+	TrendingScore    float64   `gorm:"not null;default:0"`
+	AnalyzersJSON    *string   `gorm:"type:text"` // NULL — все проверки статического анализа
 }
 
 func (CourseModel) TableName() string { return "courses" }
@@ -63,6 +68,7 @@ func toModel(c dom.Course) CourseModel {
 		Title:            c.Title,
 		Description:      c.Description,
 		Summary:          c.Summary,
+		SourceLocale:     c.SourceLocale,
 		Language:         c.Language,
 		Difficulty:       c.Difficulty,
 		DurationHours:    c.DurationHours,
@@ -77,9 +83,23 @@ func toModel(c dom.Course) CourseModel {
 		PriceCents:       c.PriceCents,
 		Rating:           c.Rating,
 		Popularity:       c.Popularity,
+		LessonsCount:     c.LessonsCount,
+		StudentsCount:    c.StudentsCount,
+		TrendingScore:    c.TrendingScore,
+		AnalyzersJSON:    analyzersJSON(c.Analyzers),
 	}
 }
 
+// analyzersJSON nil-список проверок хранится как NULL, чтобы отличать его от пустого.
+func analyzersJSON(names []string) *string {
+	if names == nil {
+		return nil
+	}
+	b, _ := json.Marshal(names)
+	s := string(b)
+	return &s
+}
+
 func toDomain(m CourseModel) dom.Course {
 	var tags []string
 	var objectives []string
@@ -87,6 +107,11 @@ func toDomain(m CourseModel) dom.Course {
 	_ = json.Unmarshal([]byte(m.TagsJSON), &tags)
 	_ = json.Unmarshal([]byte(m.ObjectivesJSON), &objectives)
 	_ = json.Unmarshal([]byte(m.RequirementsJSON), &reqs)
+	var analyzers []string
+	if m.AnalyzersJSON != nil {
+		analyzers = []string{}
+		_ = json.Unmarshal([]byte(*m.AnalyzersJSON), &analyzers)
+	}
 
 	// Определяем imageUrl для обратной совместимости
 	imageURL := m.ImageURL
@@ -100,6 +125,7 @@ func toDomain(m CourseModel) dom.Course {
 		Title:         m.Title,
 		Description:   m.Description,
 		Summary:       m.Summary,
+		SourceLocale:  m.SourceLocale,
 		Language:      m.Language,
 		Difficulty:    m.Difficulty,
 		DurationHours: m.DurationHours,
@@ -114,6 +140,10 @@ func toDomain(m CourseModel) dom.Course {
 		PriceCents:    m.PriceCents,
 		Rating:        m.Rating,
 		Popularity:    m.Popularity,
+		LessonsCount:  m.LessonsCount,
+		StudentsCount: m.StudentsCount,
+		TrendingScore: m.TrendingScore,
+		Analyzers:     analyzers,
 	}
 }
 
@@ -183,6 +213,8 @@ func (r *CourseRepository) Search(ctx context.Context, f dom.ListFilter) (dom.Li
 		q = q.Order("title desc")
 	case "popularity_desc":
 		q = q.Order("popularity desc")
+	case "trending_desc":
+		q = q.Order("trending_score desc")
 	case "rating_desc":
 		q = q.Order("rating desc")
 	case "newest":
@@ -209,6 +241,9 @@ func (r *CourseRepository) Create(ctx context.Context, course dom.Course) (dom.C
 	if row.Slug == "" {
 		row.Slug = generateSlug(row.Title)
 	}
+	if row.SourceLocale == "" {
+		row.SourceLocale = "ru"
+	}
 	if err := r.db.WithContext(ctx).Create(&row).Error; err != nil {
 		return dom.Course{}, err
 	}
@@ -251,12 +286,13 @@ func (r *CourseRepository) Update(ctx context.Context, id uuid.UUID, updated dom
 	}
 	row.Description = updated.Description
 	row.Summary = updated.Summary
+	if updated.SourceLocale != "" {
+		row.SourceLocale = updated.SourceLocale
+	}
 	if updated.Language != "" {
 		row.Language = updated.Language
 	}
 	row.Difficulty = updated.Difficulty
-	row.DurationHours = updated.DurationHours
-	row.DurationMin = updated.DurationMin
 	// serialize tags/objectives/requirements
 	tg, _ := json.Marshal(updated.Tags)
 	obj, _ := json.Marshal(updated.Objectives)
@@ -273,13 +309,25 @@ func (r *CourseRepository) Update(ctx context.Context, id uuid.UUID, updated dom
 	row.Price = updated.Price
 	row.PriceCents = updated.PriceCents
 	row.Rating = updated.Rating
-	row.Popularity = updated.Popularity
+	row.AnalyzersJSON = analyzersJSON(updated.Analyzers)
+	// Popularity, длительность и счётчики вычисляются фоновой задачей (UpdateStats) и здесь не перезаписываются
 	if err := r.db.WithContext(ctx).Save(&row).Error; err != nil {
 		return dom.Course{}, err
 	}
 	return toDomain(row), nil
 }
 
+func (r *CourseRepository) UpdateStats(ctx context.Context, id uuid.UUID, stats dom.Stats) error {
+	return r.db.WithContext(ctx).Model(&CourseModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"lessons_count":  stats.LessonsCount,
+		"students_count": stats.StudentsCount,
+		"duration_min":   stats.DurationMin,
+		"duration_hours": (stats.DurationMin + 59) / 60,
+		"popularity":     stats.Popularity,
+		"trending_score": stats.TrendingScore,
+	}).Error
+}
+
 func generateSlug(s string) string {
 	// очень простой slugifier; для прод заменить
 	b := make([]rune, 0, len(s))
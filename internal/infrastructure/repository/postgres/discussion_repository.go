@@ -0,0 +1,310 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/discussion"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DiscussionThreadModel struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	LessonID       uuid.UUID  `gorm:"type:uuid;index;not null"`
+	CourseID       uuid.UUID  `gorm:"type:uuid;index;not null"`
+	AuthorID       uuid.UUID  `gorm:"type:uuid;not null"`
+	Title          string     `gorm:"size:255;not null"`
+	Body           string     `gorm:"type:text;not null;default:''"`
+	CodeLanguage   string     `gorm:"size:16;not null;default:''"`
+	CodeSource     string     `gorm:"type:text;not null;default:''"`
+	Upvotes        int        `gorm:"not null;default:0"`
+	RepliesCount   int        `gorm:"not null;default:0"`
+	AcceptedPostID *uuid.UUID `gorm:"type:uuid"`
+	Status         string     `gorm:"size:16;not null;default:'visible'"`
+	Locked         bool       `gorm:"not null;default:false"`
+	LastActivityAt time.Time  `gorm:"not null;index"`
+	CreatedAt      time.Time  `gorm:"not null"`
+	UpdatedAt      time.Time  `gorm:"not null"`
+	EditedAt       *time.Time
+}
+
+func (DiscussionThreadModel) TableName() string { return "discussion_threads" }
+
+type DiscussionPostModel struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	ThreadID     uuid.UUID  `gorm:"type:uuid;index;not null"`
+	ParentID     *uuid.UUID `gorm:"type:uuid"`
+	AuthorID     uuid.UUID  `gorm:"type:uuid;not null"`
+	Body         string     `gorm:"type:text;not null;default:''"`
+	CodeLanguage string     `gorm:"size:16;not null;default:''"`
+	CodeSource   string     `gorm:"type:text;not null;default:''"`
+	Upvotes      int        `gorm:"not null;default:0"`
+	Accepted     bool       `gorm:"not null;default:false"`
+	Status       string     `gorm:"size:16;not null;default:'visible'"`
+	CreatedAt    time.Time  `gorm:"not null"`
+	UpdatedAt    time.Time  `gorm:"not null"`
+	EditedAt     *time.Time
+}
+
+func (DiscussionPostModel) TableName() string { return "discussion_posts" }
+
+type DiscussionVoteModel struct {
+	TargetType string    `gorm:"size:16;primaryKey"`
+	TargetID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+func (DiscussionVoteModel) TableName() string { return "discussion_votes" }
+
+func codeColumns(c *dom.CodeAttachment) (string, string) {
+	if c == nil {
+		return "", ""
+	}
+	return c.Language, c.Source
+}
+
+func codeFromColumns(lang, src string) *dom.CodeAttachment {
+	if lang == "" && src == "" {
+		return nil
+	}
+	return &dom.CodeAttachment{Language: lang, Source: src}
+}
+
+func threadToModel(t dom.Thread) DiscussionThreadModel {
+	lang, src := codeColumns(t.Code)
+	return DiscussionThreadModel{
+		ID:             t.ID,
+		LessonID:       t.LessonID,
+		CourseID:       t.CourseID,
+		AuthorID:       t.AuthorID,
+		Title:          t.Title,
+		Body:           t.Body,
+		CodeLanguage:   lang,
+		CodeSource:     src,
+		Upvotes:        t.Upvotes,
+		RepliesCount:   t.RepliesCount,
+		AcceptedPostID: t.AcceptedPostID,
+		Status:         t.Status,
+		Locked:         t.Locked,
+		LastActivityAt: t.LastActivityAt,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+		EditedAt:       t.EditedAt,
+	}
+}
+
+func threadToDomain(m DiscussionThreadModel) dom.Thread {
+	return dom.Thread{
+		ID:             m.ID,
+		LessonID:       m.LessonID,
+		CourseID:       m.CourseID,
+		AuthorID:       m.AuthorID,
+		Title:          m.Title,
+		Body:           m.Body,
+		Code:           codeFromColumns(m.CodeLanguage, m.CodeSource),
+		Upvotes:        m.Upvotes,
+		RepliesCount:   m.RepliesCount,
+		AcceptedPostID: m.AcceptedPostID,
+		Status:         m.Status,
+		Locked:         m.Locked,
+		LastActivityAt: m.LastActivityAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+		EditedAt:       m.EditedAt,
+	}
+}
+
+func postToModel(p dom.Post) DiscussionPostModel {
+	lang, src := codeColumns(p.Code)
+	return DiscussionPostModel{
+		ID:           p.ID,
+		ThreadID:     p.ThreadID,
+		ParentID:     p.ParentID,
+		AuthorID:     p.AuthorID,
+		Body:         p.Body,
+		CodeLanguage: lang,
+		CodeSource:   src,
+		Upvotes:      p.Upvotes,
+		Accepted:     p.Accepted,
+		Status:       p.Status,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+		EditedAt:     p.EditedAt,
+	}
+}
+
+func postToDomain(m DiscussionPostModel) dom.Post {
+	return dom.Post{
+		ID:        m.ID,
+		ThreadID:  m.ThreadID,
+		ParentID:  m.ParentID,
+		AuthorID:  m.AuthorID,
+		Body:      m.Body,
+		Code:      codeFromColumns(m.CodeLanguage, m.CodeSource),
+		Upvotes:   m.Upvotes,
+		Accepted:  m.Accepted,
+		Status:    m.Status,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+		EditedAt:  m.EditedAt,
+	}
+}
+
+type DiscussionRepository struct{ db *gorm.DB }
+
+func NewDiscussionRepository(db *gorm.DB) *DiscussionRepository {
+	return &DiscussionRepository{db: db}
+}
+
+func (r *DiscussionRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&DiscussionThreadModel{}, &DiscussionPostModel{}, &DiscussionVoteModel{})
+}
+
+func (r *DiscussionRepository) CreateThread(ctx context.Context, t dom.Thread) (dom.Thread, error) {
+	m := threadToModel(t)
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return dom.Thread{}, err
+	}
+	return threadToDomain(m), nil
+}
+
+func (r *DiscussionRepository) GetThread(ctx context.Context, id uuid.UUID) (dom.Thread, error) {
+	var m DiscussionThreadModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Thread{}, nil
+		}
+		return dom.Thread{}, err
+	}
+	return threadToDomain(m), nil
+}
+
+func (r *DiscussionRepository) UpdateThread(ctx context.Context, t dom.Thread) (dom.Thread, error) {
+	m := threadToModel(t)
+	// счётчики меняются только атомарно в CreatePost/SetVote
+	if err := r.db.WithContext(ctx).Omit("upvotes", "replies_count").Save(&m).Error; err != nil {
+		return dom.Thread{}, err
+	}
+	return r.GetThread(ctx, t.ID)
+}
+
+func (r *DiscussionRepository) ListThreads(ctx context.Context, f dom.ThreadFilter) ([]dom.Thread, int64, error) {
+	q := r.db.WithContext(ctx).Model(&DiscussionThreadModel{}).Where("lesson_id = ?", f.LessonID)
+	if f.IncludeHidden {
+		q = q.Where("status <> ?", dom.StatusDeleted)
+	} else {
+		q = q.Where("status = ?", dom.StatusVisible)
+	}
+	switch f.Sort {
+	case dom.SortNew:
+		q = q.Order("created_at desc")
+	case dom.SortTop:
+		q = q.Order("upvotes desc").Order("created_at desc")
+	case dom.SortUnanswered:
+		q = q.Where("accepted_post_id IS NULL").Order("created_at desc")
+	default:
+		q = q.Order("last_activity_at desc")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []DiscussionThreadModel
+	if err := q.Offset((f.Page - 1) * f.PageSize).Limit(f.PageSize).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]dom.Thread, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, threadToDomain(m))
+	}
+	return out, total, nil
+}
+
+func (r *DiscussionRepository) CreatePost(ctx context.Context, p dom.Post) (dom.Post, error) {
+	m := postToModel(p)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+		return tx.Model(&DiscussionThreadModel{}).Where("id = ?", p.ThreadID).Updates(map[string]interface{}{
+			"replies_count":    gorm.Expr("replies_count + 1"),
+			"last_activity_at": p.CreatedAt,
+		}).Error
+	})
+	if err != nil {
+		return dom.Post{}, err
+	}
+	return postToDomain(m), nil
+}
+
+func (r *DiscussionRepository) GetPost(ctx context.Context, id uuid.UUID) (dom.Post, error) {
+	var m DiscussionPostModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Post{}, nil
+		}
+		return dom.Post{}, err
+	}
+	return postToDomain(m), nil
+}
+
+func (r *DiscussionRepository) UpdatePost(ctx context.Context, p dom.Post) (dom.Post, error) {
+	m := postToModel(p)
+	if err := r.db.WithContext(ctx).Omit("upvotes").Save(&m).Error; err != nil {
+		return dom.Post{}, err
+	}
+	return r.GetPost(ctx, p.ID)
+}
+
+func (r *DiscussionRepository) ListPosts(ctx context.Context, threadID uuid.UUID) ([]dom.Post, error) {
+	var rows []DiscussionPostModel
+	if err := r.db.WithContext(ctx).Where("thread_id = ?", threadID).Order("created_at asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dom.Post, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, postToDomain(m))
+	}
+	return out, nil
+}
+
+func (r *DiscussionRepository) SetVote(ctx context.Context, targetType string, targetID, userID uuid.UUID, up bool) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if up {
+			v := DiscussionVoteModel{TargetType: targetType, TargetID: targetID, UserID: userID, CreatedAt: time.Now().UTC()}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&v).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Where("target_type = ? AND target_id = ? AND user_id = ?", targetType, targetID, userID).Delete(&DiscussionVoteModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&DiscussionVoteModel{}).Where("target_type = ? AND target_id = ?", targetType, targetID).Count(&count).Error; err != nil {
+			return err
+		}
+		var target interface{} = &DiscussionPostModel{}
+		if targetType == dom.TargetThread {
+			target = &DiscussionThreadModel{}
+		}
+		return tx.Model(target).Where("id = ?", targetID).UpdateColumn("upvotes", count).Error
+	})
+	return int(count), err
+}
+
+func (r *DiscussionRepository) VotedBy(ctx context.Context, userID uuid.UUID, targetIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	out := make(map[uuid.UUID]bool)
+	if userID == uuid.Nil || len(targetIDs) == 0 {
+		return out, nil
+	}
+	var rows []DiscussionVoteModel
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND target_id IN ?", userID, targetIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range rows {
+		out[v.TargetID] = true
+	}
+	return out, nil
+}
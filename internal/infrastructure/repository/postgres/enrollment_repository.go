@@ -49,4 +49,20 @@ func (r *EnrollmentRepository) ListByUser(ctx context.Context, userID uuid.UUID)
 	return res, nil
 }
 
-// no extra helpers
+func (r *EnrollmentRepository) CountByCourse(ctx context.Context, courseID uuid.UUID) (int64, error) {
+	var cnt int64
+	err := r.db.WithContext(ctx).Model(&EnrollmentModel{}).Where("course_id = ?", courseID).Count(&cnt).Error
+	return cnt, err
+}
+
+func (r *EnrollmentRepository) ListByCourseSince(ctx context.Context, courseID uuid.UUID, since time.Time) ([]dom.Enrollment, error) {
+	var rows []EnrollmentModel
+	if err := r.db.WithContext(ctx).Where("course_id = ? AND created_at >= ?", courseID, since).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	res := make([]dom.Enrollment, 0, len(rows))
+	for _, m := range rows {
+		res = append(res, dom.Enrollment{UserID: m.UserID, CourseID: m.CourseID, Status: m.Status, CreatedAt: m.CreatedAt})
+	}
+	return res, nil
+}
@@ -6,6 +6,7 @@ import (
 	dom "github.com/example/learngo/internal/domain/lesson"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LessonModel struct {
@@ -115,7 +116,7 @@ func (r *LessonRepository) Get(ctx context.Context, id uuid.UUID) (dom.Lesson, e
 	return lessonToDomain(m), nil
 }
 
-func (r *LessonRepository) Update(ctx context.Context, id uuid.UUID, title, content string, order int, sectionID uuid.UUID) (dom.Lesson, error) {
+func (r *LessonRepository) Update(ctx context.Context, id uuid.UUID, title, typ, content string, order int, sectionID uuid.UUID) (dom.Lesson, error) {
 	var m LessonModel
 	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -124,6 +125,9 @@ func (r *LessonRepository) Update(ctx context.Context, id uuid.UUID, title, cont
 		return dom.Lesson{}, err
 	}
 	m.Title = title
+	if typ != "" {
+		m.Type = typ
+	}
 	m.Content = content
 	m.Order = order
 	if sectionID != uuid.Nil {
@@ -138,3 +142,61 @@ func (r *LessonRepository) Update(ctx context.Context, id uuid.UUID, title, cont
 func (r *LessonRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&LessonModel{}, "id = ?", id).Error
 }
+
+func (r *LessonRepository) Rearrange(ctx context.Context, courseID uuid.UUID, plan func(o dom.Outline) (dom.Layout, error)) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// у курса может ещё не быть ни модулей, ни уроков — сериализуем перестановки по курсу advisory-локом
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "outline:"+courseID.String()).Error; err != nil {
+			return err
+		}
+		// модули и уроки курса блокируются, чтобы правки модулей вне Rearrange не разошлись с расстановкой
+		var mods []ModuleModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("course_id = ?", courseID).Order("order_index asc").Find(&mods).Error; err != nil {
+			return err
+		}
+		var rows []LessonModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("course_id = ?", courseID).Order("sort_order asc").Find(&rows).Error; err != nil {
+			return err
+		}
+		o := dom.Outline{ModuleIDs: make([]uuid.UUID, 0, len(mods)), Lessons: make([]dom.Lesson, 0, len(rows))}
+		for _, m := range mods {
+			o.ModuleIDs = append(o.ModuleIDs, m.ID)
+		}
+		for _, row := range rows {
+			o.Lessons = append(o.Lessons, lessonToDomain(row))
+		}
+		layout, err := plan(o)
+		if err != nil {
+			return err
+		}
+		for i, id := range layout.ModuleIDs {
+			if err := tx.Model(&ModuleModel{}).Where("id = ? AND course_id = ?", id, courseID).Update("order_index", i+1).Error; err != nil {
+				return err
+			}
+		}
+		if layout.Remove != uuid.Nil {
+			if err := tx.Delete(&LessonModel{}, "id = ? AND course_id = ?", layout.Remove, courseID).Error; err != nil {
+				return err
+			}
+		}
+		if layout.Save != nil {
+			m := lessonToModel(*layout.Save)
+			m.CourseID = courseID
+			if err := tx.Save(&m).Error; err != nil {
+				return err
+			}
+		}
+		for _, p := range layout.Placements {
+			err := tx.Model(&LessonModel{}).Where("id = ? AND course_id = ?", p.ID, courseID).Updates(map[string]interface{}{
+				"module_id":          p.ModuleID,
+				"sort_order":         p.Order,
+				"previous_lesson_id": p.PreviousLessonID,
+				"next_lesson_id":     p.NextLessonID,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
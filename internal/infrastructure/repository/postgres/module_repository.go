@@ -40,6 +40,17 @@ func (r *ModuleRepository) ListByCourse(ctx context.Context, courseID uuid.UUID)
 	return out, nil
 }
 
+func (r *ModuleRepository) Get(ctx context.Context, id uuid.UUID) (dom.Module, error) {
+	var mm ModuleModel
+	if err := r.db.WithContext(ctx).First(&mm, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Module{}, nil
+		}
+		return dom.Module{}, err
+	}
+	return toModuleDomain(mm), nil
+}
+
 func (r *ModuleRepository) Create(ctx context.Context, m dom.Module) (dom.Module, error) {
 	mm := toModuleModel(m)
 	if mm.ID == uuid.Nil {
@@ -66,3 +77,14 @@ func (r *ModuleRepository) Update(ctx context.Context, id uuid.UUID, title strin
 func (r *ModuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&ModuleModel{}, "id = ?", id).Error
 }
+
+func (r *ModuleRepository) Reorder(ctx context.Context, courseID uuid.UUID, ids []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&ModuleModel{}).Where("id = ? AND course_id = ?", id, courseID).Update("order_index", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
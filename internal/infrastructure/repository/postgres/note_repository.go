@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/note"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NoteModel struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID          uuid.UUID `gorm:"type:uuid;not null;index:idx_notes_user_course"`
+	CourseID        uuid.UUID `gorm:"type:uuid;not null;index:idx_notes_user_course"`
+	LessonID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	SnippetID       string    `gorm:"size:64;not null;default:''"`
+	SnippetLanguage string    `gorm:"size:16;not null;default:''"`
+	SnippetCode     string    `gorm:"type:text;not null;default:''"`
+	Body            string    `gorm:"type:text;not null"`
+	CreatedAt       time.Time `gorm:"not null"`
+	UpdatedAt       time.Time `gorm:"not null"`
+}
+
+func (NoteModel) TableName() string { return "notes" }
+
+type BookmarkModel struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LessonID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CourseID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (BookmarkModel) TableName() string { return "bookmarks" }
+
+// noteHitRow строка результата полнотекстового поиска.
+type noteHitRow struct {
+	NoteModel `gorm:"embedded"`
+	Rank      float64
+	Headline  string
+}
+
+func noteToModel(n dom.Note) NoteModel {
+	return NoteModel{
+		ID:              n.ID,
+		UserID:          n.UserID,
+		CourseID:        n.CourseID,
+		LessonID:        n.LessonID,
+		SnippetID:       n.SnippetID,
+		SnippetLanguage: n.SnippetLanguage,
+		SnippetCode:     n.SnippetCode,
+		Body:            n.Body,
+		CreatedAt:       n.CreatedAt,
+		UpdatedAt:       n.UpdatedAt,
+	}
+}
+
+func noteToDomain(m NoteModel) dom.Note {
+	return dom.Note{
+		ID:              m.ID,
+		UserID:          m.UserID,
+		CourseID:        m.CourseID,
+		LessonID:        m.LessonID,
+		SnippetID:       m.SnippetID,
+		SnippetLanguage: m.SnippetLanguage,
+		SnippetCode:     m.SnippetCode,
+		Body:            m.Body,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+	}
+}
+
+func bookmarkToDomain(m BookmarkModel) dom.Bookmark {
+	return dom.Bookmark{UserID: m.UserID, LessonID: m.LessonID, CourseID: m.CourseID, CreatedAt: m.CreatedAt}
+}
+
+type NoteRepository struct{ db *gorm.DB }
+
+func NewNoteRepository(db *gorm.DB) *NoteRepository {
+	return &NoteRepository{db: db}
+}
+
+func (r *NoteRepository) AutoMigrate() error {
+	if err := r.db.AutoMigrate(&NoteModel{}, &BookmarkModel{}); err != nil {
+		return err
+	}
+	// поисковый вектор вычисляет сама БД; конфигурация simple — тексты смешанные, ru/en
+	if err := r.db.Exec(`ALTER TABLE notes ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (to_tsvector('simple', coalesce(body, '') || ' ' || coalesce(snippet_code, ''))) STORED`).Error; err != nil {
+		return err
+	}
+	return r.db.Exec("CREATE INDEX IF NOT EXISTS idx_notes_search_vector ON notes USING GIN (search_vector)").Error
+}
+
+func (r *NoteRepository) CreateNote(ctx context.Context, n dom.Note) (dom.Note, error) {
+	m := noteToModel(n)
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return dom.Note{}, err
+	}
+	return noteToDomain(m), nil
+}
+
+func (r *NoteRepository) GetNote(ctx context.Context, id uuid.UUID) (dom.Note, error) {
+	var m NoteModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Note{}, nil
+		}
+		return dom.Note{}, err
+	}
+	return noteToDomain(m), nil
+}
+
+func (r *NoteRepository) UpdateNote(ctx context.Context, n dom.Note) (dom.Note, error) {
+	err := r.db.WithContext(ctx).Model(&NoteModel{}).Where("id = ?", n.ID).Updates(map[string]interface{}{
+		"body":       n.Body,
+		"updated_at": n.UpdatedAt,
+	}).Error
+	if err != nil {
+		return dom.Note{}, err
+	}
+	return r.GetNote(ctx, n.ID)
+}
+
+func (r *NoteRepository) DeleteNote(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&NoteModel{}, "id = ?", id).Error
+}
+
+func (r *NoteRepository) ListNotes(ctx context.Context, f dom.Filter) ([]dom.Note, error) {
+	q := r.db.WithContext(ctx).Where("user_id = ?", f.UserID)
+	if f.CourseID != nil {
+		q = q.Where("course_id = ?", *f.CourseID)
+	}
+	if f.LessonID != nil {
+		q = q.Where("lesson_id = ?", *f.LessonID)
+	}
+	var rows []NoteModel
+	if err := q.Order("created_at asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dom.Note, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, noteToDomain(m))
+	}
+	return out, nil
+}
+
+func (r *NoteRepository) SearchNotes(ctx context.Context, userID uuid.UUID, query string, limit int) ([]dom.SearchHit, error) {
+	var rows []noteHitRow
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT n.*, ts_rank(n.search_vector, q) AS rank,
+			ts_headline('simple', n.body, q, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS headline
+		FROM notes n, websearch_to_tsquery('simple', ?) q
+		WHERE n.user_id = ? AND n.search_vector @@ q
+		ORDER BY rank DESC, n.updated_at DESC
+		LIMIT ?`, query, userID, limit).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dom.SearchHit, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, dom.SearchHit{Note: noteToDomain(row.NoteModel), Headline: row.Headline, Rank: row.Rank})
+	}
+	return out, nil
+}
+
+func (r *NoteRepository) AddBookmark(ctx context.Context, b dom.Bookmark) (dom.Bookmark, error) {
+	m := BookmarkModel{UserID: b.UserID, LessonID: b.LessonID, CourseID: b.CourseID, CreatedAt: b.CreatedAt}
+	db := r.db.WithContext(ctx)
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&m).Error; err != nil {
+		return dom.Bookmark{}, err
+	}
+	var saved BookmarkModel
+	if err := db.First(&saved, "user_id = ? AND lesson_id = ?", b.UserID, b.LessonID).Error; err != nil {
+		return dom.Bookmark{}, err
+	}
+	return bookmarkToDomain(saved), nil
+}
+
+func (r *NoteRepository) RemoveBookmark(ctx context.Context, userID, lessonID uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&BookmarkModel{}, "user_id = ? AND lesson_id = ?", userID, lessonID).Error
+}
+
+func (r *NoteRepository) ListBookmarks(ctx context.Context, userID uuid.UUID, courseID *uuid.UUID) ([]dom.Bookmark, error) {
+	q := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if courseID != nil {
+		q = q.Where("course_id = ?", *courseID)
+	}
+	var rows []BookmarkModel
+	if err := q.Order("created_at desc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dom.Bookmark, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, bookmarkToDomain(m))
+	}
+	return out, nil
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/plagiarism"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PlagiarismReportModel struct {
+	AssignmentID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Submissions  int       `gorm:"not null;default:0"`
+	Threshold    float64   `gorm:"not null"`
+	ComputedAt   time.Time `gorm:"not null"`
+}
+
+func (PlagiarismReportModel) TableName() string { return "plagiarism_reports" }
+
+type PlagiarismPairModel struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	AssignmentID uuid.UUID `gorm:"type:uuid;not null;index"`
+	SubmissionA  uuid.UUID `gorm:"type:uuid;not null"`
+	SubmissionB  uuid.UUID `gorm:"type:uuid;not null"`
+	UserA        uuid.UUID `gorm:"type:uuid;not null"`
+	UserB        uuid.UUID `gorm:"type:uuid;not null"`
+	Similarity   float64   `gorm:"not null"`
+	ScoreA       float64   `gorm:"not null"`
+	ScoreB       float64   `gorm:"not null"`
+	Matches      string    `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+}
+
+func (PlagiarismPairModel) TableName() string { return "plagiarism_pairs" }
+
+func plagiarismPairToDomain(m PlagiarismPairModel) dom.Pair {
+	p := dom.Pair{
+		ID:           m.ID,
+		AssignmentID: m.AssignmentID,
+		SubmissionA:  m.SubmissionA,
+		SubmissionB:  m.SubmissionB,
+		UserA:        m.UserA,
+		UserB:        m.UserB,
+		Similarity:   m.Similarity,
+		ScoreA:       m.ScoreA,
+		ScoreB:       m.ScoreB,
+	}
+	_ = json.Unmarshal([]byte(m.Matches), &p.Matches)
+	return p
+}
+
+type PlagiarismRepository struct{ db *gorm.DB }
+
+func NewPlagiarismRepository(db *gorm.DB) *PlagiarismRepository {
+	return &PlagiarismRepository{db: db}
+}
+
+func (r *PlagiarismRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&PlagiarismReportModel{}, &PlagiarismPairModel{})
+}
+
+func (r *PlagiarismRepository) SaveReport(ctx context.Context, rep dom.Report) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		m := PlagiarismReportModel{AssignmentID: rep.AssignmentID, Submissions: rep.Submissions, Threshold: rep.Threshold, ComputedAt: rep.ComputedAt}
+		if err := tx.Save(&m).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("assignment_id = ?", rep.AssignmentID).Delete(&PlagiarismPairModel{}).Error; err != nil {
+			return err
+		}
+		if len(rep.Pairs) == 0 {
+			return nil
+		}
+		rows := make([]PlagiarismPairModel, 0, len(rep.Pairs))
+		for _, p := range rep.Pairs {
+			rows = append(rows, PlagiarismPairModel{
+				ID:           p.ID,
+				AssignmentID: rep.AssignmentID,
+				SubmissionA:  p.SubmissionA,
+				SubmissionB:  p.SubmissionB,
+				UserA:        p.UserA,
+				UserB:        p.UserB,
+				Similarity:   p.Similarity,
+				ScoreA:       p.ScoreA,
+				ScoreB:       p.ScoreB,
+				Matches:      jsonString(p.Matches),
+			})
+		}
+		return tx.CreateInBatches(rows, 100).Error
+	})
+}
+
+func (r *PlagiarismRepository) Report(ctx context.Context, assignmentID uuid.UUID) (dom.Report, error) {
+	var m PlagiarismReportModel
+	res := r.db.WithContext(ctx).Where("assignment_id = ?", assignmentID).Limit(1).Find(&m)
+	if res.Error != nil {
+		return dom.Report{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return dom.Report{}, nil
+	}
+	var rows []PlagiarismPairModel
+	if err := r.db.WithContext(ctx).Where("assignment_id = ?", assignmentID).Order("similarity desc").Find(&rows).Error; err != nil {
+		return dom.Report{}, err
+	}
+	rep := dom.Report{AssignmentID: m.AssignmentID, Submissions: m.Submissions, Threshold: m.Threshold, ComputedAt: m.ComputedAt, Pairs: make([]dom.Pair, 0, len(rows))}
+	for _, row := range rows {
+		rep.Pairs = append(rep.Pairs, plagiarismPairToDomain(row))
+	}
+	return rep, nil
+}
+
+func (r *PlagiarismRepository) Pair(ctx context.Context, id uuid.UUID) (dom.Pair, error) {
+	var m PlagiarismPairModel
+	res := r.db.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&m)
+	if res.Error != nil {
+		return dom.Pair{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return dom.Pair{}, nil
+	}
+	return plagiarismPairToDomain(m), nil
+}
+
+func (r *PlagiarismRepository) ComputedAt(ctx context.Context) (map[uuid.UUID]time.Time, error) {
+	var rows []PlagiarismReportModel
+	if err := r.db.WithContext(ctx).Select("assignment_id", "computed_at").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]time.Time, len(rows))
+	for _, m := range rows {
+		out[m.AssignmentID] = m.ComputedAt
+	}
+	return out, nil
+}
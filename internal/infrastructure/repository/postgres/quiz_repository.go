@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/quiz"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type QuizQuestionModel struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LessonID      uuid.UUID `gorm:"type:uuid;index;not null"`
+	Kind          string    `gorm:"size:16;not null"`
+	Prompt        string    `gorm:"type:text;not null"`
+	Code          string    `gorm:"type:text;not null;default:''"`
+	Options       string    `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	Correct       string    `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	Accepted      string    `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	CaseSensitive bool      `gorm:"not null;default:false"`
+	Points        int       `gorm:"not null;default:1"`
+	Order         int       `gorm:"not null;column:sort_order;default:0"`
+	CreatedAt     time.Time `gorm:"not null"`
+	UpdatedAt     time.Time `gorm:"not null"`
+}
+
+func (QuizQuestionModel) TableName() string { return "quiz_questions" }
+
+type QuizAttemptModel struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index:idx_quiz_attempts_user_lesson"`
+	LessonID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_quiz_attempts_user_lesson"`
+	CourseID    uuid.UUID  `gorm:"type:uuid;not null"`
+	Seed        int64      `gorm:"not null"`
+	QuestionIDs string     `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	Status      string     `gorm:"size:16;not null"`
+	Answers     string     `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	Results     string     `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	Score       int        `gorm:"not null;default:0"`
+	MaxScore    int        `gorm:"not null;default:0"`
+	Percent     int        `gorm:"not null;default:0"`
+	Passed      bool       `gorm:"not null;default:false"`
+	StartedAt   time.Time  `gorm:"not null"`
+	ExpiresAt   *time.Time `gorm:"default:null"`
+	SubmittedAt *time.Time `gorm:"default:null"`
+}
+
+func (QuizAttemptModel) TableName() string { return "quiz_attempts" }
+
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return "[]"
+	}
+	return string(b)
+}
+
+func quizQuestionToModel(q dom.Question) QuizQuestionModel {
+	return QuizQuestionModel{
+		ID:            q.ID,
+		LessonID:      q.LessonID,
+		Kind:          q.Kind,
+		Prompt:        q.Prompt,
+		Code:          q.Code,
+		Options:       jsonString(q.Options),
+		Correct:       jsonString(q.Correct),
+		Accepted:      jsonString(q.Accepted),
+		CaseSensitive: q.CaseSensitive,
+		Points:        q.Points,
+		Order:         q.Order,
+		CreatedAt:     q.CreatedAt,
+		UpdatedAt:     q.UpdatedAt,
+	}
+}
+
+func quizQuestionToDomain(m QuizQuestionModel) dom.Question {
+	q := dom.Question{
+		ID:            m.ID,
+		LessonID:      m.LessonID,
+		Kind:          m.Kind,
+		Prompt:        m.Prompt,
+		Code:          m.Code,
+		CaseSensitive: m.CaseSensitive,
+		Points:        m.Points,
+		Order:         m.Order,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+	_ = json.Unmarshal([]byte(m.Options), &q.Options)
+	_ = json.Unmarshal([]byte(m.Correct), &q.Correct)
+	_ = json.Unmarshal([]byte(m.Accepted), &q.Accepted)
+	return q
+}
+
+func quizAttemptToModel(a dom.Attempt) QuizAttemptModel {
+	return QuizAttemptModel{
+		ID:          a.ID,
+		UserID:      a.UserID,
+		LessonID:    a.LessonID,
+		CourseID:    a.CourseID,
+		Seed:        a.Seed,
+		QuestionIDs: jsonString(a.QuestionIDs),
+		Status:      a.Status,
+		Answers:     jsonString(a.Answers),
+		Results:     jsonString(a.Results),
+		Score:       a.Score,
+		MaxScore:    a.MaxScore,
+		Percent:     a.Percent,
+		Passed:      a.Passed,
+		StartedAt:   a.StartedAt,
+		ExpiresAt:   a.ExpiresAt,
+		SubmittedAt: a.SubmittedAt,
+	}
+}
+
+func quizAttemptToDomain(m QuizAttemptModel) dom.Attempt {
+	a := dom.Attempt{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		LessonID:    m.LessonID,
+		CourseID:    m.CourseID,
+		Seed:        m.Seed,
+		Status:      m.Status,
+		Score:       m.Score,
+		MaxScore:    m.MaxScore,
+		Percent:     m.Percent,
+		Passed:      m.Passed,
+		StartedAt:   m.StartedAt,
+		ExpiresAt:   m.ExpiresAt,
+		SubmittedAt: m.SubmittedAt,
+	}
+	_ = json.Unmarshal([]byte(m.QuestionIDs), &a.QuestionIDs)
+	_ = json.Unmarshal([]byte(m.Answers), &a.Answers)
+	_ = json.Unmarshal([]byte(m.Results), &a.Results)
+	return a
+}
+
+type QuizRepository struct{ db *gorm.DB }
+
+func NewQuizRepository(db *gorm.DB) *QuizRepository { return &QuizRepository{db: db} }
+
+func (r *QuizRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&QuizQuestionModel{}, &QuizAttemptModel{})
+}
+
+func (r *QuizRepository) ListQuestions(ctx context.Context, lessonID uuid.UUID) ([]dom.Question, error) {
+	var rows []QuizQuestionModel
+	if err := r.db.WithContext(ctx).Where("lesson_id = ?", lessonID).Order("sort_order asc, created_at asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dom.Question, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, quizQuestionToDomain(m))
+	}
+	return out, nil
+}
+
+func (r *QuizRepository) GetQuestion(ctx context.Context, id uuid.UUID) (dom.Question, error) {
+	var m QuizQuestionModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Question{}, nil
+		}
+		return dom.Question{}, err
+	}
+	return quizQuestionToDomain(m), nil
+}
+
+func (r *QuizRepository) CreateQuestion(ctx context.Context, q dom.Question) (dom.Question, error) {
+	m := quizQuestionToModel(q)
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return dom.Question{}, err
+	}
+	return quizQuestionToDomain(m), nil
+}
+
+func (r *QuizRepository) UpdateQuestion(ctx context.Context, q dom.Question) (dom.Question, error) {
+	m := quizQuestionToModel(q)
+	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
+		return dom.Question{}, err
+	}
+	return quizQuestionToDomain(m), nil
+}
+
+func (r *QuizRepository) DeleteQuestion(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&QuizQuestionModel{}, "id = ?", id).Error
+}
+
+func (r *QuizRepository) StartAttempt(ctx context.Context, a dom.Attempt, maxAttempts int, grace time.Duration) (dom.Attempt, error) {
+	m := quizAttemptToModel(a)
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// параллельные старты одного пользователя сериализуются, чтобы подсчёт и вставка были атомарны
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "quiz_attempt:"+a.UserID.String()+":"+a.LessonID.String()).Error; err != nil {
+			return err
+		}
+		var rows []QuizAttemptModel
+		if err := tx.Where("user_id = ? AND lesson_id = ?", a.UserID, a.LessonID).Order("started_at asc").Find(&rows).Error; err != nil {
+			return err
+		}
+		attempts := make([]dom.Attempt, 0, len(rows))
+		for _, row := range rows {
+			attempts = append(attempts, quizAttemptToDomain(row))
+		}
+		active, used := dom.Usage(attempts, a.StartedAt, grace)
+		if active != nil {
+			m = quizAttemptToModel(*active)
+			return nil
+		}
+		if maxAttempts > 0 && used >= maxAttempts {
+			return dom.ErrAttemptsExhausted
+		}
+		return tx.Create(&m).Error
+	})
+	if err != nil {
+		return dom.Attempt{}, err
+	}
+	return quizAttemptToDomain(m), nil
+}
+
+func (r *QuizRepository) GetAttempt(ctx context.Context, id uuid.UUID) (dom.Attempt, error) {
+	var m QuizAttemptModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Attempt{}, nil
+		}
+		return dom.Attempt{}, err
+	}
+	return quizAttemptToDomain(m), nil
+}
+
+func (r *QuizRepository) UpdateAttempt(ctx context.Context, a dom.Attempt) (dom.Attempt, error) {
+	m := quizAttemptToModel(a)
+	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
+		return dom.Attempt{}, err
+	}
+	return quizAttemptToDomain(m), nil
+}
+
+func (r *QuizRepository) ListAttempts(ctx context.Context, userID, lessonID uuid.UUID) ([]dom.Attempt, error) {
+	var rows []QuizAttemptModel
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND lesson_id = ?", userID, lessonID).Order("started_at asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dom.Attempt, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, quizAttemptToDomain(m))
+	}
+	return out, nil
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/revision"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RevisionModel struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	EntityType   string    `gorm:"size:16;not null;uniqueIndex:idx_revisions_entity_number"`
+	EntityID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_revisions_entity_number"`
+	Number       int       `gorm:"not null;uniqueIndex:idx_revisions_entity_number"`
+	AuthorID     uuid.UUID `gorm:"type:uuid;not null"`
+	Title        string    `gorm:"size:255;not null;default:''"`
+	Snapshot     string    `gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+	RestoredFrom int       `gorm:"not null;default:0"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+func (RevisionModel) TableName() string { return "revisions" }
+
+func revisionToModel(r dom.Revision) RevisionModel {
+	snapshot := string(r.Snapshot)
+	if snapshot == "" {
+		snapshot = "{}"
+	}
+	return RevisionModel{
+		ID:           r.ID,
+		EntityType:   r.EntityType,
+		EntityID:     r.EntityID,
+		Number:       r.Number,
+		AuthorID:     r.AuthorID,
+		Title:        r.Title,
+		Snapshot:     snapshot,
+		RestoredFrom: r.RestoredFrom,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+func revisionToDomain(m RevisionModel) dom.Revision {
+	r := dom.Revision{
+		ID:           m.ID,
+		EntityType:   m.EntityType,
+		EntityID:     m.EntityID,
+		Number:       m.Number,
+		AuthorID:     m.AuthorID,
+		Title:        m.Title,
+		RestoredFrom: m.RestoredFrom,
+		CreatedAt:    m.CreatedAt,
+	}
+	if m.Snapshot != "" {
+		r.Snapshot = json.RawMessage(m.Snapshot)
+	}
+	return r
+}
+
+type RevisionRepository struct{ db *gorm.DB }
+
+func NewRevisionRepository(db *gorm.DB) *RevisionRepository { return &RevisionRepository{db: db} }
+
+func (r *RevisionRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&RevisionModel{})
+}
+
+func (r *RevisionRepository) Append(ctx context.Context, rev dom.Revision) (dom.Revision, error) {
+	m := revisionToModel(rev)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// строк ещё может не быть, поэтому сериализуем запись по сущности advisory-локом
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", m.EntityType+":"+m.EntityID.String()).Error; err != nil {
+			return err
+		}
+		var last int
+		if err := tx.Model(&RevisionModel{}).Where("entity_type = ? AND entity_id = ?", m.EntityType, m.EntityID).
+			Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		m.Number = last + 1
+		return tx.Create(&m).Error
+	})
+	if err != nil {
+		return dom.Revision{}, err
+	}
+	return revisionToDomain(m), nil
+}
+
+func (r *RevisionRepository) List(ctx context.Context, entityType string, entityID uuid.UUID) ([]dom.Revision, error) {
+	var rows []RevisionModel
+	err := r.db.WithContext(ctx).Omit("snapshot").
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("number desc").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dom.Revision, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, revisionToDomain(m))
+	}
+	return out, nil
+}
+
+func (r *RevisionRepository) Get(ctx context.Context, entityType string, entityID uuid.UUID, number int) (dom.Revision, error) {
+	var m RevisionModel
+	err := r.db.WithContext(ctx).Where("entity_type = ? AND entity_id = ? AND number = ?", entityType, entityID, number).First(&m).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Revision{}, nil
+		}
+		return dom.Revision{}, err
+	}
+	return revisionToDomain(m), nil
+}
+
+func (r *RevisionRepository) Latest(ctx context.Context, entityType string, entityID uuid.UUID) (dom.Revision, error) {
+	var m RevisionModel
+	err := r.db.WithContext(ctx).Where("entity_type = ? AND entity_id = ?", entityType, entityID).Order("number desc").First(&m).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Revision{}, nil
+		}
+		return dom.Revision{}, err
+	}
+	return revisionToDomain(m), nil
+}
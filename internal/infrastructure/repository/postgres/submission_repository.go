@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/submission"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SubmissionModel struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;index:idx_submissions_user_target"`
+	CourseID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	LessonID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	AssignmentID    *uuid.UUID `gorm:"type:uuid;index:idx_submissions_user_target"`
+	Language        string     `gorm:"size:16;not null"`
+	Code            string     `gorm:"type:text;not null"`
+	Verdict         string     `gorm:"size:32;not null;index"`
+	Score           int        `gorm:"not null;default:0"`
+	PassedCount     int        `gorm:"not null;default:0"`
+	Total           int        `gorm:"not null;default:0"`
+	Tests           string     `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	Benchmarks      string     `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	CompileOutput   string     `gorm:"type:text;not null;default:''"`
+	ExecutionTimeMs int64      `gorm:"not null;default:0"`
+	MemoryKB        int        `gorm:"column:memory_kb;not null;default:0"`
+	IsBest          bool       `gorm:"not null;default:false"`
+	CreatedAt       time.Time  `gorm:"not null;index"`
+}
+
+func (SubmissionModel) TableName() string { return "submissions" }
+
+func submissionToModel(s dom.Submission) SubmissionModel {
+	return SubmissionModel{
+		ID:              s.ID,
+		UserID:          s.UserID,
+		CourseID:        s.CourseID,
+		LessonID:        s.LessonID,
+		AssignmentID:    s.AssignmentID,
+		Language:        s.Language,
+		Code:            s.Code,
+		Verdict:         s.Verdict,
+		Score:           s.Score,
+		PassedCount:     s.PassedCount,
+		Total:           s.Total,
+		Tests:           jsonString(s.Tests),
+		Benchmarks:      jsonString(s.Benchmarks),
+		CompileOutput:   s.CompileOutput,
+		ExecutionTimeMs: s.ExecutionTimeMs,
+		MemoryKB:        s.MemoryKB,
+		IsBest:          s.IsBest,
+		CreatedAt:       s.CreatedAt,
+	}
+}
+
+func submissionToDomain(m SubmissionModel) dom.Submission {
+	s := dom.Submission{
+		ID:              m.ID,
+		UserID:          m.UserID,
+		CourseID:        m.CourseID,
+		LessonID:        m.LessonID,
+		AssignmentID:    m.AssignmentID,
+		Language:        m.Language,
+		Code:            m.Code,
+		Verdict:         m.Verdict,
+		Score:           m.Score,
+		PassedCount:     m.PassedCount,
+		Total:           m.Total,
+		CompileOutput:   m.CompileOutput,
+		ExecutionTimeMs: m.ExecutionTimeMs,
+		MemoryKB:        m.MemoryKB,
+		IsBest:          m.IsBest,
+		CreatedAt:       m.CreatedAt,
+	}
+	if m.Tests != "" {
+		_ = json.Unmarshal([]byte(m.Tests), &s.Tests)
+	}
+	if m.Benchmarks != "" {
+		_ = json.Unmarshal([]byte(m.Benchmarks), &s.Benchmarks)
+	}
+	return s
+}
+
+type SubmissionRepository struct{ db *gorm.DB }
+
+func NewSubmissionRepository(db *gorm.DB) *SubmissionRepository {
+	return &SubmissionRepository{db: db}
+}
+
+func (r *SubmissionRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&SubmissionModel{})
+}
+
+// sameTarget ограничивает выборку попытками пользователя по тому же заданию (или уроку без задания).
+func sameTarget(q *gorm.DB, s dom.Submission) *gorm.DB {
+	q = q.Where("user_id = ?", s.UserID)
+	if s.AssignmentID != nil {
+		return q.Where("assignment_id = ?", *s.AssignmentID)
+	}
+	return q.Where("assignment_id IS NULL AND lesson_id = ?", s.LessonID)
+}
+
+func (r *SubmissionRepository) Create(ctx context.Context, s dom.Submission) (dom.Submission, error) {
+	m := submissionToModel(s)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		target := s.LessonID
+		if s.AssignmentID != nil {
+			target = *s.AssignmentID
+		}
+		// параллельные попытки одного пользователя не должны получить два флага is_best
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "submission:"+s.UserID.String()+":"+target.String()).Error; err != nil {
+			return err
+		}
+		var best SubmissionModel
+		res := sameTarget(tx.Model(&SubmissionModel{}), s).Where("is_best").Limit(1).Find(&best)
+		if res.Error != nil {
+			return res.Error
+		}
+		m.IsBest = res.RowsAffected == 0 || dom.Better(s, submissionToDomain(best))
+		if m.IsBest && res.RowsAffected > 0 {
+			if err := tx.Model(&SubmissionModel{}).Where("id = ?", best.ID).Update("is_best", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&m).Error
+	})
+	if err != nil {
+		return dom.Submission{}, err
+	}
+	return submissionToDomain(m), nil
+}
+
+func (r *SubmissionRepository) Get(ctx context.Context, id uuid.UUID) (dom.Submission, error) {
+	var m SubmissionModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Submission{}, nil
+		}
+		return dom.Submission{}, err
+	}
+	return submissionToDomain(m), nil
+}
+
+func (r *SubmissionRepository) List(ctx context.Context, f dom.Filter) ([]dom.Submission, int64, error) {
+	q := r.db.WithContext(ctx).Model(&SubmissionModel{})
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.CourseID != nil {
+		q = q.Where("course_id = ?", *f.CourseID)
+	}
+	if f.LessonID != nil {
+		q = q.Where("lesson_id = ?", *f.LessonID)
+	}
+	if f.AssignmentID != nil {
+		q = q.Where("assignment_id = ?", *f.AssignmentID)
+	}
+	if f.Verdict != "" {
+		q = q.Where("verdict = ?", f.Verdict)
+	}
+	if f.BestOnly {
+		q = q.Where("is_best")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []SubmissionModel
+	err := q.Omit("code", "tests").Order("created_at desc").
+		Offset((f.Page - 1) * f.PageSize).Limit(f.PageSize).Find(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]dom.Submission, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, submissionToDomain(m))
+	}
+	return out, total, nil
+}
+
+func (r *SubmissionRepository) Best(ctx context.Context, userID, assignmentID uuid.UUID) (dom.Submission, error) {
+	var m SubmissionModel
+	res := r.db.WithContext(ctx).Where("user_id = ? AND assignment_id = ? AND is_best", userID, assignmentID).Limit(1).Find(&m)
+	if res.Error != nil {
+		return dom.Submission{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return dom.Submission{}, nil
+	}
+	return submissionToDomain(m), nil
+}
+
+func (r *SubmissionRepository) BestByAssignment(ctx context.Context, assignmentID uuid.UUID) ([]dom.Submission, error) {
+	var rows []SubmissionModel
+	err := r.db.WithContext(ctx).Omit("tests").Where("assignment_id = ? AND is_best", assignmentID).Order("created_at").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dom.Submission, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, submissionToDomain(m))
+	}
+	return out, nil
+}
+
+func (r *SubmissionRepository) LatestByAssignment(ctx context.Context) (map[uuid.UUID]time.Time, error) {
+	var rows []struct {
+		AssignmentID uuid.UUID
+		Latest       time.Time
+	}
+	err := r.db.WithContext(ctx).Model(&SubmissionModel{}).Select("assignment_id, MAX(created_at) AS latest").
+		Where("assignment_id IS NOT NULL").Group("assignment_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]time.Time, len(rows))
+	for _, row := range rows {
+		out[row.AssignmentID] = row.Latest
+	}
+	return out, nil
+}
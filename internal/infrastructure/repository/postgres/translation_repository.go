@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/translation"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TranslationModel struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	EntityType   string    `gorm:"size:16;not null;uniqueIndex:idx_translations_entity_locale"`
+	EntityID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_translations_entity_locale"`
+	Locale       string    `gorm:"size:16;not null;uniqueIndex:idx_translations_entity_locale"`
+	Fields       string    `gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+	SourceHashes string    `gorm:"type:jsonb;not null;default:'{}'::jsonb"`
+	UpdatedBy    uuid.UUID `gorm:"type:uuid"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (TranslationModel) TableName() string { return "translations" }
+
+func translationToModel(t dom.Translation) TranslationModel {
+	fields, _ := json.Marshal(t.Fields)
+	hashes, _ := json.Marshal(t.SourceHashes)
+	return TranslationModel{
+		ID:           t.ID,
+		EntityType:   t.EntityType,
+		EntityID:     t.EntityID,
+		Locale:       t.Locale,
+		Fields:       string(fields),
+		SourceHashes: string(hashes),
+		UpdatedBy:    t.UpdatedBy,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+func translationToDomain(m TranslationModel) dom.Translation {
+	var fields dom.Fields
+	hashes := map[string]string{}
+	_ = json.Unmarshal([]byte(m.Fields), &fields)
+	_ = json.Unmarshal([]byte(m.SourceHashes), &hashes)
+	return dom.Translation{
+		ID:           m.ID,
+		EntityType:   m.EntityType,
+		EntityID:     m.EntityID,
+		Locale:       m.Locale,
+		Fields:       fields,
+		SourceHashes: hashes,
+		UpdatedBy:    m.UpdatedBy,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+type TranslationRepository struct{ db *gorm.DB }
+
+func NewTranslationRepository(db *gorm.DB) *TranslationRepository {
+	return &TranslationRepository{db: db}
+}
+
+func (r *TranslationRepository) AutoMigrate() error { return r.db.AutoMigrate(&TranslationModel{}) }
+
+func (r *TranslationRepository) Get(ctx context.Context, entityType string, entityID uuid.UUID, locale string) (dom.Translation, error) {
+	var m TranslationModel
+	err := r.db.WithContext(ctx).
+		First(&m, "entity_type = ? AND entity_id = ? AND locale = ?", entityType, entityID, locale).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Translation{}, nil
+		}
+		return dom.Translation{}, err
+	}
+	return translationToDomain(m), nil
+}
+
+func (r *TranslationRepository) ListByEntity(ctx context.Context, entityType string, entityID uuid.UUID) ([]dom.Translation, error) {
+	var rows []TranslationModel
+	if err := r.db.WithContext(ctx).Where("entity_type = ? AND entity_id = ?", entityType, entityID).Order("locale asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dom.Translation, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, translationToDomain(row))
+	}
+	return out, nil
+}
+
+func (r *TranslationRepository) ListByEntities(ctx context.Context, entityType string, entityIDs []uuid.UUID) ([]dom.Translation, error) {
+	if len(entityIDs) == 0 {
+		return []dom.Translation{}, nil
+	}
+	var rows []TranslationModel
+	if err := r.db.WithContext(ctx).Where("entity_type = ? AND entity_id IN ?", entityType, entityIDs).Order("locale asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dom.Translation, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, translationToDomain(row))
+	}
+	return out, nil
+}
+
+func (r *TranslationRepository) Upsert(ctx context.Context, t dom.Translation) (dom.Translation, error) {
+	m := translationToModel(t)
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	if m.UpdatedAt.IsZero() {
+		m.UpdatedAt = time.Now().UTC()
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"fields", "source_hashes", "updated_by", "updated_at"}),
+	}).Create(&m).Error
+	if err != nil {
+		return dom.Translation{}, err
+	}
+	return r.Get(ctx, t.EntityType, t.EntityID, t.Locale)
+}
+
+func (r *TranslationRepository) Delete(ctx context.Context, entityType string, entityID uuid.UUID, locale string) error {
+	return r.db.WithContext(ctx).
+		Delete(&TranslationModel{}, "entity_type = ? AND entity_id = ? AND locale = ?", entityType, entityID, locale).Error
+}
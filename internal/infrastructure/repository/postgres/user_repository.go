@@ -15,6 +15,7 @@ type UserModel struct {
 	PasswordHash string     `gorm:"size:255;not null"`
 	Name         string     `gorm:"size:100;not null"`
 	AvatarURL    string     `gorm:"type:text"`
+	Locale       string     `gorm:"size:16;not null;default:''"`
 	Role         string     `gorm:"size:32;not null"`
 	CreatedAt    time.Time  `gorm:"not null"`
 	UpdatedAt    time.Time  `gorm:"not null"`
@@ -30,6 +31,7 @@ func userToModel(u dom.User) UserModel {
 		PasswordHash: u.PasswordHash,
 		Name:         u.Name,
 		AvatarURL:    u.AvatarURL,
+		Locale:       u.Locale,
 		Role:         string(u.Role),
 		CreatedAt:    u.CreatedAt,
 		UpdatedAt:    u.UpdatedAt,
@@ -44,6 +46,7 @@ func userToDomain(m UserModel) dom.User {
 		PasswordHash: m.PasswordHash,
 		Name:         m.Name,
 		AvatarURL:    m.AvatarURL,
+		Locale:       m.Locale,
 		Role:         dom.Role(m.Role),
 		CreatedAt:    m.CreatedAt,
 		UpdatedAt:    m.UpdatedAt,
@@ -85,6 +88,7 @@ func (r *UserRepository) Update(ctx context.Context, id uuid.UUID, updated dom.U
 	}
 	m.Name = updated.Name
 	m.AvatarURL = updated.AvatarURL
+	m.Locale = updated.Locale
 	m.UpdatedAt = time.Now().UTC()
 	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
 		return dom.User{}, err
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VideoAssetModel struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LessonID    uuid.UUID `gorm:"type:uuid;index;not null"`
+	CourseID    uuid.UUID `gorm:"type:uuid;not null"`
+	ObjectKey   string    `gorm:"size:512;not null"`
+	UploadID    string    `gorm:"size:512;not null;default:''"`
+	Status      string    `gorm:"size:16;not null"`
+	ContentType string    `gorm:"size:128;not null;default:''"`
+	SizeBytes   int64     `gorm:"not null;default:0"`
+	DurationSec int       `gorm:"not null;default:0"`
+	Captions    string    `gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	CreatedBy   uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+func (VideoAssetModel) TableName() string { return "video_assets" }
+
+// videoCaptionModel субтитры в jsonb; ключ объекта в домене скрыт от JSON API, поэтому своя структура.
+type videoCaptionModel struct {
+	Locale    string `json:"locale"`
+	Label     string `json:"label"`
+	ObjectKey string `json:"object_key"`
+}
+
+func videoAssetToModel(a dom.Asset) VideoAssetModel {
+	captions := make([]videoCaptionModel, 0, len(a.Captions))
+	for _, c := range a.Captions {
+		captions = append(captions, videoCaptionModel(c))
+	}
+	return VideoAssetModel{
+		ID:          a.ID,
+		LessonID:    a.LessonID,
+		CourseID:    a.CourseID,
+		ObjectKey:   a.ObjectKey,
+		UploadID:    a.UploadID,
+		Status:      a.Status,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+		DurationSec: a.DurationSec,
+		Captions:    jsonString(captions),
+		CreatedBy:   a.CreatedBy,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+func videoAssetToDomain(m VideoAssetModel) dom.Asset {
+	a := dom.Asset{
+		ID:          m.ID,
+		LessonID:    m.LessonID,
+		CourseID:    m.CourseID,
+		ObjectKey:   m.ObjectKey,
+		UploadID:    m.UploadID,
+		Status:      m.Status,
+		ContentType: m.ContentType,
+		SizeBytes:   m.SizeBytes,
+		DurationSec: m.DurationSec,
+		CreatedBy:   m.CreatedBy,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+	var captions []videoCaptionModel
+	_ = json.Unmarshal([]byte(m.Captions), &captions)
+	a.Captions = make([]dom.Caption, 0, len(captions))
+	for _, c := range captions {
+		a.Captions = append(a.Captions, dom.Caption(c))
+	}
+	return a
+}
+
+type VideoRepository struct{ db *gorm.DB }
+
+func NewVideoRepository(db *gorm.DB) *VideoRepository { return &VideoRepository{db: db} }
+
+func (r *VideoRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&VideoAssetModel{})
+}
+
+func (r *VideoRepository) Create(ctx context.Context, a dom.Asset) (dom.Asset, error) {
+	m := videoAssetToModel(a)
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return dom.Asset{}, err
+	}
+	return videoAssetToDomain(m), nil
+}
+
+func (r *VideoRepository) Get(ctx context.Context, id uuid.UUID) (dom.Asset, error) {
+	var m VideoAssetModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return dom.Asset{}, nil
+		}
+		return dom.Asset{}, err
+	}
+	return videoAssetToDomain(m), nil
+}
+
+func (r *VideoRepository) Update(ctx context.Context, a dom.Asset) (dom.Asset, error) {
+	m := videoAssetToModel(a)
+	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
+		return dom.Asset{}, err
+	}
+	return videoAssetToDomain(m), nil
+}
+
+func (r *VideoRepository) ListByLesson(ctx context.Context, lessonID uuid.UUID) ([]dom.Asset, error) {
+	var rows []VideoAssetModel
+	if err := r.db.WithContext(ctx).Where("lesson_id = ?", lessonID).Order("created_at desc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dom.Asset, 0, len(rows))
+	for _, m := range rows {
+		out = append(out, videoAssetToDomain(m))
+	}
+	return out, nil
+}
@@ -0,0 +1,126 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	coursedom "github.com/example/learngo/internal/domain/course"
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	enrolluc "github.com/example/learngo/internal/usecase/enrollment"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLessonNotFound = errors.New("lesson not found")
+	ErrCourseNotFound = errors.New("course not found")
+)
+
+// Причины решения о доступе.
+const (
+	ReasonStaff              = "staff"        // администратор или преподаватель
+	ReasonFreeCourse         = "free_course"  // курс бесплатный целиком
+	ReasonFreePreview        = "free_preview" // урок открыт для ознакомления (Lesson.IsFree)
+	ReasonEnrolled           = "enrolled"
+	ReasonEnrollmentRequired = "enrollment_required"
+)
+
+// Decision результат проверки; при отказе Course нужен для paywall (цена, слаг).
+type Decision struct {
+	Allowed bool
+	Reason  string
+	Course  coursedom.Course
+}
+
+// Paywall сведения о курсе для ответа 402.
+type Paywall struct {
+	CourseID      uuid.UUID `json:"course_id"`
+	CourseSlug    string    `json:"course_slug"`
+	CourseTitle   string    `json:"course_title"`
+	Price         float64   `json:"price"`
+	PriceCents    int       `json:"price_cents"`
+	Currency      string    `json:"currency"`
+	LoginRequired bool      `json:"login_required"`
+}
+
+// NewPaywall собирает сведения о цене; userID == uuid.Nil — пользователь не вошёл.
+func NewPaywall(c coursedom.Course, userID uuid.UUID) Paywall {
+	p := Paywall{CourseID: c.ID, CourseSlug: c.Slug, CourseTitle: c.Title, PriceCents: c.PriceCents, Currency: coursedom.Currency, LoginRequired: userID == uuid.Nil}
+	switch {
+	case c.Price != nil:
+		p.Price = *c.Price
+		if p.PriceCents == 0 {
+			p.PriceCents = int(*c.Price*100 + 0.5)
+		}
+	case c.PriceCents > 0:
+		p.Price = float64(c.PriceCents) / 100
+	}
+	return p
+}
+
+// Service политика доступа к платному контенту курса.
+type Service interface {
+	// CheckCourse доступ к контенту курса целиком (без учёта бесплатных уроков).
+	CheckCourse(ctx context.Context, courseID, userID uuid.UUID, role string) (Decision, error)
+	// CheckLesson доступ к уроку: бесплатный урок открыт всем.
+	CheckLesson(ctx context.Context, lessonID, userID uuid.UUID, role string) (Decision, error)
+}
+
+type service struct {
+	courses     coursedom.Repository
+	lessons     lessondom.Repository
+	enrollments enrolluc.Service
+}
+
+func NewService(courses coursedom.Repository, lessons lessondom.Repository, enrollments enrolluc.Service) Service {
+	return &service{courses: courses, lessons: lessons, enrollments: enrollments}
+}
+
+func isStaff(role string) bool { return role == "admin" || role == "teacher" }
+
+func (s *service) CheckCourse(ctx context.Context, courseID, userID uuid.UUID, role string) (Decision, error) {
+	c, err := s.courses.Get(ctx, courseID)
+	if err != nil {
+		return Decision{}, err
+	}
+	if c.ID == uuid.Nil {
+		return Decision{}, ErrCourseNotFound
+	}
+	d := Decision{Course: c}
+	switch {
+	case isStaff(role):
+		d.Allowed, d.Reason = true, ReasonStaff
+	case c.IsFree:
+		d.Allowed, d.Reason = true, ReasonFreeCourse
+	case userID == uuid.Nil:
+		d.Reason = ReasonEnrollmentRequired
+	default:
+		ok, err := s.enrollments.IsEnrolled(ctx, userID, c.ID)
+		if err != nil {
+			return Decision{}, err
+		}
+		d.Allowed = ok
+		d.Reason = ReasonEnrollmentRequired
+		if ok {
+			d.Reason = ReasonEnrolled
+		}
+	}
+	return d, nil
+}
+
+func (s *service) CheckLesson(ctx context.Context, lessonID, userID uuid.UUID, role string) (Decision, error) {
+	l, err := s.lessons.Get(ctx, lessonID)
+	if err != nil {
+		return Decision{}, err
+	}
+	if l.ID == uuid.Nil {
+		return Decision{}, ErrLessonNotFound
+	}
+	d, err := s.CheckCourse(ctx, l.CourseID, userID, role)
+	if err != nil {
+		return Decision{}, err
+	}
+	if !d.Allowed && l.IsFree {
+		d.Allowed, d.Reason = true, ReasonFreePreview
+	}
+	return d, nil
+}
@@ -0,0 +1,63 @@
+package access
+
+import (
+	"context"
+	"testing"
+
+	coursedom "github.com/example/learngo/internal/domain/course"
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	mem "github.com/example/learngo/internal/infrastructure/repository/memory"
+	enrolluc "github.com/example/learngo/internal/usecase/enrollment"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func TestCheckLesson(t *testing.T) {
+	ctx := context.Background()
+	courses := mem.NewInMemoryCourseRepository()
+	lessons := mem.NewInMemoryLessonRepository()
+	enrollments := enrolluc.NewService(mem.NewInMemoryEnrollmentRepository(), utils.NewLogger("test"), nil)
+
+	price := 1990.0
+	paid, _ := courses.Create(ctx, coursedom.Course{ID: uuid.New(), Slug: "go-pro", Title: "Go Pro", Price: &price})
+	free, _ := courses.Create(ctx, coursedom.Course{ID: uuid.New(), Slug: "go-intro", Title: "Go Intro", IsFree: true})
+	locked, _ := lessons.Create(ctx, lessondom.Lesson{ID: uuid.New(), CourseID: paid.ID})
+	preview, _ := lessons.Create(ctx, lessondom.Lesson{ID: uuid.New(), CourseID: paid.ID, IsFree: true})
+	open, _ := lessons.Create(ctx, lessondom.Lesson{ID: uuid.New(), CourseID: free.ID})
+
+	student, enrolled := uuid.New(), uuid.New()
+	if err := enrollments.Enroll(ctx, enrolled, paid.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewService(courses, lessons, enrollments)
+	cases := []struct {
+		name    string
+		lesson  uuid.UUID
+		user    uuid.UUID
+		role    string
+		allowed bool
+		reason  string
+	}{
+		{"anonymous on paid", locked.ID, uuid.Nil, "", false, ReasonEnrollmentRequired},
+		{"not enrolled", locked.ID, student, "user", false, ReasonEnrollmentRequired},
+		{"enrolled", locked.ID, enrolled, "user", true, ReasonEnrolled},
+		{"free preview", preview.ID, uuid.Nil, "", true, ReasonFreePreview},
+		{"free course", open.ID, uuid.Nil, "", true, ReasonFreeCourse},
+		{"teacher", locked.ID, student, "teacher", true, ReasonStaff},
+	}
+	for _, tc := range cases {
+		d, err := svc.CheckLesson(ctx, tc.lesson, tc.user, tc.role)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if d.Allowed != tc.allowed || d.Reason != tc.reason {
+			t.Errorf("%s: got allowed=%v reason=%s", tc.name, d.Allowed, d.Reason)
+		}
+	}
+
+	pw := NewPaywall(paid, uuid.Nil)
+	if pw.PriceCents != 199000 || pw.Price != price || !pw.LoginRequired || pw.CourseSlug != "go-pro" || pw.Currency != coursedom.Currency {
+		t.Errorf("unexpected paywall: %+v", pw)
+	}
+}
@@ -2,17 +2,21 @@ package assignment
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	dom "github.com/example/learngo/internal/domain/assignment"
+	codedom "github.com/example/learngo/internal/domain/code"
+	"github.com/example/learngo/pkg/gotest"
 	"github.com/example/learngo/pkg/utils"
 	"github.com/google/uuid"
 )
 
 type Service interface {
 	ListByLesson(ctx context.Context, lessonID uuid.UUID) ([]dom.Assignment, error)
-	Create(ctx context.Context, lessonID uuid.UUID, title, prompt, starterCode, tests string, order int) (dom.Assignment, error)
+	Create(ctx context.Context, lessonID uuid.UUID, title, prompt, starterCode string, starterFiles map[string]string, tests, hiddenTests string, performance *dom.Performance, order int) (dom.Assignment, error)
 	Get(ctx context.Context, id uuid.UUID) (dom.Assignment, error)
-	Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode, tests string, order int) (dom.Assignment, error)
+	Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode string, starterFiles map[string]string, tests, hiddenTests string, performance *dom.Performance, order int) (dom.Assignment, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -29,8 +33,17 @@ func (s *service) ListByLesson(ctx context.Context, lessonID uuid.UUID) ([]dom.A
 	return s.repo.ListByLesson(ctx, lessonID)
 }
 
-func (s *service) Create(ctx context.Context, lessonID uuid.UUID, title, prompt, starterCode, tests string, order int) (dom.Assignment, error) {
-	a := dom.Assignment{ID: uuid.New(), LessonID: lessonID, Title: title, Prompt: prompt, StarterCode: starterCode, Tests: tests, Order: order}
+func (s *service) Create(ctx context.Context, lessonID uuid.UUID, title, prompt, starterCode string, starterFiles map[string]string, tests, hiddenTests string, performance *dom.Performance, order int) (dom.Assignment, error) {
+	if err := validateStarterFiles(starterFiles); err != nil {
+		return dom.Assignment{}, err
+	}
+	if err := validatePerformance(performance); err != nil {
+		return dom.Assignment{}, err
+	}
+	if err := validateTests(tests, hiddenTests); err != nil {
+		return dom.Assignment{}, err
+	}
+	a := dom.Assignment{ID: uuid.New(), LessonID: lessonID, Title: title, Prompt: prompt, StarterCode: starterCode, StarterFiles: starterFiles, Tests: tests, HiddenTests: hiddenTests, Performance: performance, Order: order}
 	return s.repo.Create(ctx, a)
 }
 
@@ -38,10 +51,61 @@ func (s *service) Get(ctx context.Context, id uuid.UUID) (dom.Assignment, error)
 	return s.repo.Get(ctx, id)
 }
 
-func (s *service) Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode, tests string, order int) (dom.Assignment, error) {
-	return s.repo.Update(ctx, id, title, prompt, starterCode, tests, order)
+func (s *service) Update(ctx context.Context, id uuid.UUID, title, prompt, starterCode string, starterFiles map[string]string, tests, hiddenTests string, performance *dom.Performance, order int) (dom.Assignment, error) {
+	if err := validateStarterFiles(starterFiles); err != nil {
+		return dom.Assignment{}, err
+	}
+	if err := validatePerformance(performance); err != nil {
+		return dom.Assignment{}, err
+	}
+	if err := validateTests(tests, hiddenTests); err != nil {
+		return dom.Assignment{}, err
+	}
+	return s.repo.Update(ctx, id, title, prompt, starterCode, starterFiles, tests, hiddenTests, performance, order)
+}
+
+// validateStarterFiles стартовый проект необязателен; если задан — те же правила, что и для решений.
+// Ошибка оборачивает codedom.ErrInvalidFiles.
+func validateStarterFiles(files map[string]string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	return codedom.ValidateFiles(files)
+}
+
+// validateTests TestMain занят раннером проверки (см. gotest.ErrTestMain).
+// Ошибка оборачивает dom.ErrInvalidTests.
+func validateTests(tests ...string) error {
+	for _, src := range tests {
+		if gotest.DeclaresTestMain(src) {
+			return fmt.Errorf("%w: tests must not declare TestMain", dom.ErrInvalidTests)
+		}
+	}
+	return nil
 }
 
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+// validatePerformance задание на скорость требует эталона и хотя бы одного бенчмарка.
+// Ошибка оборачивает dom.ErrInvalidPerformance.
+func validatePerformance(p *dom.Performance) error {
+	if p == nil {
+		return nil
+	}
+	if strings.TrimSpace(p.Reference) == "" {
+		return fmt.Errorf("%w: reference solution is required", dom.ErrInvalidPerformance)
+	}
+	if p.MaxNsRatio < 0 || p.MaxAllocsRatio < 0 {
+		return fmt.Errorf("%w: thresholds must not be negative", dom.ErrInvalidPerformance)
+	}
+	names, err := gotest.BenchmarkNames(p.Benchmarks)
+	if err != nil {
+		return fmt.Errorf("%w: benchmarks: %v", dom.ErrInvalidPerformance, err)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("%w: benchmarks declare no Benchmark functions", dom.ErrInvalidPerformance)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package codeexec
+
+import (
+	"context"
+
+	codedom "github.com/example/learngo/internal/domain/code"
+	coursedom "github.com/example/learngo/internal/domain/course"
+	"github.com/example/learngo/pkg/lint"
+	"github.com/google/uuid"
+)
+
+// StaticAnalyzer статический анализ решения на Go с учётом настроек курса.
+type StaticAnalyzer interface {
+	Analyze(ctx context.Context, code, courseID string) ([]codedom.Diagnostic, error)
+}
+
+// courseAnalyzer выполняет проверки, выбранные в курсе (Course.Analyzers); без курса — все.
+type courseAnalyzer struct {
+	linter  *lint.Linter
+	courses coursedom.Repository
+}
+
+// NewStaticAnalyzer courses == nil — настройки курсов не учитываются.
+func NewStaticAnalyzer(linter *lint.Linter, courses coursedom.Repository) StaticAnalyzer {
+	return &courseAnalyzer{linter: linter, courses: courses}
+}
+
+func (a *courseAnalyzer) Analyze(ctx context.Context, code, courseID string) ([]codedom.Diagnostic, error) {
+	var names []string
+	if id, err := uuid.Parse(courseID); err == nil && a.courses != nil {
+		c, err := a.courses.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		names = c.Analyzers
+		if names != nil && len(names) == 0 {
+			return nil, nil
+		}
+	}
+	diags, err := a.linter.Run(ctx, code, names)
+	out := make([]codedom.Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		out = append(out, codedom.Diagnostic(d))
+	}
+	return out, err
+}
+
+// startAnalysis запускает анализ параллельно с выполнением; канал отдаёт замечания
+// (nil — анализа нет). Сбой анализа не мешает выполнению и только логируется.
+func (s *service) startAnalysis(ctx context.Context, req codedom.ExecuteRequest) <-chan []codedom.Diagnostic {
+	out := make(chan []codedom.Diagnostic, 1)
+	// анализатор разбирает один файл
+	if s.analyzer == nil || req.Language != "go" || len(req.Files) > 0 {
+		out <- nil
+		return out
+	}
+	go func() {
+		diags, err := s.analyzer.Analyze(ctx, req.Code, req.CourseID)
+		if err != nil {
+			s.logger.Warn("static analysis failed", "course_id", req.CourseID, "error", err)
+		}
+		if len(diags) == 0 {
+			diags = nil
+		}
+		out <- diags
+	}()
+	return out
+}
@@ -0,0 +1,142 @@
+package codeexec
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	codedom "github.com/example/learngo/internal/domain/code"
+	"github.com/example/learngo/pkg/sandbox"
+)
+
+// maxCachedCheckers сколько собранных программ-чекеров держать на диске.
+const maxCachedCheckers = 64
+
+// sandboxChecker собирает программу-чекер в песочнице (один раз на исходник)
+// и запускает её на каждый тест, передавая codedom.CheckerInput на stdin.
+type sandboxChecker struct {
+	sb  *sandbox.Sandbox
+	dir string
+
+	mu   sync.Mutex // сборки сериализуются: чекеров немного, и они кэшируются
+	bins map[string]*checkerBin
+}
+
+// checkerBin собранный чекер. Вытесненный из кэша удаляется с диска, когда его
+// перестанут запускать.
+type checkerBin struct {
+	path    string
+	users   int
+	evicted bool
+}
+
+// NewSandboxChecker dir — каталог для собранных чекеров; пустой — временный.
+func NewSandboxChecker(sb *sandbox.Sandbox, dir string) (CustomChecker, error) {
+	if dir == "" {
+		var err error
+		if dir, err = os.MkdirTemp("", "checkers-*"); err != nil {
+			return nil, err
+		}
+	}
+	return &sandboxChecker{sb: sb, dir: dir, bins: make(map[string]*checkerBin)}, nil
+}
+
+func (c *sandboxChecker) Check(ctx context.Context, program string, in codedom.CheckerInput) (codedom.CheckResult, error) {
+	bin, err := c.acquire(ctx, program)
+	if err != nil {
+		return codedom.CheckResult{}, err
+	}
+	defer c.release(bin)
+	stdin, err := json.Marshal(in)
+	if err != nil {
+		return codedom.CheckResult{}, err
+	}
+	res, err := c.sb.Run(ctx, bin.path, strings.NewReader(string(stdin)))
+	if err != nil {
+		return codedom.CheckResult{}, err
+	}
+	msg, _, _ := strings.Cut(strings.TrimSpace(res.Stdout), "\n")
+	switch {
+	case res.TimedOut:
+		return codedom.CheckResult{}, fmt.Errorf("checker timed out")
+	case res.ExitCode == 0:
+		return codedom.CheckResult{Passed: true, Message: msg}, nil
+	case res.ExitCode == 1:
+		return codedom.CheckResult{Message: msg}, nil
+	}
+	return codedom.CheckResult{}, fmt.Errorf("checker exited with code %d: %s", res.ExitCode, strings.TrimSpace(res.Stderr))
+}
+
+// acquire возвращает собранный чекер, собирая его при первом обращении; после запуска — release.
+func (c *sandboxChecker) acquire(ctx context.Context, program string) (*checkerBin, error) {
+	sum := sha256.Sum256([]byte(program))
+	key := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if bin, ok := c.bins[key]; ok {
+		bin.users++
+		return bin, nil
+	}
+	if len(c.bins) >= maxCachedCheckers {
+		c.evict()
+	}
+
+	// у каждой сборки свой каталог: вытесненный чекер с тем же исходником ещё может работать
+	work, err := os.MkdirTemp(c.dir, key[:16]+"-*")
+	if err != nil {
+		return nil, err
+	}
+	src := filepath.Join(work, "src")
+	if err := os.MkdirAll(src, 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(src, "main.go"), []byte(program), 0o600); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(work, "checker")
+	res, err := c.sb.Build(ctx, src, path)
+	if err != nil {
+		os.RemoveAll(work)
+		return nil, err
+	}
+	if !res.OK() {
+		os.RemoveAll(work)
+		return nil, fmt.Errorf("checker does not compile: %s", strings.TrimSpace(res.Stderr))
+	}
+	bin := &checkerBin{path: path, users: 1}
+	c.bins[key] = bin
+	return bin, nil
+}
+
+// evict вытесняет из кэша один чекер, по возможности — незапущенный. Вызывается под mu.
+func (c *sandboxChecker) evict() {
+	victim := ""
+	for k, bin := range c.bins {
+		victim = k
+		if bin.users == 0 {
+			break
+		}
+	}
+	bin := c.bins[victim]
+	delete(c.bins, victim)
+	bin.evicted = true
+	if bin.users == 0 {
+		os.RemoveAll(filepath.Dir(bin.path))
+	}
+}
+
+func (c *sandboxChecker) release(bin *checkerBin) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bin.users--
+	if bin.evicted && bin.users == 0 {
+		os.RemoveAll(filepath.Dir(bin.path))
+	}
+}
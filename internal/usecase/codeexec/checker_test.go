@@ -0,0 +1,85 @@
+package codeexec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	codedom "github.com/example/learngo/internal/domain/code"
+	"github.com/example/learngo/pkg/sandbox"
+)
+
+func TestMain(m *testing.M) {
+	sandbox.Init()
+	os.Exit(m.Run())
+}
+
+const sumChecker = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var in struct{ Input, Expected, Actual string }
+	if err := json.NewDecoder(os.Stdin).Decode(&in); err != nil {
+		os.Exit(2)
+	}
+	if strings.TrimSpace(in.Actual) == strings.TrimSpace(in.Expected) {
+		fmt.Println("ok")
+		return
+	}
+	fmt.Println("expected the sum of", strings.TrimSpace(in.Input))
+	os.Exit(1)
+}
+`
+
+func TestSandboxChecker(t *testing.T) {
+	sb, err := sandbox.New(sandbox.DefaultConfig())
+	if err != nil {
+		t.Skip(err)
+	}
+	checker, err := NewSandboxChecker(sb, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	res, err := checker.Check(ctx, sumChecker, codedom.CheckerInput{Input: "2 3", Expected: "5", Actual: "5\n"})
+	if err != nil || !res.Passed || res.Message != "ok" {
+		t.Fatalf("expected accepted, got %+v, %v", res, err)
+	}
+	res, err = checker.Check(ctx, sumChecker, codedom.CheckerInput{Input: "2 3", Expected: "5", Actual: "6"})
+	if err != nil || res.Passed || res.Message != "expected the sum of 2 3" {
+		t.Fatalf("expected rejected with a message, got %+v, %v", res, err)
+	}
+	if _, err := checker.Check(ctx, "package main\n\nfunc main() { broken }\n", codedom.CheckerInput{}); err == nil {
+		t.Fatal("a checker that does not compile must be an error")
+	}
+}
+
+func TestSandboxCheckerKeepsEvictedBinaryWhileRunning(t *testing.T) {
+	work := filepath.Join(t.TempDir(), "a")
+	if err := os.MkdirAll(work, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(work, "checker")
+	if err := os.WriteFile(path, nil, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	bin := &checkerBin{path: path, users: 1}
+	c := &sandboxChecker{bins: map[string]*checkerBin{"a": bin}}
+
+	c.evict()
+	if _, err := os.Stat(path); err != nil || len(c.bins) != 0 {
+		t.Fatalf("running checker removed on eviction: %v, %d cached", err, len(c.bins))
+	}
+	c.release(bin)
+	if _, err := os.Stat(work); !os.IsNotExist(err) {
+		t.Fatalf("evicted checker is kept after the last run: %v", err)
+	}
+}
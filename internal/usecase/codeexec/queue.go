@@ -0,0 +1,99 @@
+package codeexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	codejobuc "github.com/example/learngo/internal/usecase/codejob"
+	"github.com/example/learngo/pkg/codeexec"
+	pkgcodejob "github.com/example/learngo/pkg/codejob"
+	"github.com/google/uuid"
+)
+
+// probeProgram программа проверки здоровья раннера: доходит ли задача до воркера и обратно.
+const probeProgram = "package main\n\nfunc main() {}\n"
+
+type queueExecutor struct {
+	jobs    codejobuc.Service
+	timeout time.Duration
+}
+
+// NewQueueExecutor Executor поверх очереди раннера (cmd/runner). Задачи ставятся от имени
+// системного пользователя (uuid.Nil) и не видны студентам. timeout — сколько ждать результата.
+func NewQueueExecutor(jobs codejobuc.Service, timeout time.Duration) codeexec.Executor {
+	return &queueExecutor{jobs: jobs, timeout: timeout}
+}
+
+func (e *queueExecutor) Name() string { return "queue" }
+
+// Supports воркер пока умеет только Go.
+func (e *queueExecutor) Supports(language string) bool { return language == "go" }
+
+// SupportsFiles воркер собирает и проекты из нескольких файлов (sandbox.RunFiles).
+func (e *queueExecutor) SupportsFiles(language string) bool { return e.Supports(language) }
+
+func (e *queueExecutor) Execute(ctx context.Context, req codeexec.SubmissionRequest) (*codeexec.SubmissionResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	job, err := e.jobs.Submit(ctx, uuid.Nil, codejobuc.Request{
+		Code:     req.SourceCode,
+		Files:    req.Files,
+		Language: codeexec.LanguageName(req.LanguageID),
+		Stdin:    req.Stdin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	updates, err := e.jobs.Watch(ctx, job.ID, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	for j := range updates {
+		job = j
+	}
+	if !pkgcodejob.Terminal(job.Status) {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("runner did not finish job %s in time", job.ID)
+		}
+		return nil, fmt.Errorf("runner job %s: watch ended in status %s", job.ID, job.Status)
+	}
+	if job.Status == pkgcodejob.StatusFailed {
+		return nil, fmt.Errorf("runner job %s failed: %s", job.ID, job.Error)
+	}
+	return queueResult(job), nil
+}
+
+// queueResult переводит результат воркера в формат Judge0. Воркер не отделяет ошибку
+// сборки от падения программы, поэтому ненулевой код выхода — NZEC.
+func queueResult(job pkgcodejob.Job) *codeexec.SubmissionResult {
+	res := &codeexec.SubmissionResult{
+		Stdout:   job.Stdout,
+		Stderr:   job.Stderr,
+		ExitCode: job.ExitCode,
+		Time:     fmt.Sprintf("%.3f", float64(job.ElapsedMs)/1000),
+	}
+	switch {
+	case strings.Contains(job.Stderr, "time limit exceeded"):
+		res.Status.ID = codeexec.StatusTimeLimit
+	case job.ExitCode != 0:
+		res.Status.ID = codeexec.StatusRuntimeNZEC
+	default:
+		res.Status.ID = codeexec.StatusAccepted
+	}
+	res.Status.Description = codeexec.StatusDescription(res.Status.ID)
+	return res
+}
+
+func (e *queueExecutor) Health(ctx context.Context) error {
+	res, err := e.Execute(ctx, codeexec.SubmissionRequest{SourceCode: probeProgram, LanguageID: codeexec.LanguageID["go"]})
+	if err != nil {
+		return err
+	}
+	if res.Status.ID != codeexec.StatusAccepted {
+		return errors.New("probe program failed: " + strings.TrimSpace(res.Stderr))
+	}
+	return nil
+}
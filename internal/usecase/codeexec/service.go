@@ -2,177 +2,413 @@ package codeexec
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	codedom "github.com/example/learngo/internal/domain/code"
 	"github.com/example/learngo/pkg/codeexec"
+	"github.com/example/learngo/pkg/observability"
 	"github.com/example/learngo/pkg/utils"
 )
 
+// CustomChecker выполняет программу-чекер преподавателя (режим codedom.CheckCustom).
+type CustomChecker interface {
+	Check(ctx context.Context, program string, in codedom.CheckerInput) (codedom.CheckResult, error)
+}
+
+// StreamEvent событие ExecuteStream: результат очередного теста или, последним, итог либо ошибка.
+type StreamEvent struct {
+	Test     *codedom.TestResult
+	Response *codedom.ExecuteResponse
+	Err      error
+}
+
 // Service интерфейс сервиса выполнения кода
 type Service interface {
 	Execute(ctx context.Context, req codedom.ExecuteRequest) (*codedom.ExecuteResponse, error)
+	// ExecuteStream как Execute, но результаты тестов отдаются по мере готовности (не по порядку,
+	// см. TestResult.Index). Ошибки проверки запроса возвращаются сразу; канал закрывается после итога.
+	ExecuteStream(ctx context.Context, req codedom.ExecuteRequest) (<-chan StreamEvent, error)
+	// Backends состояние бэкендов выполнения.
+	Backends() []codeexec.BackendStatus
 }
 
 type service struct {
-	judge0Client *codeexec.Client
-	logger       *utils.Logger
-	timeout      time.Duration
-	memoryLimit  int // KB
+	router      *codeexec.Router
+	checker     CustomChecker
+	cache       codeexec.ResultCache
+	analyzer    StaticAnalyzer
+	logger      *utils.Logger
+	memoryLimit int // KB
 }
 
-func NewService(judge0Client *codeexec.Client, logger *utils.Logger, timeout time.Duration, memoryLimitKB int) Service {
+// NewService выполняет код бэкендом, который выберет router; router == nil — выполнение недоступно.
+// checker == nil — тесты с программой-чекером не проходят с ошибкой чекера.
+// cache == nil — результаты не кешируются; analyzer == nil — без статического анализа.
+func NewService(router *codeexec.Router, checker CustomChecker, cache codeexec.ResultCache, analyzer StaticAnalyzer, logger *utils.Logger, memoryLimitKB int) Service {
 	return &service{
-		judge0Client: judge0Client,
-		logger:       logger,
-		timeout:      timeout,
-		memoryLimit:  memoryLimitKB,
+		router:      router,
+		checker:     checker,
+		cache:       cache,
+		analyzer:    analyzer,
+		logger:      logger,
+		memoryLimit: memoryLimitKB,
 	}
 }
 
+func (s *service) Backends() []codeexec.BackendStatus {
+	if s.router == nil {
+		return nil
+	}
+	return s.router.Status()
+}
+
 func (s *service) Execute(ctx context.Context, req codedom.ExecuteRequest) (*codedom.ExecuteResponse, error) {
 	startTime := time.Now()
-
-	// Если есть тесты, выполняем каждый тест отдельно
+	req, err := s.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	diags := s.startAnalysis(ctx, req)
+	var resp *codedom.ExecuteResponse
 	if len(req.TestCases) > 0 {
-		return s.executeWithTests(ctx, req, startTime)
+		resp, err = s.executeWithTests(ctx, req, startTime, nil)
+	} else {
+		resp, err = s.executeSimple(ctx, req, startTime)
+	}
+	if err != nil {
+		return nil, err
 	}
+	resp.Diagnostics = <-diags
+	return resp, nil
+}
 
-	// Обычное выполнение без тестов
-	return s.executeSimple(ctx, req, startTime)
+func (s *service) ExecuteStream(ctx context.Context, req codedom.ExecuteRequest) (<-chan StreamEvent, error) {
+	startTime := time.Now()
+	req, err := s.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	// по событию на тест и итог: отправка никогда не блокирует бэкенд
+	out := make(chan StreamEvent, len(req.TestCases)+1)
+	diags := s.startAnalysis(ctx, req)
+	go func() {
+		defer close(out)
+		var resp *codedom.ExecuteResponse
+		var err error
+		if len(req.TestCases) > 0 {
+			resp, err = s.executeWithTests(ctx, req, startTime, func(tr codedom.TestResult) {
+				out <- StreamEvent{Test: &tr}
+			})
+		} else {
+			resp, err = s.executeSimple(ctx, req, startTime)
+		}
+		if err == nil {
+			resp.Diagnostics = <-diags
+		}
+		out <- StreamEvent{Response: resp, Err: err}
+	}()
+	return out, nil
 }
 
-func (s *service) executeSimple(ctx context.Context, req codedom.ExecuteRequest, startTime time.Time) (*codedom.ExecuteResponse, error) {
+// prepare проверяет запрос: дерево файлов (один файл сворачивается в Code) и чекеры тестов.
+// Чекер задаётся только в контенте урока: в тестах клиента он отклоняется.
+// Проект из нескольких файлов выполняет бэкенд, который собирает деревья (codeexec.FileTrees).
+func (s *service) prepare(req codedom.ExecuteRequest) (codedom.ExecuteRequest, error) {
+	if len(req.Files) > 0 {
+		if err := codedom.ValidateFiles(req.Files); err != nil {
+			return req, err
+		}
+		if len(req.Files) == 1 {
+			// один файл — то же, что Code
+			for _, src := range req.Files {
+				req.Code = src
+			}
+			req.Files = nil
+		} else {
+			req.Code = ""
+		}
+	}
+	for i, tc := range req.TestCases {
+		if tc.Checker != nil && !req.LessonTests {
+			return req, fmt.Errorf("test_cases[%d]: %w: checkers are set in lesson content", i, codedom.ErrInvalidChecker)
+		}
+		if err := codedom.ValidateChecker(tc.Checker, tc.ExpectedOutput); err != nil {
+			return req, fmt.Errorf("test_cases[%d]: %w", i, err)
+		}
+	}
+	if s.router == nil {
+		return req, codeexec.ErrNoBackend
+	}
+	return req, nil
+}
+
+func (s *service) submission(req codedom.ExecuteRequest) (codeexec.SubmissionRequest, error) {
 	languageID, ok := codeexec.LanguageID[req.Language]
 	if !ok {
-		return nil, fmt.Errorf("unsupported language: %s", req.Language)
+		return codeexec.SubmissionRequest{}, fmt.Errorf("%w: %s", codeexec.ErrUnsupportedLanguage, req.Language)
 	}
-
-	submissionReq := codeexec.SubmissionRequest{
+	return codeexec.SubmissionRequest{
 		SourceCode:   req.Code,
+		Files:        req.Files,
 		LanguageID:   languageID,
 		Stdin:        req.Stdin,
 		CPUTimeLimit: 5, // 5 seconds
 		MemoryLimit:  s.memoryLimit,
-	}
+	}, nil
+}
 
-	var result *codeexec.SubmissionResult
-	var err error
+func route(req codedom.ExecuteRequest) codeexec.Route {
+	return codeexec.Route{Language: req.Language, Course: req.CourseID, Files: len(req.Files) > 0}
+}
 
-	if s.judge0Client != nil && s.judge0Client.IsAvailable() {
-		// Используем Judge0
-		result, err = s.judge0Client.SubmitAndWait(ctx, submissionReq, s.timeout)
-		if err != nil {
-			return nil, fmt.Errorf("judge0 execution: %w", err)
+func (s *service) executeSimple(ctx context.Context, req codedom.ExecuteRequest, startTime time.Time) (*codedom.ExecuteResponse, error) {
+	submissionReq, err := s.submission(req)
+	if err != nil {
+		return nil, err
+	}
+	key := s.cacheKey(route(req), submissionReq, req.NoCache)
+	result, cached := s.cached(ctx, key)
+	if !cached {
+		if result, err = s.router.Execute(ctx, route(req), submissionReq); err != nil {
+			return nil, fmt.Errorf("execute: %w", err)
 		}
+		s.store(ctx, route(req), submissionReq, key, result)
+	}
+	resp := s.toResponse(result)
+	resp.Cached = cached
+	resp.ExecutionTimeMs = time.Since(startTime).Milliseconds()
+	return resp, nil
+}
+
+// cacheKey ключ результата в кеше; пустой — запрос идёт мимо кеша (кеша нет, no_cache
+// или нет доступного бэкенда).
+func (s *service) cacheKey(rt codeexec.Route, sub codeexec.SubmissionRequest, noCache bool) string {
+	if s.cache == nil {
+		return ""
+	}
+	if noCache {
+		observability.CodeExecCacheLookup("bypass")
+		return ""
+	}
+	toolchain := s.router.Toolchain(rt)
+	if toolchain == "" {
+		return ""
+	}
+	return codeexec.CacheKey(sub, toolchain)
+}
+
+func (s *service) cached(ctx context.Context, key string) (*codeexec.SubmissionResult, bool) {
+	if key == "" {
+		return nil, false
+	}
+	res, ok := s.cache.Get(ctx, key)
+	if ok {
+		observability.CodeExecCacheLookup("hit")
 	} else {
-		// Fallback: локальное выполнение (только для Go в dev режиме)
-		// В продакшене должен использоваться только Judge0
-		if req.Language != "go" {
-			return nil, fmt.Errorf("judge0 not available and language %s not supported locally. Please configure Judge0", req.Language)
-		}
-		result, err = s.executeLocalGo(ctx, req.Code, req.Stdin)
-		if err != nil {
-			return nil, fmt.Errorf("local execution: %w", err)
-		}
+		observability.CodeExecCacheLookup("miss")
 	}
+	return res, ok
+}
 
-	elapsed := time.Since(startTime).Milliseconds()
+// store сохраняет результат под ключом, полученным до выполнения. Если за это время бэкенд
+// маршрута сменился (сбой, переключение), программа могла выполниться другим тулчейном —
+// такой результат не сохраняется.
+func (s *service) store(ctx context.Context, rt codeexec.Route, sub codeexec.SubmissionRequest, key string, res *codeexec.SubmissionResult) {
+	if key == "" || !codeexec.Cacheable(res) || codeexec.CacheKey(sub, s.router.Toolchain(rt)) != key {
+		return
+	}
+	s.cache.Set(ctx, key, res)
+}
 
-	// Определяем статус
-	passed := result.Status.ID == 3 && result.ExitCode == 0
+// toResponse переводит результат бэкенда в ответ API (без времени выполнения запроса).
+func (s *service) toResponse(result *codeexec.SubmissionResult) *codedom.ExecuteResponse {
+	passed := result.Status.ID == codeexec.StatusAccepted && result.ExitCode == 0
 	errorMsg := ""
 	if result.Stderr != "" {
 		errorMsg = result.Stderr
 	} else if result.CompileOutput != "" {
 		errorMsg = result.CompileOutput
-	} else if result.Status.ID != 3 {
+	} else if result.Status.ID != codeexec.StatusAccepted {
 		errorMsg = result.Status.Description
+		if result.Message != "" {
+			errorMsg += ": " + result.Message
+		}
 	}
-
-	return &codedom.ExecuteResponse{
-		Output:          result.Stdout,
-		Error:           errorMsg,
-		Passed:          passed,
-		ExecutionTimeMs: elapsed,
-		ExitCode:        result.ExitCode,
-	}, nil
+	resp := &codedom.ExecuteResponse{
+		Output:   result.Stdout,
+		Error:    errorMsg,
+		Passed:   passed,
+		ExitCode: result.ExitCode,
+		Verdict:  s.verdict(result),
+		Status:   result.Status.Description,
+		MemoryKB: result.Memory,
+	}
+	if resp.Status == "" {
+		resp.Status = codeexec.StatusDescription(result.Status.ID)
+	}
+	if result.ExitSignal != nil {
+		resp.ExitSignal = *result.ExitSignal
+	}
+	if sec, err := strconv.ParseFloat(result.Time, 64); err == nil {
+		resp.TimeMs = int64(sec * 1000)
+	}
+	return resp
 }
 
-func (s *service) executeWithTests(ctx context.Context, req codedom.ExecuteRequest, startTime time.Time) (*codedom.ExecuteResponse, error) {
-	testResults := make([]codedom.TestResult, 0, len(req.TestCases))
-	allPassed := true
-
-	for _, testCase := range req.TestCases {
-		testStart := time.Now()
+// outOfMemoryMarkers как рантаймы сообщают о неудавшемся выделении памяти.
+var outOfMemoryMarkers = []string{
+	"out of memory",  // Go, V8 («JavaScript heap out of memory»)
+	"MemoryError",    // Python
+	"std::bad_alloc", // C++
+	"java.lang.OutOfMemoryError",
+}
 
-		// Выполняем код с входными данными теста
-		testReq := codedom.ExecuteRequest{
-			Code:     req.Code,
-			Language: req.Language,
-			Stdin:    testCase.Input,
+// verdict переводит статус Judge0 в вердикт. Ни Judge0, ни локальный исполнитель не выделяют
+// превышение памяти в отдельный статус: программа падает на выделении, поэтому оно распознаётся
+// по пиковой памяти и сообщению рантайма.
+func (s *service) verdict(result *codeexec.SubmissionResult) codedom.Verdict {
+	switch id := result.Status.ID; {
+	case id == codeexec.StatusAccepted && result.ExitCode == 0:
+		return codedom.VerdictAccepted
+	case id == codeexec.StatusAccepted, id >= codeexec.StatusRuntimeSIGSEGV && id <= codeexec.StatusRuntimeOther:
+		if s.memoryLimit > 0 && result.Memory >= s.memoryLimit {
+			return codedom.VerdictMemoryLimit
 		}
-
-		result, err := s.executeSimple(ctx, testReq, testStart)
-		if err != nil {
-			testResults = append(testResults, codedom.TestResult{
-				TestCase:        testCase,
-				ActualOutput:    "",
-				Passed:          false,
-				ErrorMessage:    err.Error(),
-				ExecutionTimeMs: time.Since(testStart).Milliseconds(),
-			})
-			allPassed = false
-			continue
+		for _, marker := range outOfMemoryMarkers {
+			if strings.Contains(result.Stderr, marker) {
+				return codedom.VerdictMemoryLimit
+			}
 		}
+		return codedom.VerdictRuntimeError
+	case id == codeexec.StatusWrongAnswer:
+		return codedom.VerdictWrongAnswer
+	case id == codeexec.StatusTimeLimit:
+		return codedom.VerdictTimeLimit
+	case id == codeexec.StatusCompilationError:
+		return codedom.VerdictCompilationError
+	}
+	return codedom.VerdictInternalError
+}
 
-		// Сравниваем вывод (нормализуем пробелы)
-		actualNormalized := strings.TrimSpace(result.Output)
-		expectedNormalized := strings.TrimSpace(testCase.ExpectedOutput)
-		passed := actualNormalized == expectedNormalized
-
-		if !passed {
-			allPassed = false
+// executeWithTests выполняет программу на входах всех тестов сразу (пачкой или параллельно,
+// см. codeexec.Router.ExecuteMany); emit, если задан, получает результат каждого теста по готовности.
+func (s *service) executeWithTests(ctx context.Context, req codedom.ExecuteRequest, startTime time.Time, emit func(codedom.TestResult)) (*codedom.ExecuteResponse, error) {
+	submissionReq, err := s.submission(req)
+	if err != nil {
+		return nil, err
+	}
+	testResults := make([]codedom.TestResult, len(req.TestCases))
+	done := make([]bool, len(req.TestCases))
+	finished := 0
+	finish := func(i int, result *codeexec.SubmissionResult, cached bool) {
+		testResults[i], done[i] = s.testResult(ctx, i, req.TestCases[i], result), true
+		testResults[i].Cached = cached
+		finished++
+		if emit != nil {
+			emit(testResults[i])
 		}
+	}
 
-		testResults = append(testResults, codedom.TestResult{
-			TestCase:        testCase,
-			ActualOutput:    result.Output,
-			Passed:          passed,
-			ErrorMessage:    result.Error,
-			ExecutionTimeMs: result.ExecutionTimeMs,
+	// закешированные тесты отдаются сразу, остальные выполняются одним вызовом
+	var stdins, keys []string
+	var pending []int
+	for i, tc := range req.TestCases {
+		sub := submissionReq
+		sub.Stdin = tc.Input
+		key := s.cacheKey(route(req), sub, req.NoCache)
+		if result, ok := s.cached(ctx, key); ok {
+			finish(i, result, true)
+			continue
+		}
+		stdins, keys, pending = append(stdins, tc.Input), append(keys, key), append(pending, i)
+	}
+	var runErr error
+	if len(pending) > 0 {
+		runErr = s.router.ExecuteMany(ctx, route(req), submissionReq, stdins, func(j int, result *codeexec.SubmissionResult) {
+			sub := submissionReq
+			sub.Stdin = stdins[j]
+			s.store(ctx, route(req), sub, keys[j], result)
+			finish(pending[j], result, false)
 		})
 	}
+	if runErr != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if finished == 0 {
+			return nil, fmt.Errorf("execute: %w", runErr)
+		}
+		// часть тестов выполнена — остальные считаем непройденными
+		for i, ok := range done {
+			if ok {
+				continue
+			}
+			testResults[i] = codedom.TestResult{TestCase: req.TestCases[i], Index: i, ErrorMessage: runErr.Error()}
+			if emit != nil {
+				emit(testResults[i])
+			}
+		}
+	}
 
-	elapsed := time.Since(startTime).Milliseconds()
-
+	allPassed := true
+	verdict := codedom.VerdictAccepted
+	for _, tr := range testResults {
+		if !tr.Passed && allPassed {
+			verdict = tr.Verdict
+		}
+		allPassed = allPassed && tr.Passed
+	}
 	return &codedom.ExecuteResponse{
 		Output:          "",
 		Error:           "",
 		Passed:          allPassed,
 		TestResults:     testResults,
-		ExecutionTimeMs: elapsed,
+		ExecutionTimeMs: time.Since(startTime).Milliseconds(),
+		Verdict:         verdict,
 	}, nil
 }
 
-// executeLocalGo выполняет Go код локально (только для dev)
-func (s *service) executeLocalGo(ctx context.Context, code, stdin string) (*codeexec.SubmissionResult, error) {
-	// Это упрощенная версия для dev режима
-	// В продакшене должен использоваться только Judge0
-	result := &codeexec.SubmissionResult{
-		Status: struct {
-			ID          int    `json:"id"`
-			Description string `json:"description"`
-		}{
-			ID:          3,
-			Description: "Accepted",
-		},
-		Stdout:   "Local execution not fully implemented",
-		Stderr:   "",
-		ExitCode: 0,
-	}
-
-	return result, nil
+func (s *service) testResult(ctx context.Context, i int, testCase codedom.TestCase, result *codeexec.SubmissionResult) codedom.TestResult {
+	resp := s.toResponse(result)
+	tr := codedom.TestResult{
+		TestCase:        testCase,
+		Index:           i,
+		ActualOutput:    resp.Output,
+		ErrorMessage:    resp.Error,
+		ExecutionTimeMs: resp.TimeMs,
+		Verdict:         resp.Verdict,
+		Status:          resp.Status,
+		MemoryKB:        resp.MemoryKB,
+	}
+	if resp.Verdict != codedom.VerdictAccepted {
+		// упавшую программу чекер не проверяет
+		return tr
+	}
+	check, err := s.check(ctx, testCase, resp.Output)
+	if err != nil {
+		s.logger.Warn("output checker failed", "mode", codedom.CheckerMode(testCase.Checker), "error", err)
+		tr.ErrorMessage = "checker error: " + err.Error()
+		tr.Verdict = codedom.VerdictInternalError
+		return tr
+	}
+	tr.Passed, tr.CheckerMessage = check.Passed, check.Message
+	if !check.Passed {
+		tr.Verdict = codedom.VerdictWrongAnswer
+	}
+	return tr
+}
+
+// check сравнивает вывод чекером теста; программу-чекер запускает s.checker.
+func (s *service) check(ctx context.Context, tc codedom.TestCase, actual string) (codedom.CheckResult, error) {
+	if codedom.CheckerMode(tc.Checker) != codedom.CheckCustom {
+		return codedom.CompareOutput(tc.Checker, tc.ExpectedOutput, actual)
+	}
+	if s.checker == nil {
+		return codedom.CheckResult{}, errors.New("custom checkers are not available")
+	}
+	return s.checker.Check(ctx, tc.Checker.Program, codedom.CheckerInput{Input: tc.Input, Expected: tc.ExpectedOutput, Actual: actual})
 }
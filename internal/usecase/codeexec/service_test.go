@@ -0,0 +1,157 @@
+package codeexec
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	codedom "github.com/example/learngo/internal/domain/code"
+	coursedom "github.com/example/learngo/internal/domain/course"
+	"github.com/example/learngo/pkg/codeexec"
+	"github.com/example/learngo/pkg/lint"
+	"github.com/google/uuid"
+)
+
+func TestVerdict(t *testing.T) {
+	s := &service{memoryLimit: 1024}
+	cases := []struct {
+		status   int
+		exitCode int
+		memory   int
+		stderr   string
+		want     codedom.Verdict
+	}{
+		{codeexec.StatusAccepted, 0, 100, "", codedom.VerdictAccepted},
+		{codeexec.StatusAccepted, 1, 100, "", codedom.VerdictRuntimeError},
+		{codeexec.StatusTimeLimit, 0, 100, "", codedom.VerdictTimeLimit},
+		{codeexec.StatusCompilationError, 0, 0, "", codedom.VerdictCompilationError},
+		{codeexec.StatusRuntimeSIGSEGV, 0, 100, "", codedom.VerdictRuntimeError},
+		{codeexec.StatusRuntimeOther, 0, 1024, "", codedom.VerdictMemoryLimit},
+		{codeexec.StatusRuntimeNZEC, 1, 200, "Traceback...\nMemoryError", codedom.VerdictMemoryLimit},
+		{codeexec.StatusRuntimeSIGABRT, 0, 200, "terminate called after throwing an instance of 'std::bad_alloc'", codedom.VerdictMemoryLimit},
+		{codeexec.StatusWrongAnswer, 0, 100, "", codedom.VerdictWrongAnswer},
+		{codeexec.StatusInternalError, 0, 0, "", codedom.VerdictInternalError},
+	}
+	for _, tc := range cases {
+		var res codeexec.SubmissionResult
+		res.Status.ID, res.ExitCode, res.Memory, res.Stderr = tc.status, tc.exitCode, tc.memory, tc.stderr
+		if got := s.verdict(&res); got != tc.want {
+			t.Errorf("status %d, exit %d, memory %d: verdict %s, want %s", tc.status, tc.exitCode, tc.memory, got, tc.want)
+		}
+	}
+}
+
+// countingExecutor выполняет «программу» мгновенно: вывод — это ввод.
+type countingExecutor struct{ runs int }
+
+func (e *countingExecutor) Name() string                 { return "fake" }
+func (e *countingExecutor) Supports(string) bool         { return true }
+func (e *countingExecutor) Health(context.Context) error { return nil }
+
+func (e *countingExecutor) Execute(_ context.Context, req codeexec.SubmissionRequest) (*codeexec.SubmissionResult, error) {
+	e.runs++
+	res := &codeexec.SubmissionResult{Stdout: req.Stdin}
+	res.Status.ID = codeexec.StatusAccepted
+	return res, nil
+}
+
+func TestExecuteUsesResultCache(t *testing.T) {
+	exec := &countingExecutor{}
+	router, err := codeexec.NewRouter(codeexec.RouterConfig{MaxParallel: 1}, nil, exec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(router, nil, codeexec.NewMemoryCache(0, 0), nil, nil, 0)
+	ctx := context.Background()
+	req := codedom.ExecuteRequest{Code: "print(input())", Language: "python", TestCases: []codedom.TestCase{
+		{Input: "1", ExpectedOutput: "1"},
+		{Input: "2", ExpectedOutput: "2"},
+	}}
+
+	first, err := s.Execute(ctx, req)
+	if err != nil || !first.Passed || exec.runs != 2 {
+		t.Fatalf("first run: %+v, %v, %d executions", first, err, exec.runs)
+	}
+	// новый тест выполняется, старые берутся из кеша
+	req.TestCases = append(req.TestCases, codedom.TestCase{Input: "3", ExpectedOutput: "3"})
+	second, err := s.Execute(ctx, req)
+	if err != nil || !second.Passed || exec.runs != 3 {
+		t.Fatalf("second run: %+v, %v, %d executions", second, err, exec.runs)
+	}
+	for i, tr := range second.TestResults {
+		if tr.Cached != (i < 2) {
+			t.Errorf("test %d cached = %v", i, tr.Cached)
+		}
+	}
+
+	req.NoCache = true
+	if _, err := s.Execute(ctx, req); err != nil || exec.runs != 6 {
+		t.Fatalf("no_cache: %v, %d executions", err, exec.runs)
+	}
+}
+
+// courseStore курс с заданным набором проверок.
+type courseStore struct {
+	coursedom.Repository
+	course coursedom.Course
+}
+
+func (c courseStore) Get(context.Context, uuid.UUID) (coursedom.Course, error) { return c.course, nil }
+
+func TestStaticAnalysisFollowsCourseSettings(t *testing.T) {
+	router, err := codeexec.NewRouter(codeexec.RouterConfig{MaxParallel: 1}, nil, &countingExecutor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	courses := &courseStore{}
+	s := NewService(router, nil, nil, NewStaticAnalyzer(lint.New(0), courses), nil, 0)
+	req := codedom.ExecuteRequest{
+		Code:     "package main\n\nimport \"strings\"\n\nfunc main() {\n  strings.ToUpper(\"go\")\n}\n",
+		Language: "go",
+		CourseID: uuid.NewString(),
+	}
+	analyzers := func() []string {
+		resp, err := s.Execute(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, d := range resp.Diagnostics {
+			names = append(names, d.Analyzer)
+		}
+		return names
+	}
+
+	courses.course.Analyzers = []string{lint.UnusedResult}
+	if got := analyzers(); !slices.Equal(got, []string{lint.UnusedResult}) {
+		t.Errorf("course analyzers: %v", got)
+	}
+	courses.course.Analyzers = []string{}
+	if got := analyzers(); len(got) != 0 {
+		t.Errorf("analysis disabled, got %v", got)
+	}
+	// без настроек — все проверки, в том числе gofmt
+	courses.course.Analyzers = nil
+	if got := analyzers(); !slices.Contains(got, lint.Gofmt) || !slices.Contains(got, lint.UnusedResult) {
+		t.Errorf("default analyzers: %v", got)
+	}
+}
+
+func TestClientCheckersAreRejected(t *testing.T) {
+	router, err := codeexec.NewRouter(codeexec.RouterConfig{MaxParallel: 1}, nil, &countingExecutor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(router, nil, nil, nil, nil, 0)
+	req := codedom.ExecuteRequest{Code: "print(input())", Language: "python", TestCases: []codedom.TestCase{
+		{Input: "1", ExpectedOutput: "1", Checker: &codedom.Checker{Mode: codedom.CheckExact}},
+	}}
+	if _, err := s.Execute(context.Background(), req); !errors.Is(err, codedom.ErrInvalidChecker) {
+		t.Fatalf("client checker: %v", err)
+	}
+	req.LessonTests = true
+	if resp, err := s.Execute(context.Background(), req); err != nil || !resp.Passed {
+		t.Fatalf("lesson checker: %+v, %v", resp, err)
+	}
+}
@@ -0,0 +1,167 @@
+package codejob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	codedom "github.com/example/learngo/internal/domain/code"
+	"github.com/example/learngo/pkg/codejob"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound    = errors.New("job not found")
+	ErrInvalidCode = errors.New("invalid job")
+)
+
+const maxCodeKB = 64
+
+// Queue очередь задач раннера (queue.Publisher).
+type Queue interface {
+	Publish(ctx context.Context, body []byte) error
+}
+
+// Store состояние задач (codejob.Store поверх Redis).
+type Store interface {
+	Create(ctx context.Context, j codejob.Job) error
+	Finish(ctx context.Context, id, stdout, stderr string, exitCode int, elapsedMs int64, errMsg string) error
+	Get(ctx context.Context, id string) (codejob.Job, bool, error)
+	Subscribe(ctx context.Context, id string) (<-chan string, func(), error)
+}
+
+// Request задача на выполнение: один файл Code или проект Files (путь → содержимое).
+type Request struct {
+	Code     string
+	Files    map[string]string
+	Language string
+	Stdin    string
+}
+
+// Service асинхронное выполнение кода через воркер cmd/runner.
+// Задачи видит только их автор; после ResultTTLMin результат исчезает.
+type Service interface {
+	Submit(ctx context.Context, userID uuid.UUID, req Request) (codejob.Job, error)
+	Get(ctx context.Context, id string, userID uuid.UUID) (codejob.Job, error)
+	// Watch отдаёт текущее состояние и каждое следующее до финального статуса;
+	// канал закрывается после финального статуса или отмены ctx.
+	Watch(ctx context.Context, id string, userID uuid.UUID) (<-chan codejob.Job, error)
+}
+
+type service struct {
+	queue  Queue
+	store  Store
+	logger *utils.Logger
+	mu     sync.Mutex // канал AMQP не рассчитан на параллельную публикацию
+}
+
+func NewService(queue Queue, store Store, logger *utils.Logger) Service {
+	return &service{queue: queue, store: store, logger: logger}
+}
+
+func (s *service) Submit(ctx context.Context, userID uuid.UUID, req Request) (codejob.Job, error) {
+	if len(req.Files) > 0 {
+		if err := codedom.ValidateFiles(req.Files); err != nil {
+			return codejob.Job{}, fmt.Errorf("%w: %v", ErrInvalidCode, err)
+		}
+		req.Code = ""
+	} else {
+		if strings.TrimSpace(req.Code) == "" {
+			return codejob.Job{}, fmt.Errorf("%w: code or files are required", ErrInvalidCode)
+		}
+		if len(req.Code) > maxCodeKB*1024 {
+			return codejob.Job{}, fmt.Errorf("%w: code must be at most %d KB", ErrInvalidCode, maxCodeKB)
+		}
+	}
+	// воркер пока умеет только Go
+	if req.Language != "go" {
+		return codejob.Job{}, fmt.Errorf("%w: language %q is not supported by the runner", ErrInvalidCode, req.Language)
+	}
+	j := codejob.Job{ID: uuid.NewString(), UserID: userID.String(), Language: req.Language}
+	if err := s.store.Create(ctx, j); err != nil {
+		return codejob.Job{}, fmt.Errorf("create job: %w", err)
+	}
+	body, err := codejob.Payload{ID: j.ID, Language: req.Language, Code: req.Code, Files: req.Files, Stdin: req.Stdin}.Encode()
+	if err != nil {
+		return codejob.Job{}, err
+	}
+	s.mu.Lock()
+	err = s.queue.Publish(ctx, body)
+	s.mu.Unlock()
+	if err != nil {
+		// задача уже видна клиенту как queued — помечаем, чтобы она не висела до TTL
+		if ferr := s.store.Finish(ctx, j.ID, "", "", 0, 0, "failed to enqueue job"); ferr != nil {
+			s.logger.Error("failed to mark job failed", "job_id", j.ID, "error", ferr)
+		}
+		return codejob.Job{}, fmt.Errorf("publish job: %w", err)
+	}
+	created, _, err := s.store.Get(ctx, j.ID)
+	if err != nil || created.ID == "" {
+		j.Status = codejob.StatusQueued
+		return j, nil
+	}
+	return created, nil
+}
+
+func (s *service) Get(ctx context.Context, id string, userID uuid.UUID) (codejob.Job, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return codejob.Job{}, ErrNotFound
+	}
+	j, ok, err := s.store.Get(ctx, id)
+	if err != nil {
+		return codejob.Job{}, err
+	}
+	if !ok || j.UserID != userID.String() {
+		return codejob.Job{}, ErrNotFound
+	}
+	return j, nil
+}
+
+func (s *service) Watch(ctx context.Context, id string, userID uuid.UUID) (<-chan codejob.Job, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound
+	}
+	events, closeSub, err := s.store.Subscribe(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	first, err := s.Get(ctx, id, userID)
+	if err != nil {
+		closeSub()
+		return nil, err
+	}
+	out := make(chan codejob.Job, 1)
+	go func() {
+		defer close(out)
+		defer closeSub()
+		last := first
+		out <- first
+		for !codejob.Terminal(last.Status) {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+				j, err := s.Get(ctx, id, userID)
+				if err != nil {
+					return
+				}
+				if j.Status == last.Status {
+					continue
+				}
+				last = j
+				select {
+				case out <- j:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
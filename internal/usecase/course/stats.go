@@ -0,0 +1,110 @@
+package course
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/course"
+	enrolldom "github.com/example/learngo/internal/domain/enrollment"
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// StatsService пересчитывает вычисляемые счётчики курсов.
+type StatsService interface {
+	Recalculate(ctx context.Context, courseID uuid.UUID) (dom.Stats, error)
+	RecalculateAll(ctx context.Context) error
+}
+
+type statsService struct {
+	courses     dom.Repository
+	lessons     lessondom.Repository
+	enrollments enrolldom.Repository
+	logger      *utils.Logger
+	window      time.Duration
+	halfLife    time.Duration
+	now         func() time.Time
+}
+
+// NewStatsService конструктор. window — окно недавних записей для тренда,
+// halfLife — период полураспада веса записи.
+func NewStatsService(courses dom.Repository, lessons lessondom.Repository, enrollments enrolldom.Repository, logger *utils.Logger, window, halfLife time.Duration) StatsService {
+	if window <= 0 {
+		window = 14 * 24 * time.Hour
+	}
+	if halfLife <= 0 {
+		halfLife = 72 * time.Hour
+	}
+	return &statsService{courses: courses, lessons: lessons, enrollments: enrollments, logger: logger, window: window, halfLife: halfLife, now: time.Now}
+}
+
+func (s *statsService) Recalculate(ctx context.Context, courseID uuid.UUID) (dom.Stats, error) {
+	lessons, err := s.lessons.ListByCourse(ctx, courseID)
+	if err != nil {
+		return dom.Stats{}, fmt.Errorf("list lessons: %w", err)
+	}
+	students, err := s.enrollments.CountByCourse(ctx, courseID)
+	if err != nil {
+		return dom.Stats{}, fmt.Errorf("count enrollments: %w", err)
+	}
+	now := s.now()
+	recent, err := s.enrollments.ListByCourseSince(ctx, courseID, now.Add(-s.window))
+	if err != nil {
+		return dom.Stats{}, fmt.Errorf("recent enrollments: %w", err)
+	}
+
+	st := dom.Stats{LessonsCount: len(lessons), StudentsCount: int(students)}
+	for _, l := range lessons {
+		st.DurationMin += l.DurationMinutes
+	}
+	st.TrendingScore = trendingScore(recent, now, s.halfLife)
+	st.Popularity = popularity(st.StudentsCount, st.TrendingScore)
+
+	if err := s.courses.UpdateStats(ctx, courseID, st); err != nil {
+		return dom.Stats{}, fmt.Errorf("update stats: %w", err)
+	}
+	return st, nil
+}
+
+func (s *statsService) RecalculateAll(ctx context.Context) error {
+	courses, err := s.courses.List(ctx)
+	if err != nil {
+		return err
+	}
+	var failed int
+	for _, c := range courses {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := s.Recalculate(ctx, c.ID); err != nil {
+			failed++
+			s.logger.Error("course stats recalc failed", "course_id", c.ID, "error", err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("stats recalc failed for %d of %d courses", failed, len(courses))
+	}
+	return nil
+}
+
+// trendingScore сумма весов недавних записей с экспоненциальным затуханием:
+// запись, сделанная halfLife назад, весит 0.5.
+func trendingScore(recent []enrolldom.Enrollment, now time.Time, halfLife time.Duration) float64 {
+	var score float64
+	for _, e := range recent {
+		age := now.Sub(e.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		score += math.Exp(-math.Ln2 * age.Hours() / halfLife.Hours())
+	}
+	return math.Round(score*1000) / 1000
+}
+
+// popularity итоговая популярность: общее число студентов плюс вклад тренда.
+func popularity(students int, trending float64) int {
+	return students + int(math.Round(10*trending))
+}
@@ -0,0 +1,55 @@
+package course
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	enrolldom "github.com/example/learngo/internal/domain/enrollment"
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	mem "github.com/example/learngo/internal/infrastructure/repository/memory"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func TestRecalculateStats(t *testing.T) {
+	ctx := context.Background()
+	courses := mem.NewInMemoryCourseRepository()
+	lessons := mem.NewInMemoryLessonRepository()
+	enrollments := mem.NewInMemoryEnrollmentRepository()
+	logger := utils.NewLogger("test")
+
+	c, err := NewService(courses, logger).CreateCourse(ctx, "Stats", "Desc")
+	if err != nil {
+		t.Fatalf("CreateCourse error: %v", err)
+	}
+	for _, d := range []int{30, 45} {
+		_, _ = lessons.Create(ctx, lessondom.Lesson{ID: uuid.New(), CourseID: c.ID, DurationMinutes: d})
+	}
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	// одна свежая запись, одна ровно полураспад назад, одна вне окна
+	for _, at := range []time.Time{now, now.Add(-72 * time.Hour), now.Add(-30 * 24 * time.Hour)} {
+		_ = enrollments.Upsert(ctx, enrolldom.Enrollment{UserID: uuid.New(), CourseID: c.ID, Status: "enrolled", CreatedAt: at})
+	}
+
+	svc := NewStatsService(courses, lessons, enrollments, logger, 14*24*time.Hour, 72*time.Hour).(*statsService)
+	svc.now = func() time.Time { return now }
+	st, err := svc.Recalculate(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("Recalculate error: %v", err)
+	}
+	if st.LessonsCount != 2 || st.StudentsCount != 3 || st.DurationMin != 75 {
+		t.Fatalf("unexpected counters: %+v", st)
+	}
+	if st.TrendingScore != 1.5 {
+		t.Fatalf("unexpected trending score: %v", st.TrendingScore)
+	}
+	if st.Popularity != 3+15 {
+		t.Fatalf("unexpected popularity: %d", st.Popularity)
+	}
+
+	got, _ := courses.Get(ctx, c.ID)
+	if got.LessonsCount != 2 || got.DurationHours != 2 || got.TrendingScore != 1.5 {
+		t.Fatalf("stats not persisted: %+v", got)
+	}
+}
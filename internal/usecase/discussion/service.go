@@ -0,0 +1,527 @@
+package discussion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/discussion"
+	accessuc "github.com/example/learngo/internal/usecase/access"
+	enrolluc "github.com/example/learngo/internal/usecase/enrollment"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound    = errors.New("discussion not found")
+	ErrForbidden   = errors.New("not allowed")
+	ErrNotEnrolled = errors.New("only enrolled students can post")
+	ErrNoAccess    = errors.New("lesson is not available")
+	ErrLocked      = errors.New("thread is locked")
+)
+
+// Действия модерации.
+const (
+	ActionHide    = "hide"
+	ActionRestore = "restore"
+	ActionLock    = "lock"   // только для тредов
+	ActionUnlock  = "unlock" // только для тредов
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Actor пользователь, от имени которого выполняется действие.
+type Actor struct {
+	UserID uuid.UUID
+	Role   string
+}
+
+// Staff преподаватели и администраторы модерируют обсуждения и принимают ответы.
+func (a Actor) Staff() bool { return a.Role == "admin" || a.Role == "teacher" }
+
+// Input текст вопроса или ответа.
+type Input struct {
+	Title string
+	Body  string
+	Code  *dom.CodeAttachment
+}
+
+// ThreadPage страница тредов урока.
+type ThreadPage struct {
+	Threads  []dom.Thread `json:"threads"`
+	Total    int64        `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+// ThreadView тред с деревом ответов; пагинация — по ответам верхнего уровня.
+type ThreadView struct {
+	Thread     dom.Thread      `json:"thread"`
+	Posts      []*dom.PostNode `json:"posts"`
+	TotalRoots int             `json:"total_roots"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+}
+
+type Service interface {
+	ListThreads(ctx context.Context, lessonID uuid.UUID, actor Actor, sort string, page, pageSize int) (ThreadPage, error)
+	CreateThread(ctx context.Context, lessonID uuid.UUID, actor Actor, in Input) (dom.Thread, error)
+	GetThread(ctx context.Context, id uuid.UUID, actor Actor, page, pageSize int) (ThreadView, error)
+	UpdateThread(ctx context.Context, id uuid.UUID, actor Actor, in Input) (dom.Thread, error)
+	DeleteThread(ctx context.Context, id uuid.UUID, actor Actor) error
+
+	// Reply добавляет ответ; parentID == nil — ответ на вопрос.
+	Reply(ctx context.Context, threadID uuid.UUID, parentID *uuid.UUID, actor Actor, in Input) (dom.Post, error)
+	UpdatePost(ctx context.Context, id uuid.UUID, actor Actor, in Input) (dom.Post, error)
+	DeletePost(ctx context.Context, id uuid.UUID, actor Actor) error
+
+	// Vote ставит или снимает голос; возвращает новое число голосов.
+	Vote(ctx context.Context, targetType string, id uuid.UUID, actor Actor, up bool) (int, error)
+	// Accept отмечает принятый ответ (postID == nil снимает отметку); только персонал.
+	Accept(ctx context.Context, threadID uuid.UUID, postID *uuid.UUID, actor Actor) (dom.Thread, error)
+	Moderate(ctx context.Context, targetType string, id uuid.UUID, actor Actor, action string) error
+	// LessonOf урок, к которому относится тред или ответ; по нему проверяется доступ.
+	LessonOf(ctx context.Context, targetType string, id uuid.UUID, actor Actor) (uuid.UUID, error)
+}
+
+type service struct {
+	repo        dom.Repository
+	access      accessuc.Service
+	enrollments enrolluc.Service
+	logger      *utils.Logger
+	now         func() time.Time
+}
+
+func NewService(repo dom.Repository, access accessuc.Service, enrollments enrolluc.Service, logger *utils.Logger) Service {
+	return &service{repo: repo, access: access, enrollments: enrollments, logger: logger, now: time.Now}
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+// canRead проверяет доступ к уроку треда (запись на курс, бесплатный урок, персонал).
+func (s *service) canRead(ctx context.Context, lessonID uuid.UUID, actor Actor) error {
+	d, err := s.access.CheckLesson(ctx, lessonID, actor.UserID, actor.Role)
+	if err != nil {
+		if errors.Is(err, accessuc.ErrLessonNotFound) || errors.Is(err, accessuc.ErrCourseNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if !d.Allowed {
+		return ErrNoAccess
+	}
+	return nil
+}
+
+// canPost писать могут только записанные на курс (бесплатный урок-превью не считается) и персонал.
+func (s *service) canPost(ctx context.Context, courseID uuid.UUID, actor Actor) error {
+	if actor.Staff() {
+		return nil
+	}
+	if actor.UserID == uuid.Nil {
+		return ErrNotEnrolled
+	}
+	ok, err := s.enrollments.IsEnrolled(ctx, actor.UserID, courseID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotEnrolled
+	}
+	return nil
+}
+
+func canEdit(authorID uuid.UUID, actor Actor) bool {
+	return actor.Staff() || (actor.UserID != uuid.Nil && actor.UserID == authorID)
+}
+
+// visibleThread загружает тред, скрывая удалённые и (для не-персонала) скрытые модератором.
+func (s *service) visibleThread(ctx context.Context, id uuid.UUID, actor Actor) (dom.Thread, error) {
+	t, err := s.repo.GetThread(ctx, id)
+	if err != nil {
+		return t, err
+	}
+	if t.ID == uuid.Nil || t.Status == dom.StatusDeleted || (t.Status == dom.StatusHidden && !actor.Staff()) {
+		return dom.Thread{}, ErrNotFound
+	}
+	return t, nil
+}
+
+func (s *service) ListThreads(ctx context.Context, lessonID uuid.UUID, actor Actor, sort string, page, pageSize int) (ThreadPage, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	threads, total, err := s.repo.ListThreads(ctx, dom.ThreadFilter{LessonID: lessonID, Sort: sort, IncludeHidden: actor.Staff(), Page: page, PageSize: pageSize})
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	ids := make([]uuid.UUID, 0, len(threads))
+	for _, t := range threads {
+		ids = append(ids, t.ID)
+	}
+	voted, err := s.repo.VotedBy(ctx, actor.UserID, ids)
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	for i := range threads {
+		threads[i].Voted = voted[threads[i].ID]
+	}
+	return ThreadPage{Threads: threads, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+func (s *service) CreateThread(ctx context.Context, lessonID uuid.UUID, actor Actor, in Input) (dom.Thread, error) {
+	d, err := s.access.CheckLesson(ctx, lessonID, actor.UserID, actor.Role)
+	if err != nil {
+		if errors.Is(err, accessuc.ErrLessonNotFound) || errors.Is(err, accessuc.ErrCourseNotFound) {
+			return dom.Thread{}, ErrNotFound
+		}
+		return dom.Thread{}, err
+	}
+	if err := s.canPost(ctx, d.Course.ID, actor); err != nil {
+		return dom.Thread{}, err
+	}
+	if err := dom.ValidateBody(in.Title, true, in.Body, in.Code); err != nil {
+		return dom.Thread{}, err
+	}
+	now := s.now().UTC()
+	return s.repo.CreateThread(ctx, dom.Thread{
+		ID:             uuid.New(),
+		LessonID:       lessonID,
+		CourseID:       d.Course.ID,
+		AuthorID:       actor.UserID,
+		Title:          in.Title,
+		Body:           in.Body,
+		Code:           in.Code,
+		Status:         dom.StatusVisible,
+		LastActivityAt: now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+}
+
+func (s *service) GetThread(ctx context.Context, id uuid.UUID, actor Actor, page, pageSize int) (ThreadView, error) {
+	t, err := s.visibleThread(ctx, id, actor)
+	if err != nil {
+		return ThreadView{}, err
+	}
+	if err := s.canRead(ctx, t.LessonID, actor); err != nil {
+		return ThreadView{}, err
+	}
+	posts, err := s.repo.ListPosts(ctx, t.ID)
+	if err != nil {
+		return ThreadView{}, err
+	}
+	ids := []uuid.UUID{t.ID}
+	for i := range posts {
+		ids = append(ids, posts[i].ID)
+		// скрытые модератором ответы остаются в дереве заглушкой, если у них есть ответы
+		if posts[i].Status == dom.StatusHidden && !actor.Staff() {
+			posts[i].Status = dom.StatusDeleted
+			posts[i].Body, posts[i].Code = "", nil
+		}
+	}
+	voted, err := s.repo.VotedBy(ctx, actor.UserID, ids)
+	if err != nil {
+		return ThreadView{}, err
+	}
+	t.Voted = voted[t.ID]
+	for i := range posts {
+		posts[i].Voted = voted[posts[i].ID]
+	}
+	roots := dom.Prune(dom.BuildTree(posts))
+
+	page, pageSize = normalizePage(page, pageSize)
+	view := ThreadView{Thread: t, TotalRoots: len(roots), Page: page, PageSize: pageSize, Posts: []*dom.PostNode{}}
+	if from := (page - 1) * pageSize; from < len(roots) {
+		to := from + pageSize
+		if to > len(roots) {
+			to = len(roots)
+		}
+		view.Posts = roots[from:to]
+	}
+	return view, nil
+}
+
+func (s *service) UpdateThread(ctx context.Context, id uuid.UUID, actor Actor, in Input) (dom.Thread, error) {
+	t, err := s.visibleThread(ctx, id, actor)
+	if err != nil {
+		return t, err
+	}
+	if !canEdit(t.AuthorID, actor) {
+		return dom.Thread{}, ErrForbidden
+	}
+	if err := dom.ValidateBody(in.Title, true, in.Body, in.Code); err != nil {
+		return dom.Thread{}, err
+	}
+	now := s.now().UTC()
+	t.Title, t.Body, t.Code = in.Title, in.Body, in.Code
+	t.UpdatedAt, t.EditedAt = now, &now
+	return s.repo.UpdateThread(ctx, t)
+}
+
+func (s *service) DeleteThread(ctx context.Context, id uuid.UUID, actor Actor) error {
+	t, err := s.visibleThread(ctx, id, actor)
+	if err != nil {
+		return err
+	}
+	if !canEdit(t.AuthorID, actor) {
+		return ErrForbidden
+	}
+	t.Status = dom.StatusDeleted
+	t.UpdatedAt = s.now().UTC()
+	_, err = s.repo.UpdateThread(ctx, t)
+	return err
+}
+
+func (s *service) Reply(ctx context.Context, threadID uuid.UUID, parentID *uuid.UUID, actor Actor, in Input) (dom.Post, error) {
+	t, err := s.visibleThread(ctx, threadID, actor)
+	if err != nil {
+		return dom.Post{}, err
+	}
+	if t.Locked && !actor.Staff() {
+		return dom.Post{}, ErrLocked
+	}
+	if err := s.canPost(ctx, t.CourseID, actor); err != nil {
+		return dom.Post{}, err
+	}
+	if err := dom.ValidateBody("", false, in.Body, in.Code); err != nil {
+		return dom.Post{}, err
+	}
+	if parentID != nil {
+		if parentID, err = s.replyParent(ctx, t.ID, *parentID); err != nil {
+			return dom.Post{}, err
+		}
+	}
+	now := s.now().UTC()
+	return s.repo.CreatePost(ctx, dom.Post{
+		ID:        uuid.New(),
+		ThreadID:  t.ID,
+		ParentID:  parentID,
+		AuthorID:  actor.UserID,
+		Body:      in.Body,
+		Code:      in.Code,
+		Status:    dom.StatusVisible,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// replyParent проверяет родителя ответа; слишком глубокий ответ прикрепляется к ближайшему
+// предку на допустимой глубине, чтобы дерево не уходило вправо бесконечно.
+func (s *service) replyParent(ctx context.Context, threadID, parentID uuid.UUID) (*uuid.UUID, error) {
+	chain := []dom.Post{}
+	for id := &parentID; id != nil && len(chain) <= dom.MaxDepth; {
+		p, err := s.repo.GetPost(ctx, *id)
+		if err != nil {
+			return nil, err
+		}
+		if p.ID == uuid.Nil || p.ThreadID != threadID {
+			return nil, fmt.Errorf("%w: parent post is not in this thread", dom.ErrInvalid)
+		}
+		chain = append(chain, p)
+		id = p.ParentID
+	}
+	if chain[0].Status == dom.StatusDeleted {
+		return nil, fmt.Errorf("%w: cannot reply to a deleted post", dom.ErrInvalid)
+	}
+	// chain[0] — родитель на глубине len(chain); ответ окажется на глубине len(chain)+1
+	if over := len(chain) + 1 - dom.MaxDepth; over > 0 {
+		id := chain[over].ID
+		return &id, nil
+	}
+	return &parentID, nil
+}
+
+func (s *service) visiblePost(ctx context.Context, id uuid.UUID, actor Actor) (dom.Post, dom.Thread, error) {
+	p, err := s.repo.GetPost(ctx, id)
+	if err != nil {
+		return p, dom.Thread{}, err
+	}
+	if p.ID == uuid.Nil || p.Status == dom.StatusDeleted || (p.Status == dom.StatusHidden && !actor.Staff()) {
+		return dom.Post{}, dom.Thread{}, ErrNotFound
+	}
+	t, err := s.visibleThread(ctx, p.ThreadID, actor)
+	if err != nil {
+		return dom.Post{}, dom.Thread{}, err
+	}
+	return p, t, nil
+}
+
+func (s *service) UpdatePost(ctx context.Context, id uuid.UUID, actor Actor, in Input) (dom.Post, error) {
+	p, _, err := s.visiblePost(ctx, id, actor)
+	if err != nil {
+		return p, err
+	}
+	if !canEdit(p.AuthorID, actor) {
+		return dom.Post{}, ErrForbidden
+	}
+	if err := dom.ValidateBody("", false, in.Body, in.Code); err != nil {
+		return dom.Post{}, err
+	}
+	now := s.now().UTC()
+	p.Body, p.Code = in.Body, in.Code
+	p.UpdatedAt, p.EditedAt = now, &now
+	return s.repo.UpdatePost(ctx, p)
+}
+
+func (s *service) DeletePost(ctx context.Context, id uuid.UUID, actor Actor) error {
+	p, t, err := s.visiblePost(ctx, id, actor)
+	if err != nil {
+		return err
+	}
+	if !canEdit(p.AuthorID, actor) {
+		return ErrForbidden
+	}
+	p.Status = dom.StatusDeleted
+	p.Body, p.Code = "", nil
+	p.Accepted = false
+	p.UpdatedAt = s.now().UTC()
+	if _, err := s.repo.UpdatePost(ctx, p); err != nil {
+		return err
+	}
+	if t.AcceptedPostID != nil && *t.AcceptedPostID == p.ID {
+		t.AcceptedPostID = nil
+		_, err = s.repo.UpdateThread(ctx, t)
+	}
+	return err
+}
+
+func (s *service) LessonOf(ctx context.Context, targetType string, id uuid.UUID, actor Actor) (uuid.UUID, error) {
+	switch targetType {
+	case dom.TargetThread:
+		t, err := s.visibleThread(ctx, id, actor)
+		return t.LessonID, err
+	case dom.TargetPost:
+		_, t, err := s.visiblePost(ctx, id, actor)
+		return t.LessonID, err
+	default:
+		return uuid.Nil, fmt.Errorf("%w: unknown target %q", dom.ErrInvalid, targetType)
+	}
+}
+
+func (s *service) Vote(ctx context.Context, targetType string, id uuid.UUID, actor Actor, up bool) (int, error) {
+	var authorID, lessonID uuid.UUID
+	switch targetType {
+	case dom.TargetThread:
+		t, err := s.visibleThread(ctx, id, actor)
+		if err != nil {
+			return 0, err
+		}
+		authorID, lessonID = t.AuthorID, t.LessonID
+	case dom.TargetPost:
+		p, t, err := s.visiblePost(ctx, id, actor)
+		if err != nil {
+			return 0, err
+		}
+		authorID, lessonID = p.AuthorID, t.LessonID
+	default:
+		return 0, fmt.Errorf("%w: unknown vote target %q", dom.ErrInvalid, targetType)
+	}
+	if authorID == actor.UserID {
+		return 0, fmt.Errorf("%w: cannot vote for your own message", dom.ErrInvalid)
+	}
+	if err := s.canRead(ctx, lessonID, actor); err != nil {
+		return 0, err
+	}
+	return s.repo.SetVote(ctx, targetType, id, actor.UserID, up)
+}
+
+func (s *service) Accept(ctx context.Context, threadID uuid.UUID, postID *uuid.UUID, actor Actor) (dom.Thread, error) {
+	if !actor.Staff() {
+		return dom.Thread{}, ErrForbidden
+	}
+	t, err := s.visibleThread(ctx, threadID, actor)
+	if err != nil {
+		return t, err
+	}
+	var next dom.Post
+	if postID != nil {
+		if next, _, err = s.visiblePost(ctx, *postID, actor); err != nil {
+			return dom.Thread{}, err
+		}
+		if next.ThreadID != t.ID {
+			return dom.Thread{}, fmt.Errorf("%w: post is not in this thread", dom.ErrInvalid)
+		}
+	}
+	if t.AcceptedPostID != nil {
+		prev, err := s.repo.GetPost(ctx, *t.AcceptedPostID)
+		if err != nil {
+			return dom.Thread{}, err
+		}
+		if prev.ID != uuid.Nil && prev.Accepted {
+			prev.Accepted = false
+			if _, err := s.repo.UpdatePost(ctx, prev); err != nil {
+				return dom.Thread{}, err
+			}
+		}
+	}
+	t.AcceptedPostID = nil
+	if postID != nil {
+		next.Accepted = true
+		if _, err := s.repo.UpdatePost(ctx, next); err != nil {
+			return dom.Thread{}, err
+		}
+		t.AcceptedPostID = &next.ID
+	}
+	t.UpdatedAt = s.now().UTC()
+	return s.repo.UpdateThread(ctx, t)
+}
+
+func (s *service) Moderate(ctx context.Context, targetType string, id uuid.UUID, actor Actor, action string) error {
+	if !actor.Staff() {
+		return ErrForbidden
+	}
+	now := s.now().UTC()
+	switch targetType {
+	case dom.TargetThread:
+		t, err := s.visibleThread(ctx, id, actor)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case ActionHide:
+			t.Status = dom.StatusHidden
+		case ActionRestore:
+			t.Status = dom.StatusVisible
+		case ActionLock:
+			t.Locked = true
+		case ActionUnlock:
+			t.Locked = false
+		default:
+			return fmt.Errorf("%w: unknown action %q", dom.ErrInvalid, action)
+		}
+		t.UpdatedAt = now
+		_, err = s.repo.UpdateThread(ctx, t)
+		return err
+	case dom.TargetPost:
+		p, _, err := s.visiblePost(ctx, id, actor)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case ActionHide:
+			p.Status = dom.StatusHidden
+		case ActionRestore:
+			p.Status = dom.StatusVisible
+		default:
+			return fmt.Errorf("%w: unknown action %q", dom.ErrInvalid, action)
+		}
+		p.UpdatedAt = now
+		_, err = s.repo.UpdatePost(ctx, p)
+		return err
+	}
+	return fmt.Errorf("%w: unknown target %q", dom.ErrInvalid, targetType)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	dom "github.com/example/learngo/internal/domain/enrollment"
+	eventdom "github.com/example/learngo/internal/domain/event"
 	"github.com/example/learngo/pkg/utils"
 	"github.com/google/uuid"
 )
@@ -18,10 +19,12 @@ type Service interface {
 type service struct {
 	repo   dom.Repository
 	logger *utils.Logger
+	events eventdom.Publisher
 }
 
-func NewService(repo dom.Repository, logger *utils.Logger) Service {
-	return &service{repo: repo, logger: logger}
+// NewService конструктор; events может быть nil.
+func NewService(repo dom.Repository, logger *utils.Logger, events eventdom.Publisher) Service {
+	return &service{repo: repo, logger: logger, events: events}
 }
 
 func (s *service) Enroll(ctx context.Context, userID, courseID uuid.UUID, purchased bool) error {
@@ -29,7 +32,17 @@ func (s *service) Enroll(ctx context.Context, userID, courseID uuid.UUID, purcha
 	if purchased {
 		status = "purchased"
 	}
-	return s.repo.Upsert(ctx, dom.Enrollment{UserID: userID, CourseID: courseID, Status: status, CreatedAt: time.Now()})
+	existed, err := s.repo.IsEnrolled(ctx, userID, courseID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Upsert(ctx, dom.Enrollment{UserID: userID, CourseID: courseID, Status: status, CreatedAt: time.Now()}); err != nil {
+		return err
+	}
+	if !existed && s.events != nil {
+		s.events.Publish(ctx, eventdom.Event{Type: eventdom.EnrollmentCreated, CourseID: courseID, UserID: userID})
+	}
+	return nil
 }
 
 func (s *service) IsEnrolled(ctx context.Context, userID, courseID uuid.UUID) (bool, error) {
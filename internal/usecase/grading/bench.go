@@ -0,0 +1,78 @@
+package grading
+
+import (
+	"context"
+	"fmt"
+
+	assigndom "github.com/example/learngo/internal/domain/assignment"
+	subdom "github.com/example/learngo/internal/domain/submission"
+	"github.com/example/learngo/pkg/gotest"
+)
+
+// benchFile файл с бенчмарками задания рядом с решением и эталоном.
+const benchFile = "bench_test.go"
+
+// benchmark сравнивает бенчмарки решения, прошедшего тесты, с эталоном и меняет вердикт,
+// если решение медленнее порога или бенчмарки на нём не работают.
+func (s *service) benchmark(ctx context.Context, a assigndom.Assignment, code string, sub *subdom.Submission) {
+	files := func(main string) map[string]string {
+		return map[string]string{solutionFile: main, benchFile: a.Performance.Benchmarks}
+	}
+	run, err := s.runner.Bench(ctx, files(code), files(a.Performance.Reference), s.bench)
+	if err != nil {
+		// сломан эталон или бенчмарки задания — решение студента тут ни при чём
+		s.logger.Error("performance check failed", "assignment_id", a.ID, "error", err)
+		sub.Verdict = subdom.VerdictInternalError
+		return
+	}
+	switch {
+	case run.Student.BuildFailed:
+		sub.Verdict = subdom.VerdictCompileError
+		sub.CompileOutput += run.Student.Output
+		return
+	case run.Student.TimedOut:
+		sub.Verdict = subdom.VerdictTimeLimit
+		return
+	case run.Student.Failed:
+		sub.Verdict = subdom.VerdictRuntimeError
+		sub.Tests = append(sub.Tests, subdom.TestResult{Name: "benchmarks", Status: gotest.StatusFail, Output: run.Student.Output})
+		return
+	}
+	sub.Benchmarks = compareBenchmarks(a.Performance, run.Student.Benchmarks, run.Reference.Benchmarks)
+	for _, b := range sub.Benchmarks {
+		if !b.Passed {
+			sub.Verdict = subdom.VerdictTooSlow
+		}
+	}
+}
+
+// compareBenchmarks сверяет каждый бенчмарк эталона с тем же бенчмарком решения.
+func compareBenchmarks(p *assigndom.Performance, student, reference []gotest.Benchmark) []subdom.BenchmarkResult {
+	byName := make(map[string]gotest.Benchmark, len(student))
+	for _, b := range student {
+		byName[b.Name] = b
+	}
+	out := make([]subdom.BenchmarkResult, 0, len(reference))
+	for _, ref := range reference {
+		res := subdom.BenchmarkResult{Name: ref.Name, ReferenceNsPerOp: ref.NsPerOp, ReferenceAllocsPerOp: ref.AllocsPerOp, Passed: true}
+		b, ok := byName[ref.Name]
+		if !ok {
+			res.Passed, res.Message = false, "benchmark did not run"
+			out = append(out, res)
+			continue
+		}
+		res.NsPerOp, res.AllocsPerOp, res.BytesPerOp = b.NsPerOp, b.AllocsPerOp, b.BytesPerOp
+		if ref.NsPerOp > 0 {
+			res.NsRatio = b.NsPerOp / ref.NsPerOp
+		}
+		if limit := p.NsRatio(); res.NsRatio > limit {
+			res.Passed = false
+			res.Message = fmt.Sprintf("%.2fx slower than the reference, limit %.2fx", res.NsRatio, limit)
+		} else if p.MaxAllocsRatio > 0 && float64(b.AllocsPerOp) > float64(ref.AllocsPerOp)*p.MaxAllocsRatio {
+			res.Passed = false
+			res.Message = fmt.Sprintf("%d allocs/op, limit %.0f", b.AllocsPerOp, float64(ref.AllocsPerOp)*p.MaxAllocsRatio)
+		}
+		out = append(out, res)
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package grading
+
+import (
+	"testing"
+
+	assigndom "github.com/example/learngo/internal/domain/assignment"
+	"github.com/example/learngo/pkg/gotest"
+)
+
+func TestCompareBenchmarks(t *testing.T) {
+	perf := &assigndom.Performance{MaxNsRatio: 2, MaxAllocsRatio: 1}
+	reference := []gotest.Benchmark{
+		{Name: "BenchmarkFast", NsPerOp: 100, AllocsPerOp: 1},
+		{Name: "BenchmarkSlow", NsPerOp: 100},
+		{Name: "BenchmarkAllocs", NsPerOp: 100, AllocsPerOp: 2},
+		{Name: "BenchmarkMissing", NsPerOp: 100},
+	}
+	student := []gotest.Benchmark{
+		{Name: "BenchmarkFast", NsPerOp: 150, AllocsPerOp: 1},
+		{Name: "BenchmarkSlow", NsPerOp: 250},
+		{Name: "BenchmarkAllocs", NsPerOp: 90, AllocsPerOp: 3},
+	}
+	want := map[string]bool{"BenchmarkFast": true, "BenchmarkSlow": false, "BenchmarkAllocs": false, "BenchmarkMissing": false}
+
+	got := compareBenchmarks(perf, student, reference)
+	if len(got) != len(reference) {
+		t.Fatalf("got %d results", len(got))
+	}
+	for _, r := range got {
+		if r.Passed != want[r.Name] {
+			t.Errorf("%s: passed = %v (%s)", r.Name, r.Passed, r.Message)
+		}
+	}
+	if got[0].NsRatio != 1.5 {
+		t.Errorf("ns ratio = %v", got[0].NsRatio)
+	}
+}
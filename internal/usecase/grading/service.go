@@ -0,0 +1,279 @@
+package grading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+	"time"
+
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	subdom "github.com/example/learngo/internal/domain/submission"
+	accessuc "github.com/example/learngo/internal/usecase/access"
+	assignuc "github.com/example/learngo/internal/usecase/assignment"
+	lessonuc "github.com/example/learngo/internal/usecase/lesson"
+	progressuc "github.com/example/learngo/internal/usecase/progress"
+	submissionuc "github.com/example/learngo/internal/usecase/submission"
+	"github.com/example/learngo/pkg/gotest"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound    = errors.New("assignment not found")
+	ErrNoTests     = errors.New("assignment has no tests")
+	ErrNoAccess    = errors.New("lesson is not available")
+	ErrInvalidCode = errors.New("invalid submission")
+)
+
+// Имена файлов пакета, в котором собирается решение.
+const (
+	solutionFile    = "main.go"
+	testFile        = "solution_test.go"
+	maxSubmissionKB = 64
+	language        = "go"
+)
+
+// Options ограничения проверки.
+type Options struct {
+	// MaxConcurrent одновременных прогонов go test; остальные ждут своей очереди.
+	MaxConcurrent int
+	// Bench прогон бенчмарков заданий на скорость.
+	Bench gotest.BenchOptions
+}
+
+// Result проверенная попытка и её влияние на прогресс.
+type Result struct {
+	subdom.Submission
+	LessonCompleted bool `json:"lesson_completed"`
+}
+
+// Service проверяет решения заданий Go-тестами.
+type Service interface {
+	// Grade собирает решение вместе с тестами задания, запускает их, сохраняет попытку
+	// и возвращает вердикт. Принятое решение отмечает урок пройденным.
+	Grade(ctx context.Context, assignmentID, userID uuid.UUID, role, code string) (Result, error)
+}
+
+type service struct {
+	assignments assignuc.Service
+	lessons     lessonuc.Service
+	access      accessuc.Service
+	progress    progressuc.Service
+	submissions submissionuc.Service // история попыток; может быть nil
+	runner      gotest.Runner
+	logger      *utils.Logger
+	slots       chan struct{}
+	bench       gotest.BenchOptions
+}
+
+func NewService(assignments assignuc.Service, lessons lessonuc.Service, access accessuc.Service, progress progressuc.Service, submissions submissionuc.Service, runner gotest.Runner, logger *utils.Logger, opts Options) Service {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = 1
+	}
+	return &service{
+		assignments: assignments,
+		lessons:     lessons,
+		access:      access,
+		progress:    progress,
+		submissions: submissions,
+		runner:      runner,
+		logger:      logger,
+		slots:       make(chan struct{}, opts.MaxConcurrent),
+		bench:       opts.Bench,
+	}
+}
+
+func isStaff(role string) bool { return role == "admin" || role == "teacher" }
+
+func (s *service) Grade(ctx context.Context, assignmentID, userID uuid.UUID, role, code string) (Result, error) {
+	if strings.TrimSpace(code) == "" {
+		return Result{}, fmt.Errorf("%w: code is required", ErrInvalidCode)
+	}
+	if len(code) > maxSubmissionKB*1024 {
+		return Result{}, fmt.Errorf("%w: code must be at most %d KB", ErrInvalidCode, maxSubmissionKB)
+	}
+	// go:embed вкомпилировал бы в решение файлы пакета, в том числе скрытые тесты
+	if embedsFiles(code) {
+		return Result{}, fmt.Errorf("%w: //go:embed is not allowed", ErrInvalidCode)
+	}
+	a, err := s.assignments.Get(ctx, assignmentID)
+	if err != nil {
+		return Result{}, err
+	}
+	if a.ID == uuid.Nil {
+		return Result{}, ErrNotFound
+	}
+	if strings.TrimSpace(a.Tests) == "" && strings.TrimSpace(a.HiddenTests) == "" {
+		return Result{}, ErrNoTests
+	}
+	l, err := s.lessons.Get(ctx, a.LessonID)
+	if err != nil {
+		return Result{}, err
+	}
+	if l.ID == uuid.Nil {
+		return Result{}, ErrNotFound
+	}
+	if s.access != nil {
+		d, err := s.access.CheckLesson(ctx, l.ID, userID, role)
+		if err != nil {
+			return Result{}, err
+		}
+		if !d.Allowed {
+			return Result{}, ErrNoAccess
+		}
+	}
+
+	files := map[string]string{solutionFile: code}
+	// expected тесты задания: верно только решение, в котором каждый из них отработал и прошёл
+	var expected []string
+	if strings.TrimSpace(a.Tests) != "" {
+		files[testFile] = a.Tests
+		names, err := gotest.TestNames(a.Tests)
+		if err != nil {
+			s.logger.Warn("failed to parse tests", "assignment_id", a.ID, "error", err)
+		}
+		expected = append(expected, names...)
+	}
+	hidden := map[string]bool{}
+	if strings.TrimSpace(a.HiddenTests) != "" {
+		files[subdom.HiddenTestFile] = a.HiddenTests
+		names, err := gotest.TestNames(a.HiddenTests)
+		if err != nil {
+			s.logger.Warn("failed to parse hidden tests", "assignment_id", a.ID, "error", err)
+		}
+		for _, n := range names {
+			hidden[n] = true
+		}
+		expected = append(expected, names...)
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	}
+	start := time.Now()
+	rep, err := s.runner.Run(ctx, files)
+	if err != nil {
+		<-s.slots
+		return Result{}, fmt.Errorf("run tests: %w", err)
+	}
+
+	sub := buildSubmission(rep, expected, hidden)
+	sub.UserID, sub.CourseID, sub.LessonID, sub.AssignmentID = userID, l.CourseID, l.ID, &a.ID
+	sub.Language, sub.Code = language, code
+	sub.ExecutionTimeMs = time.Since(start).Milliseconds()
+	if rep.ElapsedMs > 0 {
+		sub.ExecutionTimeMs = rep.ElapsedMs // время самих тестов, без сборки
+	}
+	// скорость проверяется только у верного решения, в том же слоте
+	if sub.Accepted() && a.Performance.Enabled() {
+		s.benchmark(ctx, a, code, &sub)
+	}
+	<-s.slots
+	if s.submissions != nil && userID != uuid.Nil {
+		saved, err := s.submissions.Record(ctx, sub)
+		if err != nil {
+			s.logger.Error("failed to record submission", "assignment_id", a.ID, "user_id", userID, "error", err)
+		} else {
+			sub = saved
+		}
+	}
+
+	res := Result{Submission: sub}
+	if !isStaff(role) {
+		res.Submission = sub.Redacted()
+	}
+	if sub.Accepted() && userID != uuid.Nil && s.progress != nil {
+		res.LessonCompleted = s.completeLesson(ctx, l, userID, code)
+	}
+	return res, nil
+}
+
+// embedsFiles есть ли в решении директива //go:embed. Код, который не разбирается,
+// проверяется по тексту: директиву в нём всё равно нельзя пропустить.
+func embedsFiles(code string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), solutionFile, code, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return strings.Contains(code, "go:embed")
+	}
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if strings.HasPrefix(c.Text, "//go:embed") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildSubmission переводит отчёт go test в попытку с типизированным вердиктом.
+// Тест из expected, которого нет в отчёте, считается незавершённым: без него решение не верно.
+func buildSubmission(rep gotest.Report, expected []string, hidden map[string]bool) subdom.Submission {
+	sub := subdom.Submission{Tests: []subdom.TestResult{}}
+	output := rep.Output
+	reported := map[string]bool{}
+	for _, t := range rep.Tests {
+		reported[t.Name] = true
+	}
+	tests := rep.Tests
+	for _, name := range expected {
+		if !reported[name] {
+			reported[name] = true
+			tests = append(tests, gotest.TestResult{Name: name, Status: gotest.StatusIncomplete})
+		}
+	}
+	for _, t := range tests {
+		top := t.Name
+		if i := strings.IndexByte(top, '/'); i >= 0 {
+			top = top[:i]
+		}
+		sub.Tests = append(sub.Tests, subdom.TestResult{
+			Name:       t.Name,
+			Status:     t.Status,
+			Output:     t.Output,
+			DurationMs: t.ElapsedMs,
+			Hidden:     hidden[top],
+		})
+		if t.Name == top { // подтесты учитываются в родительском тесте
+			sub.Total++
+			if t.Status == gotest.StatusPass {
+				sub.PassedCount++
+			}
+		}
+		if t.Status != gotest.StatusPass {
+			output += t.Output
+		}
+	}
+	sub.CompileOutput = rep.BuildOutput
+	if sub.Total > 0 {
+		sub.Score = sub.PassedCount * 100 / sub.Total
+	}
+	switch {
+	case rep.BuildFailed:
+		sub.Verdict = subdom.VerdictCompileError
+	case rep.TimedOut:
+		sub.Verdict = subdom.VerdictTimeLimit
+	case strings.Contains(output, "runtime: out of memory"):
+		sub.Verdict = subdom.VerdictMemoryLimit
+	case rep.Passed && sub.Total > 0 && sub.PassedCount == sub.Total:
+		sub.Verdict = subdom.VerdictAccepted
+	case strings.Contains(output, "panic: "):
+		sub.Verdict = subdom.VerdictRuntimeError
+	default:
+		sub.Verdict = subdom.VerdictWrongAnswer
+	}
+	return sub
+}
+
+// completeLesson отмечает урок пройденным; сбой прогресса не отменяет вердикт.
+func (s *service) completeLesson(ctx context.Context, l lessondom.Lesson, userID uuid.UUID, code string) bool {
+	if _, err := s.progress.UpsertLessonProgress(ctx, userID, l.CourseID, l.ID, code, true, 0); err != nil {
+		s.logger.Error("failed to mark lesson completed", "lesson_id", l.ID, "user_id", userID, "error", err)
+		return false
+	}
+	return true
+}
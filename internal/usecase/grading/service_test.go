@@ -0,0 +1,39 @@
+package grading
+
+import (
+	"testing"
+
+	subdom "github.com/example/learngo/internal/domain/submission"
+	"github.com/example/learngo/pkg/gotest"
+)
+
+func TestEmbedsFiles(t *testing.T) {
+	cases := map[string]bool{
+		"package main\n\nimport _ \"embed\"\n\n//go:embed hidden_test.go\nvar s string\n\nfunc main() {}\n": true,
+		"package main\n\n// go:embed в комментарии — не директива\nfunc main() {}\n":                        false,
+		"package main\n\nfunc main() { println(\"//go:embed\") }\n":                                         false,
+		"package main\n\n//go:embed *\nvar broken = \n":                                                     true,
+	}
+	for code, want := range cases {
+		if got := embedsFiles(code); got != want {
+			t.Errorf("embedsFiles(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestBuildSubmissionMissingTests(t *testing.T) {
+	rep := gotest.Report{Passed: true, Tests: []gotest.TestResult{{Name: "TestAdd", Status: gotest.StatusPass}}}
+
+	sub := buildSubmission(rep, []string{"TestAdd"}, nil)
+	if sub.Verdict != subdom.VerdictAccepted {
+		t.Fatalf("verdict = %s", sub.Verdict)
+	}
+	// скрытый тест не попал в отчёт: решение завершило процесс раньше или подделало вывод
+	sub = buildSubmission(rep, []string{"TestAdd", "TestSecret"}, map[string]bool{"TestSecret": true})
+	if sub.Verdict != subdom.VerdictWrongAnswer || sub.Total != 2 || sub.PassedCount != 1 {
+		t.Fatalf("submission %+v", sub)
+	}
+	if last := sub.Tests[len(sub.Tests)-1]; last.Name != "TestSecret" || last.Status != gotest.StatusIncomplete || !last.Hidden {
+		t.Errorf("missing test %+v", last)
+	}
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NormalizeLocale приводит тег языка к базовому виду: "en-US" -> "en", "RU_ru" -> "ru".
+func NormalizeLocale(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag = strings.ReplaceAll(tag, "_", "-")
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+type weightedTag struct {
+	tag string
+	q   float64
+}
+
+// parseAcceptLanguage разбирает заголовок Accept-Language в список тегов по убыванию q.
+func parseAcceptLanguage(header string) []string {
+	parts := strings.Split(header, ",")
+	tags := make([]weightedTag, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tag, q := part, 1.0
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			tag = strings.TrimSpace(part[:i])
+			param := strings.TrimSpace(part[i+1:])
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if tag == "" || tag == "*" || q <= 0 {
+			continue
+		}
+		tags = append(tags, weightedTag{tag: tag, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		out = append(out, t.tag)
+	}
+	return out
+}
+
+// negotiate выбирает первую поддерживаемую локаль из Accept-Language.
+func negotiate(header string, supported map[string]struct{}) string {
+	for _, tag := range parseAcceptLanguage(header) {
+		if loc := NormalizeLocale(tag); loc != "" {
+			if _, ok := supported[loc]; ok {
+				return loc
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,542 @@
+package i18n
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	coursedom "github.com/example/learngo/internal/domain/course"
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	dom "github.com/example/learngo/internal/domain/translation"
+	userdom "github.com/example/learngo/internal/domain/user"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnsupportedLocale = errors.New("unsupported locale")
+	ErrSourceLocale      = errors.New("locale equals source locale of the course")
+	ErrNotFound          = errors.New("entity not found")
+	ErrInvalidField      = errors.New("field is not translatable for this entity")
+	ErrUnknownEntity     = errors.New("unknown entity type")
+)
+
+// translatableFields поля, допустимые для перевода, по типу сущности.
+var translatableFields = map[string][]string{
+	dom.EntityCourse: {dom.FieldTitle, dom.FieldSummary, dom.FieldDescription, dom.FieldObjectives},
+	dom.EntityLesson: {dom.FieldTheory, dom.FieldHints},
+}
+
+// Service локализация контента и управление переводами.
+type Service interface {
+	SupportedLocales() []string
+	// ResolveLocale: явный параметр -> профиль пользователя -> Accept-Language -> локаль по умолчанию.
+	ResolveLocale(ctx context.Context, explicit string, userID uuid.UUID, acceptLanguage string) string
+	SetUserLocale(ctx context.Context, userID uuid.UUID, locale string) error
+
+	LocalizeCourse(ctx context.Context, c coursedom.Course, locale string) coursedom.Course
+	LocalizeCourses(ctx context.Context, list []coursedom.Course, locale string) []coursedom.Course
+	LocalizeLesson(ctx context.Context, l lessondom.Lesson, locale string) lessondom.Lesson
+
+	List(ctx context.Context, entityType string, entityID uuid.UUID) ([]dom.Translation, error)
+	Upsert(ctx context.Context, entityType string, entityID uuid.UUID, locale string, fields dom.Fields, authorID uuid.UUID) (dom.Translation, error)
+	Delete(ctx context.Context, entityType string, entityID uuid.UUID, locale string) error
+	// ListStale переводы курса и его уроков, исходник которых изменился после перевода.
+	ListStale(ctx context.Context, courseID uuid.UUID, locale string) ([]dom.Translation, error)
+}
+
+// userLocaleTTL сколько держим в памяти язык из профиля пользователя.
+// SetUserLocale сбрасывает запись сразу, TTL ограничивает рассинхрон между инстансами API.
+const userLocaleTTL = 5 * time.Minute
+
+type cachedLocale struct {
+	locale  string
+	expires time.Time
+}
+
+type service struct {
+	repo          dom.Repository
+	courseRepo    coursedom.Repository
+	lessonRepo    lessondom.Repository
+	userRepo      userdom.Repository
+	logger        *utils.Logger
+	supported     map[string]struct{}
+	locales       []string
+	defaultLocale string
+
+	mu          sync.Mutex
+	userLocales map[uuid.UUID]cachedLocale
+}
+
+// NewService конструктор сервиса локализации. Первая из supported — запасная, если defaultLocale пуст.
+func NewService(repo dom.Repository, courseRepo coursedom.Repository, lessonRepo lessondom.Repository, userRepo userdom.Repository, logger *utils.Logger, supported []string, defaultLocale string) Service {
+	s := &service{
+		repo:       repo,
+		courseRepo: courseRepo,
+		lessonRepo: lessonRepo,
+		userRepo:   userRepo,
+		logger:     logger,
+		supported:  make(map[string]struct{}, len(supported)),
+
+		userLocales: make(map[uuid.UUID]cachedLocale),
+	}
+	for _, l := range supported {
+		l = NormalizeLocale(l)
+		if _, dup := s.supported[l]; l == "" || dup {
+			continue
+		}
+		s.supported[l] = struct{}{}
+		s.locales = append(s.locales, l)
+	}
+	s.defaultLocale = NormalizeLocale(defaultLocale)
+	if _, ok := s.supported[s.defaultLocale]; !ok && len(s.locales) > 0 {
+		s.defaultLocale = s.locales[0]
+	}
+	return s
+}
+
+func (s *service) SupportedLocales() []string { return append([]string(nil), s.locales...) }
+
+func (s *service) isSupported(locale string) bool {
+	_, ok := s.supported[locale]
+	return ok
+}
+
+func (s *service) ResolveLocale(ctx context.Context, explicit string, userID uuid.UUID, acceptLanguage string) string {
+	if loc := NormalizeLocale(explicit); s.isSupported(loc) {
+		return loc
+	}
+	if loc := s.userLocale(ctx, userID); s.isSupported(loc) {
+		return loc
+	}
+	if loc := negotiate(acceptLanguage, s.supported); loc != "" {
+		return loc
+	}
+	return s.defaultLocale
+}
+
+// userLocale язык из профиля пользователя; результат кэшируется на userLocaleTTL,
+// чтобы LocaleMiddleware не ходил в БД на каждый запрос.
+func (s *service) userLocale(ctx context.Context, userID uuid.UUID) string {
+	if userID == uuid.Nil || s.userRepo == nil {
+		return ""
+	}
+	now := time.Now()
+	s.mu.Lock()
+	e, ok := s.userLocales[userID]
+	s.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.locale
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return ""
+	}
+	s.mu.Lock()
+	// Ленивая очистка: протухшие записи выбрасываем, когда кэш разрастается
+	if len(s.userLocales) >= 10000 {
+		for id, e := range s.userLocales {
+			if !now.Before(e.expires) {
+				delete(s.userLocales, id)
+			}
+		}
+	}
+	s.userLocales[userID] = cachedLocale{locale: u.Locale, expires: now.Add(userLocaleTTL)}
+	s.mu.Unlock()
+	return u.Locale
+}
+
+func (s *service) SetUserLocale(ctx context.Context, userID uuid.UUID, locale string) error {
+	locale = NormalizeLocale(locale)
+	if !s.isSupported(locale) {
+		return ErrUnsupportedLocale
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.ID == uuid.Nil {
+		return ErrNotFound
+	}
+	u.Locale = locale
+	if _, err = s.userRepo.Update(ctx, userID, u); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.userLocales, userID)
+	s.mu.Unlock()
+	return nil
+}
+
+// fallbackChain порядок поиска перевода: запрошенная локаль, затем локаль по умолчанию.
+// Исходная локаль курса завершает цепочку — её значения уже лежат в сущности.
+func (s *service) fallbackChain(locale, sourceLocale string) []string {
+	chain := make([]string, 0, 2)
+	for _, l := range []string{locale, s.defaultLocale} {
+		if l == "" || l == sourceLocale {
+			break
+		}
+		if len(chain) == 0 || chain[len(chain)-1] != l {
+			chain = append(chain, l)
+		}
+	}
+	return chain
+}
+
+// pick собирает итоговые поля: для каждого поля берётся первое непустое значение по цепочке.
+func (s *service) pick(ctx context.Context, entityType string, entityID uuid.UUID, chain []string) (dom.Fields, bool) {
+	byLocale := make(map[string]dom.Translation, len(chain))
+	for _, loc := range chain {
+		t, err := s.repo.Get(ctx, entityType, entityID, loc)
+		if err != nil {
+			s.logger.Error("i18n: load translation failed", "entity", entityType, "id", entityID, "locale", loc, "error", err)
+			continue
+		}
+		if t.ID != uuid.Nil {
+			byLocale[loc] = t
+		}
+	}
+	return merge(byLocale, chain)
+}
+
+// merge накладывает переводы от конца цепочки к началу, чтобы приоритет был у первой локали.
+func merge(byLocale map[string]dom.Translation, chain []string) (dom.Fields, bool) {
+	var out dom.Fields
+	found := false
+	for i := len(chain) - 1; i >= 0; i-- {
+		t, ok := byLocale[chain[i]]
+		if !ok {
+			continue
+		}
+		found = true
+		overlay(&out, t.Fields)
+	}
+	return out, found
+}
+
+func overlay(dst *dom.Fields, src dom.Fields) {
+	if src.Title != "" {
+		dst.Title = src.Title
+	}
+	if src.Summary != "" {
+		dst.Summary = src.Summary
+	}
+	if src.Description != "" {
+		dst.Description = src.Description
+	}
+	if len(src.Objectives) > 0 {
+		dst.Objectives = src.Objectives
+	}
+	if src.Theory != "" {
+		dst.Theory = src.Theory
+	}
+	if len(src.Hints) > 0 {
+		dst.Hints = src.Hints
+	}
+}
+
+func sourceLocaleOf(c coursedom.Course) string {
+	if c.SourceLocale == "" {
+		return "ru"
+	}
+	return NormalizeLocale(c.SourceLocale)
+}
+
+func (s *service) LocalizeCourse(ctx context.Context, c coursedom.Course, locale string) coursedom.Course {
+	chain := s.fallbackChain(NormalizeLocale(locale), sourceLocaleOf(c))
+	if len(chain) == 0 {
+		return c
+	}
+	f, ok := s.pick(ctx, dom.EntityCourse, c.ID, chain)
+	if !ok {
+		return c
+	}
+	return applyCourse(c, f)
+}
+
+func applyCourse(c coursedom.Course, f dom.Fields) coursedom.Course {
+	if f.Title != "" {
+		c.Title = f.Title
+	}
+	if f.Summary != "" {
+		c.Summary = f.Summary
+	}
+	if f.Description != "" {
+		c.Description = f.Description
+	}
+	if len(f.Objectives) > 0 {
+		c.Objectives = f.Objectives
+	}
+	return c
+}
+
+// LocalizeCourses переводы всех курсов списка загружаются одним запросом.
+func (s *service) LocalizeCourses(ctx context.Context, list []coursedom.Course, locale string) []coursedom.Course {
+	locale = NormalizeLocale(locale)
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, c := range list {
+		if len(s.fallbackChain(locale, sourceLocaleOf(c))) > 0 {
+			ids = append(ids, c.ID)
+		}
+	}
+	out := make([]coursedom.Course, 0, len(list))
+	if len(ids) == 0 {
+		return append(out, list...)
+	}
+	all, err := s.repo.ListByEntities(ctx, dom.EntityCourse, ids)
+	if err != nil {
+		s.logger.Error("i18n: load course translations failed", "locale", locale, "error", err)
+		return append(out, list...)
+	}
+	byCourse := make(map[uuid.UUID]map[string]dom.Translation, len(ids))
+	for _, t := range all {
+		if byCourse[t.EntityID] == nil {
+			byCourse[t.EntityID] = make(map[string]dom.Translation)
+		}
+		byCourse[t.EntityID][t.Locale] = t
+	}
+	for _, c := range list {
+		chain := s.fallbackChain(locale, sourceLocaleOf(c))
+		if f, ok := merge(byCourse[c.ID], chain); ok {
+			c = applyCourse(c, f)
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (s *service) LocalizeLesson(ctx context.Context, l lessondom.Lesson, locale string) lessondom.Lesson {
+	sourceLocale := "ru"
+	if crs, err := s.courseRepo.Get(ctx, l.CourseID); err == nil && crs.ID != uuid.Nil {
+		sourceLocale = sourceLocaleOf(crs)
+	}
+	chain := s.fallbackChain(NormalizeLocale(locale), sourceLocale)
+	if len(chain) == 0 {
+		return l
+	}
+	f, ok := s.pick(ctx, dom.EntityLesson, l.ID, chain)
+	if !ok {
+		return l
+	}
+	// Меняем только theory/hints, сохраняя остальные ключи контента как есть
+	content := map[string]json.RawMessage{}
+	if len(l.Content) > 0 {
+		if err := json.Unmarshal(l.Content, &content); err != nil {
+			return l
+		}
+	}
+	if f.Theory != "" {
+		content[dom.FieldTheory], _ = json.Marshal(f.Theory)
+	}
+	if len(f.Hints) > 0 {
+		content[dom.FieldHints], _ = json.Marshal(f.Hints)
+	}
+	if raw, err := json.Marshal(content); err == nil {
+		l.Content = raw
+	}
+	return l
+}
+
+// sourceValues текущие значения исходных полей сущности.
+func (s *service) sourceValues(ctx context.Context, entityType string, entityID uuid.UUID) (map[string]interface{}, string, error) {
+	switch entityType {
+	case dom.EntityCourse:
+		c, err := s.courseRepo.Get(ctx, entityID)
+		if err != nil {
+			return nil, "", err
+		}
+		if c.ID == uuid.Nil {
+			return nil, "", ErrNotFound
+		}
+		return map[string]interface{}{
+			dom.FieldTitle:       c.Title,
+			dom.FieldSummary:     c.Summary,
+			dom.FieldDescription: c.Description,
+			dom.FieldObjectives:  c.Objectives,
+		}, sourceLocaleOf(c), nil
+	case dom.EntityLesson:
+		l, err := s.lessonRepo.Get(ctx, entityID)
+		if err != nil {
+			return nil, "", err
+		}
+		if l.ID == uuid.Nil {
+			return nil, "", ErrNotFound
+		}
+		var content lessondom.LessonContent
+		if len(l.Content) > 0 {
+			_ = json.Unmarshal(l.Content, &content)
+		}
+		sourceLocale := "ru"
+		if crs, err := s.courseRepo.Get(ctx, l.CourseID); err == nil && crs.ID != uuid.Nil {
+			sourceLocale = sourceLocaleOf(crs)
+		}
+		return map[string]interface{}{
+			dom.FieldTheory: content.Theory,
+			dom.FieldHints:  content.Hints,
+		}, sourceLocale, nil
+	}
+	return nil, "", ErrUnknownEntity
+}
+
+func hashValue(v interface{}) string {
+	b, _ := json.Marshal(v)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// presentFields список заполненных в переводе полей.
+func presentFields(f dom.Fields) []string {
+	var out []string
+	if f.Title != "" {
+		out = append(out, dom.FieldTitle)
+	}
+	if f.Summary != "" {
+		out = append(out, dom.FieldSummary)
+	}
+	if f.Description != "" {
+		out = append(out, dom.FieldDescription)
+	}
+	if len(f.Objectives) > 0 {
+		out = append(out, dom.FieldObjectives)
+	}
+	if f.Theory != "" {
+		out = append(out, dom.FieldTheory)
+	}
+	if len(f.Hints) > 0 {
+		out = append(out, dom.FieldHints)
+	}
+	return out
+}
+
+// markStale сравнивает сохранённые хэши исходника с текущими.
+func markStale(t *dom.Translation, source map[string]interface{}) {
+	t.StaleFields = nil
+	for _, field := range presentFields(t.Fields) {
+		if t.SourceHashes[field] != hashValue(source[field]) {
+			t.StaleFields = append(t.StaleFields, field)
+		}
+	}
+	t.Stale = len(t.StaleFields) > 0
+}
+
+func (s *service) List(ctx context.Context, entityType string, entityID uuid.UUID) ([]dom.Translation, error) {
+	source, _, err := s.sourceValues(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListByEntity(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		markStale(&list[i], source)
+	}
+	return list, nil
+}
+
+func (s *service) Upsert(ctx context.Context, entityType string, entityID uuid.UUID, locale string, fields dom.Fields, authorID uuid.UUID) (dom.Translation, error) {
+	locale = NormalizeLocale(locale)
+	if !s.isSupported(locale) {
+		return dom.Translation{}, ErrUnsupportedLocale
+	}
+	allowed, ok := translatableFields[entityType]
+	if !ok {
+		return dom.Translation{}, ErrUnknownEntity
+	}
+	for _, f := range presentFields(fields) {
+		if !contains(allowed, f) {
+			return dom.Translation{}, ErrInvalidField
+		}
+	}
+	source, sourceLocale, err := s.sourceValues(ctx, entityType, entityID)
+	if err != nil {
+		return dom.Translation{}, err
+	}
+	if locale == sourceLocale {
+		return dom.Translation{}, ErrSourceLocale
+	}
+	// Хэши фиксируют версию исходника, с которой сделан перевод
+	hashes := make(map[string]string)
+	for _, f := range presentFields(fields) {
+		hashes[f] = hashValue(source[f])
+	}
+	saved, err := s.repo.Upsert(ctx, dom.Translation{
+		ID:           uuid.New(),
+		EntityType:   entityType,
+		EntityID:     entityID,
+		Locale:       locale,
+		Fields:       fields,
+		SourceHashes: hashes,
+		UpdatedBy:    authorID,
+		UpdatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return dom.Translation{}, err
+	}
+	markStale(&saved, source)
+	return saved, nil
+}
+
+func (s *service) Delete(ctx context.Context, entityType string, entityID uuid.UUID, locale string) error {
+	if _, ok := translatableFields[entityType]; !ok {
+		return ErrUnknownEntity
+	}
+	return s.repo.Delete(ctx, entityType, entityID, NormalizeLocale(locale))
+}
+
+func (s *service) ListStale(ctx context.Context, courseID uuid.UUID, locale string) ([]dom.Translation, error) {
+	locale = NormalizeLocale(locale)
+	out := make([]dom.Translation, 0)
+	courseTr, err := s.List(ctx, dom.EntityCourse, courseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range courseTr {
+		if t.Stale && (locale == "" || t.Locale == locale) {
+			out = append(out, t)
+		}
+	}
+	lessons, err := s.lessonRepo.ListByCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(lessons))
+	sources := make(map[uuid.UUID]map[string]interface{}, len(lessons))
+	for _, l := range lessons {
+		ids = append(ids, l.ID)
+		var content lessondom.LessonContent
+		if len(l.Content) > 0 {
+			_ = json.Unmarshal(l.Content, &content)
+		}
+		sources[l.ID] = map[string]interface{}{dom.FieldTheory: content.Theory, dom.FieldHints: content.Hints}
+	}
+	lessonTr, err := s.repo.ListByEntities(ctx, dom.EntityLesson, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range lessonTr {
+		if locale != "" && t.Locale != locale {
+			continue
+		}
+		markStale(&t, sources[t.EntityID])
+		if t.Stale {
+			out = append(out, t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].UpdatedAt.Before(out[j].UpdatedAt) })
+	return out, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
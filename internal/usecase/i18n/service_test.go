@@ -0,0 +1,154 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	coursedom "github.com/example/learngo/internal/domain/course"
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	dom "github.com/example/learngo/internal/domain/translation"
+	mem "github.com/example/learngo/internal/infrastructure/repository/memory"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+type fakeTranslationRepo struct {
+	items map[string]dom.Translation
+	gets  int
+}
+
+func trKey(entityType string, id uuid.UUID, locale string) string {
+	return entityType + ":" + id.String() + ":" + locale
+}
+
+func (r *fakeTranslationRepo) Get(_ context.Context, entityType string, id uuid.UUID, locale string) (dom.Translation, error) {
+	r.gets++
+	return r.items[trKey(entityType, id, locale)], nil
+}
+
+func (r *fakeTranslationRepo) ListByEntity(_ context.Context, entityType string, id uuid.UUID) ([]dom.Translation, error) {
+	var out []dom.Translation
+	for _, t := range r.items {
+		if t.EntityType == entityType && t.EntityID == id {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeTranslationRepo) ListByEntities(_ context.Context, entityType string, ids []uuid.UUID) ([]dom.Translation, error) {
+	var out []dom.Translation
+	for _, id := range ids {
+		list, _ := r.ListByEntity(context.Background(), entityType, id)
+		out = append(out, list...)
+	}
+	return out, nil
+}
+
+func (r *fakeTranslationRepo) Upsert(_ context.Context, t dom.Translation) (dom.Translation, error) {
+	r.items[trKey(t.EntityType, t.EntityID, t.Locale)] = t
+	return t, nil
+}
+
+func (r *fakeTranslationRepo) Delete(_ context.Context, entityType string, id uuid.UUID, locale string) error {
+	delete(r.items, trKey(entityType, id, locale))
+	return nil
+}
+
+func newTestService(t *testing.T) (Service, *mem.InMemoryCourseRepository, *mem.InMemoryLessonRepository) {
+	t.Helper()
+	courses := mem.NewInMemoryCourseRepository()
+	lessons := mem.NewInMemoryLessonRepository()
+	svc := NewService(&fakeTranslationRepo{items: map[string]dom.Translation{}}, courses, lessons, mem.NewInMemoryUserRepository(), utils.NewLogger("test"), []string{"ru", "en"}, "ru")
+	return svc, courses, lessons
+}
+
+func TestResolveLocale(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	ctx := context.Background()
+	cases := []struct {
+		explicit, accept, want string
+	}{
+		{"", "en-US,en;q=0.9,ru;q=0.8", "en"},
+		{"", "de-DE,ru;q=0.5,en;q=0.7", "en"},
+		{"", "fr", "ru"},
+		{"ru", "en", "ru"},
+		{"xx", "en-GB", "en"},
+	}
+	for _, tc := range cases {
+		if got := svc.ResolveLocale(ctx, tc.explicit, uuid.Nil, tc.accept); got != tc.want {
+			t.Errorf("ResolveLocale(%q, %q) = %q, want %q", tc.explicit, tc.accept, got, tc.want)
+		}
+	}
+}
+
+func TestLocalizeCourseFallbackAndStale(t *testing.T) {
+	svc, courses, _ := newTestService(t)
+	ctx := context.Background()
+	crs, _ := courses.Create(ctx, coursedom.Course{ID: uuid.New(), Title: "Введение", Description: "Описание", SourceLocale: "ru"})
+
+	if _, err := svc.Upsert(ctx, dom.EntityCourse, crs.ID, "ru", dom.Fields{Title: "x"}, uuid.Nil); err != ErrSourceLocale {
+		t.Fatalf("expected ErrSourceLocale, got %v", err)
+	}
+	if _, err := svc.Upsert(ctx, dom.EntityCourse, crs.ID, "en", dom.Fields{Theory: "x"}, uuid.Nil); err != ErrInvalidField {
+		t.Fatalf("expected ErrInvalidField, got %v", err)
+	}
+	if _, err := svc.Upsert(ctx, dom.EntityCourse, crs.ID, "en", dom.Fields{Title: "Introduction"}, uuid.Nil); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	got := svc.LocalizeCourse(ctx, crs, "en")
+	if got.Title != "Introduction" {
+		t.Fatalf("title = %q, want translated", got.Title)
+	}
+	// Непереведённое поле берётся из исходника
+	if got.Description != "Описание" {
+		t.Fatalf("description = %q, want source fallback", got.Description)
+	}
+
+	crs.Title = "Введение в Go"
+	_, _ = courses.Update(ctx, crs.ID, crs)
+	list, _ := svc.List(ctx, dom.EntityCourse, crs.ID)
+	if len(list) != 1 || !list[0].Stale || list[0].StaleFields[0] != dom.FieldTitle {
+		t.Fatalf("expected stale title translation, got %+v", list)
+	}
+}
+
+func TestLocalizeCoursesBatchesLoads(t *testing.T) {
+	repo := &fakeTranslationRepo{items: map[string]dom.Translation{}}
+	courses := mem.NewInMemoryCourseRepository()
+	svc := NewService(repo, courses, mem.NewInMemoryLessonRepository(), mem.NewInMemoryUserRepository(), utils.NewLogger("test"), []string{"ru", "en"}, "ru")
+	ctx := context.Background()
+	a, _ := courses.Create(ctx, coursedom.Course{ID: uuid.New(), Title: "Первый", SourceLocale: "ru"})
+	b, _ := courses.Create(ctx, coursedom.Course{ID: uuid.New(), Title: "Второй", SourceLocale: "ru"})
+	if _, err := svc.Upsert(ctx, dom.EntityCourse, b.ID, "en", dom.Fields{Title: "Second"}, uuid.Nil); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	repo.gets = 0
+
+	got := svc.LocalizeCourses(ctx, []coursedom.Course{a, b}, "en")
+	if got[0].Title != "Первый" || got[1].Title != "Second" {
+		t.Fatalf("titles = %q, %q", got[0].Title, got[1].Title)
+	}
+	if repo.gets != 0 {
+		t.Fatalf("LocalizeCourses made %d per-course loads, want batch only", repo.gets)
+	}
+}
+
+func TestLocalizeLessonKeepsOtherContent(t *testing.T) {
+	svc, courses, lessons := newTestService(t)
+	ctx := context.Background()
+	crs, _ := courses.Create(ctx, coursedom.Course{ID: uuid.New(), Title: "Курс", SourceLocale: "ru"})
+	content, _ := json.Marshal(lessondom.LessonContent{Theory: "Теория", Hints: []string{"подсказка"}, CodeTemplate: "package main"})
+	l, _ := lessons.Create(ctx, lessondom.Lesson{ID: uuid.New(), CourseID: crs.ID, Content: content})
+
+	if _, err := svc.Upsert(ctx, dom.EntityLesson, l.ID, "en", dom.Fields{Theory: "Theory"}, uuid.Nil); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	var got lessondom.LessonContent
+	_ = json.Unmarshal(svc.LocalizeLesson(ctx, l, "en").Content, &got)
+	if got.Theory != "Theory" || got.CodeTemplate != "package main" || len(got.Hints) != 1 || got.Hints[0] != "подсказка" {
+		t.Fatalf("unexpected localized content: %+v", got)
+	}
+}
@@ -3,29 +3,74 @@ package lesson
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 
+	eventdom "github.com/example/learngo/internal/domain/event"
 	dom "github.com/example/learngo/internal/domain/lesson"
+	moduledom "github.com/example/learngo/internal/domain/module"
 	"github.com/example/learngo/pkg/utils"
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrModuleNotFound модуль для перестановки не найден.
+	ErrModuleNotFound = errors.New("module not found")
+	// ErrInvalidOrder список для перестановки не совпадает с содержимым модуля/курса.
+	ErrInvalidOrder = errors.New("invalid order")
+)
+
 type Service interface {
 	ListByCourse(ctx context.Context, courseID uuid.UUID) ([]dom.Lesson, error)
 	ListBySection(ctx context.Context, sectionID uuid.UUID) ([]dom.Lesson, error)
-	Create(ctx context.Context, courseID uuid.UUID, title, content string, order int) (dom.Lesson, error)
-	CreateInSection(ctx context.Context, courseID, sectionID uuid.UUID, title, content string, order int) (dom.Lesson, error)
+	// Create и CreateInSection валидируют контент по типу урока; ошибка — *dom.ValidationError.
+	Create(ctx context.Context, courseID uuid.UUID, title, typ, content string, order int) (dom.Lesson, error)
+	CreateInSection(ctx context.Context, courseID, sectionID uuid.UUID, title, typ, content string, order int) (dom.Lesson, error)
 	Get(ctx context.Context, id uuid.UUID) (dom.Lesson, error)
-	Update(ctx context.Context, id uuid.UUID, title, content string, order int, sectionID uuid.UUID) (dom.Lesson, error)
+	// Update с пустым typ сохраняет текущий тип урока, с order <= 0 — текущее положение.
+	Update(ctx context.Context, id uuid.UUID, title, typ, content string, order int, sectionID uuid.UUID) (dom.Lesson, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// ReorderLessons задаёт порядок уроков модуля; уроки из других модулей курса переносятся в него.
+	ReorderLessons(ctx context.Context, moduleID uuid.UUID, lessonIDs []uuid.UUID) ([]dom.Lesson, error)
+	// ReorderModules задаёт порядок модулей курса и перестраивает сквозную навигацию по урокам.
+	ReorderModules(ctx context.Context, courseID uuid.UUID, moduleIDs []uuid.UUID) ([]moduledom.Module, error)
+	// MigrateContents поднимает контент уроков курса до текущей версии схемы; возвращает число обновлённых.
+	MigrateContents(ctx context.Context, courseID uuid.UUID) (int, error)
 }
 
 type service struct {
-	repo   dom.Repository
-	logger *utils.Logger
+	repo    dom.Repository
+	modules moduledom.Repository
+	logger  *utils.Logger
+	events  eventdom.Publisher
+}
+
+// NewService конструктор; modules и events могут быть nil
+// (без модулей уроки курса упорядочиваются одной последовательностью).
+func NewService(repo dom.Repository, modules moduledom.Repository, logger *utils.Logger, events eventdom.Publisher) Service {
+	return &service{repo: repo, modules: modules, logger: logger, events: events}
+}
+
+func (s *service) publish(ctx context.Context, typ string, l dom.Lesson) {
+	if s.events == nil || l.CourseID == uuid.Nil {
+		return
+	}
+	s.events.Publish(ctx, eventdom.Event{Type: typ, CourseID: l.CourseID, EntityID: l.ID})
 }
 
-func NewService(repo dom.Repository, logger *utils.Logger) Service {
-	return &service{repo: repo, logger: logger}
+// prepareContent валидирует контент и возвращает его в каноничном виде текущей версии.
+func prepareContent(typ, content string) (string, json.RawMessage, error) {
+	typ = dom.NormalizeType(typ)
+	c, err := dom.ParseContent(typ, json.RawMessage(content))
+	if err != nil {
+		return "", nil, err
+	}
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", nil, err
+	}
+	return typ, raw, nil
 }
 
 func (s *service) ListByCourse(ctx context.Context, courseID uuid.UUID) ([]dom.Lesson, error) {
@@ -36,26 +81,229 @@ func (s *service) ListBySection(ctx context.Context, sectionID uuid.UUID) ([]dom
 	return s.repo.ListBySection(ctx, sectionID)
 }
 
-func (s *service) Create(ctx context.Context, courseID uuid.UUID, title, content string, order int) (dom.Lesson, error) {
-	raw := json.RawMessage(content)
-	l := dom.Lesson{ID: uuid.New(), CourseID: courseID, Title: title, Content: raw, Order: order}
-	return s.repo.Create(ctx, l)
+func (s *service) Create(ctx context.Context, courseID uuid.UUID, title, typ, content string, order int) (dom.Lesson, error) {
+	return s.CreateInSection(ctx, courseID, uuid.Nil, title, typ, content, order)
 }
 
-func (s *service) CreateInSection(ctx context.Context, courseID, sectionID uuid.UUID, title, content string, order int) (dom.Lesson, error) {
-	raw := json.RawMessage(content)
-	l := dom.Lesson{ID: uuid.New(), CourseID: courseID, SectionID: sectionID, Title: title, Content: raw, Order: order}
-	return s.repo.Create(ctx, l)
+func (s *service) CreateInSection(ctx context.Context, courseID, sectionID uuid.UUID, title, typ, content string, order int) (dom.Lesson, error) {
+	typ, raw, err := prepareContent(typ, content)
+	if err != nil {
+		return dom.Lesson{}, err
+	}
+	if order <= 0 {
+		order = math.MaxInt32 // в конец модуля; реальный номер проставит PlanOutline
+	}
+	l := dom.Lesson{ID: uuid.New(), CourseID: courseID, ModuleID: sectionID, SectionID: sectionID, Title: title, Type: typ, Content: raw, Order: order}
+	// вставка и пересчёт навигации в одной транзакции, иначе параллельная перестановка оставит урок вне цепочки
+	err = s.repo.Rearrange(ctx, courseID, func(o dom.Outline) (dom.Layout, error) {
+		lessons := append(o.Lessons, l)
+		return dom.Layout{Save: &l, Placements: dom.PlanOutline(lessons, o.ModuleOrder(), l.ID)}, nil
+	})
+	if err != nil {
+		return dom.Lesson{}, err
+	}
+	s.publish(ctx, eventdom.LessonCreated, l)
+	return s.repo.Get(ctx, l.ID)
 }
 
+// Get возвращает урок; контент старой версии схемы мигрируется на лету (без сохранения).
 func (s *service) Get(ctx context.Context, id uuid.UUID) (dom.Lesson, error) {
-	return s.repo.Get(ctx, id)
+	l, err := s.repo.Get(ctx, id)
+	if err != nil || l.ID == uuid.Nil {
+		return l, err
+	}
+	l.Type = dom.NormalizeType(l.Type)
+	if migrated, changed, err := dom.MigrateContent(l.Type, l.Content); err == nil && changed {
+		l.Content = migrated
+	} else if err != nil {
+		s.logger.Warn("lesson content migration failed", "lesson_id", id, "error", err)
+	}
+	return l, nil
 }
 
-func (s *service) Update(ctx context.Context, id uuid.UUID, title, content string, order int, sectionID uuid.UUID) (dom.Lesson, error) {
-	return s.repo.Update(ctx, id, title, content, order, sectionID)
+func (s *service) Update(ctx context.Context, id uuid.UUID, title, typ, content string, order int, sectionID uuid.UUID) (dom.Lesson, error) {
+	existing, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return dom.Lesson{}, err
+	}
+	if existing.ID == uuid.Nil {
+		return dom.Lesson{}, nil
+	}
+	if typ == "" {
+		typ = existing.Type
+	}
+	typ, raw, err := prepareContent(typ, content)
+	if err != nil {
+		return dom.Lesson{}, err
+	}
+	var updated dom.Lesson
+	err = s.repo.Rearrange(ctx, existing.CourseID, func(o dom.Outline) (dom.Layout, error) {
+		i := indexOf(o.Lessons, id)
+		if i < 0 {
+			return dom.Layout{}, errLessonGone
+		}
+		l := o.Lessons[i]
+		l.Title, l.Type, l.Content = title, typ, raw
+		if order > 0 {
+			l.Order = order
+		}
+		if sectionID != uuid.Nil {
+			l.ModuleID, l.SectionID = sectionID, sectionID
+		}
+		o.Lessons[i], updated = l, l
+		return dom.Layout{Save: &l, Placements: dom.PlanOutline(o.Lessons, o.ModuleOrder(), id)}, nil
+	})
+	if errors.Is(err, errLessonGone) {
+		return dom.Lesson{}, nil
+	}
+	if err != nil {
+		return dom.Lesson{}, err
+	}
+	s.publish(ctx, eventdom.LessonUpdated, updated)
+	return s.repo.Get(ctx, id)
 }
 
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	// курс нужен для события, после удаления его уже не получить
+	l, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if l.ID == uuid.Nil {
+		return nil
+	}
+	err = s.repo.Rearrange(ctx, l.CourseID, func(o dom.Outline) (dom.Layout, error) {
+		rest := make([]dom.Lesson, 0, len(o.Lessons))
+		for _, x := range o.Lessons {
+			if x.ID != id {
+				rest = append(rest, x)
+			}
+		}
+		return dom.Layout{Remove: id, Placements: dom.PlanOutline(rest, o.ModuleOrder(), uuid.Nil)}, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(ctx, eventdom.LessonDeleted, l)
+	return nil
+}
+
+// errLessonGone урок удалён между чтением и перестановкой.
+var errLessonGone = errors.New("lesson is gone")
+
+func indexOf(lessons []dom.Lesson, id uuid.UUID) int {
+	for i, l := range lessons {
+		if l.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) ReorderLessons(ctx context.Context, moduleID uuid.UUID, lessonIDs []uuid.UUID) ([]dom.Lesson, error) {
+	if s.modules == nil {
+		return nil, ErrModuleNotFound
+	}
+	mod, err := s.modules.Get(ctx, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	if mod.ID == uuid.Nil {
+		return nil, ErrModuleNotFound
+	}
+	err = s.repo.Rearrange(ctx, mod.CourseID, func(o dom.Outline) (dom.Layout, error) {
+		if !containsID(o.ModuleIDs, moduleID) {
+			return dom.Layout{}, ErrModuleNotFound
+		}
+		current := o.Lessons
+		byID := make(map[uuid.UUID]int, len(current))
+		for i, l := range current {
+			byID[l.ID] = i
+		}
+		listed := make(map[uuid.UUID]bool, len(lessonIDs))
+		for pos, id := range lessonIDs {
+			i, ok := byID[id]
+			if !ok {
+				return dom.Layout{}, fmt.Errorf("%w: lesson %s is not in this course", ErrInvalidOrder, id)
+			}
+			if listed[id] {
+				return dom.Layout{}, fmt.Errorf("%w: lesson %s is listed twice", ErrInvalidOrder, id)
+			}
+			listed[id] = true
+			current[i].ModuleID, current[i].SectionID = moduleID, moduleID
+			current[i].Order = pos + 1
+		}
+		for _, l := range current {
+			if l.Module() == moduleID && !listed[l.ID] {
+				return dom.Layout{}, fmt.Errorf("%w: lesson %s of the module is missing", ErrInvalidOrder, l.ID)
+			}
+		}
+		// перемещённые уроки вынуты из исходных модулей: в целевом модуле порядок задан списком
+		return dom.Layout{Placements: dom.PlanOutline(current, o.ModuleOrder(), uuid.Nil)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListBySection(ctx, moduleID)
+}
+
+func (s *service) ReorderModules(ctx context.Context, courseID uuid.UUID, moduleIDs []uuid.UUID) ([]moduledom.Module, error) {
+	if s.modules == nil {
+		return nil, ErrModuleNotFound
+	}
+	// порядок модулей и расстановка уроков пишутся одной транзакцией под блокировкой модулей
+	err := s.repo.Rearrange(ctx, courseID, func(o dom.Outline) (dom.Layout, error) {
+		if len(o.ModuleIDs) != len(moduleIDs) {
+			return dom.Layout{}, fmt.Errorf("%w: expected %d modules, got %d", ErrInvalidOrder, len(o.ModuleIDs), len(moduleIDs))
+		}
+		known := make(map[uuid.UUID]bool, len(o.ModuleIDs))
+		for _, id := range o.ModuleIDs {
+			known[id] = true
+		}
+		for _, id := range moduleIDs {
+			if !known[id] {
+				return dom.Layout{}, fmt.Errorf("%w: module %s is not in this course or listed twice", ErrInvalidOrder, id)
+			}
+			delete(known, id)
+		}
+		next := dom.Outline{ModuleIDs: moduleIDs}
+		return dom.Layout{ModuleIDs: moduleIDs, Placements: dom.PlanOutline(o.Lessons, next.ModuleOrder(), uuid.Nil)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.modules.ListByCourse(ctx, courseID)
+}
+
+func (s *service) MigrateContents(ctx context.Context, courseID uuid.UUID) (int, error) {
+	lessons, err := s.repo.ListByCourse(ctx, courseID)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, l := range lessons {
+		typ := dom.NormalizeType(l.Type)
+		migrated, changed, err := dom.MigrateContent(typ, l.Content)
+		if err != nil {
+			s.logger.Warn("lesson content migration failed", "lesson_id", l.ID, "error", err)
+			continue
+		}
+		if !changed && typ == l.Type {
+			continue
+		}
+		if _, err := s.repo.Update(ctx, l.ID, l.Title, typ, string(migrated), l.Order, uuid.Nil); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
 }
@@ -0,0 +1,252 @@
+package note
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	coursedom "github.com/example/learngo/internal/domain/course"
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	dom "github.com/example/learngo/internal/domain/note"
+	accessuc "github.com/example/learngo/internal/usecase/access"
+	"github.com/example/learngo/pkg/markdown"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound        = errors.New("note not found")
+	ErrLessonNotFound  = errors.New("lesson not found")
+	ErrCourseNotFound  = errors.New("course not found")
+	ErrSnippetNotFound = errors.New("snippet not found in lesson")
+	ErrNoAccess        = errors.New("lesson is not available")
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Export Markdown-документ с заметками по курсу.
+type Export struct {
+	Filename string
+	Markdown string
+}
+
+// Service заметки и закладки студента. Все операции выполняются от имени владельца:
+// чужая заметка неотличима от несуществующей.
+type Service interface {
+	CreateNote(ctx context.Context, userID uuid.UUID, role string, lessonID uuid.UUID, snippetID, body string) (dom.Note, error)
+	GetNote(ctx context.Context, userID, id uuid.UUID) (dom.Note, error)
+	UpdateNote(ctx context.Context, userID, id uuid.UUID, body string) (dom.Note, error)
+	DeleteNote(ctx context.Context, userID, id uuid.UUID) error
+	ListNotes(ctx context.Context, userID uuid.UUID, courseID, lessonID *uuid.UUID) ([]dom.Note, error)
+	Search(ctx context.Context, userID uuid.UUID, query string, limit int) ([]dom.SearchHit, error)
+	ExportCourse(ctx context.Context, userID, courseID uuid.UUID) (Export, error)
+
+	AddBookmark(ctx context.Context, userID, lessonID uuid.UUID) (dom.Bookmark, error)
+	RemoveBookmark(ctx context.Context, userID, lessonID uuid.UUID) error
+	ListBookmarks(ctx context.Context, userID uuid.UUID, courseID *uuid.UUID) ([]dom.Bookmark, error)
+}
+
+type service struct {
+	repo    dom.Repository
+	lessons lessondom.Repository
+	courses coursedom.Repository
+	access  accessuc.Service
+	md      *markdown.Renderer
+	now     func() time.Time
+}
+
+func NewService(repo dom.Repository, lessons lessondom.Repository, courses coursedom.Repository, access accessuc.Service, md *markdown.Renderer) Service {
+	return &service{repo: repo, lessons: lessons, courses: courses, access: access, md: md, now: time.Now}
+}
+
+func (s *service) lesson(ctx context.Context, id uuid.UUID) (lessondom.Lesson, error) {
+	l, err := s.lessons.Get(ctx, id)
+	if err != nil {
+		return lessondom.Lesson{}, err
+	}
+	if l.ID == uuid.Nil {
+		return lessondom.Lesson{}, ErrLessonNotFound
+	}
+	return l, nil
+}
+
+// snippet находит исполняемый фрагмент в теории урока.
+func (s *service) snippet(l lessondom.Lesson, id string) (markdown.Snippet, error) {
+	if s.md == nil {
+		return markdown.Snippet{}, ErrSnippetNotFound
+	}
+	content, err := lessondom.DecodeContent(l.Type, l.Content)
+	if err != nil {
+		return markdown.Snippet{}, fmt.Errorf("decode lesson content: %w", err)
+	}
+	res, err := s.md.Render(content.Base().Theory)
+	if err != nil {
+		return markdown.Snippet{}, err
+	}
+	sn, ok := res.Snippet(id)
+	if !ok {
+		return markdown.Snippet{}, ErrSnippetNotFound
+	}
+	return sn, nil
+}
+
+func (s *service) CreateNote(ctx context.Context, userID uuid.UUID, role string, lessonID uuid.UUID, snippetID, body string) (dom.Note, error) {
+	if err := dom.ValidateBody(body); err != nil {
+		return dom.Note{}, err
+	}
+	l, err := s.lesson(ctx, lessonID)
+	if err != nil {
+		return dom.Note{}, err
+	}
+	// заметки пишутся к тому, что студент может открыть
+	if s.access != nil {
+		d, err := s.access.CheckLesson(ctx, lessonID, userID, role)
+		if err != nil {
+			return dom.Note{}, err
+		}
+		if !d.Allowed {
+			return dom.Note{}, ErrNoAccess
+		}
+	}
+	now := s.now().UTC()
+	n := dom.Note{
+		ID:        uuid.New(),
+		UserID:    userID,
+		LessonID:  l.ID,
+		CourseID:  l.CourseID,
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if snippetID = strings.TrimSpace(snippetID); snippetID != "" {
+		sn, err := s.snippet(l, snippetID)
+		if err != nil {
+			return dom.Note{}, err
+		}
+		n.SnippetID, n.SnippetLanguage, n.SnippetCode = sn.ID, sn.Language, sn.Code
+	}
+	return s.repo.CreateNote(ctx, n)
+}
+
+func (s *service) GetNote(ctx context.Context, userID, id uuid.UUID) (dom.Note, error) {
+	n, err := s.repo.GetNote(ctx, id)
+	if err != nil {
+		return dom.Note{}, err
+	}
+	if n.ID == uuid.Nil || n.UserID != userID {
+		return dom.Note{}, ErrNotFound
+	}
+	return n, nil
+}
+
+func (s *service) UpdateNote(ctx context.Context, userID, id uuid.UUID, body string) (dom.Note, error) {
+	if err := dom.ValidateBody(body); err != nil {
+		return dom.Note{}, err
+	}
+	n, err := s.GetNote(ctx, userID, id)
+	if err != nil {
+		return dom.Note{}, err
+	}
+	n.Body = body
+	n.UpdatedAt = s.now().UTC()
+	return s.repo.UpdateNote(ctx, n)
+}
+
+func (s *service) DeleteNote(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.GetNote(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteNote(ctx, id)
+}
+
+func (s *service) ListNotes(ctx context.Context, userID uuid.UUID, courseID, lessonID *uuid.UUID) ([]dom.Note, error) {
+	return s.repo.ListNotes(ctx, dom.Filter{UserID: userID, CourseID: courseID, LessonID: lessonID})
+}
+
+func (s *service) Search(ctx context.Context, userID uuid.UUID, query string, limit int) ([]dom.SearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []dom.SearchHit{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	return s.repo.SearchNotes(ctx, userID, query, limit)
+}
+
+func (s *service) ExportCourse(ctx context.Context, userID, courseID uuid.UUID) (Export, error) {
+	c, err := s.courses.Get(ctx, courseID)
+	if err != nil {
+		return Export{}, err
+	}
+	if c.ID == uuid.Nil {
+		return Export{}, ErrCourseNotFound
+	}
+	lessons, err := s.lessons.ListByCourse(ctx, courseID)
+	if err != nil {
+		return Export{}, err
+	}
+	sort.SliceStable(lessons, func(i, j int) bool { return lessons[i].Order < lessons[j].Order })
+	refs := make([]dom.ExportLesson, 0, len(lessons))
+	for _, l := range lessons {
+		refs = append(refs, dom.ExportLesson{ID: l.ID, Title: l.Title})
+	}
+	notes, err := s.repo.ListNotes(ctx, dom.Filter{UserID: userID, CourseID: &courseID})
+	if err != nil {
+		return Export{}, err
+	}
+	name := c.Slug
+	if name == "" {
+		name = c.ID.String()
+	}
+	return Export{
+		Filename: name + "-notes.md",
+		Markdown: dom.ExportMarkdown(c.Title, refs, notes, s.now()),
+	}, nil
+}
+
+func (s *service) AddBookmark(ctx context.Context, userID, lessonID uuid.UUID) (dom.Bookmark, error) {
+	// закладка — это «вернуться позже», поэтому доступ к уроку не требуется
+	l, err := s.lesson(ctx, lessonID)
+	if err != nil {
+		return dom.Bookmark{}, err
+	}
+	b, err := s.repo.AddBookmark(ctx, dom.Bookmark{UserID: userID, LessonID: l.ID, CourseID: l.CourseID, CreatedAt: s.now().UTC()})
+	if err != nil {
+		return dom.Bookmark{}, err
+	}
+	b.LessonTitle = l.Title
+	return b, nil
+}
+
+func (s *service) RemoveBookmark(ctx context.Context, userID, lessonID uuid.UUID) error {
+	return s.repo.RemoveBookmark(ctx, userID, lessonID)
+}
+
+func (s *service) ListBookmarks(ctx context.Context, userID uuid.UUID, courseID *uuid.UUID) ([]dom.Bookmark, error) {
+	list, err := s.repo.ListBookmarks(ctx, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	out := list[:0]
+	for _, b := range list {
+		l, err := s.lessons.Get(ctx, b.LessonID)
+		if err != nil {
+			return nil, err
+		}
+		if l.ID == uuid.Nil {
+			continue // урок удалён
+		}
+		b.LessonTitle = l.Title
+		out = append(out, b)
+	}
+	return out, nil
+}
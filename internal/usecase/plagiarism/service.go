@@ -0,0 +1,187 @@
+package plagiarism
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	assignmentdom "github.com/example/learngo/internal/domain/assignment"
+	dom "github.com/example/learngo/internal/domain/plagiarism"
+	submissiondom "github.com/example/learngo/internal/domain/submission"
+	"github.com/example/learngo/pkg/plagiarism"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound           = errors.New("plagiarism pair not found")
+	ErrAssignmentNotFound = errors.New("assignment not found")
+)
+
+// Options параметры анализа.
+type Options struct {
+	// Threshold минимальное сходство пары для отчёта, 0..1.
+	Threshold float64
+	// CommonShare доля решений, начиная с которой общий фрагмент считается идиомой задания.
+	CommonShare float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.Threshold <= 0 || o.Threshold > 1 {
+		o.Threshold = 0.5
+	}
+	if o.CommonShare <= 0 {
+		o.CommonShare = 0.5
+	}
+	return o
+}
+
+// Service поиск заимствований среди лучших решений задания. Сравниваются только решения
+// на Go; стартовый код задания заимствованием не считается.
+type Service interface {
+	// Analyze пересчитывает отчёт по заданию.
+	Analyze(ctx context.Context, assignmentID uuid.UUID) (dom.Report, error)
+	// AnalyzeUpdated пересчитывает отчёты заданий с попытками новее последнего анализа.
+	AnalyzeUpdated(ctx context.Context) error
+	// Report последний отчёт по заданию; без анализа — пустой отчёт.
+	Report(ctx context.Context, assignmentID uuid.UUID) (dom.Report, error)
+	// Pair пара с выровненным кодом обоих решений.
+	Pair(ctx context.Context, id uuid.UUID) (dom.PairView, error)
+}
+
+type service struct {
+	repo        dom.Repository
+	submissions submissiondom.Repository
+	assignments assignmentdom.Repository
+	logger      *utils.Logger
+	opts        Options
+	now         func() time.Time
+}
+
+func NewService(repo dom.Repository, submissions submissiondom.Repository, assignments assignmentdom.Repository, logger *utils.Logger, opts Options) Service {
+	return &service{repo: repo, submissions: submissions, assignments: assignments, logger: logger, opts: opts.withDefaults(), now: time.Now}
+}
+
+func (s *service) Analyze(ctx context.Context, assignmentID uuid.UUID) (dom.Report, error) {
+	a, err := s.assignments.Get(ctx, assignmentID)
+	if err != nil {
+		return dom.Report{}, fmt.Errorf("load assignment: %w", err)
+	}
+	if a.ID == uuid.Nil {
+		return dom.Report{}, ErrAssignmentNotFound
+	}
+	subs, err := s.submissions.BestByAssignment(ctx, assignmentID)
+	if err != nil {
+		return dom.Report{}, fmt.Errorf("load submissions: %w", err)
+	}
+	var ignore map[uint64]bool
+	if starter := a.Starter()["main.go"]; starter != "" {
+		ignore = plagiarism.Fingerprints(starter, plagiarism.DefaultOptions())
+	}
+
+	var docs []*plagiarism.Document
+	var compared []submissiondom.Submission
+	for _, sub := range subs {
+		if sub.Language != "" && sub.Language != "go" {
+			continue
+		}
+		d, err := plagiarism.NewDocument(sub.Code, plagiarism.DefaultOptions())
+		if err != nil {
+			continue // решение, которое не удалось разобрать, не сравнивается
+		}
+		docs = append(docs, d)
+		compared = append(compared, sub)
+	}
+
+	rep := dom.Report{AssignmentID: assignmentID, Submissions: len(docs), Threshold: s.opts.Threshold, Pairs: []dom.Pair{}, ComputedAt: s.now().UTC()}
+	for _, p := range plagiarism.CompareAll(docs, ignore, s.opts.Threshold, s.opts.CommonShare) {
+		sa, sb := compared[p.I], compared[p.J]
+		pair := dom.Pair{
+			ID:           uuid.New(),
+			AssignmentID: assignmentID,
+			SubmissionA:  sa.ID,
+			SubmissionB:  sb.ID,
+			UserA:        sa.UserID,
+			UserB:        sb.UserID,
+			Similarity:   p.Similarity(),
+			ScoreA:       p.ScoreA,
+			ScoreB:       p.ScoreB,
+			Matches:      make([]dom.Match, 0, len(p.Matches)),
+		}
+		for _, m := range p.Matches {
+			pair.Matches = append(pair.Matches, dom.Match{AStart: m.AStart, AEnd: m.AEnd, BStart: m.BStart, BEnd: m.BEnd})
+		}
+		rep.Pairs = append(rep.Pairs, pair)
+	}
+	if err := s.repo.SaveReport(ctx, rep); err != nil {
+		return dom.Report{}, fmt.Errorf("save report: %w", err)
+	}
+	return rep, nil
+}
+
+func (s *service) AnalyzeUpdated(ctx context.Context) error {
+	latest, err := s.submissions.LatestByAssignment(ctx)
+	if err != nil {
+		return err
+	}
+	computed, err := s.repo.ComputedAt(ctx)
+	if err != nil {
+		return err
+	}
+	for id, at := range latest {
+		if done, ok := computed[id]; ok && !at.After(done) {
+			continue
+		}
+		rep, err := s.Analyze(ctx, id)
+		if errors.Is(err, ErrAssignmentNotFound) {
+			continue
+		}
+		if err != nil {
+			s.logger.Error("plagiarism analysis failed", "assignment_id", id, "error", err)
+			continue
+		}
+		if len(rep.Pairs) > 0 {
+			s.logger.Info("plagiarism suspected", "assignment_id", id, "pairs", len(rep.Pairs))
+		}
+	}
+	return nil
+}
+
+func (s *service) Report(ctx context.Context, assignmentID uuid.UUID) (dom.Report, error) {
+	rep, err := s.repo.Report(ctx, assignmentID)
+	if err != nil {
+		return dom.Report{}, err
+	}
+	if rep.AssignmentID == uuid.Nil {
+		return dom.Report{AssignmentID: assignmentID, Threshold: s.opts.Threshold, Pairs: []dom.Pair{}}, nil
+	}
+	return rep, nil
+}
+
+func (s *service) Pair(ctx context.Context, id uuid.UUID) (dom.PairView, error) {
+	p, err := s.repo.Pair(ctx, id)
+	if err != nil {
+		return dom.PairView{}, err
+	}
+	if p.ID == uuid.Nil {
+		return dom.PairView{}, ErrNotFound
+	}
+	a, err := s.submissions.Get(ctx, p.SubmissionA)
+	if err != nil {
+		return dom.PairView{}, err
+	}
+	b, err := s.submissions.Get(ctx, p.SubmissionB)
+	if err != nil {
+		return dom.PairView{}, err
+	}
+	matches := make([]plagiarism.Match, 0, len(p.Matches))
+	for _, m := range p.Matches {
+		matches = append(matches, plagiarism.Match{AStart: m.AStart, AEnd: m.AEnd, BStart: m.BStart, BEnd: m.BEnd})
+	}
+	view := dom.PairView{Pair: p, CodeA: a.Code, CodeB: b.Code}
+	for _, r := range plagiarism.Align(a.Code, b.Code, matches) {
+		view.Rows = append(view.Rows, dom.Row{LeftLine: r.LeftLine, Left: r.Left, RightLine: r.RightLine, Right: r.Right, Match: r.Match})
+	}
+	return view, nil
+}
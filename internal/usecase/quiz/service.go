@@ -0,0 +1,337 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	dom "github.com/example/learngo/internal/domain/quiz"
+	progressuc "github.com/example/learngo/internal/usecase/progress"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrNotQuiz           = errors.New("lesson is not a quiz")
+	ErrNoQuestions       = errors.New("quiz has no questions")
+	ErrForbidden         = errors.New("attempt belongs to another user")
+	ErrAttemptsExhausted = dom.ErrAttemptsExhausted
+	ErrAttemptClosed     = errors.New("attempt already submitted")
+	ErrAttemptExpired    = errors.New("attempt time limit exceeded")
+)
+
+// submitGrace запас на сетевую задержку при проверке лимита времени.
+const submitGrace = 30 * time.Second
+
+// AttemptView попытка вместе с вопросами в том виде, в каком их видит студент.
+type AttemptView struct {
+	Attempt   dom.Attempt          `json:"attempt"`
+	Questions []dom.PublicQuestion `json:"questions"`
+}
+
+// Summary сводка попыток пользователя по квизу.
+type Summary struct {
+	Attempts     []dom.Attempt `json:"attempts"`
+	MaxAttempts  int           `json:"max_attempts"`
+	AttemptsLeft int           `json:"attempts_left"` // -1 — без ограничений
+	BestPercent  int           `json:"best_percent"`
+	Passed       bool          `json:"passed"`
+	PassingScore int           `json:"passing_score"`
+}
+
+type Service interface {
+	// Управление банком вопросов (преподаватель); ответы возвращаются полностью.
+	ListQuestions(ctx context.Context, lessonID uuid.UUID) ([]dom.Question, error)
+	CreateQuestion(ctx context.Context, lessonID uuid.UUID, q dom.Question) (dom.Question, error)
+	UpdateQuestion(ctx context.Context, id uuid.UUID, q dom.Question) (dom.Question, error)
+	DeleteQuestion(ctx context.Context, id uuid.UUID) error
+
+	// Прохождение (студент); правильные ответы наружу не отдаются.
+	StartAttempt(ctx context.Context, userID, lessonID uuid.UUID) (AttemptView, error)
+	GetAttempt(ctx context.Context, userID, attemptID uuid.UUID) (AttemptView, error)
+	SubmitAttempt(ctx context.Context, userID, attemptID uuid.UUID, answers []dom.Answer) (dom.Attempt, error)
+	Summary(ctx context.Context, userID, lessonID uuid.UUID) (Summary, error)
+}
+
+type service struct {
+	repo     dom.Repository
+	lessons  lessondom.Repository
+	progress progressuc.Service
+	logger   *utils.Logger
+	now      func() time.Time
+}
+
+// NewService конструктор; progress может быть nil — тогда результат не попадает в прогресс.
+func NewService(repo dom.Repository, lessons lessondom.Repository, progress progressuc.Service, logger *utils.Logger) Service {
+	return &service{repo: repo, lessons: lessons, progress: progress, logger: logger, now: time.Now}
+}
+
+// quizLesson возвращает урок-квиз и его настройки.
+func (s *service) quizLesson(ctx context.Context, lessonID uuid.UUID) (lessondom.Lesson, lessondom.QuizContent, error) {
+	l, err := s.lessons.Get(ctx, lessonID)
+	if err != nil {
+		return l, lessondom.QuizContent{}, err
+	}
+	if l.ID == uuid.Nil {
+		return l, lessondom.QuizContent{}, ErrNotFound
+	}
+	if lessondom.NormalizeType(l.Type) != lessondom.TypeQuiz {
+		return l, lessondom.QuizContent{}, ErrNotQuiz
+	}
+	c, err := lessondom.DecodeContent(l.Type, l.Content)
+	if err != nil {
+		return l, lessondom.QuizContent{}, fmt.Errorf("decode quiz settings: %w", err)
+	}
+	return l, *c.(*lessondom.QuizContent), nil
+}
+
+func (s *service) ListQuestions(ctx context.Context, lessonID uuid.UUID) ([]dom.Question, error) {
+	return s.repo.ListQuestions(ctx, lessonID)
+}
+
+func (s *service) CreateQuestion(ctx context.Context, lessonID uuid.UUID, q dom.Question) (dom.Question, error) {
+	if _, _, err := s.quizLesson(ctx, lessonID); err != nil {
+		return dom.Question{}, err
+	}
+	if err := q.Validate(); err != nil {
+		return dom.Question{}, err
+	}
+	now := s.now().UTC()
+	q.ID, q.LessonID, q.CreatedAt, q.UpdatedAt = uuid.New(), lessonID, now, now
+	return s.repo.CreateQuestion(ctx, q)
+}
+
+func (s *service) UpdateQuestion(ctx context.Context, id uuid.UUID, q dom.Question) (dom.Question, error) {
+	existing, err := s.repo.GetQuestion(ctx, id)
+	if err != nil {
+		return dom.Question{}, err
+	}
+	if existing.ID == uuid.Nil {
+		return dom.Question{}, ErrNotFound
+	}
+	if err := q.Validate(); err != nil {
+		return dom.Question{}, err
+	}
+	q.ID, q.LessonID, q.CreatedAt, q.UpdatedAt = existing.ID, existing.LessonID, existing.CreatedAt, s.now().UTC()
+	return s.repo.UpdateQuestion(ctx, q)
+}
+
+func (s *service) DeleteQuestion(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteQuestion(ctx, id)
+}
+
+func (s *service) StartAttempt(ctx context.Context, userID, lessonID uuid.UUID) (AttemptView, error) {
+	l, settings, err := s.quizLesson(ctx, lessonID)
+	if err != nil {
+		return AttemptView{}, err
+	}
+	attempts, err := s.repo.ListAttempts(ctx, userID, lessonID)
+	if err != nil {
+		return AttemptView{}, err
+	}
+	now := s.now().UTC()
+	// незавершённая попытка продолжается, а не создаётся заново — иначе лимит обходится перезапуском
+	active, used := dom.Usage(attempts, now, submitGrace)
+	if active != nil {
+		return s.view(ctx, *active, settings)
+	}
+	if settings.MaxAttempts > 0 && used >= settings.MaxAttempts {
+		return AttemptView{}, ErrAttemptsExhausted
+	}
+
+	bank, err := s.repo.ListQuestions(ctx, lessonID)
+	if err != nil {
+		return AttemptView{}, err
+	}
+	if len(bank) == 0 {
+		return AttemptView{}, ErrNoQuestions
+	}
+	seed := rand.Int63()
+	pool := dom.SelectPool(bank, settings.PoolSize, seed)
+	a := dom.Attempt{
+		ID:        uuid.New(),
+		UserID:    userID,
+		LessonID:  lessonID,
+		CourseID:  l.CourseID,
+		Seed:      seed,
+		Status:    dom.AttemptInProgress,
+		StartedAt: now,
+	}
+	for _, q := range pool {
+		a.QuestionIDs = append(a.QuestionIDs, q.ID)
+		a.MaxScore += q.Points
+	}
+	if settings.TimeLimitSec > 0 {
+		exp := now.Add(time.Duration(settings.TimeLimitSec) * time.Second)
+		a.ExpiresAt = &exp
+	}
+	// Проверка выше — быстрый путь; окончательно лимит проверяется в репозитории под локом,
+	// иначе параллельные старты превысят MaxAttempts
+	created, err := s.repo.StartAttempt(ctx, a, settings.MaxAttempts, submitGrace)
+	if err != nil {
+		return AttemptView{}, err
+	}
+	return s.view(ctx, created, settings)
+}
+
+func (s *service) GetAttempt(ctx context.Context, userID, attemptID uuid.UUID) (AttemptView, error) {
+	a, err := s.ownAttempt(ctx, userID, attemptID)
+	if err != nil {
+		return AttemptView{}, err
+	}
+	_, settings, err := s.quizLesson(ctx, a.LessonID)
+	if err != nil {
+		return AttemptView{}, err
+	}
+	return s.view(ctx, a, settings)
+}
+
+func (s *service) SubmitAttempt(ctx context.Context, userID, attemptID uuid.UUID, answers []dom.Answer) (dom.Attempt, error) {
+	a, err := s.ownAttempt(ctx, userID, attemptID)
+	if err != nil {
+		return dom.Attempt{}, err
+	}
+	if a.Status != dom.AttemptInProgress {
+		return dom.Attempt{}, ErrAttemptClosed
+	}
+	now := s.now().UTC()
+	if a.ExpiresAt != nil && now.After(a.ExpiresAt.Add(submitGrace)) {
+		return dom.Attempt{}, ErrAttemptExpired
+	}
+	_, settings, err := s.quizLesson(ctx, a.LessonID)
+	if err != nil {
+		return dom.Attempt{}, err
+	}
+	questions, err := s.attemptQuestions(ctx, a)
+	if err != nil {
+		return dom.Attempt{}, err
+	}
+
+	byQuestion := make(map[uuid.UUID]dom.Answer, len(answers))
+	for _, ans := range answers {
+		byQuestion[ans.QuestionID] = ans
+	}
+	a.Answers, a.Results, a.Score, a.MaxScore = nil, nil, 0, 0
+	for _, q := range questions {
+		ans := byQuestion[q.ID]
+		ans.QuestionID = q.ID
+		res := q.Grade(ans)
+		a.Answers = append(a.Answers, ans)
+		a.Results = append(a.Results, res)
+		a.Score += res.Points
+		a.MaxScore += res.MaxPoints
+	}
+	if a.MaxScore > 0 {
+		a.Percent = a.Score * 100 / a.MaxScore
+	}
+	a.Passed = a.Percent >= settings.PassingScore
+	a.Status = dom.AttemptSubmitted
+	a.SubmittedAt = &now
+
+	saved, err := s.repo.UpdateAttempt(ctx, a)
+	if err != nil {
+		return dom.Attempt{}, err
+	}
+	s.recordProgress(ctx, saved)
+	return saved, nil
+}
+
+func (s *service) Summary(ctx context.Context, userID, lessonID uuid.UUID) (Summary, error) {
+	_, settings, err := s.quizLesson(ctx, lessonID)
+	if err != nil {
+		return Summary{}, err
+	}
+	attempts, err := s.repo.ListAttempts(ctx, userID, lessonID)
+	if err != nil {
+		return Summary{}, err
+	}
+	sum := Summary{Attempts: attempts, MaxAttempts: settings.MaxAttempts, PassingScore: settings.PassingScore, AttemptsLeft: -1}
+	used := 0
+	now := s.now().UTC()
+	for _, a := range attempts {
+		if a.Status == dom.AttemptSubmitted || (a.ExpiresAt != nil && now.After(a.ExpiresAt.Add(submitGrace))) {
+			used++
+		}
+		if a.Percent > sum.BestPercent {
+			sum.BestPercent = a.Percent
+		}
+		sum.Passed = sum.Passed || a.Passed
+	}
+	if settings.MaxAttempts > 0 {
+		sum.AttemptsLeft = settings.MaxAttempts - used
+		if sum.AttemptsLeft < 0 {
+			sum.AttemptsLeft = 0
+		}
+	}
+	return sum, nil
+}
+
+// recordProgress отмечает урок пройденным; неудачная попытка не снимает ранее полученный зачёт.
+func (s *service) recordProgress(ctx context.Context, a dom.Attempt) {
+	if s.progress == nil {
+		return
+	}
+	completed := a.Passed
+	if !completed {
+		attempts, err := s.repo.ListAttempts(ctx, a.UserID, a.LessonID)
+		if err == nil {
+			for _, prev := range attempts {
+				completed = completed || prev.Passed
+			}
+		}
+	}
+	if _, err := s.progress.UpsertLessonProgress(ctx, a.UserID, a.CourseID, a.LessonID, "", completed, 0); err != nil {
+		s.logger.Error("quiz progress update failed", "attempt_id", a.ID, "error", err)
+	}
+}
+
+func (s *service) ownAttempt(ctx context.Context, userID, attemptID uuid.UUID) (dom.Attempt, error) {
+	a, err := s.repo.GetAttempt(ctx, attemptID)
+	if err != nil {
+		return dom.Attempt{}, err
+	}
+	if a.ID == uuid.Nil {
+		return dom.Attempt{}, ErrNotFound
+	}
+	if a.UserID != userID {
+		return dom.Attempt{}, ErrForbidden
+	}
+	return a, nil
+}
+
+// attemptQuestions вопросы попытки в порядке выдачи; удалённые из банка пропускаются.
+func (s *service) attemptQuestions(ctx context.Context, a dom.Attempt) ([]dom.Question, error) {
+	bank, err := s.repo.ListQuestions(ctx, a.LessonID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]dom.Question, len(bank))
+	for _, q := range bank {
+		byID[q.ID] = q
+	}
+	out := make([]dom.Question, 0, len(a.QuestionIDs))
+	for _, id := range a.QuestionIDs {
+		if q, ok := byID[id]; ok {
+			out = append(out, q)
+		}
+	}
+	return out, nil
+}
+
+func (s *service) view(ctx context.Context, a dom.Attempt, settings lessondom.QuizContent) (AttemptView, error) {
+	questions, err := s.attemptQuestions(ctx, a)
+	if err != nil {
+		return AttemptView{}, err
+	}
+	// тот же seed даёт тот же порядок вариантов при повторном открытии попытки
+	rnd := rand.New(rand.NewSource(a.Seed))
+	view := AttemptView{Attempt: a, Questions: make([]dom.PublicQuestion, 0, len(questions))}
+	for _, q := range questions {
+		view.Questions = append(view.Questions, q.Public(rnd, settings.Shuffle))
+	}
+	return view, nil
+}
@@ -0,0 +1,233 @@
+package revision
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	assigndom "github.com/example/learngo/internal/domain/assignment"
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	dom "github.com/example/learngo/internal/domain/revision"
+	assignuc "github.com/example/learngo/internal/usecase/assignment"
+	lessonuc "github.com/example/learngo/internal/usecase/lesson"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound       = errors.New("revision not found")
+	ErrEntityNotFound = errors.New("entity not found")
+	ErrUnknownKind    = errors.New("unknown entity kind")
+)
+
+// DiffResult структурный diff между двумя ревизиями.
+type DiffResult struct {
+	From    int          `json:"from"`
+	To      int          `json:"to"`
+	Changes []dom.Change `json:"changes"`
+}
+
+type Service interface {
+	// RecordLesson и RecordAssignment сохраняют состояние после изменения как новую ревизию.
+	RecordLesson(ctx context.Context, l lessondom.Lesson, authorID uuid.UUID) (dom.Revision, error)
+	RecordAssignment(ctx context.Context, a assigndom.Assignment, authorID uuid.UUID) (dom.Revision, error)
+	// EnsureBaseline сохраняет текущее состояние сущности ревизией без автора, если история ещё пуста.
+	// Вызывается перед изменением, чтобы к правкам, сделанным до появления истории, можно было откатиться.
+	EnsureBaseline(ctx context.Context, kind string, id uuid.UUID) error
+	List(ctx context.Context, kind string, id uuid.UUID) ([]dom.Revision, error)
+	Get(ctx context.Context, kind string, id uuid.UUID, number int) (dom.Revision, error)
+	// Diff сравнивает ревизии from и to; to <= 0 — последняя ревизия.
+	Diff(ctx context.Context, kind string, id uuid.UUID, from, to int) (DiffResult, error)
+	// Rollback применяет снимок ревизии к сущности и записывает результат новой ревизией.
+	Rollback(ctx context.Context, kind string, id uuid.UUID, number int, authorID uuid.UUID) (dom.Revision, error)
+}
+
+// lessonSnapshot содержимое урока в ревизии; навигация next/prev не хранится —
+// она пересчитывается при любой перестановке и только зашумляла бы diff.
+type lessonSnapshot struct {
+	Title           string          `json:"title"`
+	Type            string          `json:"type"`
+	Content         json.RawMessage `json:"content"`
+	ModuleID        uuid.UUID       `json:"module_id"`
+	Order           int             `json:"order"`
+	DurationMinutes int             `json:"duration_minutes"`
+	IsFree          bool            `json:"is_free"`
+}
+
+type assignmentSnapshot struct {
+	Title        string                 `json:"title"`
+	Prompt       string                 `json:"prompt"`
+	StarterCode  string                 `json:"starter_code"`
+	StarterFiles map[string]string      `json:"starter_files,omitempty"`
+	Tests        string                 `json:"tests"`
+	HiddenTests  string                 `json:"hidden_tests,omitempty"`
+	Performance  *assigndom.Performance `json:"performance,omitempty"`
+	Order        int                    `json:"order"`
+}
+
+type service struct {
+	repo        dom.Repository
+	lessons     lessonuc.Service
+	assignments assignuc.Service
+	logger      *utils.Logger
+	now         func() time.Time
+}
+
+func NewService(repo dom.Repository, lessons lessonuc.Service, assignments assignuc.Service, logger *utils.Logger) Service {
+	return &service{repo: repo, lessons: lessons, assignments: assignments, logger: logger, now: time.Now}
+}
+
+func (s *service) append(ctx context.Context, kind string, id uuid.UUID, title string, snapshot interface{}, authorID uuid.UUID, restoredFrom int) (dom.Revision, error) {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return dom.Revision{}, err
+	}
+	return s.repo.Append(ctx, dom.Revision{
+		ID:           uuid.New(),
+		EntityType:   kind,
+		EntityID:     id,
+		AuthorID:     authorID,
+		Title:        title,
+		Snapshot:     raw,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    s.now().UTC(),
+	})
+}
+
+func toLessonSnapshot(l lessondom.Lesson) lessonSnapshot {
+	return lessonSnapshot{
+		Title:           l.Title,
+		Type:            lessondom.NormalizeType(l.Type),
+		Content:         l.Content,
+		ModuleID:        l.Module(),
+		Order:           l.Order,
+		DurationMinutes: l.DurationMinutes,
+		IsFree:          l.IsFree,
+	}
+}
+
+func toAssignmentSnapshot(a assigndom.Assignment) assignmentSnapshot {
+	return assignmentSnapshot{Title: a.Title, Prompt: a.Prompt, StarterCode: a.StarterCode, StarterFiles: a.StarterFiles, Tests: a.Tests, HiddenTests: a.HiddenTests, Performance: a.Performance, Order: a.Order}
+}
+
+func (s *service) RecordLesson(ctx context.Context, l lessondom.Lesson, authorID uuid.UUID) (dom.Revision, error) {
+	return s.append(ctx, dom.KindLesson, l.ID, l.Title, toLessonSnapshot(l), authorID, 0)
+}
+
+func (s *service) RecordAssignment(ctx context.Context, a assigndom.Assignment, authorID uuid.UUID) (dom.Revision, error) {
+	return s.append(ctx, dom.KindAssignment, a.ID, a.Title, toAssignmentSnapshot(a), authorID, 0)
+}
+
+func (s *service) EnsureBaseline(ctx context.Context, kind string, id uuid.UUID) error {
+	latest, err := s.repo.Latest(ctx, kind, id)
+	if err != nil || latest.ID != uuid.Nil {
+		return err
+	}
+	switch kind {
+	case dom.KindLesson:
+		l, err := s.lessons.Get(ctx, id)
+		if err != nil || l.ID == uuid.Nil {
+			return err
+		}
+		_, err = s.RecordLesson(ctx, l, uuid.Nil)
+		return err
+	case dom.KindAssignment:
+		a, err := s.assignments.Get(ctx, id)
+		if err != nil || a.ID == uuid.Nil {
+			return err
+		}
+		_, err = s.RecordAssignment(ctx, a, uuid.Nil)
+		return err
+	}
+	return ErrUnknownKind
+}
+
+func (s *service) List(ctx context.Context, kind string, id uuid.UUID) ([]dom.Revision, error) {
+	if kind != dom.KindLesson && kind != dom.KindAssignment {
+		return nil, ErrUnknownKind
+	}
+	return s.repo.List(ctx, kind, id)
+}
+
+func (s *service) Get(ctx context.Context, kind string, id uuid.UUID, number int) (dom.Revision, error) {
+	if kind != dom.KindLesson && kind != dom.KindAssignment {
+		return dom.Revision{}, ErrUnknownKind
+	}
+	rev, err := s.repo.Get(ctx, kind, id, number)
+	if err != nil {
+		return dom.Revision{}, err
+	}
+	if rev.ID == uuid.Nil {
+		return dom.Revision{}, fmt.Errorf("%w: %s %s has no revision %d", ErrNotFound, kind, id, number)
+	}
+	return rev, nil
+}
+
+func (s *service) Diff(ctx context.Context, kind string, id uuid.UUID, from, to int) (DiffResult, error) {
+	if to <= 0 {
+		latest, err := s.repo.Latest(ctx, kind, id)
+		if err != nil {
+			return DiffResult{}, err
+		}
+		if latest.ID == uuid.Nil {
+			return DiffResult{}, fmt.Errorf("%w: %s %s has no history", ErrNotFound, kind, id)
+		}
+		to = latest.Number
+	}
+	a, err := s.Get(ctx, kind, id, from)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	b, err := s.Get(ctx, kind, id, to)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	changes, err := dom.Diff(a.Snapshot, b.Snapshot)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	return DiffResult{From: from, To: to, Changes: changes}, nil
+}
+
+func (s *service) Rollback(ctx context.Context, kind string, id uuid.UUID, number int, authorID uuid.UUID) (dom.Revision, error) {
+	rev, err := s.Get(ctx, kind, id, number)
+	if err != nil {
+		return dom.Revision{}, err
+	}
+	switch kind {
+	case dom.KindLesson:
+		var snap lessonSnapshot
+		if err := json.Unmarshal(rev.Snapshot, &snap); err != nil {
+			return dom.Revision{}, err
+		}
+		// положение урока не откатываем: с тех пор курс мог быть переупорядочен
+		l, err := s.lessons.Update(ctx, id, snap.Title, snap.Type, string(snap.Content), 0, uuid.Nil)
+		if err != nil {
+			return dom.Revision{}, err
+		}
+		if l.ID == uuid.Nil {
+			return dom.Revision{}, ErrEntityNotFound
+		}
+		return s.append(ctx, kind, id, l.Title, toLessonSnapshot(l), authorID, number)
+	case dom.KindAssignment:
+		var snap assignmentSnapshot
+		if err := json.Unmarshal(rev.Snapshot, &snap); err != nil {
+			return dom.Revision{}, err
+		}
+		current, err := s.assignments.Get(ctx, id)
+		if err != nil {
+			return dom.Revision{}, err
+		}
+		if current.ID == uuid.Nil {
+			return dom.Revision{}, ErrEntityNotFound
+		}
+		a, err := s.assignments.Update(ctx, id, snap.Title, snap.Prompt, snap.StarterCode, snap.StarterFiles, snap.Tests, snap.HiddenTests, snap.Performance, current.Order)
+		if err != nil {
+			return dom.Revision{}, err
+		}
+		return s.append(ctx, kind, id, a.Title, toAssignmentSnapshot(a), authorID, number)
+	}
+	return dom.Revision{}, ErrUnknownKind
+}
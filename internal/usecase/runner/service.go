@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/example/learngo/pkg/sandbox"
 	"github.com/example/learngo/pkg/utils"
 )
 
@@ -25,16 +26,18 @@ type Service interface {
 	Execute(ctx context.Context, code string) (Result, error)
 }
 
-// service простая dev-реализация через локальный go run (не для продакшена).
+// service выполняет код в песочнице; без неё — через локальный go run (только dev).
 type service struct {
-	logger *utils.Logger
+	logger  *utils.Logger
+	sandbox *sandbox.Sandbox
 	// таймаут на выполнение
 	timeout time.Duration
 }
 
-// NewService создаёт dev-реализацию.
-func NewService(logger *utils.Logger) Service {
-	return &service{logger: logger, timeout: 5 * time.Second}
+// NewService создаёт раннер. sb == nil — dev-режим без изоляции.
+// Бинарник, использующий песочницу, должен вызывать sandbox.Init в начале main.
+func NewService(logger *utils.Logger, sb *sandbox.Sandbox) Service {
+	return &service{logger: logger, sandbox: sb, timeout: 5 * time.Second}
 }
 
 func (s *service) Execute(ctx context.Context, code string) (Result, error) {
@@ -42,6 +45,10 @@ func (s *service) Execute(ctx context.Context, code string) (Result, error) {
 		return Result{}, errors.New("empty code")
 	}
 
+	if s.sandbox != nil {
+		return s.executeSandboxed(ctx, code)
+	}
+
 	// Контекст с таймаутом
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
@@ -85,3 +92,30 @@ func (s *service) Execute(ctx context.Context, code string) (Result, error) {
 	}
 	return res, nil
 }
+
+// executeSandboxed собирает и запускает код в песочнице; лимиты времени задаёт её конфигурация.
+func (s *service) executeSandboxed(ctx context.Context, code string) (Result, error) {
+	start := time.Now()
+	out, err := s.sandbox.RunGo(ctx, code, "")
+	if err != nil {
+		s.logger.Error("sandbox run failed", "error", err)
+		return Result{}, err
+	}
+	phase := out.Build
+	if out.Compiled {
+		phase = out.Run
+	}
+	res := Result{
+		Stdout:    phase.Stdout,
+		Stderr:    phase.Stderr,
+		ExitCode:  phase.ExitCode,
+		ElapsedMs: time.Since(start).Milliseconds(),
+	}
+	if phase.TimedOut {
+		res.Stderr += "\ntime limit exceeded"
+	}
+	if phase.OutputTruncated {
+		res.Stderr += "\noutput limit exceeded, output truncated"
+	}
+	return res, nil
+}
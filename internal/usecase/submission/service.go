@@ -0,0 +1,116 @@
+package submission
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dom "github.com/example/learngo/internal/domain/submission"
+	"github.com/google/uuid"
+)
+
+var ErrNotFound = errors.New("submission not found")
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Page страница попыток.
+type Page struct {
+	Submissions []dom.Submission `json:"submissions"`
+	Total       int64            `json:"total"`
+	Page        int              `json:"page"`
+	PageSize    int              `json:"page_size"`
+}
+
+// Service история попыток. Студент видит только свои попытки и без вывода
+// скрытых тестов; преподаватели и администраторы — все и полностью.
+type Service interface {
+	// Record сохраняет проверенную попытку; лучшая попытка пересчитывается автоматически.
+	Record(ctx context.Context, s dom.Submission) (dom.Submission, error)
+	Get(ctx context.Context, id, userID uuid.UUID, role string) (dom.Submission, error)
+	List(ctx context.Context, f dom.Filter, userID uuid.UUID, role string) (Page, error)
+	// Best лучшая попытка пользователя по заданию.
+	Best(ctx context.Context, ownerID, assignmentID, userID uuid.UUID, role string) (dom.Submission, error)
+}
+
+type service struct {
+	repo dom.Repository
+	now  func() time.Time
+}
+
+func NewService(repo dom.Repository) Service {
+	return &service{repo: repo, now: time.Now}
+}
+
+func isStaff(role string) bool { return role == "admin" || role == "teacher" }
+
+func (s *service) Record(ctx context.Context, sub dom.Submission) (dom.Submission, error) {
+	if sub.ID == uuid.Nil {
+		sub.ID = uuid.New()
+	}
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = s.now().UTC()
+	}
+	return s.repo.Create(ctx, sub)
+}
+
+func (s *service) Get(ctx context.Context, id, userID uuid.UUID, role string) (dom.Submission, error) {
+	sub, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return dom.Submission{}, err
+	}
+	if sub.ID == uuid.Nil {
+		return dom.Submission{}, ErrNotFound
+	}
+	if isStaff(role) {
+		return sub, nil
+	}
+	if sub.UserID != userID {
+		return dom.Submission{}, ErrNotFound
+	}
+	return sub.Redacted(), nil
+}
+
+func (s *service) List(ctx context.Context, f dom.Filter, userID uuid.UUID, role string) (Page, error) {
+	if !isStaff(role) {
+		f.UserID = &userID
+	}
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.PageSize < 1 {
+		f.PageSize = defaultPageSize
+	}
+	if f.PageSize > maxPageSize {
+		f.PageSize = maxPageSize
+	}
+	list, total, err := s.repo.List(ctx, f)
+	if err != nil {
+		return Page{}, err
+	}
+	if !isStaff(role) {
+		for i := range list {
+			list[i] = list[i].Redacted()
+		}
+	}
+	return Page{Submissions: list, Total: total, Page: f.Page, PageSize: f.PageSize}, nil
+}
+
+func (s *service) Best(ctx context.Context, ownerID, assignmentID, userID uuid.UUID, role string) (dom.Submission, error) {
+	if !isStaff(role) && ownerID != userID {
+		return dom.Submission{}, ErrNotFound
+	}
+	sub, err := s.repo.Best(ctx, ownerID, assignmentID)
+	if err != nil {
+		return dom.Submission{}, err
+	}
+	if sub.ID == uuid.Nil {
+		return dom.Submission{}, ErrNotFound
+	}
+	if !isStaff(role) {
+		return sub.Redacted(), nil
+	}
+	return sub, nil
+}
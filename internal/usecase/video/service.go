@@ -0,0 +1,387 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	lessondom "github.com/example/learngo/internal/domain/lesson"
+	dom "github.com/example/learngo/internal/domain/video"
+	enrolluc "github.com/example/learngo/internal/usecase/enrollment"
+	lessonuc "github.com/example/learngo/internal/usecase/lesson"
+	"github.com/example/learngo/pkg/storage"
+	"github.com/example/learngo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound       = errors.New("video asset not found")
+	ErrLessonNotFound = errors.New("lesson not found")
+	ErrNotVideoLesson = errors.New("lesson is not a video lesson")
+	ErrInvalidUpload  = errors.New("invalid upload")
+	ErrUploadClosed   = errors.New("upload is not in progress")
+	ErrNotReady       = errors.New("video is not uploaded yet")
+	ErrNotEnrolled    = errors.New("enrollment required")
+)
+
+// maxParts ограничение S3 на число частей multipart-загрузки.
+const maxParts = 10000
+
+// minPartSize минимальный размер части S3 (кроме последней).
+const minPartSize = 5 << 20
+
+// ObjectStorage операции объектного хранилища, нужные видео-пайплайну (реализация — storage.S3Client).
+type ObjectStorage interface {
+	NewMultipartUpload(ctx context.Context, key, contentType string) (string, error)
+	PresignUploadPart(ctx context.Context, key, uploadID string, partNumber int, expiry time.Duration) (string, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []storage.CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+	PutObject(ctx context.Context, key, contentType string, data []byte) error
+	StatObject(ctx context.Context, key string) (storage.ObjectInfo, error)
+	RemoveObject(ctx context.Context, key string) error
+}
+
+// Options лимиты загрузки и время жизни подписанных ссылок.
+type Options struct {
+	PartSize     int64
+	MaxSize      int64
+	UploadURLTTL time.Duration
+	PlaybackTTL  time.Duration
+}
+
+// UploadPart подписанная ссылка на PUT одной части.
+type UploadPart struct {
+	PartNumber int    `json:"part_number"`
+	URL        string `json:"url"`
+}
+
+// UploadSession всё, что нужно клиенту для загрузки файла частями напрямую в S3.
+type UploadSession struct {
+	Asset     dom.Asset    `json:"asset"`
+	PartSize  int64        `json:"part_size"`
+	Parts     []UploadPart `json:"parts"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// CaptionTrack субтитры с подписанной ссылкой.
+type CaptionTrack struct {
+	Locale string `json:"locale"`
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+}
+
+// Playback подписанные кратковременные ссылки на видео и субтитры.
+type Playback struct {
+	AssetID     uuid.UUID      `json:"asset_id"`
+	URL         string         `json:"url"`
+	ContentType string         `json:"content_type"`
+	DurationSec int            `json:"duration_sec"`
+	Captions    []CaptionTrack `json:"captions"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+}
+
+type Service interface {
+	// StartUpload создаёт видеофайл для урока и подписывает ссылки на все части.
+	StartUpload(ctx context.Context, lessonID, authorID uuid.UUID, filename, contentType string, size int64) (UploadSession, error)
+	// CompleteUpload собирает файл из частей и привязывает его к уроку (content.video_asset_id).
+	CompleteUpload(ctx context.Context, assetID uuid.UUID, parts []storage.CompletedPart, durationSec int) (dom.Asset, error)
+	AbortUpload(ctx context.Context, assetID uuid.UUID) error
+	Get(ctx context.Context, assetID uuid.UUID) (dom.Asset, error)
+	ListByLesson(ctx context.Context, lessonID uuid.UUID) ([]dom.Asset, error)
+	// UploadCaptions валидирует WebVTT и сохраняет субтитры для локали (заменяя прежние).
+	UploadCaptions(ctx context.Context, assetID uuid.UUID, locale, label string, data []byte) (dom.Asset, error)
+	// Playback выдаёт ссылки только записанным на курс; бесплатные уроки и privileged (преподаватели) — без проверки.
+	Playback(ctx context.Context, lessonID, userID uuid.UUID, privileged bool) (Playback, error)
+}
+
+type service struct {
+	repo        dom.Repository
+	store       ObjectStorage
+	lessons     lessonuc.Service
+	enrollments enrolluc.Service
+	logger      *utils.Logger
+	opts        Options
+	now         func() time.Time
+}
+
+func NewService(repo dom.Repository, store ObjectStorage, lessons lessonuc.Service, enrollments enrolluc.Service, logger *utils.Logger, opts Options) Service {
+	if opts.PartSize < minPartSize {
+		opts.PartSize = minPartSize
+	}
+	if opts.UploadURLTTL <= 0 {
+		opts.UploadURLTTL = time.Hour
+	}
+	if opts.PlaybackTTL <= 0 {
+		opts.PlaybackTTL = 10 * time.Minute
+	}
+	return &service{repo: repo, store: store, lessons: lessons, enrollments: enrollments, logger: logger, opts: opts, now: time.Now}
+}
+
+func (s *service) videoLesson(ctx context.Context, lessonID uuid.UUID) (lessondom.Lesson, *lessondom.VideoContent, error) {
+	l, err := s.lessons.Get(ctx, lessonID)
+	if err != nil {
+		return l, nil, err
+	}
+	if l.ID == uuid.Nil {
+		return l, nil, ErrLessonNotFound
+	}
+	if lessondom.NormalizeType(l.Type) != lessondom.TypeVideo {
+		return l, nil, ErrNotVideoLesson
+	}
+	c, err := lessondom.DecodeContent(l.Type, l.Content)
+	if err != nil {
+		return l, nil, err
+	}
+	return l, c.(*lessondom.VideoContent), nil
+}
+
+func (s *service) getAsset(ctx context.Context, id uuid.UUID) (dom.Asset, error) {
+	a, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return a, err
+	}
+	if a.ID == uuid.Nil {
+		return a, ErrNotFound
+	}
+	return a, nil
+}
+
+func (s *service) StartUpload(ctx context.Context, lessonID, authorID uuid.UUID, filename, contentType string, size int64) (UploadSession, error) {
+	l, _, err := s.videoLesson(ctx, lessonID)
+	if err != nil {
+		return UploadSession{}, err
+	}
+	if !strings.HasPrefix(contentType, "video/") {
+		return UploadSession{}, fmt.Errorf("%w: content type must be video/*", ErrInvalidUpload)
+	}
+	if size <= 0 || (s.opts.MaxSize > 0 && size > s.opts.MaxSize) {
+		return UploadSession{}, fmt.Errorf("%w: size must be between 1 and %d bytes", ErrInvalidUpload, s.opts.MaxSize)
+	}
+	partSize := s.opts.PartSize
+	if (size+partSize-1)/partSize > maxParts {
+		partSize = (size + maxParts - 1) / maxParts
+	}
+	count := int((size + partSize - 1) / partSize)
+
+	id := uuid.New()
+	ext := strings.ToLower(path.Ext(filename))
+	key := fmt.Sprintf("videos/%s/%s/source%s", l.CourseID, id, ext)
+	uploadID, err := s.store.NewMultipartUpload(ctx, key, contentType)
+	if err != nil {
+		return UploadSession{}, err
+	}
+	now := s.now().UTC()
+	asset, err := s.repo.Create(ctx, dom.Asset{
+		ID:          id,
+		LessonID:    l.ID,
+		CourseID:    l.CourseID,
+		ObjectKey:   key,
+		UploadID:    uploadID,
+		Status:      dom.StatusUploading,
+		ContentType: contentType,
+		SizeBytes:   size,
+		Captions:    []dom.Caption{},
+		CreatedBy:   authorID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		_ = s.store.AbortMultipartUpload(ctx, key, uploadID)
+		return UploadSession{}, err
+	}
+	parts := make([]UploadPart, 0, count)
+	for n := 1; n <= count; n++ {
+		u, err := s.store.PresignUploadPart(ctx, key, uploadID, n, s.opts.UploadURLTTL)
+		if err != nil {
+			return UploadSession{}, err
+		}
+		parts = append(parts, UploadPart{PartNumber: n, URL: u})
+	}
+	return UploadSession{Asset: asset, PartSize: partSize, Parts: parts, ExpiresAt: now.Add(s.opts.UploadURLTTL)}, nil
+}
+
+func (s *service) CompleteUpload(ctx context.Context, assetID uuid.UUID, parts []storage.CompletedPart, durationSec int) (dom.Asset, error) {
+	a, err := s.getAsset(ctx, assetID)
+	if err != nil {
+		return a, err
+	}
+	if a.Status != dom.StatusUploading {
+		return dom.Asset{}, ErrUploadClosed
+	}
+	if len(parts) == 0 {
+		return dom.Asset{}, fmt.Errorf("%w: parts are required", ErrInvalidUpload)
+	}
+	seen := make(map[int]bool, len(parts))
+	for _, p := range parts {
+		if p.PartNumber < 1 || p.PartNumber > maxParts || p.ETag == "" || seen[p.PartNumber] {
+			return dom.Asset{}, fmt.Errorf("%w: part %d is invalid or duplicated", ErrInvalidUpload, p.PartNumber)
+		}
+		seen[p.PartNumber] = true
+	}
+	if err := s.store.CompleteMultipartUpload(ctx, a.ObjectKey, a.UploadID, parts); err != nil {
+		return dom.Asset{}, fmt.Errorf("%w: %v", ErrInvalidUpload, err)
+	}
+	// размер из StartUpload заявлен клиентом, проверяем фактический размер собранного объекта
+	info, err := s.store.StatObject(ctx, a.ObjectKey)
+	if err != nil {
+		s.fail(ctx, a)
+		return dom.Asset{}, fmt.Errorf("stat uploaded video: %w", err)
+	}
+	if s.opts.MaxSize > 0 && info.Size > s.opts.MaxSize {
+		s.fail(ctx, a)
+		return dom.Asset{}, fmt.Errorf("%w: uploaded %d bytes, limit is %d", ErrInvalidUpload, info.Size, s.opts.MaxSize)
+	}
+	a.SizeBytes = info.Size
+	a.Status = dom.StatusReady
+	a.UploadID = ""
+	if durationSec > 0 {
+		a.DurationSec = durationSec
+	}
+	a.UpdatedAt = s.now().UTC()
+	a, err = s.repo.Update(ctx, a)
+	if err != nil {
+		return dom.Asset{}, err
+	}
+	if err := s.attach(ctx, a); err != nil {
+		return dom.Asset{}, err
+	}
+	return a, nil
+}
+
+// fail удаляет собранный объект и помечает файл как failed; ошибки только логируются,
+// вызывающий уже возвращает клиенту основную причину.
+func (s *service) fail(ctx context.Context, a dom.Asset) {
+	if err := s.store.RemoveObject(ctx, a.ObjectKey); err != nil {
+		s.logger.Warn("video remove failed", "asset_id", a.ID, "error", err)
+	}
+	a.Status = dom.StatusFailed
+	a.UploadID = ""
+	a.UpdatedAt = s.now().UTC()
+	if _, err := s.repo.Update(ctx, a); err != nil {
+		s.logger.Error("video mark failed", "asset_id", a.ID, "error", err)
+	}
+}
+
+// attach записывает файл в контент урока; внешний video_url больше не нужен.
+func (s *service) attach(ctx context.Context, a dom.Asset) error {
+	l, content, err := s.videoLesson(ctx, a.LessonID)
+	if err != nil {
+		return err
+	}
+	content.VideoAssetID = a.ID.String()
+	content.VideoURL = ""
+	if a.DurationSec > 0 {
+		content.DurationSec = a.DurationSec
+	}
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	_, err = s.lessons.Update(ctx, l.ID, l.Title, l.Type, string(raw), 0, uuid.Nil)
+	return err
+}
+
+func (s *service) AbortUpload(ctx context.Context, assetID uuid.UUID) error {
+	a, err := s.getAsset(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if a.Status != dom.StatusUploading {
+		return ErrUploadClosed
+	}
+	if err := s.store.AbortMultipartUpload(ctx, a.ObjectKey, a.UploadID); err != nil {
+		return err
+	}
+	a.Status = dom.StatusAborted
+	a.UploadID = ""
+	a.UpdatedAt = s.now().UTC()
+	_, err = s.repo.Update(ctx, a)
+	return err
+}
+
+func (s *service) Get(ctx context.Context, assetID uuid.UUID) (dom.Asset, error) {
+	return s.getAsset(ctx, assetID)
+}
+
+func (s *service) ListByLesson(ctx context.Context, lessonID uuid.UUID) ([]dom.Asset, error) {
+	return s.repo.ListByLesson(ctx, lessonID)
+}
+
+func (s *service) UploadCaptions(ctx context.Context, assetID uuid.UUID, locale, label string, data []byte) (dom.Asset, error) {
+	a, err := s.getAsset(ctx, assetID)
+	if err != nil {
+		return a, err
+	}
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" || len(locale) > 16 || strings.ContainsAny(locale, "/\\. ") {
+		return dom.Asset{}, fmt.Errorf("%w: invalid locale", dom.ErrInvalidCaptions)
+	}
+	if _, err := dom.ValidateWebVTT(data); err != nil {
+		return dom.Asset{}, err
+	}
+	if label == "" {
+		label = locale
+	}
+	key := fmt.Sprintf("videos/%s/%s/captions/%s.vtt", a.CourseID, a.ID, locale)
+	if err := s.store.PutObject(ctx, key, "text/vtt; charset=utf-8", data); err != nil {
+		return dom.Asset{}, err
+	}
+	a.SetCaption(dom.Caption{Locale: locale, Label: label, ObjectKey: key})
+	a.UpdatedAt = s.now().UTC()
+	return s.repo.Update(ctx, a)
+}
+
+func (s *service) Playback(ctx context.Context, lessonID, userID uuid.UUID, privileged bool) (Playback, error) {
+	l, content, err := s.videoLesson(ctx, lessonID)
+	if err != nil {
+		return Playback{}, err
+	}
+	if !privileged && !l.IsFree {
+		if userID == uuid.Nil {
+			return Playback{}, ErrNotEnrolled
+		}
+		ok, err := s.enrollments.IsEnrolled(ctx, userID, l.CourseID)
+		if err != nil {
+			return Playback{}, err
+		}
+		if !ok {
+			return Playback{}, ErrNotEnrolled
+		}
+	}
+	assetID, err := uuid.Parse(content.VideoAssetID)
+	if err != nil {
+		return Playback{}, ErrNotReady
+	}
+	a, err := s.getAsset(ctx, assetID)
+	if err != nil {
+		return Playback{}, err
+	}
+	if a.Status != dom.StatusReady {
+		return Playback{}, ErrNotReady
+	}
+	u, err := s.store.PresignGet(ctx, a.ObjectKey, s.opts.PlaybackTTL)
+	if err != nil {
+		return Playback{}, err
+	}
+	p := Playback{
+		AssetID:     a.ID,
+		URL:         u,
+		ContentType: a.ContentType,
+		DurationSec: a.DurationSec,
+		Captions:    make([]CaptionTrack, 0, len(a.Captions)),
+		ExpiresAt:   s.now().UTC().Add(s.opts.PlaybackTTL),
+	}
+	for _, c := range a.Captions {
+		cu, err := s.store.PresignGet(ctx, c.ObjectKey, s.opts.PlaybackTTL)
+		if err != nil {
+			return Playback{}, err
+		}
+		p.Captions = append(p.Captions, CaptionTrack{Locale: c.Locale, Label: c.Label, URL: cu})
+	}
+	return p, nil
+}
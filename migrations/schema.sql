@@ -8,6 +8,7 @@ CREATE TABLE IF NOT EXISTS users (
     password_hash VARCHAR(255) NOT NULL,
     name VARCHAR(100) NOT NULL DEFAULT '',
     avatar_url TEXT,
+    locale VARCHAR(16) NOT NULL DEFAULT '',
     role VARCHAR(32) NOT NULL,
     created_at TIMESTAMP NOT NULL,
     updated_at TIMESTAMP NOT NULL,
@@ -21,6 +22,7 @@ CREATE TABLE IF NOT EXISTS courses (
     title VARCHAR(255) NOT NULL,
     description TEXT NOT NULL,
     summary TEXT NOT NULL DEFAULT '',
+    source_locale VARCHAR(16) NOT NULL DEFAULT 'ru',
     language VARCHAR(32) NOT NULL DEFAULT 'go',
     difficulty VARCHAR(32) NOT NULL DEFAULT 'beginner',
     duration_hours INTEGER NOT NULL DEFAULT 0,
@@ -34,7 +36,11 @@ CREATE TABLE IF NOT EXISTS courses (
     price DECIMAL(10,2),
     price_cents INTEGER NOT NULL DEFAULT 0,
     rating DOUBLE PRECISION NOT NULL DEFAULT 0,
-    popularity INTEGER NOT NULL DEFAULT 0
+    popularity INTEGER NOT NULL DEFAULT 0,
+    lessons_count INTEGER NOT NULL DEFAULT 0,
+    students_count INTEGER NOT NULL DEFAULT 0,
+    trending_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    analyzers_json TEXT -- JSON-массив проверок статического анализа; NULL — все
 );
 
 -- Modules table
@@ -140,9 +146,232 @@ CREATE TABLE IF NOT EXISTS assignments (
     title VARCHAR(255) NOT NULL,
     prompt TEXT NOT NULL,
     starter_code TEXT NOT NULL,
+    starter_files JSONB NOT NULL DEFAULT '{}'::jsonb,
     tests TEXT NOT NULL,
+    hidden_tests TEXT NOT NULL DEFAULT '',
+    performance JSONB, -- бенчмарки, эталон и пороги заданий на скорость
     sort_order INTEGER NOT NULL
 );
 
 CREATE INDEX IF NOT EXISTS idx_assignments_lesson_id ON assignments(lesson_id);
 
+-- Translations table (переводы курсов и уроков)
+CREATE TABLE IF NOT EXISTS translations (
+    id UUID PRIMARY KEY,
+    entity_type VARCHAR(16) NOT NULL,
+    entity_id UUID NOT NULL,
+    locale VARCHAR(16) NOT NULL,
+    fields JSONB NOT NULL DEFAULT '{}'::jsonb,
+    source_hashes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    updated_by UUID,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_translations_entity_locale ON translations(entity_type, entity_id, locale);
+
+-- Quiz questions (банк вопросов урока-квиза; correct/accepted не отдаются студентам)
+CREATE TABLE IF NOT EXISTS quiz_questions (
+    id UUID PRIMARY KEY,
+    lesson_id UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    prompt TEXT NOT NULL,
+    code TEXT NOT NULL DEFAULT '',
+    options JSONB NOT NULL DEFAULT '[]'::jsonb,
+    correct JSONB NOT NULL DEFAULT '[]'::jsonb,
+    accepted JSONB NOT NULL DEFAULT '[]'::jsonb,
+    case_sensitive BOOLEAN NOT NULL DEFAULT false,
+    points INTEGER NOT NULL DEFAULT 1,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_lesson_id ON quiz_questions(lesson_id);
+
+-- Quiz attempts
+CREATE TABLE IF NOT EXISTS quiz_attempts (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    lesson_id UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    course_id UUID NOT NULL,
+    seed BIGINT NOT NULL,
+    question_ids JSONB NOT NULL DEFAULT '[]'::jsonb,
+    status VARCHAR(16) NOT NULL,
+    answers JSONB NOT NULL DEFAULT '[]'::jsonb,
+    results JSONB NOT NULL DEFAULT '[]'::jsonb,
+    score INTEGER NOT NULL DEFAULT 0,
+    max_score INTEGER NOT NULL DEFAULT 0,
+    percent INTEGER NOT NULL DEFAULT 0,
+    passed BOOLEAN NOT NULL DEFAULT false,
+    started_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP,
+    submitted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_attempts_user_lesson ON quiz_attempts(user_id, lesson_id);
+
+-- Revisions (история уроков и заданий: полный снимок после каждого изменения)
+CREATE TABLE IF NOT EXISTS revisions (
+    id UUID PRIMARY KEY,
+    entity_type VARCHAR(16) NOT NULL,
+    entity_id UUID NOT NULL,
+    number INTEGER NOT NULL,
+    author_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    snapshot JSONB NOT NULL DEFAULT '{}'::jsonb,
+    restored_from INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revisions_entity_number ON revisions(entity_type, entity_id, number);
+
+-- Video assets (исходники видеоуроков в S3; captions — [{locale,label,object_key}])
+CREATE TABLE IF NOT EXISTS video_assets (
+    id UUID PRIMARY KEY,
+    lesson_id UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    course_id UUID NOT NULL,
+    object_key VARCHAR(512) NOT NULL,
+    upload_id VARCHAR(512) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL,
+    content_type VARCHAR(128) NOT NULL DEFAULT '',
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    duration_sec INTEGER NOT NULL DEFAULT 0,
+    captions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_assets_lesson_id ON video_assets(lesson_id);
+
+-- Discussions (вопросы к урокам, ответы с вложенностью, голоса)
+CREATE TABLE IF NOT EXISTS discussion_threads (
+    id UUID PRIMARY KEY,
+    lesson_id UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    course_id UUID NOT NULL,
+    author_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    code_language VARCHAR(16) NOT NULL DEFAULT '',
+    code_source TEXT NOT NULL DEFAULT '',
+    upvotes INTEGER NOT NULL DEFAULT 0,
+    replies_count INTEGER NOT NULL DEFAULT 0,
+    accepted_post_id UUID,
+    status VARCHAR(16) NOT NULL DEFAULT 'visible',
+    locked BOOLEAN NOT NULL DEFAULT false,
+    last_activity_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    edited_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_discussion_threads_lesson_id ON discussion_threads(lesson_id);
+CREATE INDEX IF NOT EXISTS idx_discussion_threads_last_activity_at ON discussion_threads(last_activity_at);
+
+CREATE TABLE IF NOT EXISTS discussion_posts (
+    id UUID PRIMARY KEY,
+    thread_id UUID NOT NULL REFERENCES discussion_threads(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES discussion_posts(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    code_language VARCHAR(16) NOT NULL DEFAULT '',
+    code_source TEXT NOT NULL DEFAULT '',
+    upvotes INTEGER NOT NULL DEFAULT 0,
+    accepted BOOLEAN NOT NULL DEFAULT false,
+    status VARCHAR(16) NOT NULL DEFAULT 'visible',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    edited_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_discussion_posts_thread_id ON discussion_posts(thread_id);
+
+CREATE TABLE IF NOT EXISTS discussion_votes (
+    target_type VARCHAR(16) NOT NULL,
+    target_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (target_type, target_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_discussion_votes_user_id ON discussion_votes(user_id);
+
+-- Notes & bookmarks (личные заметки студентов, полнотекстовый поиск)
+CREATE TABLE IF NOT EXISTS notes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    course_id UUID NOT NULL,
+    lesson_id UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    snippet_id VARCHAR(64) NOT NULL DEFAULT '',
+    snippet_language VARCHAR(16) NOT NULL DEFAULT '',
+    snippet_code TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(body, '') || ' ' || coalesce(snippet_code, ''))) STORED
+);
+
+CREATE INDEX IF NOT EXISTS idx_notes_user_course ON notes(user_id, course_id);
+CREATE INDEX IF NOT EXISTS idx_notes_lesson_id ON notes(lesson_id);
+CREATE INDEX IF NOT EXISTS idx_notes_search_vector ON notes USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    lesson_id UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    course_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, lesson_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_course_id ON bookmarks(course_id);
+
+-- Submissions (история попыток решения заданий с вердиктами)
+CREATE TABLE IF NOT EXISTS submissions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    course_id UUID NOT NULL,
+    lesson_id UUID NOT NULL REFERENCES lessons(id) ON DELETE CASCADE,
+    assignment_id UUID REFERENCES assignments(id) ON DELETE CASCADE,
+    language VARCHAR(16) NOT NULL,
+    code TEXT NOT NULL,
+    verdict VARCHAR(32) NOT NULL,
+    score INTEGER NOT NULL DEFAULT 0,
+    passed_count INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    tests JSONB NOT NULL DEFAULT '[]'::jsonb,
+    benchmarks JSONB NOT NULL DEFAULT '[]'::jsonb,
+    compile_output TEXT NOT NULL DEFAULT '',
+    execution_time_ms BIGINT NOT NULL DEFAULT 0,
+    memory_kb INTEGER NOT NULL DEFAULT 0,
+    is_best BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_submissions_user_target ON submissions(user_id, assignment_id);
+CREATE INDEX IF NOT EXISTS idx_submissions_course_id ON submissions(course_id);
+CREATE INDEX IF NOT EXISTS idx_submissions_lesson_id ON submissions(lesson_id);
+CREATE INDEX IF NOT EXISTS idx_submissions_verdict ON submissions(verdict);
+CREATE INDEX IF NOT EXISTS idx_submissions_created_at ON submissions(created_at);
+
+-- Поиск заимствований: последний анализ задания и подозрительные пары решений
+CREATE TABLE IF NOT EXISTS plagiarism_reports (
+    assignment_id UUID PRIMARY KEY REFERENCES assignments(id) ON DELETE CASCADE,
+    submissions INTEGER NOT NULL DEFAULT 0,
+    threshold DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS plagiarism_pairs (
+    id UUID PRIMARY KEY,
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    submission_a UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    submission_b UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    user_a UUID NOT NULL,
+    user_b UUID NOT NULL,
+    similarity DOUBLE PRECISION NOT NULL,
+    score_a DOUBLE PRECISION NOT NULL,
+    score_b DOUBLE PRECISION NOT NULL,
+    matches JSONB NOT NULL DEFAULT '[]'::jsonb
+);
+
+CREATE INDEX IF NOT EXISTS idx_plagiarism_pairs_assignment_id ON plagiarism_pairs(assignment_id);
@@ -0,0 +1,102 @@
+package codeexec
+
+import (
+	"sync"
+	"time"
+)
+
+// Состояния автомата отключения бэкенда.
+const (
+	BreakerClosed   = "closed"    // бэкенд работает
+	BreakerOpen     = "open"      // бэкенд отключён до конца паузы
+	BreakerHalfOpen = "half_open" // пауза прошла, идёт пробный запрос
+)
+
+// Breaker отключает бэкенд после threshold сбоев подряд на время cooldown,
+// затем пропускает один пробный запрос: успех включает бэкенд, сбой — снова отключает.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	trial     bool // пробный запрос в полуоткрытом состоянии уже выдан
+	now       func() time.Time
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown, state: BreakerClosed, now: time.Now}
+}
+
+// Allow можно ли отправить запрос в бэкенд.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state, b.trial = BreakerHalfOpen, true
+		return true
+	case BreakerHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	}
+	return true
+}
+
+// Success запрос (или проверка здоровья) прошёл — бэкенд включается.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state, b.failures, b.trial = BreakerClosed, 0, false
+}
+
+// Failure запрос не прошёл.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.open()
+	}
+}
+
+// Release запрос отменён вызывающим, и его исход неизвестен: выданный пробный запрос
+// возвращается, следующий Allow выдаст его снова.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerHalfOpen {
+		b.trial = false
+	}
+}
+
+// Trip отключает бэкенд сразу (проверка здоровья не прошла).
+func (b *Breaker) Trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.open()
+}
+
+func (b *Breaker) open() {
+	b.state, b.openedAt, b.trial = BreakerOpen, b.now(), false
+}
+
+// State текущее состояние; открытый автомат с истёкшей паузой показывается как half_open.
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,167 @@
+package codeexec
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheVersion меняется, когда меняется формат ключа или сохранённого результата.
+const cacheVersion = "1"
+
+// ResultCache кеш результатов выполнения по ключу CacheKey. Кеш не бывает причиной
+// ошибки выполнения: промах и сбой хранилища для вызывающего одинаковы.
+type ResultCache interface {
+	Get(ctx context.Context, key string) (*SubmissionResult, bool)
+	Set(ctx context.Context, key string, res *SubmissionResult)
+}
+
+// CacheKey адрес результата по содержимому: исходник или дерево файлов, язык, ввод, лимиты и тулчейн
+// (см. Router.Toolchain) — всё, от чего зависит результат детерминированной программы.
+func CacheKey(req SubmissionRequest, toolchain string) string {
+	h := sha256.New()
+	var n [8]byte
+	for _, field := range []string{cacheVersion, toolchain, req.SourceCode, req.Stdin} {
+		// длина перед полем: границы полей не сдвинуть содержимым
+		binary.BigEndian.PutUint64(n[:], uint64(len(field)))
+		h.Write(n[:])
+		h.Write([]byte(field))
+	}
+	for _, v := range []int{req.LanguageID, req.CPUTimeLimit, req.MemoryLimit} {
+		binary.BigEndian.PutUint64(n[:], uint64(v))
+		h.Write(n[:])
+	}
+	paths := make([]string, 0, len(req.Files))
+	for p := range req.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		for _, field := range []string{p, req.Files[p]} {
+			binary.BigEndian.PutUint64(n[:], uint64(len(field)))
+			h.Write(n[:])
+			h.Write([]byte(field))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cacheable стоит ли кешировать результат: превышение времени зависит от нагрузки,
+// внутренняя ошибка — от состояния бэкенда, а не от программы.
+func Cacheable(res *SubmissionResult) bool {
+	switch res.Status.ID {
+	case StatusInQueue, StatusProcessing, StatusTimeLimit, StatusInternalError:
+		return false
+	}
+	return true
+}
+
+// MemoryCache LRU-кеш результатов в памяти процесса с ограничением возраста записей.
+type MemoryCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	res     SubmissionResult
+	expires time.Time
+}
+
+// NewMemoryCache capacity <= 0 — 1024 результата; ttl <= 0 — записи не устаревают.
+func NewMemoryCache(capacity int, ttl time.Duration) *MemoryCache {
+	if capacity <= 0 {
+		capacity = 1024
+	}
+	return &MemoryCache{ttl: ttl, capacity: capacity, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string) (*SubmissionResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memoryEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	res := e.res
+	return &res, true
+}
+
+func (c *MemoryCache) Set(_ context.Context, key string, res *SubmissionResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &memoryEntry{key: key, res: *res}
+	if c.ttl > 0 {
+		e.expires = time.Now().Add(c.ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(e)
+	for c.order.Len() > c.capacity {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.items, last.Value.(*memoryEntry).key)
+	}
+}
+
+const cacheKeyPrefix = "codeexec:result:"
+
+// RedisCache кеш результатов в Redis, общий для экземпляров приложения. Пока Redis
+// недоступен, результаты читаются и пишутся в fallback.
+type RedisCache struct {
+	rdb      *redis.Client
+	ttl      time.Duration
+	fallback ResultCache
+}
+
+// NewRedisCache fallback может быть nil — тогда при сбое Redis кеша нет.
+func NewRedisCache(rdb *redis.Client, ttl time.Duration, fallback ResultCache) *RedisCache {
+	return &RedisCache{rdb: rdb, ttl: ttl, fallback: fallback}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (*SubmissionResult, bool) {
+	data, err := c.rdb.Get(ctx, cacheKeyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false
+	}
+	var res SubmissionResult
+	if err == nil && json.Unmarshal(data, &res) == nil {
+		return &res, true
+	}
+	if c.fallback == nil {
+		return nil, false
+	}
+	return c.fallback.Get(ctx, key)
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, res *SubmissionResult) {
+	data, err := json.Marshal(res)
+	if err == nil {
+		err = c.rdb.Set(ctx, cacheKeyPrefix+key, data, c.ttl).Err()
+	}
+	if err != nil && c.fallback != nil {
+		c.fallback.Set(ctx, key, res)
+	}
+}
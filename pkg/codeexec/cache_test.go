@@ -0,0 +1,55 @@
+package codeexec
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	base := SubmissionRequest{SourceCode: "print(1)", LanguageID: LanguageID["python"], Stdin: "x", CPUTimeLimit: 5, MemoryLimit: 1024}
+	key := CacheKey(base, "local/Python 3.12")
+	if CacheKey(base, "local/Python 3.12") != key {
+		t.Fatal("key is not stable")
+	}
+	variants := []struct {
+		name      string
+		req       SubmissionRequest
+		toolchain string
+	}{
+		{"toolchain", base, "local/Python 3.13"},
+		{"source", SubmissionRequest{SourceCode: "print(2)", LanguageID: base.LanguageID, Stdin: "x", CPUTimeLimit: 5, MemoryLimit: 1024}, "local/Python 3.12"},
+		{"field boundary", SubmissionRequest{SourceCode: "print(1)x", LanguageID: base.LanguageID, CPUTimeLimit: 5, MemoryLimit: 1024}, "local/Python 3.12"},
+		{"language", SubmissionRequest{SourceCode: "print(1)", LanguageID: LanguageID["javascript"], Stdin: "x", CPUTimeLimit: 5, MemoryLimit: 1024}, "local/Python 3.12"},
+		{"limits", SubmissionRequest{SourceCode: "print(1)", LanguageID: base.LanguageID, Stdin: "x", CPUTimeLimit: 5, MemoryLimit: 2048}, "local/Python 3.12"},
+		{"files", SubmissionRequest{SourceCode: "print(1)", Files: map[string]string{"a.py": "1"}, LanguageID: base.LanguageID, Stdin: "x", CPUTimeLimit: 5, MemoryLimit: 1024}, "local/Python 3.12"},
+	}
+	for _, v := range variants {
+		if CacheKey(v.req, v.toolchain) == key {
+			t.Errorf("%s does not change the key", v.name)
+		}
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2, time.Hour)
+	for _, key := range []string{"a", "b"} {
+		c.Set(ctx, key, &SubmissionResult{Stdout: key})
+	}
+	c.Get(ctx, "a") // b становится самой старой записью
+	c.Set(ctx, "c", &SubmissionResult{Stdout: "c"})
+	if _, ok := c.Get(ctx, "b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if res, ok := c.Get(ctx, "a"); !ok || res.Stdout != "a" {
+		t.Errorf("a = %+v, %v", res, ok)
+	}
+
+	expiring := NewMemoryCache(0, time.Millisecond)
+	expiring.Set(ctx, "a", &SubmissionResult{})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := expiring.Get(ctx, "a"); ok {
+		t.Error("expired entry returned")
+	}
+}
@@ -0,0 +1,139 @@
+package codeexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Executor бэкенд выполнения кода: Judge0, локальный исполнитель, очередь раннера.
+// Ошибка Execute означает сбой бэкенда; ошибки компиляции и падения программы — статус результата.
+type Executor interface {
+	// Name имя бэкенда в правилах маршрутизации и логах.
+	Name() string
+	// Supports может ли бэкенд выполнить программу на языке (имя из LanguageID).
+	Supports(language string) bool
+	Execute(ctx context.Context, req SubmissionRequest) (*SubmissionResult, error)
+	// Health активная проверка: бэкенд отвечает и готов выполнять программы.
+	Health(ctx context.Context) error
+}
+
+// MultiExecutor бэкенд, который выполняет одну программу на нескольких входах эффективнее,
+// чем по одному запросу: пачкой (Judge0) или с одной сборкой (локальный исполнитель).
+type MultiExecutor interface {
+	Executor
+	// ExecuteMany выполняет req с каждым stdin из stdins. emit вызывается по мере готовности
+	// результатов, по разу на вход; вызовы emit не конкурентны. Ошибка — сбой бэкенда:
+	// входы, для которых emit не вызван, остались без результата.
+	ExecuteMany(ctx context.Context, req SubmissionRequest, stdins []string, emit func(i int, res *SubmissionResult)) error
+}
+
+// Versioned бэкенд, который сообщает версию тулчейна языка; она входит в ключ кеша
+// результатов, чтобы обновление компилятора не отдавало старые результаты.
+type Versioned interface {
+	Toolchain(language string) string
+}
+
+// FileTrees бэкенд, который выполняет проекты из нескольких файлов (SubmissionRequest.Files).
+// Остальные бэкенды получают только SourceCode.
+type FileTrees interface {
+	SupportsFiles(language string) bool
+}
+
+// ErrMultiFileUnsupported ни один бэкенд маршрута не выполняет проекты из нескольких файлов на языке.
+var ErrMultiFileUnsupported = errors.New("multi-file projects are not supported for the language")
+
+// LanguageName имя языка по ID Judge0; пустая строка — язык неизвестен.
+func LanguageName(id int) string {
+	for name, langID := range LanguageID {
+		if langID == id {
+			return name
+		}
+	}
+	return ""
+}
+
+// Ping проверяет, что Judge0 отвечает (GET /about).
+func (c *Client) Ping(ctx context.Context) error {
+	if !c.IsAvailable() {
+		return errors.New("judge0 url is not configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/about", nil)
+	if err != nil {
+		return err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-RapidAPI-Key", c.apiKey)
+		req.Header.Set("X-RapidAPI-Host", "judge0-ce.p.rapidapi.com")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("judge0 ping: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("judge0 ping: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type judge0Executor struct {
+	client  *Client
+	timeout time.Duration
+}
+
+// NewJudge0Executor Executor поверх клиента Judge0; timeout — сколько ждать результата.
+func NewJudge0Executor(client *Client, timeout time.Duration) Executor {
+	return &judge0Executor{client: client, timeout: timeout}
+}
+
+func (e *judge0Executor) Name() string { return "judge0" }
+
+func (e *judge0Executor) Supports(language string) bool {
+	_, ok := LanguageID[language]
+	return ok
+}
+
+func (e *judge0Executor) Execute(ctx context.Context, req SubmissionRequest) (*SubmissionResult, error) {
+	return e.client.SubmitAndWait(ctx, req, e.timeout)
+}
+
+func (e *judge0Executor) ExecuteMany(ctx context.Context, req SubmissionRequest, stdins []string, emit func(i int, res *SubmissionResult)) error {
+	reqs := make([]SubmissionRequest, len(stdins))
+	for i, stdin := range stdins {
+		reqs[i] = req
+		reqs[i].Stdin = stdin
+	}
+	// каждая пачка ждёт в очереди Judge0 отдельно
+	batches := (len(reqs) + MaxBatchSize - 1) / MaxBatchSize
+	return e.client.SubmitBatchAndWait(ctx, reqs, time.Duration(max(batches, 1))*e.timeout, emit)
+}
+
+// Toolchain в Judge0 версия компилятора закреплена за ID языка.
+func (e *judge0Executor) Toolchain(language string) string {
+	return fmt.Sprintf("language-%d", LanguageID[language])
+}
+
+func (e *judge0Executor) Health(ctx context.Context) error {
+	return e.client.Ping(ctx)
+}
+
+// Name имя локального исполнителя в правилах маршрутизации.
+func (e *LocalExecutor) Name() string { return "local" }
+
+// Toolchain версия тулчейна языка — первая строка вывода его Check.
+func (e *LocalExecutor) Toolchain(language string) string {
+	return e.versions[language]
+}
+
+// Health локальному исполнителю нужен хотя бы один рабочий тулчейн.
+func (e *LocalExecutor) Health(context.Context) error {
+	if len(e.tools) == 0 {
+		return errors.New("no toolchains found")
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package codeexec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MaxBatchSize сколько submissions Judge0 принимает в одном batch-запросе (MAX_SUBMISSION_BATCH_SIZE по умолчанию).
+const MaxBatchSize = 20
+
+// SubmitBatch создаёт до MaxBatchSize submissions одним запросом и возвращает их токены в том же порядке.
+func (c *Client) SubmitBatch(ctx context.Context, reqs []SubmissionRequest) ([]string, error) {
+	if len(reqs) > MaxBatchSize {
+		return nil, fmt.Errorf("judge0 batch: at most %d submissions, got %d", MaxBatchSize, len(reqs))
+	}
+	encoded := make([]SubmissionRequest, len(reqs))
+	for i, req := range reqs {
+		if c.callbacks != nil {
+			req.CallbackURL = c.callbacks.URL()
+		}
+		encoded[i] = req.encoded()
+	}
+	body, err := json.Marshal(map[string]interface{}{"submissions": encoded})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	var created []struct {
+		Token string          `json:"token"`
+		Error json.RawMessage `json:"error,omitempty"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, c.apiURL+"/submissions/batch?base64_encoded=true", body, http.StatusCreated, &created); err != nil {
+		return nil, err
+	}
+	if len(created) != len(reqs) {
+		return nil, fmt.Errorf("judge0 batch: %d tokens for %d submissions", len(created), len(reqs))
+	}
+	tokens := make([]string, len(created))
+	for i, sub := range created {
+		if sub.Token == "" {
+			return nil, fmt.Errorf("judge0 batch: submission %d rejected: %s", i, sub.Error)
+		}
+		tokens[i] = sub.Token
+	}
+	return tokens, nil
+}
+
+// GetBatch результаты submissions по токенам в том же порядке.
+func (c *Client) GetBatch(ctx context.Context, tokens []string) ([]SubmissionResult, error) {
+	var out struct {
+		Submissions []SubmissionResult `json:"submissions"`
+	}
+	u := c.apiURL + "/submissions/batch?base64_encoded=true&tokens=" + url.QueryEscape(strings.Join(tokens, ","))
+	if err := c.doJSON(ctx, http.MethodGet, u, nil, http.StatusOK, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Submissions) != len(tokens) {
+		return nil, fmt.Errorf("judge0 batch: %d results for %d tokens", len(out.Submissions), len(tokens))
+	}
+	for i := range out.Submissions {
+		if err := out.Submissions[i].decode(); err != nil {
+			return nil, err
+		}
+	}
+	return out.Submissions, nil
+}
+
+// SubmitBatchAndWait отправляет все запросы пачками по MaxBatchSize и ждёт результаты: с callback-ами —
+// уведомлений, иначе опрашивает их одним запросом на пачку. emit вызывается для каждого результата,
+// как только он готов.
+func (c *Client) SubmitBatchAndWait(ctx context.Context, reqs []SubmissionRequest, timeout time.Duration, emit func(i int, res *SubmissionResult)) error {
+	tokens := make([]string, 0, len(reqs))
+	for start := 0; start < len(reqs); start += MaxBatchSize {
+		end := min(start+MaxBatchSize, len(reqs))
+		batch, err := c.SubmitBatch(ctx, reqs[start:end])
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, batch...)
+	}
+
+	pending := make(map[string]int, len(tokens))
+	for i, token := range tokens {
+		pending[token] = i
+	}
+	interval := pollInterval
+	var delivered <-chan *SubmissionResult
+	if c.callbacks != nil {
+		ch := make(chan *SubmissionResult, len(tokens))
+		c.callbacks.subscribe(tokens, ch)
+		defer c.callbacks.unsubscribe(tokens)
+		delivered, interval = ch, callbackPollInterval
+	}
+	finish := func(res *SubmissionResult, token string) {
+		// результат мог прийти и вебхуком, и опросом
+		if i, ok := pending[token]; ok {
+			delete(pending, token)
+			emit(i, res)
+		}
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return fmt.Errorf("timeout waiting for %d of %d results", len(pending), len(tokens))
+		case res := <-delivered:
+			finish(res, res.Token)
+			continue
+		case <-ticker.C:
+		}
+		var still []string
+		for _, token := range tokens {
+			if _, ok := pending[token]; ok {
+				still = append(still, token)
+			}
+		}
+		for start := 0; start < len(still); start += MaxBatchSize {
+			chunk := still[start:min(start+MaxBatchSize, len(still))]
+			results, err := c.GetBatch(ctx, chunk)
+			if err != nil {
+				return err
+			}
+			for j, token := range chunk {
+				// Status 1-2 = In Queue / Processing
+				if results[j].Status.ID <= StatusProcessing {
+					continue
+				}
+				res := results[j]
+				finish(&res, token)
+			}
+		}
+	}
+	return nil
+}
+
+// doJSON выполняет запрос к API и декодирует ответ со статусом want в out.
+func (c *Client) doJSON(ctx context.Context, method, u string, body []byte, want int, out interface{}) error {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("X-RapidAPI-Key", c.apiKey)
+		httpReq.Header.Set("X-RapidAPI-Host", "judge0-ce.p.rapidapi.com")
+	}
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("judge0 api error: %d - %s", resp.StatusCode, string(b))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package codeexec
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeJudge0 выполняет «программу» мгновенно: stdout — это stdin; нечётные токены
+// отдаются готовыми только со второго опроса. Поля передаются в base64, как у Judge0.
+func fakeJudge0(t *testing.T) (*httptest.Server, *int) {
+	var mu sync.Mutex
+	stdins := map[string]string{}
+	polled := map[string]int{}
+	posts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path != "/submissions/batch" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			posts++
+			var body struct {
+				Submissions []SubmissionRequest `json:"submissions"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Submissions) > MaxBatchSize {
+				http.Error(w, "bad batch", http.StatusUnprocessableEntity)
+				return
+			}
+			out := make([]map[string]string, len(body.Submissions))
+			for i, s := range body.Submissions {
+				token := strconv.Itoa(len(stdins))
+				stdin, _ := base64.StdEncoding.DecodeString(s.Stdin)
+				stdins[token] = string(stdin)
+				out[i] = map[string]string{"token": token}
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(out)
+		case http.MethodGet:
+			var subs []SubmissionResult
+			for _, token := range strings.Split(r.URL.Query().Get("tokens"), ",") {
+				var res SubmissionResult
+				n, _ := strconv.Atoi(token)
+				polled[token]++
+				if n%2 == 1 && polled[token] < 2 {
+					res.setStatus(StatusProcessing)
+				} else {
+					res.setStatus(StatusAccepted)
+					res.Stdout = base64.StdEncoding.EncodeToString([]byte(stdins[token]))
+				}
+				subs = append(subs, res)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"submissions": subs})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &posts
+}
+
+func TestSubmitBatchAndWait(t *testing.T) {
+	srv, posts := fakeJudge0(t)
+	e := NewJudge0Executor(NewClient(srv.URL, ""), 10*time.Second).(MultiExecutor)
+
+	stdins := make([]string, 25)
+	for i := range stdins {
+		stdins[i] = fmt.Sprintf("in-%d", i)
+	}
+	got := make([]string, len(stdins))
+	err := e.ExecuteMany(context.Background(), SubmissionRequest{SourceCode: "x", LanguageID: LanguageID["go"]}, stdins, func(i int, res *SubmissionResult) {
+		if got[i] != "" {
+			t.Errorf("result %d emitted twice", i)
+		}
+		got[i] = res.Stdout
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range stdins {
+		if got[i] != stdins[i] {
+			t.Errorf("result %d = %q, want %q", i, got[i], stdins[i])
+		}
+	}
+	if *posts != 2 {
+		t.Errorf("%d batch submissions, want 2 for 25 tests", *posts)
+	}
+}
@@ -0,0 +1,130 @@
+package codeexec
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// pollInterval опрос статуса submission без callback-ов.
+	pollInterval = 500 * time.Millisecond
+	// callbackPollInterval опрос при включённых callback-ах: только на случай потерянного
+	// уведомления (например, оно пришло на другой экземпляр приложения).
+	callbackPollInterval = 5 * time.Second
+	// earlyResultTTL сколько хранится результат, пришедший раньше, чем Submit вернул токен.
+	earlyResultTTL = time.Minute
+)
+
+// ErrCallbackForbidden уведомление пришло с неверным секретом.
+var ErrCallbackForbidden = errors.New("judge0 callback: invalid secret")
+
+type earlyResult struct {
+	res *SubmissionResult
+	at  time.Time
+}
+
+// Callbacks принимает результаты, которые Judge0 отправляет PUT-запросом на callback_url,
+// и передаёт их ждущим SubmitAndWait и SubmitBatchAndWait. Подключается к клиенту через UseCallbacks.
+type Callbacks struct {
+	url    string
+	secret string
+
+	mu      sync.Mutex
+	waiters map[string]chan<- *SubmissionResult
+	early   map[string]earlyResult
+}
+
+// NewCallbacks callbackURL — адрес вебхука, доступный из Judge0; секрет добавляется к нему
+// параметром secret и проверяется в Deliver.
+func NewCallbacks(callbackURL, secret string) (*Callbacks, error) {
+	if secret == "" {
+		return nil, errors.New("judge0 callback: empty secret")
+	}
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("judge0 callback: invalid url %q", callbackURL)
+	}
+	q := u.Query()
+	q.Set("secret", secret)
+	u.RawQuery = q.Encode()
+	return &Callbacks{
+		url:     u.String(),
+		secret:  secret,
+		waiters: map[string]chan<- *SubmissionResult{},
+		early:   map[string]earlyResult{},
+	}, nil
+}
+
+// UseCallbacks включает уведомления Judge0 для submissions клиента; nil — только опрос.
+func (c *Client) UseCallbacks(cb *Callbacks) {
+	c.callbacks = cb
+}
+
+// URL callback_url для submissions.
+func (cb *Callbacks) URL() string {
+	return cb.url
+}
+
+// Deliver принимает тело уведомления Judge0 (результат submission с base64-полями).
+// Уведомления о submissions, которых никто не ждёт, хранятся недолго и затем отбрасываются.
+func (cb *Callbacks) Deliver(secret string, payload []byte) error {
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(cb.secret)) != 1 {
+		return ErrCallbackForbidden
+	}
+	var res SubmissionResult
+	if err := json.Unmarshal(payload, &res); err != nil {
+		return fmt.Errorf("judge0 callback: %w", err)
+	}
+	if res.Token == "" {
+		return errors.New("judge0 callback: missing token")
+	}
+	if err := res.decode(); err != nil {
+		return err
+	}
+	if res.Status.ID <= StatusProcessing {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if ch, ok := cb.waiters[res.Token]; ok {
+		delete(cb.waiters, res.Token)
+		ch <- &res
+		return nil
+	}
+	now := time.Now()
+	for token, e := range cb.early {
+		if now.Sub(e.at) > earlyResultTTL {
+			delete(cb.early, token)
+		}
+	}
+	cb.early[res.Token] = earlyResult{res: &res, at: now}
+	return nil
+}
+
+// subscribe направляет результаты tokens в ch; ёмкости ch должно хватать на все токены.
+func (cb *Callbacks) subscribe(tokens []string, ch chan<- *SubmissionResult) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	for _, token := range tokens {
+		if e, ok := cb.early[token]; ok {
+			delete(cb.early, token)
+			ch <- e.res
+			continue
+		}
+		cb.waiters[token] = ch
+	}
+}
+
+func (cb *Callbacks) unsubscribe(tokens []string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	for _, token := range tokens {
+		delete(cb.waiters, token)
+	}
+}
@@ -0,0 +1,73 @@
+package codeexec
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSubmitAndWaitCallback(t *testing.T) {
+	var cb *Callbacks
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := cb.Deliver(r.URL.Query().Get("secret"), body); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+		}
+	}))
+	defer hook.Close()
+
+	// вывод не UTF-8; Judge0 разбивает base64 переводами строк
+	stdout := "\xff\x00" + string(bytes.Repeat([]byte("x"), 100))
+	judge0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			// опрос ничего не знает: результат доставляет только callback
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": map[string]interface{}{"id": StatusProcessing}})
+			return
+		}
+		var req SubmissionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		enc := base64.StdEncoding.EncodeToString([]byte(stdout))
+		result, _ := json.Marshal(map[string]interface{}{
+			"token":  "t1",
+			"stdout": enc[:60] + "\n" + enc[60:] + "\n",
+			"time":   "0.012",
+			"memory": 2048,
+			"status": map[string]interface{}{"id": StatusAccepted, "description": "Accepted"},
+		})
+		// уведомление приходит раньше, чем ответ на создание submission
+		put, _ := http.NewRequest(http.MethodPut, req.CallbackURL, bytes.NewReader(result))
+		if resp, err := http.DefaultClient.Do(put); err != nil || resp.StatusCode != http.StatusOK {
+			t.Errorf("callback delivery: %v %v", resp, err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(SubmissionResponse{Token: "t1"})
+	}))
+	defer judge0.Close()
+
+	var err error
+	cb, err = NewCallbacks(hook.URL+"/internal/judge0/callbacks", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(judge0.URL, "")
+	client.UseCallbacks(cb)
+
+	res, err := client.SubmitAndWait(context.Background(), SubmissionRequest{SourceCode: "x", LanguageID: LanguageID["go"]}, 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stdout != stdout || res.Memory != 2048 || res.Status.ID != StatusAccepted {
+		t.Fatalf("result %+v", res)
+	}
+
+	if err := cb.Deliver("wrong", []byte(`{"token":"t2"}`)); !errors.Is(err, ErrCallbackForbidden) {
+		t.Fatalf("err = %v, want ErrCallbackForbidden", err)
+	}
+}
@@ -3,6 +3,7 @@ package codeexec
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,11 +11,13 @@ import (
 	"time"
 )
 
-// Client клиент для Judge0 API
+// Client клиент для Judge0 API. Исходники, ввод и вывод передаются в base64: иначе Judge0
+// отклоняет вывод, который не является корректным UTF-8.
 type Client struct {
 	apiURL     string
 	apiKey     string
 	httpClient *http.Client
+	callbacks  *Callbacks
 }
 
 // NewClient создает новый клиент Judge0
@@ -44,6 +47,10 @@ type SubmissionRequest struct {
 	Stdin        string `json:"stdin,omitempty"`
 	CPUTimeLimit int    `json:"cpu_time_limit,omitempty"` // seconds
 	MemoryLimit  int    `json:"memory_limit,omitempty"`   // KB
+	CallbackURL  string `json:"callback_url,omitempty"`   // заполняет клиент, см. UseCallbacks
+	// Files проект из нескольких файлов (путь → содержимое) вместо SourceCode; Judge0 его
+	// не принимает, выполняют только бэкенды FileTrees.
+	Files map[string]string `json:"-"`
 }
 
 // SubmissionResponse ответ от Judge0
@@ -53,6 +60,7 @@ type SubmissionResponse struct {
 
 // SubmissionResult результат выполнения
 type SubmissionResult struct {
+	Token  string `json:"token,omitempty"`
 	Status struct {
 		ID          int    `json:"id"`
 		Description string `json:"description"`
@@ -64,18 +72,37 @@ type SubmissionResult struct {
 	Memory        int    `json:"memory"` // KB
 	ExitCode      int    `json:"exit_code"`
 	ExitSignal    *int   `json:"exit_signal"`
+	Message       string `json:"message"` // пояснение Judge0 к статусу (например, к Internal Error)
+}
+
+// encoded копия запроса с исходником и вводом в base64 (base64_encoded=true).
+func (r SubmissionRequest) encoded() SubmissionRequest {
+	r.SourceCode = base64.StdEncoding.EncodeToString([]byte(r.SourceCode))
+	r.Stdin = base64.StdEncoding.EncodeToString([]byte(r.Stdin))
+	return r
+}
+
+// decode раскодирует текстовые поля ответа, полученного с base64_encoded=true.
+// Judge0 разбивает base64 на строки по 60 символов; переводы строк декодер пропускает.
+func (r *SubmissionResult) decode() error {
+	for _, f := range []*string{&r.Stdout, &r.Stderr, &r.CompileOutput, &r.Message} {
+		b, err := base64.StdEncoding.DecodeString(*f)
+		if err != nil {
+			return fmt.Errorf("decode base64 result: %w", err)
+		}
+		*f = string(b)
+	}
+	return nil
 }
 
 // Submit создает submission и возвращает token
 func (c *Client) Submit(ctx context.Context, req SubmissionRequest) (string, error) {
-	url := c.apiURL + "/submissions"
-	if c.apiKey != "" {
-		url += "?base64_encoded=false&wait=false"
-	} else {
-		url += "?base64_encoded=false&wait=false"
+	url := c.apiURL + "/submissions?base64_encoded=true&wait=false"
+	if c.callbacks != nil {
+		req.CallbackURL = c.callbacks.URL()
 	}
 
-	jsonData, err := json.Marshal(req)
+	jsonData, err := json.Marshal(req.encoded())
 	if err != nil {
 		return "", fmt.Errorf("marshal request: %w", err)
 	}
@@ -112,7 +139,7 @@ func (c *Client) Submit(ctx context.Context, req SubmissionRequest) (string, err
 
 // GetResult получает результат выполнения по token
 func (c *Client) GetResult(ctx context.Context, token string) (*SubmissionResult, error) {
-	url := c.apiURL + "/submissions/" + token + "?base64_encoded=false"
+	url := c.apiURL + "/submissions/" + token + "?base64_encoded=true"
 
 	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -139,51 +166,58 @@ func (c *Client) GetResult(ctx context.Context, token string) (*SubmissionResult
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
+	if err := result.decode(); err != nil {
+		return nil, err
+	}
 
 	return &result, nil
 }
 
-// SubmitAndWait создает submission и ждет результата (для синхронного выполнения)
+// SubmitAndWait создает submission и ждет результата (для синхронного выполнения).
+// С callback-ами результат приходит на вебхук, а редкий опрос страхует от потерянных
+// уведомлений; без них статус опрашивается каждые 500ms.
 func (c *Client) SubmitAndWait(ctx context.Context, req SubmissionRequest, timeout time.Duration) (*SubmissionResult, error) {
 	token, err := c.Submit(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	// Ждем результат с polling
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(500 * time.Millisecond)
+	interval := pollInterval
+	var delivered <-chan *SubmissionResult
+	if c.callbacks != nil {
+		ch := make(chan *SubmissionResult, 1)
+		c.callbacks.subscribe([]string{token}, ch)
+		defer c.callbacks.unsubscribe([]string{token})
+		delivered, interval = ch, callbackPollInterval
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for time.Now().Before(deadline) {
+	for {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
+		case <-deadline.C:
+			return nil, fmt.Errorf("timeout waiting for result")
+		case result := <-delivered:
+			return result, nil
 		case <-ticker.C:
 			result, err := c.GetResult(ctx, token)
 			if err != nil {
 				return nil, err
 			}
-
-			// Status 3 = Accepted (completed)
-			if result.Status.ID == 3 {
+			// Status 1-2 = In Queue / Processing; остальные — итог, в том числе ошибки
+			// компиляции и выполнения
+			if result.Status.ID > StatusProcessing {
 				return result, nil
 			}
-
-			// Status 1-2 = In Queue / Processing
-			if result.Status.ID <= 2 {
-				continue
-			}
-
-			// Другие статусы (ошибки компиляции, runtime errors и т.д.)
-			return result, nil
 		}
 	}
-
-	return nil, fmt.Errorf("timeout waiting for result")
 }
 
-// IsAvailable проверяет доступность API
+// IsAvailable задан ли адрес API; проверка, что Judge0 отвечает, — Ping
 func (c *Client) IsAvailable() bool {
 	return c.apiURL != ""
 }
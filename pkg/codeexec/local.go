@@ -0,0 +1,451 @@
+package codeexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/example/learngo/pkg/sandbox"
+)
+
+// ErrUnsupportedLanguage для языка нет тулчейна на этой машине.
+var ErrUnsupportedLanguage = errors.New("language is not supported by the local executor")
+
+// Toolchain как собрать и запустить программу на одном языке. Команды выполняются
+// в рабочем каталоге с исходником: первый элемент ищется в PATH, "./..." — результат сборки.
+type Toolchain struct {
+	Source  string   // имя файла с исходником
+	Compile []string // пустая — язык интерпретируемый
+	Run     []string
+	// Check команда проверки, что тулчейн установлен и работает (в песочнице — под её пользователем).
+	// Первая строка её вывода считается версией тулчейна.
+	Check []string
+	// MemoryOverhead добавляется к лимиту памяти запуска: рантайму (JVM, V8) нужна память сверх программы.
+	MemoryOverhead uint64
+	// Mounts каталоги и файлы тулчейна (допустимы шаблоны filepath.Glob): в песочнице компилятор
+	// видит только их (только для чтения) и рабочий каталог. Пустой — toolchainMounts.
+	Mounts []string
+}
+
+// toolchainMounts где лежат компиляторы и их библиотеки в обычном дистрибутиве Linux.
+var toolchainMounts = []string{"/usr", "/bin", "/lib", "/lib32", "/lib64", "/etc/ld.so.cache"}
+
+// javaMounts javac ставится через alternatives, а conf JDK в Debian ссылается в /etc/java-*.
+var javaMounts = append([]string{"/etc/alternatives", "/etc/java-*"}, toolchainMounts...)
+
+// DefaultToolchains тулчейны для языков из LanguageID. В Java класс с main должен называться Main, как в Judge0.
+func DefaultToolchains() map[string]Toolchain {
+	return map[string]Toolchain{
+		"go":         {Source: "main.go", Compile: []string{"go", "build", "-trimpath", "-o", "main", "main.go"}, Check: []string{"go", "version"}, Run: []string{"./main"}},
+		"python":     {Source: "main.py", Check: []string{"python3", "--version"}, Run: []string{"python3", "-B", "main.py"}},
+		"javascript": {Source: "main.js", Check: []string{"node", "--version"}, Run: []string{"node", "main.js"}, MemoryOverhead: 128 << 20},
+		"java":       {Source: "Main.java", Compile: []string{"javac", "-encoding", "UTF-8", "Main.java"}, Check: []string{"java", "-version"}, Run: []string{"java", "-XX:+UseSerialGC", "-cp", ".", "Main"}, MemoryOverhead: 512 << 20, Mounts: javaMounts},
+		"cpp":        {Source: "main.cpp", Compile: []string{"g++", "-std=c++17", "-O2", "-o", "main", "main.cpp"}, Check: []string{"g++", "--version"}, Run: []string{"./main"}},
+	}
+}
+
+// LocalOptions настройки локального исполнителя.
+type LocalOptions struct {
+	// Toolchains nil — DefaultToolchains().
+	Toolchains map[string]Toolchain
+	Compile    sandbox.Limits
+	Run        sandbox.Limits
+	// MaxParallel сколько входов ExecuteMany запускает одновременно; 0 — 4.
+	MaxParallel int
+	// Sandbox nil — программы запускаются без изоляции, только с таймаутами и обрезкой вывода.
+	// Так можно работать только на машине разработчика.
+	Sandbox *sandbox.Sandbox
+}
+
+// DefaultLocalOptions ограничения фаз как у песочницы по умолчанию.
+func DefaultLocalOptions() LocalOptions {
+	cfg := sandbox.DefaultConfig()
+	return LocalOptions{Compile: cfg.Build, Run: cfg.Run}
+}
+
+// LocalExecutor собирает и запускает программы на этой машине — замена Judge0 для dev-окружения.
+// С песочницей Go собирается Sandbox.Build, остальные компиляторы и все программы идут
+// через Sandbox.Exec (без сети, под nobody, с rlimit'ами): компиляторы — в пустом корне
+// с одним тулчейном, запуск — с seccomp.
+// Результат в формате Judge0. Безопасен для конкурентного использования.
+type LocalExecutor struct {
+	opts     LocalOptions
+	tools    map[string]Toolchain // только языки, чьи команды нашлись в PATH
+	versions map[string]string
+}
+
+// NewLocalExecutor находит команды тулчейнов и выполняет их Check; языки, у которых
+// команды не нашлись или проверка не прошла, не поддерживаются.
+func NewLocalExecutor(opts LocalOptions) *LocalExecutor {
+	toolchains := opts.Toolchains
+	if toolchains == nil {
+		toolchains = DefaultToolchains()
+	}
+	tools := make(map[string]Toolchain, len(toolchains))
+	for lang, tc := range toolchains {
+		if len(tc.Run) == 0 || tc.Source == "" {
+			continue
+		}
+		var err error
+		if tc.Compile, err = resolveCommand(tc.Compile); err != nil {
+			continue
+		}
+		if tc.Run, err = resolveCommand(tc.Run); err != nil {
+			continue
+		}
+		tools[lang] = tc
+	}
+	e := &LocalExecutor{opts: opts, tools: tools, versions: make(map[string]string, len(tools))}
+	for lang, tc := range tools {
+		version, ok := e.check(tc)
+		if !ok {
+			delete(tools, lang)
+			continue
+		}
+		// без Check версией служит путь к команде запуска
+		if version == "" {
+			version = strings.Join(tc.Run, " ")
+		}
+		e.versions[lang] = version
+	}
+	return e
+}
+
+// check выполняет Check тулчейна и возвращает первую строку вывода (java -version пишет в stderr).
+func (e *LocalExecutor) check(tc Toolchain) (string, bool) {
+	argv, err := resolveCommand(tc.Check)
+	if err != nil || len(argv) == 0 {
+		return "", err == nil
+	}
+	dir, err := os.MkdirTemp("", "codeexec-check-*")
+	if err != nil {
+		return "", false
+	}
+	defer os.RemoveAll(dir)
+	res, err := e.exec(context.Background(), argv, dir, e.opts.Compile, "", false, nil)
+	if err != nil || !res.OK() {
+		return "", false
+	}
+	out := strings.TrimSpace(res.Stdout + "\n" + res.Stderr)
+	version, _, _ := strings.Cut(out, "\n")
+	return version, true
+}
+
+// resolveCommand заменяет имя программы абсолютным путём; "./..." оставляет как есть.
+func resolveCommand(argv []string) ([]string, error) {
+	if len(argv) == 0 || strings.HasPrefix(argv[0], "./") {
+		return argv, nil
+	}
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		return nil, err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return nil, err
+	}
+	return append([]string{path}, argv[1:]...), nil
+}
+
+// Languages языки, которые можно выполнить.
+func (e *LocalExecutor) Languages() []string {
+	langs := make([]string, 0, len(e.tools))
+	for lang := range e.tools {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Supports можно ли выполнить программу на языке.
+func (e *LocalExecutor) Supports(language string) bool {
+	_, ok := e.tools[language]
+	return ok
+}
+
+// SupportsFiles проекты из нескольких файлов собираются только для Go: go build собирает
+// пакет main из корня дерева.
+func (e *LocalExecutor) SupportsFiles(language string) bool {
+	return language == "go" && e.Supports(language)
+}
+
+// Sandboxed выполняются ли программы в песочнице.
+func (e *LocalExecutor) Sandboxed() bool {
+	return e.opts.Sandbox != nil
+}
+
+// Execute собирает и запускает программу. CPUTimeLimit и MemoryLimit запроса, если заданы,
+// заменяют лимиты запуска. Ошибка компиляции, падение и превышение лимитов — не ошибки,
+// а статус результата.
+func (e *LocalExecutor) Execute(ctx context.Context, req SubmissionRequest) (*SubmissionResult, error) {
+	var out *SubmissionResult
+	err := e.ExecuteMany(ctx, req, []string{req.Stdin}, func(_ int, res *SubmissionResult) { out = res })
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExecuteMany собирает программу один раз и запускает её на каждом входе, не больше
+// MaxParallel запусков одновременно. При ошибке компиляции её результат получает каждый вход.
+func (e *LocalExecutor) ExecuteMany(ctx context.Context, req SubmissionRequest, stdins []string, emit func(i int, res *SubmissionResult)) error {
+	lang := LanguageName(req.LanguageID)
+	tc, ok := e.tools[lang]
+	if !ok {
+		return fmt.Errorf("%w: language id %d", ErrUnsupportedLanguage, req.LanguageID)
+	}
+
+	if len(req.Files) > 0 && !e.SupportsFiles(lang) {
+		return fmt.Errorf("%w: %s", ErrMultiFileUnsupported, lang)
+	}
+
+	work, err := os.MkdirTemp("", "codeexec-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+	src := work
+	if len(req.Files) > 0 {
+		// дерево — в подкаталоге: его файлы и каталоги не пересекутся с собранной программой
+		src = filepath.Join(work, "src")
+		if err := sandbox.WriteTree(src, req.Files); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(filepath.Join(work, tc.Source), []byte(req.SourceCode), 0o644); err != nil {
+		return err
+	}
+
+	if len(tc.Compile) > 0 {
+		res, err := e.compile(ctx, lang, tc, work, src)
+		if err != nil {
+			return fmt.Errorf("compile: %w", err)
+		}
+		if !res.OK() {
+			for i := range stdins {
+				result := &SubmissionResult{CompileOutput: res.Stderr + res.Stdout, ExitCode: res.ExitCode}
+				if res.TimedOut {
+					result.CompileOutput += "\ncompilation time limit exceeded"
+				}
+				result.setStatus(StatusCompilationError)
+				emit(i, result)
+			}
+			return nil
+		}
+	}
+
+	limits := e.opts.Run
+	if req.CPUTimeLimit > 0 {
+		limits.CPUTime = time.Duration(req.CPUTimeLimit) * time.Second
+		if limits.WallTime < 2*limits.CPUTime {
+			limits.WallTime = 2 * limits.CPUTime
+		}
+	}
+	if req.MemoryLimit > 0 {
+		limits.MemoryBytes = uint64(req.MemoryLimit) << 10
+	}
+	if limits.MemoryBytes > 0 {
+		limits.MemoryBytes += tc.MemoryOverhead
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, e.maxParallel())
+	for i, stdin := range stdins {
+		select {
+		case sem <- struct{}{}:
+		case <-runCtx.Done():
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, stdin string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res, err := e.exec(runCtx, tc.Run, work, limits, stdin, true, nil)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("run: %w", err)
+					cancel()
+				}
+				return
+			}
+			emit(i, runResult(res))
+		}(i, stdin)
+	}
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
+
+func (e *LocalExecutor) maxParallel() int {
+	if e.opts.MaxParallel > 0 {
+		return e.opts.MaxParallel
+	}
+	return 4
+}
+
+func runResult(res sandbox.Result) *SubmissionResult {
+	result := &SubmissionResult{Stdout: res.Stdout, Stderr: res.Stderr, ExitCode: res.ExitCode, Memory: int(res.MemoryKB)}
+	if res.OutputTruncated {
+		result.Stderr += "\noutput limit exceeded, output truncated"
+	}
+	result.Time = fmt.Sprintf("%.3f", res.Elapsed.Seconds())
+	result.setStatus(runStatus(res))
+	return result
+}
+
+// compile собирает программу в work; src — каталог исходников (дерево файлов проекта или work).
+func (e *LocalExecutor) compile(ctx context.Context, lang string, tc Toolchain, work, src string) (sandbox.Result, error) {
+	if lang == "go" && e.opts.Sandbox != nil {
+		// тулчейн Go собирает с общим кэшем сборки; пользователю песочницы он недоступен
+		return e.opts.Sandbox.Build(ctx, src, filepath.Join(work, "main"))
+	}
+	if src != work {
+		// go build пакета из корня дерева; go.mod, если его нет, — как у Sandbox.Build
+		if _, err := os.Stat(filepath.Join(src, "go.mod")); errors.Is(err, os.ErrNotExist) {
+			if err := os.WriteFile(filepath.Join(src, "go.mod"), []byte("module main\n\ngo 1.21\n"), 0o644); err != nil {
+				return sandbox.Result{}, err
+			}
+		}
+		// -C: TMPDIR команды — её рабочий каталог, а go не читает go.mod из корня TMPDIR
+		return e.exec(ctx, []string{tc.Compile[0], "build", "-C", src, "-trimpath", "-o", filepath.Join(work, "main"), "."}, work, e.opts.Compile, "", false, nil)
+	}
+	// компилятор видит исходник, но не файлы хоста: #include "/etc/..." не найдёт ничего
+	mounts := tc.Mounts
+	if len(mounts) == 0 {
+		mounts = toolchainMounts
+	}
+	return e.exec(ctx, tc.Compile, work, e.opts.Compile, "", false, expandMounts(mounts))
+}
+
+// expandMounts раскрывает шаблоны путей; пути без совпадений пропускаются.
+func expandMounts(patterns []string) []string {
+	var out []string
+	for _, p := range patterns {
+		matches, _ := filepath.Glob(p)
+		out = append(out, matches...)
+	}
+	return out
+}
+
+// exec выполняет команду в рабочем каталоге; untrusted — команда исполняет код студента.
+// mounts — пути хоста, видимые команде в песочнице (sandbox.Command.Mounts); без песочницы не действуют.
+func (e *LocalExecutor) exec(ctx context.Context, argv []string, dir string, limits sandbox.Limits, stdin string, untrusted bool, mounts []string) (sandbox.Result, error) {
+	argv = append([]string(nil), argv...)
+	if strings.HasPrefix(argv[0], "./") {
+		argv[0] = filepath.Join(dir, argv[0])
+	}
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + dir,
+		"TMPDIR=" + dir,
+		"LANG=C.UTF-8",
+		"GOCACHE=" + filepath.Join(os.TempDir(), "learngo-local-gocache"),
+		"GOFLAGS=-mod=mod",
+		"GOPROXY=off",
+		"GOTOOLCHAIN=local",
+		"CGO_ENABLED=0",
+	}
+	if e.opts.Sandbox != nil {
+		return e.opts.Sandbox.Exec(ctx, sandbox.Command{
+			Path:      argv[0],
+			Args:      argv,
+			Env:       env,
+			Dir:       dir,
+			Untrusted: untrusted,
+			Mounts:    mounts,
+			Limits:    limits,
+		}, strings.NewReader(stdin))
+	}
+	return runPlain(ctx, argv, dir, env, limits, stdin)
+}
+
+// runPlain запускает команду без изоляции: соблюдаются только OutputBytes и время —
+// CPUTime здесь ограничивает время по часам, как и WallTime.
+func runPlain(ctx context.Context, argv []string, dir string, env []string, limits sandbox.Limits, stdin string) (sandbox.Result, error) {
+	parent := ctx
+	deadline := limits.WallTime
+	if limits.CPUTime > 0 && (deadline == 0 || limits.CPUTime < deadline) {
+		deadline = limits.CPUTime
+	}
+	if deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+		defer cancel()
+	}
+	stdout := &sandbox.CappedBuffer{Limit: limits.OutputBytes}
+	stderr := &sandbox.CappedBuffer{Limit: limits.OutputBytes}
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// потомки программы могут держать pipe открытым и после её завершения
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	res := sandbox.Result{
+		Stdout:          stdout.String(),
+		Stderr:          stderr.String(),
+		Elapsed:         time.Since(start),
+		OutputTruncated: stdout.Truncated || stderr.Truncated,
+	}
+	if parent.Err() != nil {
+		return res, parent.Err()
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+		return res, err
+	}
+	res.ExitCode = cmd.ProcessState.ExitCode()
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		res.Signal = ws.Signal().String()
+	}
+	if ru, ok := cmd.ProcessState.SysUsage().(*syscall.Rusage); ok {
+		res.MemoryKB = ru.Maxrss
+	}
+	res.TimedOut = ctx.Err() != nil
+	return res, nil
+}
+
+// signalStatus статусы Judge0 для сигналов по их описанию в syscall.
+var signalStatus = map[string]int{
+	"segmentation fault":       StatusRuntimeSIGSEGV,
+	"file size limit exceeded": StatusRuntimeSIGXFSZ,
+	"floating point exception": StatusRuntimeSIGFPE,
+	"aborted":                  StatusRuntimeSIGABRT,
+}
+
+func runStatus(res sandbox.Result) int {
+	switch {
+	case res.TimedOut:
+		return StatusTimeLimit
+	case res.Signal != "":
+		if status, ok := signalStatus[res.Signal]; ok {
+			return status
+		}
+		return StatusRuntimeOther
+	case res.ExitCode != 0:
+		return StatusRuntimeNZEC
+	}
+	return StatusAccepted
+}
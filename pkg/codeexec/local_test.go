@@ -0,0 +1,154 @@
+package codeexec
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/learngo/pkg/sandbox"
+)
+
+func TestMain(m *testing.M) {
+	sandbox.Init()
+	os.Exit(m.Run())
+}
+
+var echoPrograms = map[string]string{
+	"go": `package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+func main() {
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Print("hello ", line)
+}
+`,
+	"python":     "import sys\nprint('hello ' + sys.stdin.readline().strip())\n",
+	"javascript": "const line = require('fs').readFileSync(0, 'utf8').split('\\n')[0];\nconsole.log('hello ' + line);\n",
+	"java": `import java.util.Scanner;
+
+public class Main {
+	public static void main(String[] args) {
+		System.out.println("hello " + new Scanner(System.in).nextLine());
+	}
+}
+`,
+	"cpp": "#include <iostream>\n#include <string>\nint main() { std::string s; std::getline(std::cin, s); std::cout << \"hello \" << s << std::endl; }\n",
+}
+
+// executors локальный исполнитель без изоляции и, если ядро позволяет, в песочнице.
+func executors(t *testing.T) map[string]*LocalExecutor {
+	t.Helper()
+	opts := DefaultLocalOptions()
+	opts.Run.WallTime = 10 * time.Second
+	out := map[string]*LocalExecutor{"plain": NewLocalExecutor(opts)}
+	if sb, err := sandbox.New(sandbox.DefaultConfig()); err == nil {
+		opts.Sandbox = sb
+		out["sandbox"] = NewLocalExecutor(opts)
+	} else {
+		t.Logf("sandbox unavailable: %v", err)
+	}
+	return out
+}
+
+func TestLocalExecutorRunsLanguages(t *testing.T) {
+	for name, e := range executors(t) {
+		for lang, src := range echoPrograms {
+			if !e.Supports(lang) {
+				t.Logf("%s: %s toolchain not found", name, lang)
+				continue
+			}
+			t.Run(name+"/"+lang, func(t *testing.T) {
+				res, err := e.Execute(context.Background(), SubmissionRequest{SourceCode: src, LanguageID: LanguageID[lang], Stdin: "world\n"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.Status.ID != StatusAccepted || res.Stdout != "hello world\n" {
+					t.Fatalf("status %d %q, stdout %q, stderr %q, compile %q", res.Status.ID, res.Status.Description, res.Stdout, res.Stderr, res.CompileOutput)
+				}
+			})
+		}
+	}
+}
+
+func TestLocalExecutorStatuses(t *testing.T) {
+	cases := []struct {
+		name, lang, src string
+		want            int
+	}{
+		{"compile error", "go", "package main\n\nfunc main() { undefined() }\n", StatusCompilationError},
+		{"non-zero exit", "python", "import sys\nsys.exit(3)\n", StatusRuntimeNZEC},
+		{"time limit", "python", "while True:\n    pass\n", StatusTimeLimit},
+		{"time limit in node", "javascript", "while (true) {}\n", StatusTimeLimit},
+		{"segfault", "cpp", "int main() { volatile int *p = nullptr; return *p; }\n", StatusRuntimeSIGSEGV},
+	}
+	for name, e := range executors(t) {
+		for _, tc := range cases {
+			if !e.Supports(tc.lang) {
+				continue
+			}
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				res, err := e.Execute(context.Background(), SubmissionRequest{SourceCode: tc.src, LanguageID: LanguageID[tc.lang], CPUTimeLimit: 1})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.Status.ID != tc.want {
+					t.Fatalf("status %d %q, want %d; stderr %q", res.Status.ID, res.Status.Description, tc.want, res.Stderr)
+				}
+				if tc.want == StatusCompilationError && !strings.Contains(res.CompileOutput, "undefined") {
+					t.Fatalf("compile output %q", res.CompileOutput)
+				}
+			})
+		}
+	}
+}
+
+func TestLocalExecutorRunsFileTrees(t *testing.T) {
+	files := map[string]string{
+		"go.mod":              "module example.com/hello\n\ngo 1.21\n",
+		"main.go":             "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/hello/main/greet\"\n)\n\nfunc main() { fmt.Println(greet.Hello()) }\n",
+		"main/greet/greet.go": "package greet\n\nfunc Hello() string { return \"hello tree\" }\n",
+	}
+	for name, e := range executors(t) {
+		if !e.SupportsFiles("go") {
+			t.Logf("%s: go toolchain not found", name)
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			res, err := e.Execute(context.Background(), SubmissionRequest{Files: files, LanguageID: LanguageID["go"]})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status.ID != StatusAccepted || res.Stdout != "hello tree\n" {
+				t.Fatalf("status %d %q, stdout %q, stderr %q, compile %q", res.Status.ID, res.Status.Description, res.Stdout, res.Stderr, res.CompileOutput)
+			}
+		})
+		if !e.Supports("python") {
+			continue
+		}
+		if _, err := e.Execute(context.Background(), SubmissionRequest{Files: map[string]string{"a.py": "", "b.py": ""}, LanguageID: LanguageID["python"]}); !errors.Is(err, ErrMultiFileUnsupported) {
+			t.Errorf("%s: python tree err = %v, want ErrMultiFileUnsupported", name, err)
+		}
+	}
+}
+
+func TestLocalExecutorCompilerSeesOnlyToolchain(t *testing.T) {
+	e, ok := executors(t)["sandbox"]
+	if !ok || !e.Supports("cpp") {
+		t.Skip("sandbox or g++ unavailable")
+	}
+	res, err := e.Execute(context.Background(), SubmissionRequest{SourceCode: "#include \"/etc/passwd\"\nint main() {}\n", LanguageID: LanguageID["cpp"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status.ID != StatusCompilationError || strings.Contains(res.CompileOutput, "root:") {
+		t.Fatalf("status %d, compile output %q", res.Status.ID, res.CompileOutput)
+	}
+}
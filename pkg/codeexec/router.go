@@ -0,0 +1,474 @@
+package codeexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/example/learngo/pkg/utils"
+)
+
+// ErrNoBackend все подходящие бэкенды отключены или не справились с запросом.
+var ErrNoBackend = errors.New("no healthy code execution backend")
+
+// Route по чему выбирается цепочка бэкендов.
+type Route struct {
+	Language string
+	Course   string // ID курса; пустой — запуск вне курса
+	Files    bool   // проект из нескольких файлов: подходят только бэкенды FileTrees
+}
+
+// Rule программы языка Language и/или курса Course выполняются бэкендами Backends
+// в порядке предпочтения. Пустое поле совпадает с любым значением.
+type Rule struct {
+	Language string
+	Course   string
+	Backends []string
+}
+
+// ParseRules разбирает правила через ";": "<ключ>=<бэкенд>,<бэкенд>...", где ключ —
+// "<язык>", "course:<id>" или "course:<id>/<язык>". Например:
+// "java=judge0,local; course:4f1c.../go=queue,local".
+func ParseRules(s string) ([]Rule, error) {
+	var rules []Rule
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("route %q: expected key=backends", part)
+		}
+		var rule Rule
+		key = strings.TrimSpace(key)
+		if course, ok := strings.CutPrefix(key, "course:"); ok {
+			rule.Course, rule.Language, _ = strings.Cut(course, "/")
+		} else {
+			rule.Language = key
+		}
+		if rule.Language != "" {
+			if _, ok := LanguageID[rule.Language]; !ok {
+				return nil, fmt.Errorf("route %q: unknown language %q", part, rule.Language)
+			}
+		}
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				rule.Backends = append(rule.Backends, name)
+			}
+		}
+		if len(rule.Backends) == 0 || (rule.Language == "" && rule.Course == "") {
+			return nil, fmt.Errorf("route %q: key and at least one backend are required", part)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// RouterConfig настройки маршрутизации. Нулевые значения заменяются значениями по умолчанию.
+type RouterConfig struct {
+	Rules []Rule
+	// Default цепочка без подходящего правила; пустая — все бэкенды в порядке регистрации.
+	Default          []string
+	ProbeInterval    time.Duration // 30s
+	ProbeTimeout     time.Duration // 10s
+	FailureThreshold int           // 3 сбоя подряд отключают бэкенд
+	Cooldown         time.Duration // 30s до пробного запроса
+	// MaxParallel сколько входов ExecuteMany выполняется одновременно на бэкенде без MultiExecutor; 4.
+	MaxParallel int
+}
+
+// BackendStatus состояние бэкенда для мониторинга.
+type BackendStatus struct {
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	LastError string     `json:"last_error,omitempty"`
+	LastProbe *time.Time `json:"last_probe,omitempty"`
+}
+
+type backend struct {
+	exec    Executor
+	breaker *Breaker
+
+	mu        sync.Mutex
+	lastErr   string
+	lastProbe time.Time
+}
+
+func (b *backend) record(err error, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastErr = ""
+	if err != nil {
+		b.lastErr = err.Error()
+	}
+	if probe {
+		b.lastProbe = time.Now()
+	}
+}
+
+// Router выбирает бэкенд по правилам и переключается на следующий в цепочке, если бэкенд
+// не поддерживает язык, отключён автоматом Breaker или не справился с запросом.
+// Фоновые проверки здоровья (Start) отключают и включают бэкенды заранее.
+type Router struct {
+	cfg      RouterConfig
+	backends []*backend
+	byName   map[string]*backend
+	logger   *utils.Logger
+}
+
+// NewRouter имена бэкендов в правилах должны быть среди executors.
+func NewRouter(cfg RouterConfig, logger *utils.Logger, executors ...Executor) (*Router, error) {
+	if len(executors) == 0 {
+		return nil, errors.New("codeexec: at least one executor is required")
+	}
+	if cfg.ProbeInterval <= 0 {
+		cfg.ProbeInterval = 30 * time.Second
+	}
+	if cfg.ProbeTimeout <= 0 {
+		cfg.ProbeTimeout = 10 * time.Second
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.MaxParallel <= 0 {
+		cfg.MaxParallel = 4
+	}
+	r := &Router{cfg: cfg, byName: make(map[string]*backend, len(executors)), logger: logger}
+	for _, e := range executors {
+		if _, dup := r.byName[e.Name()]; dup {
+			return nil, fmt.Errorf("codeexec: duplicate executor %q", e.Name())
+		}
+		b := &backend{exec: e, breaker: NewBreaker(cfg.FailureThreshold, cfg.Cooldown)}
+		r.backends = append(r.backends, b)
+		r.byName[e.Name()] = b
+	}
+	names := append([]string(nil), cfg.Default...)
+	for _, rule := range cfg.Rules {
+		names = append(names, rule.Backends...)
+	}
+	for _, name := range names {
+		if _, ok := r.byName[name]; !ok {
+			return nil, fmt.Errorf("codeexec: unknown executor %q in routes", name)
+		}
+	}
+	return r, nil
+}
+
+// Start проверяет здоровье бэкендов сразу и затем каждые ProbeInterval, пока не отменён ctx.
+func (r *Router) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.cfg.ProbeInterval)
+		defer ticker.Stop()
+		for {
+			r.Probe(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Probe один раунд проверок здоровья всех бэкендов.
+func (r *Router) Probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, b := range r.backends {
+		wg.Add(1)
+		go func(b *backend) {
+			defer wg.Done()
+			pctx, cancel := context.WithTimeout(ctx, r.cfg.ProbeTimeout)
+			defer cancel()
+			err := b.exec.Health(pctx)
+			if ctx.Err() != nil {
+				return
+			}
+			wasUp := b.breaker.State() == BreakerClosed
+			b.record(err, true)
+			if err != nil {
+				b.breaker.Trip()
+				if wasUp {
+					r.warn("code execution backend is unhealthy", "backend", b.exec.Name(), "error", err)
+				}
+				return
+			}
+			b.breaker.Success()
+			if !wasUp {
+				r.info("code execution backend recovered", "backend", b.exec.Name())
+			}
+		}(b)
+	}
+	wg.Wait()
+}
+
+// Execute выполняет программу первым подходящим бэкендом цепочки маршрута.
+func (r *Router) Execute(ctx context.Context, route Route, req SubmissionRequest) (*SubmissionResult, error) {
+	if route.Language == "" {
+		route.Language = LanguageName(req.LanguageID)
+	}
+	route.Files = route.Files || len(req.Files) > 0
+	var lastErr error
+	supported := false
+	for _, b := range r.chain(route) {
+		if !accepts(b.exec, route) {
+			continue
+		}
+		supported = true
+		if !b.breaker.Allow() {
+			continue
+		}
+		res, err := b.exec.Execute(ctx, req)
+		if ctx.Err() != nil {
+			b.breaker.Release()
+			return nil, ctx.Err()
+		}
+		if err == nil && res.Status.ID != StatusInternalError {
+			b.breaker.Success()
+			return res, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s: %s", res.Status.Description, strings.TrimSpace(res.Stderr))
+		}
+		b.breaker.Failure()
+		b.record(err, false)
+		r.warn("code execution backend failed, trying the next one", "backend", b.exec.Name(), "error", err)
+		lastErr = err
+	}
+	if !supported {
+		return nil, unsupported(route)
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoBackend, lastErr)
+	}
+	return nil, ErrNoBackend
+}
+
+// ExecuteMany выполняет программу на каждом из stdins (см. MultiExecutor). Если бэкенд
+// не справился, входы без результата уходят следующему бэкенду цепочки.
+func (r *Router) ExecuteMany(ctx context.Context, route Route, req SubmissionRequest, stdins []string, emit func(i int, res *SubmissionResult)) error {
+	if route.Language == "" {
+		route.Language = LanguageName(req.LanguageID)
+	}
+	route.Files = route.Files || len(req.Files) > 0
+	pending := make([]int, len(stdins))
+	for i := range pending {
+		pending[i] = i
+	}
+	var mu sync.Mutex
+	var lastErr error
+	supported := false
+	for _, b := range r.chain(route) {
+		if !accepts(b.exec, route) {
+			continue
+		}
+		supported = true
+		if !b.breaker.Allow() {
+			continue
+		}
+		inputs := make([]string, len(pending))
+		for j, i := range pending {
+			inputs[j] = stdins[i]
+		}
+		done := make([]bool, len(pending))
+		err := r.runMany(ctx, b.exec, req, inputs, func(j int, res *SubmissionResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			done[j] = true
+			emit(pending[j], res)
+		})
+		if ctx.Err() != nil {
+			b.breaker.Release()
+			return ctx.Err()
+		}
+		if err == nil {
+			b.breaker.Success()
+			return nil
+		}
+		b.breaker.Failure()
+		b.record(err, false)
+		r.warn("code execution backend failed, trying the next one", "backend", b.exec.Name(), "error", err)
+		lastErr = err
+		var rest []int
+		for j, i := range pending {
+			if !done[j] {
+				rest = append(rest, i)
+			}
+		}
+		pending = rest
+	}
+	if !supported {
+		return unsupported(route)
+	}
+	if lastErr != nil {
+		return fmt.Errorf("%w: %v", ErrNoBackend, lastErr)
+	}
+	return ErrNoBackend
+}
+
+// runMany выполняет входы одним бэкендом: через MultiExecutor или параллельными Execute.
+// Результат со статусом Internal Error считается сбоем бэкенда.
+func (r *Router) runMany(ctx context.Context, e Executor, req SubmissionRequest, stdins []string, emit func(i int, res *SubmissionResult)) error {
+	var mu sync.Mutex
+	var internal error
+	accept := func(i int, res *SubmissionResult) {
+		if res.Status.ID == StatusInternalError {
+			mu.Lock()
+			if internal == nil {
+				internal = fmt.Errorf("%s: %s", res.Status.Description, strings.TrimSpace(res.Stderr))
+			}
+			mu.Unlock()
+			return
+		}
+		emit(i, res)
+	}
+	if me, ok := e.(MultiExecutor); ok {
+		if err := me.ExecuteMany(ctx, req, stdins, accept); err != nil {
+			return err
+		}
+		return internal
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, r.cfg.MaxParallel)
+	for i, stdin := range stdins {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, stdin string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			one := req
+			one.Stdin = stdin
+			res, err := e.Execute(ctx, one)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			accept(i, res)
+		}(i, stdin)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return internal
+}
+
+// Toolchain чем сейчас выполнилась бы программа маршрута: имя первого доступного бэкенда
+// и версия его тулчейна, если бэкенд её сообщает (Versioned). Входит в ключ кеша результатов;
+// пустая строка — подходящего бэкенда нет.
+func (r *Router) Toolchain(route Route) string {
+	for _, b := range r.chain(route) {
+		if !accepts(b.exec, route) || b.breaker.State() == BreakerOpen {
+			continue
+		}
+		id := b.exec.Name()
+		if v, ok := b.exec.(Versioned); ok {
+			id += "/" + v.Toolchain(route.Language)
+		}
+		return id
+	}
+	return ""
+}
+
+// accepts может ли бэкенд выполнить программу маршрута.
+func accepts(e Executor, route Route) bool {
+	if !e.Supports(route.Language) {
+		return false
+	}
+	if !route.Files {
+		return true
+	}
+	ft, ok := e.(FileTrees)
+	return ok && ft.SupportsFiles(route.Language)
+}
+
+func unsupported(route Route) error {
+	if route.Files {
+		return fmt.Errorf("%w: %s", ErrMultiFileUnsupported, route.Language)
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedLanguage, route.Language)
+}
+
+// chain бэкенды для маршрута: самое точное правило (курс и язык, затем курс, затем язык),
+// иначе цепочка по умолчанию.
+func (r *Router) chain(route Route) []*backend {
+	var best *Rule
+	bestScore := 0
+	for i := range r.cfg.Rules {
+		rule := &r.cfg.Rules[i]
+		if (rule.Course != "" && rule.Course != route.Course) || (rule.Language != "" && rule.Language != route.Language) {
+			continue
+		}
+		score := 1
+		if rule.Course != "" {
+			score += 2
+		}
+		if rule.Language != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+	names := r.cfg.Default
+	if best != nil {
+		names = best.Backends
+	}
+	if len(names) == 0 {
+		return r.backends
+	}
+	out := make([]*backend, 0, len(names))
+	for _, name := range names {
+		out = append(out, r.byName[name])
+	}
+	return out
+}
+
+// Status состояние бэкендов в порядке регистрации.
+func (r *Router) Status() []BackendStatus {
+	out := make([]BackendStatus, 0, len(r.backends))
+	for _, b := range r.backends {
+		b.mu.Lock()
+		st := BackendStatus{Name: b.exec.Name(), State: b.breaker.State(), LastError: b.lastErr}
+		if !b.lastProbe.IsZero() {
+			t := b.lastProbe
+			st.LastProbe = &t
+		}
+		b.mu.Unlock()
+		out = append(out, st)
+	}
+	return out
+}
+
+func (r *Router) warn(msg string, args ...any) {
+	if r.logger != nil {
+		r.logger.Warn(msg, args...)
+	}
+}
+
+func (r *Router) info(msg string, args ...any) {
+	if r.logger != nil {
+		r.logger.Info(msg, args...)
+	}
+}
@@ -0,0 +1,225 @@
+package codeexec
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeExecutor struct {
+	name      string
+	languages []string
+	err       error  // ошибка Execute
+	failOn    string // вход, на котором Execute возвращает ошибку
+	health    error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fakeExecutor) Name() string { return f.name }
+
+func (f *fakeExecutor) Supports(language string) bool {
+	for _, l := range f.languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeExecutor) Execute(_ context.Context, req SubmissionRequest) (*SubmissionResult, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.failOn != "" && req.Stdin == f.failOn {
+		return nil, errors.New("backend crashed")
+	}
+	res := &SubmissionResult{Stdout: f.name, Stderr: req.Stdin}
+	res.setStatus(StatusAccepted)
+	return res, nil
+}
+
+func (f *fakeExecutor) Health(context.Context) error { return f.health }
+
+func goRequest() SubmissionRequest {
+	return SubmissionRequest{SourceCode: "package main", LanguageID: LanguageID["go"]}
+}
+
+func TestRouterFailsOverAndOpensBreaker(t *testing.T) {
+	primary := &fakeExecutor{name: "judge0", languages: []string{"go", "python"}, err: errors.New("connection refused")}
+	fallback := &fakeExecutor{name: "local", languages: []string{"go"}}
+	r, err := NewRouter(RouterConfig{FailureThreshold: 2, Cooldown: time.Hour}, nil, primary, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		res, err := r.Execute(context.Background(), Route{}, goRequest())
+		if err != nil || res.Stdout != "local" {
+			t.Fatalf("run %d: %v, %+v", i, err, res)
+		}
+	}
+	if primary.calls != 2 {
+		t.Fatalf("primary called %d times, breaker should open after 2 failures", primary.calls)
+	}
+	if st := r.Status(); st[0].State != BreakerOpen || st[0].LastError == "" {
+		t.Fatalf("status %+v", st)
+	}
+
+	// python умеет только отключённый judge0
+	_, err = r.Execute(context.Background(), Route{}, SubmissionRequest{LanguageID: LanguageID["python"]})
+	if !errors.Is(err, ErrNoBackend) {
+		t.Fatalf("err = %v, want ErrNoBackend", err)
+	}
+	_, err = r.Execute(context.Background(), Route{}, SubmissionRequest{LanguageID: LanguageID["java"]})
+	if !errors.Is(err, ErrUnsupportedLanguage) {
+		t.Fatalf("err = %v, want ErrUnsupportedLanguage", err)
+	}
+
+	// успешная проверка здоровья включает бэкенд, проваленная — отключает
+	primary.err = nil
+	r.Probe(context.Background())
+	if res, err := r.Execute(context.Background(), Route{}, goRequest()); err != nil || res.Stdout != "judge0" {
+		t.Fatalf("after recovery: %v, %+v", err, res)
+	}
+	primary.health = errors.New("503")
+	r.Probe(context.Background())
+	if res, err := r.Execute(context.Background(), Route{}, goRequest()); err != nil || res.Stdout != "local" {
+		t.Fatalf("after failed probe: %v, %+v", err, res)
+	}
+}
+
+func TestRouterReleasesTrialOnCancel(t *testing.T) {
+	primary := &fakeExecutor{name: "judge0", languages: []string{"go"}, err: errors.New("connection refused")}
+	fallback := &fakeExecutor{name: "local", languages: []string{"go"}}
+	r, err := NewRouter(RouterConfig{FailureThreshold: 1, Cooldown: time.Nanosecond}, nil, primary, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Execute(context.Background(), Route{}, goRequest()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+
+	// пробный запрос отменён — бэкенд не остаётся в half_open без права на новую пробу
+	primary.err = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Execute(ctx, Route{}, goRequest()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if err := r.ExecuteMany(ctx, Route{}, goRequest(), []string{"1"}, func(int, *SubmissionResult) {}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ExecuteMany err = %v, want context.Canceled", err)
+	}
+	if res, err := r.Execute(context.Background(), Route{}, goRequest()); err != nil || res.Stdout != "judge0" {
+		t.Fatalf("after cancelled trial: %v, %+v", err, res)
+	}
+}
+
+// treeExecutor fakeExecutor, который собирает проекты из нескольких файлов.
+type treeExecutor struct{ *fakeExecutor }
+
+func (e treeExecutor) SupportsFiles(language string) bool { return e.Supports(language) }
+
+func TestRouterSendsFileTreesToFileTreeBackends(t *testing.T) {
+	judge0 := &fakeExecutor{name: "judge0", languages: []string{"go", "python"}}
+	local := treeExecutor{&fakeExecutor{name: "local", languages: []string{"go"}}}
+	r, err := NewRouter(RouterConfig{Default: []string{"judge0", "local"}}, nil, judge0, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := SubmissionRequest{Files: map[string]string{"main.go": "package main", "util.go": "package main"}, LanguageID: LanguageID["go"]}
+	if res, err := r.Execute(context.Background(), Route{}, tree); err != nil || res.Stdout != "local" {
+		t.Fatalf("tree: %v, %+v", err, res)
+	}
+	if got := r.Toolchain(Route{Language: "go", Files: true}); got != "local" {
+		t.Errorf("toolchain = %q, want local", got)
+	}
+	if res, err := r.Execute(context.Background(), Route{}, goRequest()); err != nil || res.Stdout != "judge0" {
+		t.Fatalf("single file: %v, %+v", err, res)
+	}
+	tree.LanguageID = LanguageID["python"]
+	if _, err := r.Execute(context.Background(), Route{}, tree); !errors.Is(err, ErrMultiFileUnsupported) {
+		t.Fatalf("err = %v, want ErrMultiFileUnsupported", err)
+	}
+}
+
+func TestRouterRules(t *testing.T) {
+	rules, err := ParseRules("go=local,judge0; course:c1=queue; course:c1/python=judge0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := []string{"go", "python"}
+	r, err := NewRouter(RouterConfig{Rules: rules, Default: []string{"judge0"}}, nil,
+		&fakeExecutor{name: "judge0", languages: all},
+		&fakeExecutor{name: "local", languages: all},
+		&fakeExecutor{name: "queue", languages: all},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		route Route
+		want  string
+	}{
+		{Route{Language: "go"}, "local"},
+		{Route{Language: "python"}, "judge0"},
+		{Route{Language: "go", Course: "c1"}, "queue"},
+		{Route{Language: "python", Course: "c1"}, "judge0"},
+		{Route{Language: "go", Course: "c2"}, "local"},
+	}
+	for _, tc := range cases {
+		res, err := r.Execute(context.Background(), tc.route, SubmissionRequest{LanguageID: LanguageID[tc.route.Language]})
+		if err != nil || res.Stdout != tc.want {
+			t.Errorf("%+v: got %+v, %v; want %s", tc.route, res, err, tc.want)
+		}
+	}
+
+	if _, err := ParseRules("rust=local"); err == nil {
+		t.Error("unknown language accepted")
+	}
+	if _, err := NewRouter(RouterConfig{Default: []string{"nope"}}, nil, &fakeExecutor{name: "local"}); err == nil {
+		t.Error("unknown backend accepted")
+	}
+}
+
+func TestRouterExecuteManyReroutesUnfinishedInputs(t *testing.T) {
+	primary := &fakeExecutor{name: "queue", languages: []string{"go"}, failOn: "3"}
+	fallback := &fakeExecutor{name: "local", languages: []string{"go"}}
+	r, err := NewRouter(RouterConfig{MaxParallel: 1}, nil, primary, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdins := []string{"0", "1", "2", "3", "4", "5"}
+	got := map[int]string{}
+	err = r.ExecuteMany(context.Background(), Route{}, goRequest(), stdins, func(i int, res *SubmissionResult) {
+		if _, dup := got[i]; dup {
+			t.Errorf("input %d emitted twice", i)
+		}
+		if res.Stderr != stdins[i] {
+			t.Errorf("input %d got result for %q", i, res.Stderr)
+		}
+		got[i] = res.Stdout
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(stdins) {
+		t.Fatalf("got %d results, want %d", len(got), len(stdins))
+	}
+	// с MaxParallel 1 входы до сбоя выполнил queue, остальные — local
+	for i := range stdins {
+		want := "queue"
+		if i >= 3 {
+			want = "local"
+		}
+		if got[i] != want {
+			t.Errorf("input %d executed by %s, want %s", i, got[i], want)
+		}
+	}
+}
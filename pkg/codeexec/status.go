@@ -0,0 +1,45 @@
+package codeexec
+
+// Статусы выполнения в нумерации Judge0; локальный исполнитель возвращает те же.
+const (
+	StatusInQueue          = 1
+	StatusProcessing       = 2
+	StatusAccepted         = 3
+	StatusWrongAnswer      = 4
+	StatusTimeLimit        = 5
+	StatusCompilationError = 6
+	StatusRuntimeSIGSEGV   = 7
+	StatusRuntimeSIGXFSZ   = 8
+	StatusRuntimeSIGFPE    = 9
+	StatusRuntimeSIGABRT   = 10
+	StatusRuntimeNZEC      = 11
+	StatusRuntimeOther     = 12
+	StatusInternalError    = 13
+)
+
+var statusDescription = map[int]string{
+	StatusInQueue:          "In Queue",
+	StatusProcessing:       "Processing",
+	StatusAccepted:         "Accepted",
+	StatusWrongAnswer:      "Wrong Answer",
+	StatusTimeLimit:        "Time Limit Exceeded",
+	StatusCompilationError: "Compilation Error",
+	StatusRuntimeSIGSEGV:   "Runtime Error (SIGSEGV)",
+	StatusRuntimeSIGXFSZ:   "Runtime Error (SIGXFSZ)",
+	StatusRuntimeSIGFPE:    "Runtime Error (SIGFPE)",
+	StatusRuntimeSIGABRT:   "Runtime Error (SIGABRT)",
+	StatusRuntimeNZEC:      "Runtime Error (NZEC)",
+	StatusRuntimeOther:     "Runtime Error (Other)",
+	StatusInternalError:    "Internal Error",
+}
+
+// StatusDescription описание статуса, как у Judge0.
+func StatusDescription(id int) string {
+	return statusDescription[id]
+}
+
+// setStatus заполняет статус результата с описанием, как у Judge0.
+func (r *SubmissionResult) setStatus(id int) {
+	r.Status.ID = id
+	r.Status.Description = statusDescription[id]
+}
@@ -0,0 +1,176 @@
+// Package codejob общий формат асинхронных задач выполнения кода:
+// API кладёт задачу в очередь runner.jobs, воркер cmd/runner пишет статус и результат в Redis.
+package codejob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Статусы задачи.
+const (
+	StatusQueued     = "queued"
+	StatusProcessing = "processing"
+	StatusDone       = "done"
+	StatusFailed     = "failed"
+)
+
+// Terminal сообщает, что статус финальный и больше не изменится.
+func Terminal(status string) bool { return status == StatusDone || status == StatusFailed }
+
+// Payload сообщение в очереди.
+type Payload struct {
+	ID       string `json:"id"`
+	Language string `json:"language"`
+	Code     string `json:"code,omitempty"`
+	// Files проект из нескольких файлов (путь → содержимое); если задан, Code не используется.
+	Files map[string]string `json:"files,omitempty"`
+	Stdin string            `json:"stdin,omitempty"`
+}
+
+// Job состояние задачи в Redis.
+type Job struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Language  string    `json:"language"`
+	Status    string    `json:"status"`
+	Stdout    string    `json:"stdout"`
+	Stderr    string    `json:"stderr"`
+	ExitCode  int       `json:"exit_code"`
+	ElapsedMs int64     `json:"elapsed_ms"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+const keyPrefix = "runner:job:"
+
+// Key ключ hash с состоянием задачи.
+func Key(id string) string { return keyPrefix + id }
+
+// channel канал pub/sub, в который публикуются переходы статуса.
+func channel(id string) string { return keyPrefix + id + ":events" }
+
+// Store хранилище состояния задач в Redis. Каждая запись продлевает TTL ключа.
+type Store struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func NewStore(rdb *redis.Client, ttl time.Duration) *Store {
+	return &Store{rdb: rdb, ttl: ttl}
+}
+
+// Create записывает новую задачу в статусе queued.
+func (s *Store) Create(ctx context.Context, j Job) error {
+	now := time.Now().UTC()
+	j.Status, j.CreatedAt, j.UpdatedAt = StatusQueued, now, now
+	return s.write(ctx, j.ID, map[string]interface{}{
+		"id":         j.ID,
+		"user_id":    j.UserID,
+		"language":   j.Language,
+		"status":     j.Status,
+		"created_at": now.Format(time.RFC3339Nano),
+		"updated_at": now.Format(time.RFC3339Nano),
+	})
+}
+
+// SetProcessing переводит задачу в processing.
+func (s *Store) SetProcessing(ctx context.Context, id string) error {
+	return s.write(ctx, id, map[string]interface{}{
+		"status":     StatusProcessing,
+		"updated_at": time.Now().UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// Finish сохраняет результат; errMsg != "" означает сбой воркера (failed), а не ошибку программы.
+func (s *Store) Finish(ctx context.Context, id, stdout, stderr string, exitCode int, elapsedMs int64, errMsg string) error {
+	status := StatusDone
+	if errMsg != "" {
+		status = StatusFailed
+	}
+	return s.write(ctx, id, map[string]interface{}{
+		"status":     status,
+		"stdout":     stdout,
+		"stderr":     stderr,
+		"exitCode":   exitCode,
+		"elapsedMs":  elapsedMs,
+		"error":      errMsg,
+		"updated_at": time.Now().UTC().Format(time.RFC3339Nano),
+	})
+}
+
+func (s *Store) write(ctx context.Context, id string, fields map[string]interface{}) error {
+	_, err := s.rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, Key(id), fields)
+		if s.ttl > 0 {
+			p.Expire(ctx, Key(id), s.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// подписчики перечитывают состояние сами, в канал уходит только новый статус
+	return s.rdb.Publish(ctx, channel(id), fields["status"]).Err()
+}
+
+// Get читает задачу; ok == false, если задачи нет или её TTL истёк.
+func (s *Store) Get(ctx context.Context, id string) (Job, bool, error) {
+	m, err := s.rdb.HGetAll(ctx, Key(id)).Result()
+	if err != nil {
+		return Job{}, false, err
+	}
+	if len(m) == 0 || m["status"] == "" {
+		return Job{}, false, nil
+	}
+	j := Job{
+		ID:       id,
+		UserID:   m["user_id"],
+		Language: m["language"],
+		Status:   m["status"],
+		Stdout:   m["stdout"],
+		Stderr:   m["stderr"],
+		Error:    m["error"],
+	}
+	j.ExitCode, _ = strconv.Atoi(m["exitCode"])
+	j.ElapsedMs, _ = strconv.ParseInt(m["elapsedMs"], 10, 64)
+	j.CreatedAt, _ = time.Parse(time.RFC3339Nano, m["created_at"])
+	j.UpdatedAt, _ = time.Parse(time.RFC3339Nano, m["updated_at"])
+	return j, true, nil
+}
+
+// Subscribe подписывается на переходы статуса задачи. Подписку нужно оформить до
+// чтения текущего состояния, иначе переход между чтением и подпиской потеряется.
+func (s *Store) Subscribe(ctx context.Context, id string) (<-chan string, func(), error) {
+	sub := s.rdb.Subscribe(ctx, channel(id))
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, nil, err
+	}
+	out := make(chan string, 4)
+	go func() {
+		defer close(out)
+		for msg := range sub.Channel() {
+			select {
+			case out <- msg.Payload:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, func() { _ = sub.Close() }, nil
+}
+
+// Encode сериализует сообщение для очереди.
+func (p Payload) Encode() ([]byte, error) {
+	if p.ID == "" {
+		return nil, errors.New("job id is required")
+	}
+	return json.Marshal(p)
+}
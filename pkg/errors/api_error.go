@@ -54,6 +54,7 @@ const (
 	ErrCodeRateLimit          = "RATE_LIMIT_EXCEEDED"
 	ErrCodeBadRequest         = "BAD_REQUEST"
 	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	ErrCodePaymentRequired    = "PAYMENT_REQUIRED"
 )
 
 // Predefined error messages
@@ -66,4 +67,5 @@ const (
 	MsgRateLimitExceeded  = "Rate limit exceeded"
 	MsgBadRequest         = "Bad request"
 	MsgServiceUnavailable = "Service unavailable"
+	MsgPaymentRequired    = "Enrollment required"
 )
@@ -0,0 +1,251 @@
+package gotest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Benchmark результат одного бенчмарка: медиана по запускам.
+type Benchmark struct {
+	Name        string  `json:"name"` // без суффикса -GOMAXPROCS
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+	Runs        int     `json:"runs"`
+}
+
+// BenchOptions параметры прогона бенчмарков.
+type BenchOptions struct {
+	// Count раундов; в каждом раунде каждый бенчмарк запускается один раз.
+	Count int
+	// Benchtime значение -test.benchtime, например "100ms" или "1000x"; пустое — по умолчанию go test.
+	Benchtime string
+	// Timeout ограничение на сборку и все раунды; 0 — таймаут раннера.
+	Timeout time.Duration
+}
+
+// BenchReport бенчмарки одного решения.
+type BenchReport struct {
+	Benchmarks  []Benchmark `json:"benchmarks"`
+	BuildFailed bool        `json:"build_failed"`
+	Failed      bool        `json:"failed"` // бенчмарк упал (паника, b.Fatal)
+	TimedOut    bool        `json:"timed_out"`
+	Output      string      `json:"output,omitempty"` // вывод сборки или упавшего прогона
+}
+
+// BenchRun результат сравнения решения студента с эталоном.
+type BenchRun struct {
+	Student   BenchReport
+	Reference BenchReport
+}
+
+// ParseBench разбирает текстовый вывод бенчмарков (-test.benchmem) и объединяет
+// повторные запуски одного бенчмарка в медиану.
+func ParseBench(r io.Reader) ([]Benchmark, error) {
+	samples := map[string][]Benchmark{}
+	var order []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		b, ok := parseBenchLine(sc.Text())
+		if !ok {
+			continue
+		}
+		if _, seen := samples[b.Name]; !seen {
+			order = append(order, b.Name)
+		}
+		samples[b.Name] = append(samples[b.Name], b)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]Benchmark, 0, len(order))
+	for _, name := range order {
+		out = append(out, median(samples[name]))
+	}
+	return out, nil
+}
+
+// parseBenchLine строка вида «BenchmarkSum-8   1000000   1052 ns/op   120 B/op   3 allocs/op».
+func parseBenchLine(line string) (Benchmark, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return Benchmark{}, false
+	}
+	if _, err := strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return Benchmark{}, false
+	}
+	b := Benchmark{Name: fields[0], Runs: 1}
+	if i := strings.LastIndexByte(b.Name, '-'); i > 0 {
+		if _, err := strconv.Atoi(b.Name[i+1:]); err == nil {
+			b.Name = b.Name[:i]
+		}
+	}
+	found := false
+	for i := 2; i+1 < len(fields); i += 2 {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Benchmark{}, false
+		}
+		switch fields[i+1] {
+		case "ns/op":
+			b.NsPerOp, found = v, true
+		case "B/op":
+			b.BytesPerOp = int64(v)
+		case "allocs/op":
+			b.AllocsPerOp = int64(v)
+		}
+	}
+	return b, found
+}
+
+func median(samples []Benchmark) Benchmark {
+	res := Benchmark{Name: samples[0].Name, Runs: len(samples)}
+	pick := func(get func(Benchmark) float64) float64 {
+		vals := make([]float64, len(samples))
+		for i, s := range samples {
+			vals[i] = get(s)
+		}
+		sort.Float64s(vals)
+		if n := len(vals); n%2 == 0 {
+			return (vals[n/2-1] + vals[n/2]) / 2
+		}
+		return vals[len(vals)/2]
+	}
+	res.NsPerOp = pick(func(b Benchmark) float64 { return b.NsPerOp })
+	res.BytesPerOp = int64(pick(func(b Benchmark) float64 { return float64(b.BytesPerOp) }))
+	res.AllocsPerOp = int64(pick(func(b Benchmark) float64 { return float64(b.AllocsPerOp) }))
+	return res
+}
+
+// BenchmarkNames возвращает имена функций Benchmark*, объявленных в исходнике _test.go.
+func BenchmarkNames(src string) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "x_test.go", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && strings.HasPrefix(fd.Name.Name, "Benchmark") {
+			names = append(names, fd.Name.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Bench собирает тестовые бинарники решения и эталона и запускает их бенчмарки поочерёдно,
+// раунд за раундом, в одинаковом окружении (с песочницей — в ней, с лимитами r.Limits),
+// чтобы фоновая нагрузка одинаково сказывалась на обоих. Ошибка —
+// если не собрался или упал эталон: это ошибка задания, а не решения.
+func (r *LocalRunner) Bench(ctx context.Context, student, reference map[string]string, opts BenchOptions) (BenchRun, error) {
+	work, err := os.MkdirTemp("", "gobench-*")
+	if err != nil {
+		return BenchRun{}, err
+	}
+	defer os.RemoveAll(work)
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = r.timeout()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	count := opts.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	var run BenchRun
+	refBin, out, err := r.build(ctx, work, "reference", reference)
+	if err != nil {
+		return BenchRun{}, err
+	}
+	if refBin == "" {
+		return BenchRun{}, fmt.Errorf("reference solution does not build: %s", out)
+	}
+	studentBin, out, err := r.build(ctx, work, "student", student)
+	if err != nil {
+		return BenchRun{}, err
+	}
+	if studentBin == "" {
+		run.Student = BenchReport{BuildFailed: true, Output: out, TimedOut: ctx.Err() != nil}
+		return run, nil
+	}
+
+	// общий таймаут ограничивает все раунды вместе, лимиты песочницы — каждый запуск
+	limits := r.Limits
+	limits.WallTime, limits.CPUTime = timeout, timeout
+	args := []string{"-test.run", "^$", "-test.bench", ".", "-test.benchmem", "-test.count", "1"}
+	if opts.Benchtime != "" {
+		args = append(args, "-test.benchtime", opts.Benchtime)
+	}
+	var refOut, studentOut bytes.Buffer
+	for i := 0; i < count; i++ {
+		res, err := r.exec(ctx, refBin, args, limits)
+		if ctx.Err() != nil || res.TimedOut {
+			return BenchRun{}, errors.New("reference benchmarks timed out")
+		}
+		if err != nil {
+			return BenchRun{}, err
+		}
+		if !res.OK() {
+			return BenchRun{}, fmt.Errorf("reference benchmarks failed: %s", res.Stdout+res.Stderr)
+		}
+		refOut.WriteString(res.Stdout)
+
+		res, err = r.exec(ctx, studentBin, args, limits)
+		if ctx.Err() != nil || res.TimedOut {
+			run.Student = BenchReport{TimedOut: true, Output: res.Stdout + res.Stderr}
+			return run, nil
+		}
+		if err != nil {
+			return BenchRun{}, err
+		}
+		if !res.OK() {
+			run.Student = BenchReport{Failed: true, Output: res.Stdout + res.Stderr}
+			return run, nil
+		}
+		studentOut.WriteString(res.Stdout)
+	}
+	if run.Reference.Benchmarks, err = ParseBench(&refOut); err != nil {
+		return BenchRun{}, err
+	}
+	if run.Student.Benchmarks, err = ParseBench(&studentOut); err != nil {
+		return BenchRun{}, err
+	}
+	return run, nil
+}
+
+// writePackage создаёт модуль ModulePath с файлами одного пакета.
+func writePackage(dir string, files map[string]string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+ModulePath+"\n\ngo 1.21\n"), 0o600); err != nil {
+		return err
+	}
+	for name, src := range files {
+		// имена файлов задаёт сервис, но подкаталоги не допускаем
+		if filepath.Base(name) != name {
+			return errors.New("invalid file name: " + name)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package gotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const benchOutput = `goos: linux
+goarch: amd64
+pkg: submission
+BenchmarkSum-8   	 1000000	      1052 ns/op	     120 B/op	       3 allocs/op
+BenchmarkSum-8   	 1000000	      1010 ns/op	     120 B/op	       3 allocs/op
+BenchmarkSum-8   	 1000000	      2000 ns/op	     128 B/op	       4 allocs/op
+BenchmarkSort/small-8         	   50000	     30000 ns/op
+--- BENCH: BenchmarkSort/small-8
+    a_test.go:10: log line
+PASS
+ok  	submission	3.412s
+`
+
+func TestParseBench(t *testing.T) {
+	got, err := ParseBench(strings.NewReader(benchOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Benchmark{
+		{Name: "BenchmarkSum", NsPerOp: 1052, BytesPerOp: 120, AllocsPerOp: 3, Runs: 3},
+		{Name: "BenchmarkSort/small", NsPerOp: 30000, Runs: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("benchmarks %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("benchmark %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLocalRunnerBench(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds two test binaries")
+	}
+	bench := "package main\n\nimport \"testing\"\n\nfunc BenchmarkJoin(b *testing.B) {\n\tfor i := 0; i < b.N; i++ {\n\t\tjoin(100)\n\t}\n}\n"
+	fast := "package main\n\nimport \"strings\"\n\nfunc join(n int) string {\n\tvar sb strings.Builder\n\tfor i := 0; i < n; i++ {\n\t\tsb.WriteByte('x')\n\t}\n\treturn sb.String()\n}\n\nfunc main() {}\n"
+	slow := "package main\n\nfunc join(n int) string {\n\ts := \"\"\n\tfor i := 0; i < n; i++ {\n\t\ts += \"x\"\n\t}\n\treturn s\n}\n\nfunc main() {}\n"
+
+	for name, r := range runners(t) {
+		t.Run(name, func(t *testing.T) {
+			run, err := r.Bench(context.Background(),
+				map[string]string{"main.go": slow, "bench_test.go": bench},
+				map[string]string{"main.go": fast, "bench_test.go": bench},
+				BenchOptions{Count: 2, Benchtime: "2000x"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(run.Student.Benchmarks) != 1 || len(run.Reference.Benchmarks) != 1 {
+				t.Fatalf("run %+v", run)
+			}
+			s, ref := run.Student.Benchmarks[0], run.Reference.Benchmarks[0]
+			if s.Runs != 2 || s.AllocsPerOp <= ref.AllocsPerOp {
+				t.Errorf("student %+v, reference %+v", s, ref)
+			}
+
+			broken := map[string]string{"main.go": "package main\n\nfunc main() {}\n", "bench_test.go": bench}
+			run, err = r.Bench(context.Background(), broken, map[string]string{"main.go": fast, "bench_test.go": bench}, BenchOptions{Benchtime: "1x"})
+			if err != nil || !run.Student.BuildFailed {
+				t.Errorf("broken solution: %+v, %v", run.Student, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package gotest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// ErrTestMain тесты объявляют свой TestMain: его место занимает TestMain раннера.
+var ErrTestMain = errors.New("test files must not declare TestMain")
+
+// Маркеры вывода `-test.v=test2json` (см. testing.markFraming и cmd/internal/test2json).
+const (
+	markFraming  = 'V' &^ '@'
+	markErrBegin = 'O' &^ '@'
+	markErrEnd   = 'N' &^ '@'
+	markEscape   = '[' &^ '@'
+)
+
+// framingFile имя файла с TestMain раннера; сортируется раньше файлов сервиса,
+// поэтому его тест выполняется первым.
+const framingFile = "0000_framing_test.go"
+
+// framingTag начало подписанной строки: framingTag + hex(HMAC) + ":" + строка.
+const framingTag = "\x00gotest:"
+
+// framingSource TestMain, который уводит вывод пакета testing в свой канал и подписывает
+// каждую строку ключом сборки. Первый тест возвращает os.Stdout на настоящий вывод, так что
+// вывод решения и тестов подписи не получает. Итог прогона — подписанная строка ^VPASS/^VFAIL.
+// Имена случайны, чтобы код решения не мог на них сослаться.
+const framingSource = `package main
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+)
+
+var stdout%[1]s = os.NewFile(1, "stdout")
+
+func TestMain(m *testing.M) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		os.Exit(2)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadString('\n')
+			if line != "" {
+				line = strings.TrimSuffix(line, "\n")
+				mac := hmac.New(sha256.New, []byte(%[2]q))
+				mac.Write([]byte(line))
+				stdout%[1]s.WriteString(%[3]q + hex.EncodeToString(mac.Sum(nil)) + ":" + line + "\n")
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	os.Stdout = w
+	code := m.Run()
+	status := %[4]q
+	if code != 0 {
+		status = %[5]q
+	}
+	w.WriteString(status + "\n")
+	w.Close()
+	<-done
+	os.Exit(code)
+}
+
+func Test%[1]s(t *testing.T) {
+	os.Stdout = stdout%[1]s
+}
+`
+
+// framing ключ и имена TestMain одного прогона.
+type framing struct {
+	key  string
+	test string // имя служебного теста
+}
+
+func newFraming() (framing, error) {
+	var b [40]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return framing{}, err
+	}
+	return framing{key: hex.EncodeToString(b[:32]), test: "Test_" + hex.EncodeToString(b[32:])}, nil
+}
+
+// source исходник framingFile.
+func (f framing) source() string {
+	suffix := strings.TrimPrefix(f.test, "Test")
+	return fmt.Sprintf(framingSource, suffix, f.key, framingTag, string(markFraming)+"PASS", string(markFraming)+"FAIL")
+}
+
+// untag готовит вывод тестового бинарника для test2json: у строк с верной подписью подпись
+// снимается, в остальном выводе маркеры экранируются, как это делает пакет testing с выводом
+// тестов. Второе значение — встретилась ли подписанная итоговая строка.
+func (f framing) untag(out string) (string, bool) {
+	var b strings.Builder
+	done := false
+	for _, line := range strings.SplitAfter(out, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		if i := strings.Index(text, framingTag); i >= 0 {
+			if framed, ok := f.verify(text[i+len(framingTag):]); ok {
+				if i > 0 {
+					// вывод решения без перевода строки перед строкой testing
+					b.WriteString(escape(text[:i]) + "\n")
+				}
+				b.WriteString(framed + "\n")
+				if framed == string(markFraming)+"PASS" || framed == string(markFraming)+"FAIL" {
+					done = true
+				}
+				continue
+			}
+		}
+		b.WriteString(escape(line))
+	}
+	return b.String(), done
+}
+
+// verify проверяет подпись "hex(HMAC):строка" и возвращает строку.
+func (f framing) verify(s string) (string, bool) {
+	sum, line, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", false
+	}
+	want, err := hex.DecodeString(sum)
+	if err != nil {
+		return "", false
+	}
+	mac := hmac.New(sha256.New, []byte(f.key))
+	mac.Write([]byte(line))
+	return line, hmac.Equal(mac.Sum(nil), want)
+}
+
+// escape экранирует маркеры test2json в выводе, который не пришёл от пакета testing.
+func escape(s string) string {
+	if !strings.ContainsAny(s, string([]byte{markFraming, markErrBegin, markErrEnd, markEscape})) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case markFraming, markErrBegin, markErrEnd, markEscape:
+			b.WriteByte(markEscape)
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// declaresTestMain сообщает, объявлен ли TestMain в файлах _test.go.
+func declaresTestMain(files map[string]string) bool {
+	for name, src := range files {
+		if strings.HasSuffix(name, "_test.go") && DeclaresTestMain(src) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeclaresTestMain сообщает, объявлен ли TestMain в исходнике _test.go. Такие тесты раннер
+// не запускает (ErrTestMain). Исходник, который не разбирается, TestMain не объявляет.
+func DeclaresTestMain(src string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), "x_test.go", src, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == "TestMain" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+// Package gotest собирает и запускает Go-тесты и разбирает их вывод в формате `go test -json`.
+package gotest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Статусы отдельного теста.
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+	// StatusIncomplete тест запущен, но не завершился (таймаут, паника в другом тесте).
+	StatusIncomplete = "incomplete"
+)
+
+// Event событие `go test -json` (см. `go doc test2json`).
+type Event struct {
+	Time        time.Time `json:"Time"`
+	Action      string    `json:"Action"`
+	Package     string    `json:"Package"`
+	ImportPath  string    `json:"ImportPath"` // события сборки (build-output/build-fail)
+	Test        string    `json:"Test"`
+	Elapsed     float64   `json:"Elapsed"` // секунды
+	Output      string    `json:"Output"`
+	FailedBuild string    `json:"FailedBuild"`
+}
+
+// TestResult итог одного теста (подтесты — отдельные записи вида Parent/Sub).
+type TestResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Output    string `json:"output"`
+	ElapsedMs int64  `json:"elapsed_ms"`
+}
+
+// Report разобранный прогон одного пакета.
+type Report struct {
+	Tests       []TestResult `json:"tests"`
+	Passed      bool         `json:"passed"`
+	BuildFailed bool         `json:"build_failed"`
+	TimedOut    bool         `json:"timed_out"`
+	BuildOutput string       `json:"build_output,omitempty"`
+	Output      string       `json:"output,omitempty"` // вывод уровня пакета (вне тестов)
+	ElapsedMs   int64        `json:"elapsed_ms"`
+}
+
+// Parse читает поток `go test -json`. Строки, не являющиеся JSON (ошибки компиляции
+// в старых версиях Go печатаются как есть), попадают в BuildOutput.
+func Parse(r io.Reader) (Report, error) {
+	var rep Report
+	tests := map[string]*TestResult{}
+	var order []string
+	var build, pkgOut strings.Builder
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var ev Event
+		if line[0] != '{' || json.Unmarshal(line, &ev) != nil {
+			build.Write(line)
+			build.WriteByte('\n')
+			continue
+		}
+		switch ev.Action {
+		case "build-output":
+			build.WriteString(ev.Output)
+			continue
+		case "build-fail":
+			rep.BuildFailed = true
+			continue
+		}
+		if ev.Test == "" {
+			switch ev.Action {
+			case "output":
+				pkgOut.WriteString(ev.Output)
+				if strings.HasPrefix(ev.Output, "panic: test timed out") {
+					rep.TimedOut = true
+				}
+			case "pass":
+				rep.Passed = true
+				rep.ElapsedMs = millis(ev.Elapsed)
+			case "fail":
+				rep.ElapsedMs = millis(ev.Elapsed)
+				if ev.FailedBuild != "" {
+					rep.BuildFailed = true
+				}
+			}
+			continue
+		}
+		t, ok := tests[ev.Test]
+		if !ok {
+			t = &TestResult{Name: ev.Test, Status: StatusIncomplete}
+			tests[ev.Test] = t
+			order = append(order, ev.Test)
+		}
+		switch ev.Action {
+		case "output":
+			t.Output += ev.Output
+		case "pass":
+			t.Status, t.ElapsedMs = StatusPass, millis(ev.Elapsed)
+		case "fail":
+			t.Status, t.ElapsedMs = StatusFail, millis(ev.Elapsed)
+		case "skip":
+			t.Status, t.ElapsedMs = StatusSkip, millis(ev.Elapsed)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return rep, err
+	}
+	for _, name := range order {
+		rep.Tests = append(rep.Tests, *tests[name])
+	}
+	rep.BuildOutput = build.String()
+	rep.Output = pkgOut.String()
+	// без событий тестов и без успешного завершения пакета — значит, до тестов не дошло
+	if !rep.Passed && len(rep.Tests) == 0 && rep.BuildOutput != "" {
+		rep.BuildFailed = true
+	}
+	if rep.BuildFailed {
+		rep.Passed = false
+	}
+	return rep, nil
+}
+
+func millis(sec float64) int64 { return int64(sec*1000 + 0.5) }
+
+// TestNames возвращает имена тестов (TestXxx, кроме TestMain), объявленных в исходнике _test.go.
+func TestNames(src string) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "x_test.go", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && isTest(fd.Name.Name) {
+			names = append(names, fd.Name.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isTest имя тестовой функции по правилам go test: Test, за которым не строчная буква.
+func isTest(name string) bool {
+	rest, ok := strings.CutPrefix(name, "Test")
+	if !ok || name == "TestMain" {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || !unicode.IsLower(r)
+}
@@ -0,0 +1,87 @@
+package gotest
+
+import (
+	"strings"
+	"testing"
+)
+
+const runOutput = `{"Action":"start","Package":"submission"}
+{"Action":"run","Package":"submission","Test":"TestAdd"}
+{"Action":"output","Package":"submission","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}
+{"Action":"pass","Package":"submission","Test":"TestAdd","Elapsed":0.012}
+{"Action":"run","Package":"submission","Test":"TestBad"}
+{"Action":"run","Package":"submission","Test":"TestBad/sub"}
+{"Action":"output","Package":"submission","Test":"TestBad/sub","Output":"    a_test.go:4: nope\n"}
+{"Action":"fail","Package":"submission","Test":"TestBad/sub","Elapsed":0}
+{"Action":"fail","Package":"submission","Test":"TestBad","Elapsed":0}
+{"Action":"run","Package":"submission","Test":"TestSkip"}
+{"Action":"skip","Package":"submission","Test":"TestSkip","Elapsed":0}
+{"Action":"run","Package":"submission","Test":"TestHang"}
+{"Action":"output","Package":"submission","Output":"FAIL\tsubmission\t0.001s\n"}
+{"Action":"fail","Package":"submission","Elapsed":0.001}
+`
+
+func TestParseRun(t *testing.T) {
+	rep, err := Parse(strings.NewReader(runOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Passed || rep.BuildFailed {
+		t.Fatalf("passed=%v build_failed=%v, want failed run", rep.Passed, rep.BuildFailed)
+	}
+	want := map[string]string{
+		"TestAdd":     StatusPass,
+		"TestBad":     StatusFail,
+		"TestBad/sub": StatusFail,
+		"TestSkip":    StatusSkip,
+		"TestHang":    StatusIncomplete,
+	}
+	if len(rep.Tests) != len(want) {
+		t.Fatalf("got %d tests, want %d", len(rep.Tests), len(want))
+	}
+	for _, tr := range rep.Tests {
+		if tr.Status != want[tr.Name] {
+			t.Errorf("%s: status %q, want %q", tr.Name, tr.Status, want[tr.Name])
+		}
+	}
+	if rep.Tests[0].ElapsedMs != 12 {
+		t.Errorf("elapsed %d ms, want 12", rep.Tests[0].ElapsedMs)
+	}
+	if !strings.Contains(rep.Tests[2].Output, "nope") {
+		t.Errorf("subtest output lost: %q", rep.Tests[2].Output)
+	}
+}
+
+func TestParseBuildFailure(t *testing.T) {
+	cases := map[string]string{
+		"build events": `{"ImportPath":"submission [submission.test]","Action":"build-output","Output":"./b.go:2:12: undefined: x\n"}
+{"ImportPath":"submission [submission.test]","Action":"build-fail"}
+{"Action":"fail","Package":"submission","Elapsed":0,"FailedBuild":"submission [submission.test]"}
+`,
+		"plain text": "# submission\n./b.go:2:12: undefined: x\n" + `{"Action":"output","Package":"submission","Output":"FAIL\tsubmission [build failed]\n"}
+{"Action":"fail","Package":"submission","Elapsed":0}
+`,
+	}
+	for name, in := range cases {
+		rep, err := Parse(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !rep.BuildFailed || rep.Passed {
+			t.Errorf("%s: build_failed=%v passed=%v", name, rep.BuildFailed, rep.Passed)
+		}
+		if !strings.Contains(rep.BuildOutput, "undefined: x") {
+			t.Errorf("%s: build output %q", name, rep.BuildOutput)
+		}
+	}
+}
+
+func TestTestNames(t *testing.T) {
+	names, err := TestNames("package main\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) {}\nfunc TestMain(m *testing.M) {}\nfunc Testify() {}\nfunc helper() {}\nfunc TestA(t *testing.T) {}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "TestA,TestB" {
+		t.Errorf("names = %v", names)
+	}
+}
@@ -0,0 +1,214 @@
+package gotest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/example/learngo/pkg/sandbox"
+)
+
+// ModulePath путь модуля, в котором собирается решение.
+const ModulePath = "submission"
+
+// Runner собирает тесты пакета и запускает их, возвращая отчёт в формате `go test -json`.
+type Runner interface {
+	Run(ctx context.Context, files map[string]string) (Report, error)
+	// Bench сравнивает бенчмарки решения и эталона (см. LocalRunner.Bench).
+	Bench(ctx context.Context, student, reference map[string]string, opts BenchOptions) (BenchRun, error)
+}
+
+// LocalRunner собирает тестовый бинарник (go test -c) и запускает его отдельно от исходников:
+// код студента не видит файлов пакета, в том числе скрытых тестов.
+// С песочницей сборка идёт через Sandbox.BuildTest, а бинарник — через Sandbox.RunProgram
+// в пустом корне, без сети, с rlimit'ами и seccomp. Без песочницы изоляции нет:
+// так можно работать только на машине разработчика.
+type LocalRunner struct {
+	// Timeout ограничение на прогон тестов.
+	Timeout time.Duration
+	// GoBin путь к go; пустой — go из PATH. Песочница берёт go из своей конфигурации.
+	GoBin string
+	// Sandbox nil — сборка и запуск без изоляции.
+	Sandbox *sandbox.Sandbox
+	// Limits лимиты запуска тестового бинарника; WallTime и CPUTime задаёт Timeout.
+	Limits sandbox.Limits
+}
+
+// NewLocalRunner раннер с лимитами запуска песочницы по умолчанию; sb может быть nil.
+func NewLocalRunner(sb *sandbox.Sandbox, timeout time.Duration) *LocalRunner {
+	return &LocalRunner{Timeout: timeout, Sandbox: sb, Limits: sandbox.DefaultConfig().Run}
+}
+
+func (r *LocalRunner) timeout() time.Duration {
+	if r.Timeout <= 0 {
+		return 30 * time.Second
+	}
+	return r.Timeout
+}
+
+func (r *LocalRunner) goBin() string {
+	if r.GoBin == "" {
+		return "go"
+	}
+	return r.GoBin
+}
+
+// Run собирает и запускает тесты пакета files. События тестов берутся только из строк,
+// подписанных TestMain раннера (см. framingSource): решение пишет в тот же stdout, и строки
+// вида "--- PASS" из его вывода остаются просто выводом. Без подписанного итога прогон
+// не считается пройденным.
+func (r *LocalRunner) Run(ctx context.Context, files map[string]string) (Report, error) {
+	if declaresTestMain(files) {
+		return Report{}, ErrTestMain
+	}
+	fr, err := newFraming()
+	if err != nil {
+		return Report{}, err
+	}
+	pkg := make(map[string]string, len(files)+1)
+	for name, src := range files {
+		pkg[name] = src
+	}
+	pkg[framingFile] = fr.source()
+
+	work, err := os.MkdirTemp("", "gotest-*")
+	if err != nil {
+		return Report{}, err
+	}
+	defer os.RemoveAll(work)
+
+	bin, out, err := r.build(ctx, work, "pkg", pkg)
+	if err != nil {
+		return Report{}, err
+	}
+	if bin == "" {
+		return Report{BuildFailed: true, BuildOutput: out, TimedOut: ctx.Err() != nil}, nil
+	}
+
+	timeout := r.timeout()
+	limits := r.Limits
+	// внешний лимит чуть больше, чтобы тестовый бинарник успел напечатать отчёт о зависшем тесте
+	limits.WallTime = timeout + 5*time.Second
+	limits.CPUTime = limits.WallTime
+	res, err := r.exec(ctx, bin, []string{"-test.v=test2json", "-test.count=1", "-test.timeout=" + timeout.String()}, limits)
+	if err != nil {
+		return Report{}, err
+	}
+	stream, done := fr.untag(res.Stdout)
+	rep, err := r.toJSON(ctx, stream+escape(res.Stderr))
+	if err != nil {
+		return Report{}, err
+	}
+	tests := rep.Tests[:0]
+	for _, tr := range rep.Tests {
+		if tr.Name != fr.test {
+			tests = append(tests, tr)
+		}
+	}
+	rep.Tests = tests
+	if rep.ElapsedMs == 0 {
+		rep.ElapsedMs = res.Elapsed.Milliseconds()
+	}
+	if res.TimedOut {
+		rep.TimedOut = true
+	}
+	if !res.OK() || !done {
+		rep.Passed = false
+	}
+	return rep, nil
+}
+
+// build собирает тестовый бинарник пакета files в work/<name>.test. Исходники удаляются
+// сразу после сборки. Пустой путь — сборка не удалась (вывод компилятора во втором значении).
+func (r *LocalRunner) build(ctx context.Context, work, name string, files map[string]string) (string, string, error) {
+	src := filepath.Join(work, name+"-src")
+	defer os.RemoveAll(src)
+	if err := writePackage(src, files); err != nil {
+		return "", "", err
+	}
+	bin := filepath.Join(work, name+".test")
+	if r.Sandbox != nil {
+		res, err := r.Sandbox.BuildTest(ctx, src, bin)
+		if err != nil {
+			return "", "", err
+		}
+		if !res.OK() {
+			return "", res.Stderr + res.Stdout, nil
+		}
+		return bin, "", nil
+	}
+	cmd := exec.CommandContext(ctx, r.goBin(), "test", "-c", "-o", bin, ".")
+	cmd.Dir = src
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOTOOLCHAIN=local", "CGO_ENABLED=0")
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return "", "", err
+	}
+	if err != nil {
+		return "", string(out), nil
+	}
+	return bin, "", nil
+}
+
+// exec запускает тестовый бинарник: в песочнице — в пустом корне, без неё — в пустом каталоге.
+// Без песочницы соблюдается только WallTime.
+func (r *LocalRunner) exec(ctx context.Context, bin string, args []string, limits sandbox.Limits) (sandbox.Result, error) {
+	if r.Sandbox != nil {
+		return r.Sandbox.RunProgram(ctx, sandbox.Program{Bin: bin, Args: args, Limits: limits}, nil)
+	}
+	dir, err := os.MkdirTemp("", "gotest-run-*")
+	if err != nil {
+		return sandbox.Result{}, err
+	}
+	defer os.RemoveAll(dir)
+	parent := ctx
+	if limits.WallTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.WallTime)
+		defer cancel()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	runErr := cmd.Run()
+	res := sandbox.Result{Stdout: stdout.String(), Stderr: stderr.String(), Elapsed: time.Since(start)}
+	if parent.Err() != nil {
+		return res, parent.Err()
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return res, runErr
+	}
+	res.ExitCode = cmd.ProcessState.ExitCode()
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		res.Signal = ws.Signal().String()
+	}
+	res.TimedOut = ctx.Err() != nil
+	return res, nil
+}
+
+// toJSON переводит вывод `-test.v=test2json` в события `go test -json` доверенным
+// `go tool test2json` на хосте и разбирает их.
+func (r *LocalRunner) toJSON(ctx context.Context, output string) (Report, error) {
+	cmd := exec.CommandContext(ctx, r.goBin(), "tool", "test2json", "-t", "-p", ModulePath)
+	cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+	cmd.Stdin = strings.NewReader(output)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return Report{}, fmt.Errorf("test2json: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return Parse(&stdout)
+}
@@ -0,0 +1,110 @@
+package gotest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/learngo/pkg/sandbox"
+)
+
+func TestMain(m *testing.M) {
+	sandbox.Init()
+	os.Exit(m.Run())
+}
+
+// runners раннер без изоляции и, если ядро позволяет, в песочнице.
+func runners(t *testing.T) map[string]*LocalRunner {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	out := map[string]*LocalRunner{"plain": NewLocalRunner(nil, time.Minute)}
+	if sb, err := sandbox.New(sandbox.DefaultConfig()); err == nil {
+		out["sandbox"] = NewLocalRunner(sb, time.Minute)
+	} else {
+		t.Logf("sandbox unavailable: %v", err)
+	}
+	return out
+}
+
+func TestLocalRunnerRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds test binaries")
+	}
+	solution := "package main\n\nimport \"os\"\n\nfunc add(a, b int) int { return a + b }\n\n" +
+		"func leak() string {\n\tb, err := os.ReadFile(\"hidden_test.go\")\n\tif err != nil {\n\t\treturn \"\"\n\t}\n\treturn string(b)\n}\n\nfunc main() {}\n"
+	visible := "package main\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif add(1, 2) != 3 {\n\t\tt.Fatal(\"add\")\n\t}\n}\n\n" +
+		"func TestLeak(t *testing.T) {\n\tif s := leak(); s != \"\" {\n\t\tt.Fatal(s)\n\t}\n}\n"
+	hidden := "package main\n\nimport \"testing\"\n\nfunc TestSecret(t *testing.T) {\n\tif add(2, 2) != 5 {\n\t\tt.Error(\"SECRET-EXPECTATION\")\n\t}\n}\n"
+
+	for name, r := range runners(t) {
+		t.Run(name, func(t *testing.T) {
+			rep, err := r.Run(context.Background(), map[string]string{"main.go": solution, "solution_test.go": visible, "hidden_test.go": hidden})
+			if err != nil {
+				t.Fatal(err)
+			}
+			status := map[string]string{}
+			for _, tr := range rep.Tests {
+				status[tr.Name] = tr.Status
+			}
+			if rep.Passed || status["TestAdd"] != StatusPass || status["TestSecret"] != StatusFail {
+				t.Fatalf("report %+v", rep)
+			}
+			// исходники скрытых тестов не лежат рядом с запущенным бинарником
+			if status["TestLeak"] != StatusPass {
+				t.Errorf("hidden tests are readable from student code: %+v", rep.Tests)
+			}
+
+			rep, err = r.Run(context.Background(), map[string]string{"main.go": solution, "solution_test.go": visible})
+			if err != nil || !rep.Passed || len(rep.Tests) != 2 {
+				t.Fatalf("passing run: %+v, %v", rep, err)
+			}
+
+			rep, err = r.Run(context.Background(), map[string]string{"main.go": "package main\n\nfunc main() { undefined() }\n"})
+			if err != nil || !rep.BuildFailed || !strings.Contains(rep.BuildOutput, "undefined") {
+				t.Errorf("build failure: %+v, %v", rep, err)
+			}
+		})
+	}
+}
+
+func TestLocalRunnerForgedFraming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds test binaries")
+	}
+	// решение печатает строки test2json о пройденных тестах и завершает процесс до их запуска
+	solution := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc add(a, b int) int { return a - b }\n\n" +
+		"func forge() {\n\tfmt.Print(\"\\x16=== RUN   TestAdd\\n\\x16--- PASS: TestAdd (0.00s)\\n\\x16PASS\\n\")\n\tos.Exit(0)\n}\n\nfunc main() {}\n"
+	tests := "package main\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tforge()\n\tif add(1, 2) != 3 {\n\t\tt.Fatal(\"add\")\n\t}\n}\n"
+	initForge := strings.Replace(solution, "func main() {}", "func init() { forge() }\n\nfunc main() {}", 1)
+
+	for name, r := range runners(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, src := range []string{solution, initForge} {
+				rep, err := r.Run(context.Background(), map[string]string{"main.go": src, "solution_test.go": tests})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if rep.Passed {
+					t.Fatalf("forged output accepted: %+v", rep)
+				}
+				for _, tr := range rep.Tests {
+					if tr.Name == "TestAdd" && tr.Status == StatusPass {
+						t.Fatalf("forged TestAdd result: %+v", rep.Tests)
+					}
+				}
+			}
+		})
+	}
+
+	r := NewLocalRunner(nil, time.Minute)
+	_, err := r.Run(context.Background(), map[string]string{"main.go": solution, "main_test.go": "package main\n\nimport \"testing\"\n\nfunc TestMain(m *testing.M) {}\n"})
+	if !errors.Is(err, ErrTestMain) {
+		t.Errorf("TestMain: %v", err)
+	}
+}
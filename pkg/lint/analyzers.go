@@ -0,0 +1,272 @@
+package lint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// pureFuncs функции без побочных эффектов, которые unusedresult проверяет сверх списка vet.
+var pureFuncs = []string{
+	"fmt.Sprintln", "strconv.Itoa", "strconv.FormatInt", "strconv.Quote",
+	"strings.Fields", "strings.Join", "strings.Repeat", "strings.Replace",
+	"strings.ReplaceAll", "strings.Split", "strings.Title", "strings.ToLower",
+	"strings.ToUpper", "strings.Trim", "strings.TrimLeft", "strings.TrimPrefix",
+	"strings.TrimRight", "strings.TrimSpace", "strings.TrimSuffix",
+	"slices.Clone", "maps.Clone", "math.Abs", "math.Max", "math.Min", "math.Sqrt",
+}
+
+// unusedResultAnalyzer unusedresult со списком функций vet и pureFuncs. Проход из x/tools читает
+// списки из своих глобальных флагов, поэтому здесь своя копия со своими флагами: go vet и другие
+// пользователи unusedresult.Analyzer в процессе настроек не замечают.
+var unusedResultAnalyzer = newUnusedResultAnalyzer()
+
+func newUnusedResultAnalyzer() *analysis.Analyzer {
+	funcs, methods := stringSet{}, stringSet{}
+	// значения по умолчанию прохода x/tools только читаются
+	_ = funcs.Set(unusedresult.Analyzer.Flags.Lookup("funcs").Value.String() + "," + strings.Join(pureFuncs, ","))
+	_ = methods.Set(unusedresult.Analyzer.Flags.Lookup("stringmethods").Value.String())
+	a := &analysis.Analyzer{
+		Name:     UnusedResult,
+		Doc:      unusedresult.Analyzer.Doc,
+		URL:      unusedresult.Analyzer.URL,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Run: func(pass *analysis.Pass) (any, error) {
+			return runUnusedResult(pass, funcs, methods)
+		},
+	}
+	a.Flags.Var(&funcs, "funcs", "comma-separated list of functions whose results must be used")
+	a.Flags.Var(&methods, "stringmethods", "comma-separated list of names of methods of type func() string whose results must be used")
+	return a
+}
+
+// runUnusedResult повторяет проход unusedresult: результат функции из funcs или метода
+// func() string из methods не используется.
+func runUnusedResult(pass *analysis.Pass, funcs, methods stringSet) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	stringSig := types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(types.NewParam(token.NoPos, nil, "", types.Typ[types.String])), false)
+	inspect.Preorder([]ast.Node{(*ast.ExprStmt)(nil)}, func(n ast.Node) {
+		call, ok := ast.Unparen(n.(*ast.ExprStmt).X).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok {
+			return
+		}
+		sig := fn.Type().(*types.Signature)
+		switch {
+		case sig.Recv() != nil:
+			if methods[fn.Name()] && types.Identical(sig, stringSig) {
+				pass.Reportf(call.Lparen, "result of (%s).%s call not used", sig.Recv().Type(), fn.Name())
+			}
+		case fn.Pkg() != nil && funcs[fn.Pkg().Path()+"."+fn.Name()]:
+			pass.Reportf(call.Lparen, "result of %s.%s call not used", fn.Pkg().Path(), fn.Name())
+		}
+	})
+	return nil, nil
+}
+
+// stringSet значение флага — список через запятую.
+type stringSet map[string]bool
+
+func (s *stringSet) String() string {
+	items := make([]string, 0, len(*s))
+	for item := range *s {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func (s *stringSet) Set(v string) error {
+	m := stringSet{}
+	for _, item := range strings.Split(v, ",") {
+		if item != "" {
+			m[item] = true
+		}
+	}
+	*s = m
+	return nil
+}
+
+// errCheckAnalyzer упрощённый errcheck: в golang.org/x/tools такого прохода нет.
+var errCheckAnalyzer = &analysis.Analyzer{
+	Name:     ErrCheck,
+	Doc:      "ошибка, которую возвращает вызов, не проверяется",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runErrCheck,
+}
+
+// errCheckExcluded вызовы, ошибку которых принято не проверять: вывод на консоль и запись в память.
+var errCheckExcluded = map[string]bool{
+	"fmt.Print": true, "fmt.Printf": true, "fmt.Println": true,
+	"fmt.Fprint": true, "fmt.Fprintf": true, "fmt.Fprintln": true,
+	"(*bytes.Buffer).Write": true, "(*bytes.Buffer).WriteByte": true, "(*bytes.Buffer).WriteRune": true, "(*bytes.Buffer).WriteString": true,
+	"(*strings.Builder).Write": true, "(*strings.Builder).WriteByte": true, "(*strings.Builder).WriteRune": true, "(*strings.Builder).WriteString": true,
+	"(*bufio.Writer).Write": true, "(*bufio.Writer).WriteByte": true, "(*bufio.Writer).WriteRune": true, "(*bufio.Writer).WriteString": true,
+}
+
+func runErrCheck(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	errType := types.Universe.Lookup("error").Type()
+	inspect.Preorder([]ast.Node{(*ast.ExprStmt)(nil)}, func(n ast.Node) {
+		call, ok := ast.Unparen(n.(*ast.ExprStmt).X).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		sig, ok := pass.TypesInfo.TypeOf(call.Fun).(*types.Signature)
+		if !ok || sig.Results().Len() == 0 {
+			return
+		}
+		if !types.Identical(sig.Results().At(sig.Results().Len()-1).Type(), errType) {
+			return
+		}
+		if fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func); ok && errCheckExcluded[fn.FullName()] {
+			return
+		}
+		pass.Reportf(call.Pos(), "error returned by %s is not checked", types.ExprString(call.Fun))
+	})
+	return nil, nil
+}
+
+// ineffAssignAnalyzer упрощённый ineffassign: в golang.org/x/tools такого прохода нет.
+var ineffAssignAnalyzer = &analysis.Analyzer{
+	Name:     IneffAssign,
+	Doc:      "присвоенное значение перезаписывается, не будучи прочитанным",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runIneffAssign,
+}
+
+// runIneffAssign ищет в одном блоке присваивание переменной, за которым в том же блоке
+// следует новое присваивание ей же без чтения между ними. Переменные, захваченные
+// замыканиями или чей адрес берётся, не проверяются; на инструкции с переходом поиск
+// по блоку прекращается.
+func runIneffAssign(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	escaped := map[types.Object]bool{}
+	inspect.Preorder([]ast.Node{(*ast.FuncLit)(nil), (*ast.UnaryExpr)(nil)}, func(n ast.Node) {
+		switch x := n.(type) {
+		case *ast.FuncLit:
+			ast.Inspect(x.Body, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					escaped[pass.TypesInfo.Uses[id]] = true
+				}
+				return true
+			})
+		case *ast.UnaryExpr:
+			if x.Op == token.AND {
+				if id, ok := ast.Unparen(x.X).(*ast.Ident); ok {
+					escaped[pass.TypesInfo.Uses[id]] = true
+				}
+			}
+		}
+	})
+
+	local := func(id *ast.Ident) *types.Var {
+		obj := pass.TypesInfo.ObjectOf(id)
+		v, ok := obj.(*types.Var)
+		if !ok || id.Name == "_" || v.IsField() || escaped[obj] || v.Parent() == nil || v.Parent() == pass.Pkg.Scope() {
+			return nil
+		}
+		return v
+	}
+	inspect.Preorder([]ast.Node{(*ast.BlockStmt)(nil)}, func(n ast.Node) {
+		block := n.(*ast.BlockStmt)
+		for i, stmt := range block.List {
+			as, ok := stmt.(*ast.AssignStmt)
+			if !ok || (as.Tok != token.ASSIGN && as.Tok != token.DEFINE) {
+				continue
+			}
+			for _, lhs := range as.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				v := local(id)
+				if v == nil {
+					continue
+				}
+				if overwritten(pass.TypesInfo, block.List[i+1:], v) {
+					pass.Reportf(id.Pos(), "ineffectual assignment to %s", id.Name)
+				}
+			}
+		}
+	})
+	return nil, nil
+}
+
+// overwritten следующее обращение к v в stmts — присваивание без чтения.
+func overwritten(info *types.Info, stmts []ast.Stmt, v *types.Var) bool {
+	for _, stmt := range stmts {
+		if jumps(stmt) {
+			return false
+		}
+		if as, ok := stmt.(*ast.AssignStmt); ok && (as.Tok == token.ASSIGN || as.Tok == token.DEFINE) {
+			target := false
+			for _, lhs := range as.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && info.ObjectOf(id) == v {
+					target = true
+				}
+			}
+			if target {
+				return !reads(info, as.Rhs, v) && !readsLHS(info, as.Lhs, v)
+			}
+		}
+		if reads(info, []ast.Node{stmt}, v) {
+			return false
+		}
+	}
+	return false
+}
+
+// jumps есть ли в инструкции return, break, continue, goto или метка: дальше блок
+// может не выполниться, а значение — пригодиться.
+func jumps(stmt ast.Stmt) bool {
+	found := false
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.ReturnStmt, *ast.BranchStmt, *ast.LabeledStmt:
+			found = true
+		case *ast.FuncLit:
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// reads упоминается ли v в узлах.
+func reads[N ast.Node](info *types.Info, nodes []N, v *types.Var) bool {
+	found := false
+	for _, n := range nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && info.Uses[id] == v {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// readsLHS читается ли v в левой части присваивания, например в индексе a[v] = 0.
+func readsLHS(info *types.Info, lhs []ast.Expr, v *types.Var) bool {
+	for _, e := range lhs {
+		if id, ok := e.(*ast.Ident); ok && info.ObjectOf(id) == v {
+			continue
+		}
+		if reads(info, []ast.Expr{e}, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package lint
+
+import (
+	"go/format"
+	"strings"
+)
+
+// maxDiffLines для исходников длиннее построчный diff не строится: одно замечание на весь файл.
+const maxDiffLines = 2000
+
+// gofmtDiff замечания к строкам, которые gofmt записал бы иначе; в Suggestion — строки
+// после форматирования. Вставленные gofmt строки относятся к предыдущей строке исходника.
+func gofmtDiff(src string) []Diagnostic {
+	formatted, err := format.Source([]byte(src))
+	if err != nil || string(formatted) == src {
+		return nil
+	}
+	a := splitLines(src)
+	b := splitLines(string(formatted))
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return []Diagnostic{{Analyzer: Gofmt, Line: 1, EndLine: len(a), Message: "file is not gofmt-ed"}}
+	}
+
+	// lcs[i][j] длина наибольшей общей подпоследовательности a[i:] и b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []Diagnostic
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			i, j = i+1, j+1
+			continue
+		}
+		// расхождение: до следующей общей строки
+		si, sj := i, j
+		for (i < len(a) || j < len(b)) && !(i < len(a) && j < len(b) && a[i] == b[j]) {
+			if j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]) {
+				i++
+			} else {
+				j++
+			}
+		}
+		d := Diagnostic{Analyzer: Gofmt, Message: "not formatted according to gofmt"}
+		switch {
+		case i > si:
+			d.Line, d.EndLine = si+1, i
+			d.Suggestion = strings.Join(b[sj:j], "\n")
+		case si > 0:
+			// только вставка: к предыдущей строке
+			d.Line, d.EndLine = si, si
+			d.Suggestion = strings.Join(append([]string{a[si-1]}, b[sj:j]...), "\n")
+		default:
+			d.Line, d.EndLine = 1, 1
+			d.Suggestion = strings.Join(append(append([]string{}, b[sj:j]...), a[0]), "\n")
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,198 @@
+// Package lint статический анализ Go-решений: go vet, отличия от gofmt и проходы
+// golang.org/x/tools/go/analysis (unusedresult, shadow) вместе со своими проверками,
+// у которых нет готового прохода (непроверенная ошибка, бесполезное присваивание).
+// Замечания приходят с номерами строк.
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"runtime"
+	"sort"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/analysis/passes/shadow"
+	"golang.org/x/tools/go/packages"
+)
+
+// Имена проверок.
+const (
+	Vet          = "vet"
+	Gofmt        = "gofmt"
+	UnusedResult = "unusedresult"
+	Shadow       = "shadow"
+	ErrCheck     = "errcheck"
+	IneffAssign  = "ineffassign"
+)
+
+// modulePath путь модуля, в котором проверяется решение.
+const modulePath = "submission"
+
+// ErrUnknownAnalyzer проверки с таким именем нет.
+var ErrUnknownAnalyzer = errors.New("unknown analyzer")
+
+// Diagnostic замечание к строкам [Line, EndLine] исходника.
+type Diagnostic struct {
+	Analyzer   string `json:"analyzer"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column,omitempty"`
+	EndLine    int    `json:"end_line,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"` // исправленный текст строк Line..EndLine
+}
+
+// analyzers проходы go/analysis; vet и gofmt выполняются отдельно. Имя прохода — имя проверки.
+var analyzers = []*analysis.Analyzer{unusedResultAnalyzer, shadow.Analyzer, errCheckAnalyzer, ineffAssignAnalyzer}
+
+// Names все проверки в порядке выполнения — набор по умолчанию.
+func Names() []string {
+	names := []string{Vet, Gofmt}
+	for _, a := range analyzers {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// Known сообщает, есть ли проверка с таким именем.
+func Known(name string) bool {
+	for _, n := range Names() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Linter выполняет проверки над одним файлом пакета main.
+type Linter struct {
+	// GoBin путь к go для vet; пустой — go из PATH.
+	GoBin string
+	// VetTimeout ограничение на go vet.
+	VetTimeout time.Duration
+}
+
+func New(vetTimeout time.Duration) *Linter {
+	return &Linter{VetTimeout: vetTimeout}
+}
+
+// Run выполняет проверки names (nil — все) и возвращает замечания по возрастанию строк.
+// Ошибка go vet (нет go, истёк таймаут) или прохода возвращается вместе с замечаниями
+// остальных проверок.
+// Файл, который не удалось разобрать, замечаний не даёт: об ошибках сообщит компилятор;
+// файл с ошибками типов не проверяют ни vet, ни проходы go/analysis.
+func (l *Linter) Run(ctx context.Context, src string, names []string) ([]Diagnostic, error) {
+	if names == nil {
+		names = Names()
+	}
+	enabled := map[string]bool{}
+	for _, n := range names {
+		if !Known(n) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAnalyzer, n)
+		}
+		enabled[n] = true
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	if err != nil {
+		return []Diagnostic{}, nil
+	}
+	out := []Diagnostic{}
+	var vetErr error
+	if enabled[Vet] {
+		// unusedresult, если включён, выполняется своим проходом с расширенным списком функций
+		var diags []Diagnostic
+		diags, vetErr = l.vet(ctx, src, enabled[UnusedResult])
+		out = append(out, diags...)
+	}
+	if enabled[Gofmt] {
+		out = append(out, gofmtDiff(src)...)
+	}
+	var passes []*analysis.Analyzer
+	for _, a := range analyzers {
+		if enabled[a.Name] {
+			passes = append(passes, a)
+		}
+	}
+	var passErr error
+	if len(passes) > 0 {
+		var diags []Diagnostic
+		diags, passErr = analyze(fset, f, passes)
+		out = append(out, diags...)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Line != out[j].Line {
+			return out[i].Line < out[j].Line
+		}
+		return out[i].Column < out[j].Column
+	})
+	return out, errors.Join(vetErr, passErr)
+}
+
+// analyze выполняет проходы драйвером go/analysis/checker над пакетом main из файла f.
+// Пакет типизируется здесь же, зависимости — по export data установленного тулчейна;
+// файл с ошибками типов проходы пропускают, как и go vet.
+func analyze(fset *token.FileSet, f *ast.File, passes []*analysis.Analyzer) ([]Diagnostic, error) {
+	pkg := &packages.Package{
+		ID:              modulePath,
+		Name:            f.Name.Name,
+		PkgPath:         modulePath,
+		Fset:            fset,
+		Syntax:          []*ast.File{f},
+		CompiledGoFiles: []string{"main.go"},
+		TypesSizes:      types.SizesFor("gc", runtime.GOARCH),
+		TypesInfo: &types.Info{
+			Types:        map[ast.Expr]types.TypeAndValue{},
+			Defs:         map[*ast.Ident]types.Object{},
+			Uses:         map[*ast.Ident]types.Object{},
+			Implicits:    map[ast.Node]types.Object{},
+			Instances:    map[*ast.Ident]types.Instance{},
+			Scopes:       map[ast.Node]*types.Scope{},
+			Selections:   map[*ast.SelectorExpr]*types.Selection{},
+			FileVersions: map[*ast.File]string{},
+		},
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", nil),
+		Sizes:    pkg.TypesSizes,
+		Error: func(err error) {
+			if terr, ok := err.(types.Error); ok {
+				pkg.TypeErrors = append(pkg.TypeErrors, terr)
+			}
+		},
+	}
+	pkg.Types, _ = conf.Check(pkg.PkgPath, fset, pkg.Syntax, pkg.TypesInfo)
+	pkg.IllTyped = len(pkg.TypeErrors) > 0
+
+	graph, err := checker.Analyze(passes, []*packages.Package{pkg}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var diags []Diagnostic
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			if pkg.IllTyped {
+				continue // проход пропущен из-за ошибок типов
+			}
+			return nil, fmt.Errorf("%s: %w", act.Analyzer.Name, act.Err)
+		}
+		for _, d := range act.Diagnostics {
+			start, end := fset.Position(d.Pos), fset.Position(d.End)
+			diag := Diagnostic{Analyzer: act.Analyzer.Name, Line: start.Line, Column: start.Column, Message: d.Message}
+			if d.End.IsValid() && end.Line > start.Line {
+				diag.EndLine = end.Line
+			}
+			diags = append(diags, diag)
+		}
+	}
+	return diags, nil
+}
@@ -0,0 +1,129 @@
+package lint
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+)
+
+const sample = `package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func parse(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		_ = n
+		_ = err
+	}
+	return n, err
+}
+
+func main() {
+	name := "go"
+	strings.ToUpper(name)
+	os.Remove("tmp")
+	total := 0
+	total = len(name)
+	v, err := parse("42")
+	w, err := parse("7")
+	if err != nil {
+		return
+	}
+	fmt.Println(total, v, w)
+}
+`
+
+func TestAnalyzers(t *testing.T) {
+	l := New(0)
+	diags, err := l.Run(context.Background(), sample, []string{UnusedResult, Shadow, ErrCheck, IneffAssign})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{ // проверка → строка
+		Shadow:       13,
+		UnusedResult: 22,
+		ErrCheck:     23,
+		IneffAssign:  24,
+	}
+	got := map[string][]int{}
+	for _, d := range diags {
+		got[d.Analyzer] = append(got[d.Analyzer], d.Line)
+	}
+	for name, line := range want {
+		if len(got[name]) == 0 || got[name][0] != line {
+			t.Errorf("%s: lines %v, want %d", name, got[name], line)
+		}
+	}
+	// первое err перезаписано вторым := без проверки
+	if lines := got[IneffAssign]; len(lines) != 2 || lines[1] != 26 {
+		t.Errorf("ineffassign lines %v, want [24 26]", lines)
+	}
+	if _, err := l.Run(context.Background(), sample, []string{"golint"}); err == nil {
+		t.Error("unknown analyzer accepted")
+	}
+	// расширенный список — у своей копии прохода, флаги x/tools не меняются
+	if funcs := unusedresult.Analyzer.Flags.Lookup("funcs").Value.String(); strings.Contains(funcs, "strings.ToUpper") {
+		t.Errorf("unusedresult.Analyzer flags changed: %s", funcs)
+	}
+}
+
+func TestGofmtDiff(t *testing.T) {
+	src := "package main\n\nfunc main() {\nx:=1\n\tprintln( x )\n}\n"
+	diags := gofmtDiff(src)
+	if len(diags) != 1 {
+		t.Fatalf("diagnostics %+v", diags)
+	}
+	d := diags[0]
+	if d.Line != 4 || d.EndLine != 5 || d.Suggestion != "\tx := 1\n\tprintln(x)" {
+		t.Errorf("diagnostic %+v", d)
+	}
+	if diags := gofmtDiff("package main\n\nfunc main() {}\n"); len(diags) != 0 {
+		t.Errorf("formatted source: %+v", diags)
+	}
+}
+
+func TestVet(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	src := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%d\\n\", \"x\")\n}\n"
+	diags, err := New(0).Run(context.Background(), src, []string{Vet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || diags[0].Line != 6 || diags[0].Analyzer != Vet {
+		t.Errorf("diagnostics %+v", diags)
+	}
+}
+
+func TestVetLeavesUnusedResultToPass(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	src := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Sprintf(\"%d\", 1)\n}\n"
+	diags, err := New(0).Run(context.Background(), src, []string{Vet, UnusedResult})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || diags[0].Analyzer != UnusedResult || diags[0].Line != 6 {
+		t.Errorf("diagnostics %+v", diags)
+	}
+	// без прохода о неиспользованном результате сообщает vet
+	diags, err = New(0).Run(context.Background(), src, []string{Vet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || diags[0].Analyzer != Vet {
+		t.Errorf("vet only: %+v", diags)
+	}
+}
@@ -0,0 +1,162 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// coldVetTimeout ограничение на go vet, пока vetCache пуст: vet сам собирает export data
+// стандартной библиотеки, это дольше обычного лимита.
+const coldVetTimeout = 2 * time.Minute
+
+// vetCache GOCACHE для go vet — свой временный каталог на процесс, а не кэш сервера.
+var vetCache struct {
+	once sync.Once
+	dir  string
+	err  error
+	warm atomic.Bool // был успешный прогон: export data в кэше
+}
+
+func vetCacheDir() (string, error) {
+	vetCache.once.Do(func() {
+		vetCache.dir, vetCache.err = os.MkdirTemp("", "vet-cache-*")
+	})
+	return vetCache.dir, vetCache.err
+}
+
+// vetDiagnostic замечание в выводе go vet -json.
+type vetDiagnostic struct {
+	Posn    string `json:"posn"`
+	End     string `json:"end"`
+	Message string `json:"message"`
+}
+
+// vet запускает go vet -json над файлом во временном модуле. go vet не выполняет код решения;
+// сеть и cgo отключены, окружения сервера (секретов в переменных) go vet не получает.
+// Ошибки компиляции замечаний не дают. noUnusedResult отключает unusedresult: его замечания
+// даёт проход UnusedResult.
+func (l *Linter) vet(ctx context.Context, src string, noUnusedResult bool) ([]Diagnostic, error) {
+	dir, err := os.MkdirTemp("", "vet-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+modulePath+"\n\ngo 1.21\n"), 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o600); err != nil {
+		return nil, err
+	}
+
+	cache, err := vetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	timeout := l.VetTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if !vetCache.warm.Load() {
+		timeout = max(timeout, coldVetTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	bin := l.GoBin
+	if bin == "" {
+		bin = "go"
+	}
+	args := []string{"vet", "-json"}
+	if noUnusedResult {
+		args = append(args, "-unusedresult=false")
+	}
+	cmd := exec.CommandContext(ctx, bin, append(args, ".")...)
+	cmd.Dir = dir
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"), "HOME=" + dir, "GOCACHE=" + cache,
+		"GOFLAGS=-mod=mod", "GOPROXY=off", "GOTOOLCHAIN=local", "CGO_ENABLED=0",
+	}
+	// в зависимости от версии go отчёт пишется в stdout или stderr
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return nil, runErr
+	}
+	vetCache.warm.Store(true)
+	return parseVet(output.Bytes()), nil
+}
+
+// parseVet разбирает вывод go vet -json: JSON-объекты пакет → проверка → замечания
+// вперемешку со строками «# пакет» и текстовыми ошибками сборки.
+func parseVet(out []byte) []Diagnostic {
+	var diags []Diagnostic
+	for len(out) > 0 {
+		start := bytes.IndexByte(out, '{')
+		if start < 0 {
+			break
+		}
+		dec := json.NewDecoder(bytes.NewReader(out[start:]))
+		var report map[string]map[string]json.RawMessage
+		if err := dec.Decode(&report); err != nil {
+			// не JSON — пропускаем строку
+			if nl := bytes.IndexByte(out[start:], '\n'); nl >= 0 {
+				out = out[start+nl+1:]
+				continue
+			}
+			break
+		}
+		out = out[start+int(dec.InputOffset()):]
+		for _, checks := range report {
+			names := make([]string, 0, len(checks))
+			for name := range checks {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				var list []vetDiagnostic
+				if err := json.Unmarshal(checks[name], &list); err != nil {
+					continue // {"error": ...} — ошибка проверки, а не замечание
+				}
+				for _, v := range list {
+					line, col := position(v.Posn)
+					if line == 0 {
+						continue
+					}
+					d := Diagnostic{Analyzer: Vet, Line: line, Column: col, Message: name + ": " + v.Message}
+					if end, _ := position(v.End); end > line {
+						d.EndLine = end
+					}
+					diags = append(diags, d)
+				}
+			}
+		}
+	}
+	return diags
+}
+
+// position строка и столбец из «путь:строка:столбец».
+func position(posn string) (line, col int) {
+	parts := strings.Split(posn, ":")
+	if len(parts) < 3 {
+		return 0, 0
+	}
+	line, _ = strconv.Atoi(parts[len(parts)-2])
+	col, _ = strconv.Atoi(parts[len(parts)-1])
+	return line, col
+}
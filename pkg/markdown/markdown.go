@@ -0,0 +1,219 @@
+// Package markdown рендерит Markdown теории уроков в безопасный HTML
+// с подсветкой кода и извлекает исполняемые фрагменты (```go run).
+package markdown
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// version входит в ключ кеша: меняется при изменении пайплайна рендеринга.
+const version = "1"
+
+const (
+	attrSnippetID = "data-snippet-id"
+	runFlag       = "run"
+	highlightCSS  = "monokai"
+)
+
+// Snippet фрагмент кода из теории, помеченный для запуска (```go run).
+type Snippet struct {
+	ID       string `json:"id"` // стабилен для одного и того же кода
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// Result результат рендеринга.
+type Result struct {
+	HTML     string    `json:"html"`
+	Snippets []Snippet `json:"snippets"`
+	Hash     string    `json:"hash"` // sha256 исходника, ключ кеша
+}
+
+// Snippet возвращает исполняемый фрагмент по ID.
+func (r Result) Snippet(id string) (Snippet, bool) {
+	for _, s := range r.Snippets {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return Snippet{}, false
+}
+
+// Renderer потокобезопасный рендерер с LRU-кешем по хешу содержимого.
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key string
+	res Result
+}
+
+// New создаёт рендерер; cacheSize <= 0 — кеш по умолчанию на 512 документов.
+func New(cacheSize int) *Renderer {
+	if cacheSize <= 0 {
+		cacheSize = 512
+	}
+	r := &Renderer{policy: newPolicy(), capacity: cacheSize, order: list.New(), items: make(map[string]*list.Element)}
+	r.md = goldmark.New(
+		// сырой HTML в Markdown не пропускается (безопасный режим goldmark по умолчанию)
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(highlightCSS),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+				highlighting.WithWrapperRenderer(wrapSnippet),
+			),
+		),
+	)
+	return r
+}
+
+// Render возвращает HTML и исполняемые фрагменты; повторный рендер того же текста берётся из кеша.
+func (r *Renderer) Render(src string) (Result, error) {
+	sum := sha256.Sum256([]byte(version + "\x00" + src))
+	key := hex.EncodeToString(sum[:])
+	if res, ok := r.get(key); ok {
+		return res, nil
+	}
+
+	source := []byte(src)
+	doc := r.md.Parser().Parse(text.NewReader(source))
+	res := Result{Hash: key, Snippets: []Snippet{}}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		block, ok := n.(*ast.FencedCodeBlock)
+		if !entering || !ok || block.Info == nil {
+			return ast.WalkContinue, nil
+		}
+		fields := strings.Fields(string(block.Info.Segment.Value(source)))
+		if len(fields) < 2 || !contains(fields[1:], runFlag) {
+			return ast.WalkContinue, nil
+		}
+		var code bytes.Buffer
+		for i := 0; i < block.Lines().Len(); i++ {
+			line := block.Lines().At(i)
+			code.Write(line.Value(source))
+		}
+		sn := Snippet{ID: snippetID(fields[0], code.String()), Language: strings.ToLower(fields[0]), Code: code.String()}
+		block.SetAttributeString(attrSnippetID, []byte(sn.ID))
+		if _, dup := res.Snippet(sn.ID); !dup {
+			res.Snippets = append(res.Snippets, sn)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	var buf bytes.Buffer
+	if err := r.md.Renderer().Render(&buf, source, doc); err != nil {
+		return Result{}, err
+	}
+	res.HTML = r.policy.Sanitize(buf.String())
+	r.put(key, res)
+	return res, nil
+}
+
+// CSS стили подсветки синтаксиса для классов chroma.
+func (r *Renderer) CSS() string {
+	var buf bytes.Buffer
+	_ = chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&buf, styles.Get(highlightCSS))
+	return buf.String()
+}
+
+// wrapSnippet оборачивает исполняемые фрагменты в контейнер с data-атрибутами для кнопки «Запустить».
+func wrapSnippet(w util.BufWriter, c highlighting.CodeBlockContext, entering bool) {
+	var id []byte
+	if attrs := c.Attributes(); attrs != nil {
+		if v, ok := attrs.GetString(attrSnippetID); ok {
+			id, _ = v.([]byte)
+		}
+	}
+	lang, _ := c.Language()
+	if entering {
+		if id != nil {
+			_, _ = w.WriteString(`<div class="snippet" data-snippet-id="` + string(id) + `" data-language="` + escapeAttr(string(lang)) + `">`)
+		}
+		if !c.Highlighted() {
+			_, _ = w.WriteString("<pre><code>")
+		}
+		return
+	}
+	if !c.Highlighted() {
+		_, _ = w.WriteString("</code></pre>\n")
+	}
+	if id != nil {
+		_, _ = w.WriteString("</div>\n")
+	}
+}
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9 _-]+$`)).OnElements("span", "pre", "code", "div")
+	p.AllowAttrs(attrSnippetID).Matching(regexp.MustCompile(`^[a-f0-9]{12}$`)).OnElements("div")
+	p.AllowAttrs("data-language").Matching(regexp.MustCompile(`^[a-z0-9+#-]*$`)).OnElements("div")
+	return p
+}
+
+func snippetID(lang, code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(lang) + "\x00" + code))
+	return hex.EncodeToString(sum[:6])
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func escapeAttr(s string) string {
+	return strings.NewReplacer(`&`, "&amp;", `"`, "&quot;", `<`, "&lt;", `>`, "&gt;").Replace(s)
+}
+
+func (r *Renderer) get(key string) (Result, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	el, ok := r.items[key]
+	if !ok {
+		return Result{}, false
+	}
+	r.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).res, true
+}
+
+func (r *Renderer) put(key string, res Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if el, ok := r.items[key]; ok {
+		r.order.MoveToFront(el)
+		return
+	}
+	r.items[key] = r.order.PushFront(&cacheEntry{key: key, res: res})
+	for r.order.Len() > r.capacity {
+		last := r.order.Back()
+		r.order.Remove(last)
+		delete(r.items, last.Value.(*cacheEntry).key)
+	}
+}
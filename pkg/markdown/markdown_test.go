@@ -0,0 +1,33 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSanitizesAndExtractsSnippets(t *testing.T) {
+	src := "# Привет\n\n<script>alert(1)</script>\n\n[x](javascript:alert(1))\n\n" +
+		"```go run\npackage main\n\nfunc main() { println(\"hi\") }\n```\n\n```go\nvar x = 1\n```\n"
+	r := New(8)
+	res, err := r.Render(src)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(res.HTML, "<script") || strings.Contains(res.HTML, "javascript:") {
+		t.Fatalf("unsafe html survived: %s", res.HTML)
+	}
+	if len(res.Snippets) != 1 || res.Snippets[0].Language != "go" || !strings.Contains(res.Snippets[0].Code, "println") {
+		t.Fatalf("unexpected snippets: %+v", res.Snippets)
+	}
+	if !strings.Contains(res.HTML, `data-snippet-id="`+res.Snippets[0].ID+`"`) {
+		t.Fatalf("snippet container missing: %s", res.HTML)
+	}
+	if !strings.Contains(res.HTML, `class="chroma"`) {
+		t.Fatalf("code is not highlighted: %s", res.HTML)
+	}
+
+	again, _ := r.Render(src)
+	if again.Hash != res.Hash || r.order.Len() != 1 {
+		t.Fatalf("expected cached result")
+	}
+}
@@ -24,11 +24,23 @@ var (
 		},
 		[]string{"method", "path"},
 	)
+	codeExecCacheTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "code_exec_cache_requests_total",
+			Help: "Code execution result cache lookups by result (hit, miss, bypass)",
+		},
+		[]string{"result"},
+	)
 )
 
 // InitMetrics регистрирует метрики в глобальном реестре Prometheus.
 func InitMetrics() {
-	prometheus.MustRegister(httpRequestsTotal, httpRequestDuration)
+	prometheus.MustRegister(httpRequestsTotal, httpRequestDuration, codeExecCacheTotal)
+}
+
+// CodeExecCacheLookup учитывает обращение к кешу результатов выполнения: hit, miss или bypass.
+func CodeExecCacheLookup(result string) {
+	codeExecCacheTotal.WithLabelValues(result).Inc()
 }
 
 // MetricsMiddleware собирает базовые метрики HTTP запросов.
@@ -0,0 +1,55 @@
+package plagiarism
+
+import "strings"
+
+// Row строка выровненного просмотра пары: совпавшие фрагменты стоят друг напротив друга.
+// Номер строки 0 — с этой стороны пусто.
+type Row struct {
+	LeftLine  int    `json:"left_line,omitempty"`
+	Left      string `json:"left"`
+	RightLine int    `json:"right_line,omitempty"`
+	Right     string `json:"right"`
+	Match     int    `json:"match,omitempty"` // номер совпадения (с 1) для подсветки; 0 — строки не совпали
+}
+
+// Align выравнивает два исходника по совпадениям. Из совпадений берётся цепочка, идущая
+// в обоих файлах сверху вниз (остальные, например переставленные функции, остаются
+// подсвеченными только в отчёте); между совпадениями строки идут попарно.
+func Align(a, b string, matches []Match) []Row {
+	left := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	right := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	var rows []Row
+	la, lb := 1, 1 // следующие невыведенные строки
+	side := func(lines []string, n int, last int) (int, string) {
+		if n > last || n < 1 || n > len(lines) {
+			return 0, ""
+		}
+		return n, lines[n-1]
+	}
+	pairUp := func(toA, toB, match int) {
+		for la <= toA || lb <= toB {
+			var r Row
+			r.LeftLine, r.Left = side(left, la, toA)
+			r.RightLine, r.Right = side(right, lb, toB)
+			r.Match = match
+			rows = append(rows, r)
+			la, lb = la+1, lb+1
+			if la > toA+1 {
+				la = toA + 1
+			}
+			if lb > toB+1 {
+				lb = toB + 1
+			}
+		}
+	}
+	for i, m := range matches {
+		// совпадение, пересекающееся с уже выведенным, в цепочку не входит
+		if m.AStart < la || m.BStart < lb || m.AEnd > len(left) || m.BEnd > len(right) {
+			continue
+		}
+		pairUp(m.AStart-1, m.BStart-1, 0)
+		pairUp(m.AEnd, m.BEnd, i+1)
+	}
+	pairUp(len(left), len(right), 0)
+	return rows
+}
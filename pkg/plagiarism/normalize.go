@@ -0,0 +1,117 @@
+// Package plagiarism ищет заимствования в Go-решениях: исходник сводится к последовательности
+// узлов синтаксического дерева без имён, комментариев и форматирования, из неё снимаются
+// отпечатки методом winnowing (Schleimer, Wilkerson, Aiken — как в MOSS), и решения
+// сравниваются по общим отпечаткам.
+package plagiarism
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// Token нормализованный токен и строка исходника, к которой он относится.
+type Token struct {
+	Kind string
+	Line int
+}
+
+// Normalize разбирает Go-файл и возвращает узлы дерева в порядке обхода. Имена (переменных,
+// функций, типов) сводятся к одному виду, литералы — к своему типу, скобки, комментарии
+// и импорты пропускаются; := и = не различаются. Файл с синтаксическими ошибками разбирается
+// частично; ошибка — только если разобрать не удалось ничего. var x = v внутри функции
+// записывается как x := v.
+func Normalize(src string) ([]Token, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", src, parser.SkipObjectResolution)
+	if f == nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	z := &normalizer{fset: fset}
+	for _, decl := range f.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			continue
+		}
+		ast.Inspect(decl, z.visit)
+	}
+	return z.out, nil
+}
+
+type normalizer struct {
+	fset  *token.FileSet
+	out   []Token
+	stack []ast.Node // открытые узлы: Inspect сообщает о выходе из узла вызовом с nil
+}
+
+func (z *normalizer) emit(kind string, pos token.Pos) {
+	z.out = append(z.out, Token{Kind: kind, Line: z.fset.Position(pos).Line})
+}
+
+func (z *normalizer) visit(n ast.Node) bool {
+	if n == nil {
+		top := z.stack[len(z.stack)-1]
+		z.stack = z.stack[:len(z.stack)-1]
+		// конец блока отделяет вложенные конструкции от следующих за ними
+		if _, ok := top.(*ast.BlockStmt); ok {
+			z.emit("}", top.End())
+		}
+		return true
+	}
+	switch x := n.(type) {
+	case *ast.CommentGroup:
+		return false
+	case *ast.ParenExpr:
+		// лишние скобки не меняют программу
+	case *ast.DeclStmt:
+		if spec, ok := shortVarDecl(x); ok {
+			z.emit("assign"+token.ASSIGN.String(), x.Pos())
+			for _, name := range spec.Names {
+				ast.Inspect(name, z.visit)
+			}
+			for _, v := range spec.Values {
+				ast.Inspect(v, z.visit)
+			}
+			return false
+		}
+		z.emit("DeclStmt", x.Pos())
+	case *ast.Ident:
+		z.emit("ident", x.Pos())
+	case *ast.BasicLit:
+		z.emit(x.Kind.String(), x.Pos())
+	case *ast.BinaryExpr:
+		z.emit("binary"+x.Op.String(), x.OpPos)
+	case *ast.UnaryExpr:
+		z.emit("unary"+x.Op.String(), x.OpPos)
+	case *ast.AssignStmt:
+		op := x.Tok
+		if op == token.DEFINE {
+			op = token.ASSIGN
+		}
+		z.emit("assign"+op.String(), x.TokPos)
+	case *ast.IncDecStmt:
+		z.emit(x.Tok.String(), x.TokPos)
+	case *ast.BranchStmt:
+		z.emit(x.Tok.String(), x.TokPos)
+	case *ast.GenDecl:
+		z.emit(x.Tok.String(), x.TokPos)
+	default:
+		z.emit(strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast."), n.Pos())
+	}
+	z.stack = append(z.stack, n)
+	return true
+}
+
+// shortVarDecl объявление вида var a, b = x, y без типа, равносильное a, b := x, y.
+func shortVarDecl(d *ast.DeclStmt) (*ast.ValueSpec, bool) {
+	gd, ok := d.Decl.(*ast.GenDecl)
+	if !ok || gd.Tok != token.VAR || len(gd.Specs) != 1 {
+		return nil, false
+	}
+	spec, ok := gd.Specs[0].(*ast.ValueSpec)
+	if !ok || spec.Type != nil || len(spec.Values) != len(spec.Names) {
+		return nil, false
+	}
+	return spec, true
+}
@@ -0,0 +1,139 @@
+package plagiarism
+
+import "testing"
+
+const original = `package main
+
+import "fmt"
+
+// sumEven сумма чётных чисел
+func sumEven(nums []int) int {
+	total := 0
+	for _, n := range nums {
+		if n%2 == 0 {
+			total += n
+		}
+	}
+	return total
+}
+
+func main() {
+	var n int
+	fmt.Scan(&n)
+	nums := make([]int, n)
+	for i := range nums {
+		fmt.Scan(&nums[i])
+	}
+	fmt.Println(sumEven(nums))
+}
+`
+
+// копия с другими именами, комментариями, скобками, форматированием и порядком функций
+const disguised = `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	var count int
+	fmt.Scan(&count)
+	values := make([]int, count)
+	for idx := range values { fmt.Scan(&values[idx]) }
+	fmt.Println(evens(values))  // ответ
+	_ = os.Args
+}
+
+func evens(xs []int) int {
+	var acc = 0
+	for _, x := range xs {
+		if (x % 2) == 0 {
+			acc += x
+		}
+	}
+	return acc
+}
+`
+
+// своё решение той же задачи
+const independent = `package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+func main() {
+	sc := bufio.NewScanner(os.Stdin)
+	sc.Split(bufio.ScanWords)
+	sc.Scan()
+	sum := 0
+	for sc.Scan() {
+		v, err := strconv.Atoi(sc.Text())
+		if err != nil {
+			panic(err)
+		}
+		if v&1 == 1 {
+			continue
+		}
+		sum = sum + v
+	}
+	fmt.Printf("%d\n", sum)
+}
+`
+
+func mustDocument(t *testing.T, src string) *Document {
+	t.Helper()
+	d, err := NewDocument(src, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestCompareDetectsDisguisedCopy(t *testing.T) {
+	a, b, c := mustDocument(t, original), mustDocument(t, disguised), mustDocument(t, independent)
+	copied := Compare(a, b, nil)
+	if copied.Similarity() < 0.7 {
+		t.Errorf("disguised copy similarity %.2f, want >= 0.7", copied.Similarity())
+	}
+	if len(copied.Matches) == 0 {
+		t.Error("no matched fragments")
+	}
+	if own := Compare(a, c, nil); own.Similarity() > 0.3 {
+		t.Errorf("independent solution similarity %.2f, want <= 0.3", own.Similarity())
+	}
+
+	pairs := CompareAll([]*Document{a, c, b}, nil, 0.5, 0)
+	if len(pairs) != 1 || pairs[0].I != 0 || pairs[0].J != 2 {
+		t.Fatalf("pairs %+v, want only (0, 2)", pairs)
+	}
+	// код из стартового шаблона не считается заимствованием
+	if ignored := Compare(a, b, Fingerprints(original, DefaultOptions())); ignored.Similarity() != 0 {
+		t.Errorf("similarity with starter code ignored = %.2f, want 0", ignored.Similarity())
+	}
+}
+
+func TestAlign(t *testing.T) {
+	a := "a1\nshared1\nshared2\na4\n"
+	b := "b1\nb2\nshared1\nshared2\n"
+	rows := Align(a, b, []Match{{AStart: 2, AEnd: 3, BStart: 3, BEnd: 4}})
+	want := []Row{
+		{LeftLine: 1, Left: "a1", RightLine: 1, Right: "b1"},
+		{RightLine: 2, Right: "b2"},
+		{LeftLine: 2, Left: "shared1", RightLine: 3, Right: "shared1", Match: 1},
+		{LeftLine: 3, Left: "shared2", RightLine: 4, Right: "shared2", Match: 1},
+		{LeftLine: 4, Left: "a4"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows %+v", rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package plagiarism
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// Options параметры отпечатков. Совпадение длиной не меньше K+Window-1 токенов находится
+// всегда, короче K — никогда.
+type Options struct {
+	K      int // длина k-граммы в токенах
+	Window int // окно winnowing
+}
+
+// DefaultOptions k-граммы примерно в полторы строки кода: учебные решения короткие.
+func DefaultOptions() Options {
+	return Options{K: 10, Window: 6}
+}
+
+// Fingerprint отпечаток k-граммы и строки исходника, которые она покрывает.
+type Fingerprint struct {
+	Hash      uint64
+	StartLine int
+	EndLine   int
+}
+
+// Document нормализованное решение с отпечатками.
+type Document struct {
+	Prints []Fingerprint
+}
+
+// NewDocument нормализует исходник и снимает отпечатки.
+func NewDocument(src string, opts Options) (*Document, error) {
+	tokens, err := Normalize(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Prints: Winnow(tokens, opts)}, nil
+}
+
+// Winnow отпечатки последовательности: в каждом окне из Window хешей k-грамм берётся
+// минимальный (при равенстве — самый правый), каждая позиция — один раз.
+func Winnow(tokens []Token, opts Options) []Fingerprint {
+	if opts.K <= 0 || opts.Window <= 0 {
+		opts = DefaultOptions()
+	}
+	n := len(tokens) - opts.K + 1
+	if n <= 0 {
+		return nil
+	}
+	hashes := make([]uint64, n)
+	for i := range hashes {
+		h := fnv.New64a()
+		for _, t := range tokens[i : i+opts.K] {
+			h.Write([]byte(t.Kind))
+			h.Write([]byte{0})
+		}
+		hashes[i] = h.Sum64()
+	}
+	window := min(opts.Window, n)
+	var out []Fingerprint
+	last := -1
+	for start := 0; start+window <= n; start++ {
+		m := start
+		for i := start + 1; i < start+window; i++ {
+			if hashes[i] <= hashes[m] {
+				m = i
+			}
+		}
+		if m != last {
+			out = append(out, Fingerprint{Hash: hashes[m], StartLine: tokens[m].Line, EndLine: tokens[m+opts.K-1].Line})
+			last = m
+		}
+	}
+	return out
+}
+
+// Match совпадающие фрагменты: строки [AStart, AEnd] первого решения и [BStart, BEnd] второго.
+type Match struct {
+	AStart, AEnd int
+	BStart, BEnd int
+}
+
+// Comparison результат сравнения двух решений.
+type Comparison struct {
+	ScoreA  float64 // доля отпечатков первого решения, найденных во втором
+	ScoreB  float64 // и наоборот
+	Matches []Match
+}
+
+// Similarity доля общего кода пары: максимум из долей, чтобы решение, целиком
+// вошедшее в более длинное, не терялось.
+func (c Comparison) Similarity() float64 {
+	return max(c.ScoreA, c.ScoreB)
+}
+
+// Compare сравнивает решения; отпечатки из ignore (стартовый код, общие идиомы) не учитываются.
+func Compare(a, b *Document, ignore map[uint64]bool) Comparison {
+	inB := map[uint64]Fingerprint{}
+	totalB := 0
+	for _, p := range b.Prints {
+		if ignore[p.Hash] {
+			continue
+		}
+		totalB++
+		if _, ok := inB[p.Hash]; !ok {
+			inB[p.Hash] = p
+		}
+	}
+	var c Comparison
+	totalA, sharedA := 0, 0
+	common := map[uint64]bool{}
+	for _, p := range a.Prints {
+		if ignore[p.Hash] {
+			continue
+		}
+		totalA++
+		q, ok := inB[p.Hash]
+		if !ok {
+			continue
+		}
+		sharedA++
+		common[p.Hash] = true
+		c.Matches = append(c.Matches, Match{AStart: p.StartLine, AEnd: p.EndLine, BStart: q.StartLine, BEnd: q.EndLine})
+	}
+	sharedB := 0
+	for _, p := range b.Prints {
+		if common[p.Hash] && !ignore[p.Hash] {
+			sharedB++
+		}
+	}
+	if totalA > 0 {
+		c.ScoreA = float64(sharedA) / float64(totalA)
+	}
+	if totalB > 0 {
+		c.ScoreB = float64(sharedB) / float64(totalB)
+	}
+	c.Matches = mergeMatches(c.Matches)
+	return c
+}
+
+// mergeMatches склеивает соседние и перекрывающиеся совпадения, идущие в обоих решениях
+// в одном направлении.
+func mergeMatches(ms []Match) []Match {
+	if len(ms) == 0 {
+		return nil
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].AStart != ms[j].AStart {
+			return ms[i].AStart < ms[j].AStart
+		}
+		return ms[i].BStart < ms[j].BStart
+	})
+	out := []Match{ms[0]}
+	for _, m := range ms[1:] {
+		cur := &out[len(out)-1]
+		if m.AStart <= cur.AEnd+1 && m.BStart >= cur.BStart && m.BStart <= cur.BEnd+1 {
+			cur.AEnd = max(cur.AEnd, m.AEnd)
+			cur.BEnd = max(cur.BEnd, m.BEnd)
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// Pair пара решений из CompareAll (индексы в переданном срезе, I < J).
+type Pair struct {
+	I, J int
+	Comparison
+}
+
+// CompareAll сравнивает все пары решений и возвращает пары со сходством не ниже threshold,
+// от самых похожих. Сравниваются только пары с общими отпечатками (инвертированный индекс).
+// Отпечаток, встречающийся больше чем в commonShare решений (при пяти и более решениях),
+// считается общей идиомой и игнорируется, как и отпечатки из ignore; commonShare <= 0 — не игнорировать.
+func CompareAll(docs []*Document, ignore map[uint64]bool, threshold, commonShare float64) []Pair {
+	index := map[uint64][]int{}
+	for i, d := range docs {
+		if d == nil {
+			continue
+		}
+		seen := map[uint64]bool{}
+		for _, p := range d.Prints {
+			if !seen[p.Hash] && !ignore[p.Hash] {
+				seen[p.Hash] = true
+				index[p.Hash] = append(index[p.Hash], i)
+			}
+		}
+	}
+	skip := map[uint64]bool{}
+	for h := range ignore {
+		skip[h] = true
+	}
+	if commonShare > 0 && len(docs) >= 5 {
+		for h, ds := range index {
+			if float64(len(ds)) > commonShare*float64(len(docs)) {
+				skip[h] = true
+			}
+		}
+	}
+	candidates := map[[2]int]bool{}
+	for h, ds := range index {
+		if skip[h] {
+			continue
+		}
+		for x := 0; x < len(ds); x++ {
+			for y := x + 1; y < len(ds); y++ {
+				candidates[[2]int{ds[x], ds[y]}] = true
+			}
+		}
+	}
+	var out []Pair
+	for c := range candidates {
+		cmp := Compare(docs[c[0]], docs[c[1]], skip)
+		if cmp.Similarity() >= threshold {
+			out = append(out, Pair{I: c[0], J: c[1], Comparison: cmp})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if si, sj := out[i].Similarity(), out[j].Similarity(); si != sj {
+			return si > sj
+		}
+		if out[i].I != out[j].I {
+			return out[i].I < out[j].I
+		}
+		return out[i].J < out[j].J
+	})
+	return out
+}
+
+// Fingerprints множество отпечатков исходника — например, стартового кода для ignore.
+// Исходник, который не удалось разобрать, отпечатков не даёт.
+func Fingerprints(src string, opts Options) map[uint64]bool {
+	out := map[uint64]bool{}
+	d, err := NewDocument(src, opts)
+	if err != nil {
+		return out
+	}
+	for _, p := range d.Prints {
+		out[p.Hash] = true
+	}
+	return out
+}
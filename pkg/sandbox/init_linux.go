@@ -0,0 +1,268 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// securebits: root внутри неймспейса не получает capabilities при exec,
+// смена uid их не трогает, и всё это нельзя отменить.
+const (
+	secbitNoroot               = 1 << 0
+	secbitNorootLocked         = 1 << 1
+	secbitNoSetuidFixup        = 1 << 2
+	secbitNoSetuidFixupLocked  = 1 << 3
+	secbitKeepCapsLocked       = 1 << 5
+	secbitNoCapAmbientRaise    = 1 << 6
+	secbitNoCapAmbientRaiseLck = 1 << 7
+)
+
+// Init превращает процесс во вспомогательный процесс песочницы, если он так запущен:
+// настраивает изоляцию и заменяет себя целевой программой (в этом случае не возвращается).
+// В остальных случаях ничего не делает. Вызывать первой строкой main.
+func Init() {
+	if len(os.Args) != 2 || os.Args[0] != initArg0 {
+		return
+	}
+	// prctl, seccomp и креды действуют на поток; exec выполнит именно этот поток
+	runtime.LockOSThread()
+	syscall.CloseOnExec(3)
+	report := os.NewFile(3, "sandbox-report")
+
+	var spec initSpec
+	if err := json.Unmarshal([]byte(os.Args[1]), &spec); err != nil {
+		fail(report, fmt.Errorf("decode spec: %w", err))
+	}
+	if err := setup(spec); err != nil {
+		fail(report, err)
+	}
+	if spec.Mode == modeProbe {
+		os.Exit(0)
+	}
+	err := syscall.Exec(spec.Path, spec.Args, spec.Env)
+	fail(report, fmt.Errorf("exec %s: %w", spec.Path, err))
+}
+
+func fail(report *os.File, err error) {
+	fmt.Fprint(report, err.Error())
+	os.Exit(125)
+}
+
+func setup(spec initSpec) error {
+	// монтирования не должны утекать обратно в родительский неймспейс
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+	if spec.Mode == modeBuild || (spec.Mode == modeExec && spec.Root == "") {
+		if err := unix.Chdir(spec.Dir); err != nil {
+			return fmt.Errorf("chdir: %w", err)
+		}
+	} else if err := setupRoot(spec); err != nil {
+		return err
+	}
+	if err := dropPrivileges(spec.DropUID); err != nil {
+		return err
+	}
+	if err := setRlimits(spec.Limits); err != nil {
+		return err
+	}
+	if spec.Mode == modeBuild || (spec.Mode == modeExec && !spec.Seccomp) {
+		// сборка запускает только доверенный тулчейн, которому нужны fork и весь GOROOT
+		return nil
+	}
+	return installSeccomp()
+}
+
+// setupRoot собирает новый корень: tmpfs только для чтения с /prog и записываемый /tmp
+// ограниченного размера — и делает в него chroot. В modeExec вместо /prog в корне
+// spec.Mounts только для чтения и рабочий каталог spec.Dir на запись.
+func setupRoot(spec initSpec) error {
+	size := uint64(1 << 20)
+	if spec.Bin != "" {
+		fi, err := os.Stat(spec.Bin)
+		if err != nil {
+			return err
+		}
+		size += uint64(fi.Size())
+	}
+	root := spec.Root
+	if err := unix.Mount("tmpfs", root, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, fmt.Sprintf("size=%d,mode=0755", size)); err != nil {
+		return fmt.Errorf("mount root: %w", err)
+	}
+	if spec.Bin != "" {
+		if err := copyFile(spec.Bin, filepath.Join(root, progName)); err != nil {
+			return fmt.Errorf("copy binary: %w", err)
+		}
+	}
+	tmp := filepath.Join(root, "tmp")
+	if err := os.Mkdir(tmp, 0o755); err != nil {
+		return err
+	}
+	opts := fmt.Sprintf("size=%d,nr_inodes=1024,mode=1777", spec.Scratch)
+	if err := unix.Mount("tmpfs", tmp, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, opts); err != nil {
+		return fmt.Errorf("mount scratch: %w", err)
+	}
+	if spec.Mode == modeExec {
+		for _, p := range spec.Mounts {
+			if err := bindMount(root, p, true); err != nil {
+				return fmt.Errorf("mount %s: %w", p, err)
+			}
+		}
+		if err := bindMount(root, spec.Dir, false); err != nil {
+			return fmt.Errorf("mount work dir: %w", err)
+		}
+	}
+	if err := unix.Mount("", root, "", unix.MS_REMOUNT|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("remount root read-only: %w", err)
+	}
+	if err := unix.Chroot(root); err != nil {
+		return fmt.Errorf("chroot: %w", err)
+	}
+	if err := unix.Chdir(spec.Dir); err != nil {
+		return fmt.Errorf("chdir: %w", err)
+	}
+	return nil
+}
+
+// bindMount делает путь хоста p видимым в новом корне по тому же пути. Символическая
+// ссылка переносится как есть: её цель должна быть среди смонтированных путей.
+func bindMount(root, p string, readOnly bool) error {
+	fi, err := os.Lstat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(root, p)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	switch {
+	case fi.Mode()&fs.ModeSymlink != 0:
+		link, err := os.Readlink(p)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	case fi.IsDir():
+		err = os.Mkdir(target, 0o755)
+	default:
+		err = os.WriteFile(target, nil, 0o644)
+	}
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	if err := unix.Mount(p, target, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return err
+	}
+	if !readOnly {
+		return nil
+	}
+	// флаги исходного монтирования, запертые в неймспейсе, при перемонтировании сохраняются
+	flags := uintptr(unix.MS_REMOUNT | unix.MS_BIND | unix.MS_RDONLY | unix.MS_NOSUID | unix.MS_NODEV)
+	var st unix.Statfs_t
+	if err := unix.Statfs(target, &st); err == nil && st.Flags&unix.ST_NOEXEC != 0 {
+		flags |= unix.MS_NOEXEC
+	}
+	return unix.Mount("", target, "", flags, "")
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// dropPrivileges сбрасывает capabilities, при возможности переключается на nobody
+// и запрещает повышение привилегий через exec.
+func dropPrivileges(dropUID bool) error {
+	bits := secbitNoroot | secbitNorootLocked | secbitNoSetuidFixup | secbitNoSetuidFixupLocked |
+		secbitKeepCapsLocked | secbitNoCapAmbientRaise | secbitNoCapAmbientRaiseLck
+	if err := unix.Prctl(unix.PR_SET_SECUREBITS, uintptr(bits), 0, 0, 0); err != nil {
+		return fmt.Errorf("set securebits: %w", err)
+	}
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil {
+		return fmt.Errorf("clear ambient caps: %w", err)
+	}
+	for c := 0; c <= 63; c++ {
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0); err != nil && err != unix.EINVAL {
+			return fmt.Errorf("drop capability %d: %w", c, err)
+		}
+	}
+	if dropUID {
+		if err := syscall.Setgroups(nil); err != nil {
+			return fmt.Errorf("setgroups: %w", err)
+		}
+		if err := syscall.Setresgid(sandboxID, sandboxID, sandboxID); err != nil {
+			return fmt.Errorf("setresgid: %w", err)
+		}
+		if err := syscall.Setresuid(sandboxID, sandboxID, sandboxID); err != nil {
+			return fmt.Errorf("setresuid: %w", err)
+		}
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("set no_new_privs: %w", err)
+	}
+	return nil
+}
+
+func setRlimits(l Limits) error {
+	set := func(name string, res int, cur, max uint64) error {
+		if err := unix.Setrlimit(res, &unix.Rlimit{Cur: cur, Max: max}); err != nil {
+			return fmt.Errorf("setrlimit %s: %w", name, err)
+		}
+		return nil
+	}
+	if err := set("core", unix.RLIMIT_CORE, 0, 0); err != nil {
+		return err
+	}
+	if l.CPUTime > 0 {
+		// мягкий лимит присылает SIGXCPU, жёсткий через секунду — SIGKILL
+		sec := uint64((l.CPUTime + time.Second - 1) / time.Second)
+		if err := set("cpu", unix.RLIMIT_CPU, sec, sec+1); err != nil {
+			return err
+		}
+	}
+	for _, r := range []struct {
+		name string
+		res  int
+		v    uint64
+	}{
+		{"data", unix.RLIMIT_DATA, l.MemoryBytes},
+		{"nproc", unix.RLIMIT_NPROC, l.Processes},
+		{"fsize", unix.RLIMIT_FSIZE, l.FileSize},
+		{"nofile", unix.RLIMIT_NOFILE, l.OpenFiles},
+	} {
+		if r.v == 0 {
+			continue
+		}
+		if err := set(r.name, r.res, r.v, r.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
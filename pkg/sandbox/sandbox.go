@@ -0,0 +1,188 @@
+// Package sandbox запускает недоверенный Go-код в изолированном окружении Linux.
+//
+// Работа делится на две фазы. Сборка (go build) выполняет только доверенный тулчейн:
+// она идёт в отдельных user/mount/net/pid-неймспейсах с rlimit'ами, но с доступом
+// к GOROOT и кэшу сборки. Запуск собранного статического бинарника изолирован полностью:
+// новый пользователь, пустой корень на tmpfs (только чтение) с бинарником /prog и
+// ограниченным по размеру /tmp, нет сети, rlimit'ы на CPU/память/процессы/размер файлов,
+// сброшенные capabilities и seccomp-фильтр, запрещающий fork, сокеты и системные вызовы
+// администрирования. Вывод обрезается до Limits.OutputBytes.
+//
+// Изоляцию настраивает вспомогательный процесс — тот же бинарник, перезапущенный
+// через /proc/self/exe. Поэтому main (и TestMain в тестах) каждого бинарника,
+// который пользуется песочницей, должен первым делом вызвать Init.
+package sandbox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// ErrUnsupported песочница недоступна: не Linux, нет user namespaces или seccomp.
+	ErrUnsupported = errors.New("sandbox: not supported")
+	// ErrSetup не удалось подготовить изолированное окружение.
+	ErrSetup = errors.New("sandbox: setup failed")
+)
+
+// Limits ограничения одной фазы. Нулевое значение поля — без ограничения.
+type Limits struct {
+	WallTime    time.Duration // по часам; по истечении процесс убивается
+	CPUTime     time.Duration // RLIMIT_CPU, округляется вверх до секунды
+	MemoryBytes uint64        // RLIMIT_DATA (RLIMIT_AS не годится: Go-рантайм резервирует гигабайты адресов)
+	Processes   uint64        // RLIMIT_NPROC (потоки Go-рантайма тоже считаются)
+	FileSize    uint64        // RLIMIT_FSIZE: максимальный размер записываемого файла
+	OpenFiles   uint64        // RLIMIT_NOFILE
+	OutputBytes int           // отдельно для stdout и stderr; остальное отбрасывается
+}
+
+// Config настройки песочницы.
+type Config struct {
+	// GoBin путь к go; пустой — go из PATH.
+	GoBin string
+	// CacheDir GOCACHE для сборок; пустой — <TMPDIR>/learngo-sandbox-cache.
+	CacheDir string
+	// ScratchBytes размер tmpfs /tmp, единственного каталога, доступного на запись при запуске.
+	ScratchBytes uint64
+	Build        Limits
+	Run          Limits
+}
+
+// DefaultConfig ограничения для учебных задач.
+func DefaultConfig() Config {
+	return Config{
+		ScratchBytes: 16 << 20,
+		Build: Limits{
+			WallTime:    30 * time.Second,
+			CPUTime:     60 * time.Second,
+			MemoryBytes: 2 << 30,
+			Processes:   256,
+			FileSize:    256 << 20,
+			OpenFiles:   1024,
+			OutputBytes: 64 << 10,
+		},
+		Run: Limits{
+			WallTime:    5 * time.Second,
+			CPUTime:     5 * time.Second,
+			MemoryBytes: 256 << 20,
+			Processes:   64,
+			FileSize:    16 << 20,
+			OpenFiles:   64,
+			OutputBytes: 64 << 10,
+		},
+	}
+}
+
+// Command программа, которую Exec запускает с файловой системой хоста.
+type Command struct {
+	Path string   // путь к исполняемому файлу; без "/" ищется в PATH
+	Args []string // argv, включая argv[0]
+	Env  []string
+	// Dir рабочий каталог; если программа идёт под nobody, каталог передаётся ему во владение.
+	Dir string
+	// Mounts если заданы, файловая система хоста не видна: корень — пустой tmpfs, как у Run,
+	// в нём только эти пути хоста (только для чтения), Dir (на запись) и /tmp. Отсутствующие
+	// на хосте пути пропускаются, символические ссылки переносятся как есть.
+	Mounts []string
+	// Untrusted программа сама выполняет недоверенный код (интерпретатор, собранный бинарник):
+	// ставится seccomp-фильтр, запрещающий fork и сокеты. Компиляторам он не подходит — им нужен fork.
+	Untrusted bool
+	Limits    Limits
+}
+
+// Program собранный бинарник для RunProgram.
+type Program struct {
+	Bin  string
+	Args []string // аргументы без argv[0]
+	// Limits нулевое значение — Config.Run.
+	Limits Limits
+}
+
+// Result результат одной фазы.
+type Result struct {
+	Stdout          string
+	Stderr          string
+	ExitCode        int    // -1, если процесс завершён сигналом
+	Signal          string // описание сигнала, если процесс им завершён
+	Elapsed         time.Duration
+	MemoryKB        int64 // пиковый RSS процесса (ru_maxrss); 0 — неизвестен
+	TimedOut        bool  // превышено WallTime или CPUTime
+	OutputTruncated bool
+}
+
+// OK процесс завершился сам с кодом 0.
+func (r Result) OK() bool {
+	return r.ExitCode == 0 && !r.TimedOut
+}
+
+// Outcome результат сборки и запуска программы.
+type Outcome struct {
+	Build Result
+	// Run заполнен, только если сборка прошла успешно.
+	Run      Result
+	Compiled bool
+}
+
+func (c Config) cacheDir() string {
+	if c.CacheDir != "" {
+		return c.CacheDir
+	}
+	return filepath.Join(os.TempDir(), "learngo-sandbox-cache")
+}
+
+func (c Config) goBin() string {
+	if c.GoBin != "" {
+		return c.GoBin
+	}
+	return "go"
+}
+
+// CappedBuffer сохраняет первые Limit байт (0 — без ограничения) и молча отбрасывает остальное,
+// чтобы процесс не блокировался на записи в pipe.
+type CappedBuffer struct {
+	Limit int
+	// Truncated часть записанного отброшена.
+	Truncated bool
+	buf       bytes.Buffer
+}
+
+func (b *CappedBuffer) Write(p []byte) (int, error) {
+	if b.Limit <= 0 {
+		b.buf.Write(p)
+		return len(p), nil
+	}
+	if room := b.Limit - b.buf.Len(); room < len(p) {
+		b.Truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+func (b *CappedBuffer) String() string { return b.buf.String() }
+
+// WriteTree раскладывает файлы (путь через "/" → содержимое) в dir. Пути проверяет
+// вызывающий; здесь — только защита от выхода за пределы dir.
+func WriteTree(dir string, files map[string]string) error {
+	for name, content := range files {
+		rel := filepath.FromSlash(name)
+		if !filepath.IsLocal(rel) {
+			return fmt.Errorf("sandbox: invalid file path %q", name)
+		}
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
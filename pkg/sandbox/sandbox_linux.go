@@ -0,0 +1,361 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// initArg0 argv[0] вспомогательного процесса; по нему Init узнаёт, что надо настраивать изоляцию.
+	initArg0 = "learngo-sandbox-init"
+	// sandboxID uid/gid (nobody), под которым идёт запуск, если сервис работает от root.
+	sandboxID = 65534
+	progName  = "prog"
+)
+
+const (
+	modeBuild = "build"
+	modeRun   = "run"
+	modeProbe = "probe"
+	modeExec  = "exec"
+)
+
+// initSpec задание вспомогательному процессу; передаётся JSON-ом в argv[1].
+type initSpec struct {
+	Mode    string   `json:"mode"`
+	Path    string   `json:"path,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	Dir     string   `json:"dir,omitempty"`
+	Root    string   `json:"root,omitempty"`    // пустой каталог, куда монтируется новый корень
+	Mounts  []string `json:"mounts,omitempty"`  // только для modeExec с Root: пути хоста в новом корне
+	Bin     string   `json:"bin,omitempty"`     // бинарник, копируемый в /prog
+	Scratch uint64   `json:"scratch,omitempty"` // размер /tmp
+	DropUID bool     `json:"drop_uid,omitempty"`
+	Seccomp bool     `json:"seccomp,omitempty"` // только для modeExec; в modeRun фильтр есть всегда
+	Limits  Limits   `json:"limits"`
+}
+
+var (
+	supportOnce sync.Once
+	supportErr  error
+)
+
+// Supported проверяет (один раз за процесс), что ядро позволяет собрать песочницу:
+// пробный вспомогательный процесс проходит всю настройку запуска, кроме exec.
+func Supported() error {
+	supportOnce.Do(func() { supportErr = probe() })
+	return supportErr
+}
+
+func probe() error {
+	if seccompArch == 0 {
+		return fmt.Errorf("%w: seccomp filter is not defined for %s", ErrUnsupported, runtime.GOARCH)
+	}
+	root, err := os.MkdirTemp("", "sandbox-probe-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(root)
+	res, err := spawn(context.Background(), initSpec{
+		Mode:    modeProbe,
+		Dir:     "/tmp",
+		Root:    root,
+		Scratch: 1 << 20,
+		DropUID: os.Getuid() == 0,
+		Limits:  Limits{WallTime: 10 * time.Second, OutputBytes: 4 << 10},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("%w: probe exited with %d: %s", ErrUnsupported, res.ExitCode, strings.TrimSpace(res.Stderr))
+	}
+	return nil
+}
+
+// Sandbox собирает и запускает недоверенный Go-код. Безопасен для конкурентного использования.
+type Sandbox struct {
+	cfg   Config
+	goBin string
+}
+
+// New проверяет поддержку песочницы и наличие тулчейна Go.
+func New(cfg Config) (*Sandbox, error) {
+	if err := Supported(); err != nil {
+		return nil, err
+	}
+	goBin, err := exec.LookPath(cfg.goBin())
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: go toolchain: %w", err)
+	}
+	if goBin, err = filepath.Abs(goBin); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cfg.cacheDir(), 0o700); err != nil {
+		return nil, fmt.Errorf("sandbox: cache dir: %w", err)
+	}
+	if cfg.ScratchBytes == 0 {
+		// tmpfs с size=0 не ограничен — так нельзя
+		cfg.ScratchBytes = DefaultConfig().ScratchBytes
+	}
+	return &Sandbox{cfg: cfg, goBin: goBin}, nil
+}
+
+// Build собирает пакет main из корня модуля dir в бинарник out; go.mod создаётся, если его нет.
+// Ошибка компиляции — не ошибка: Result.OK() == false, вывод компилятора в Result.Stderr.
+func (s *Sandbox) Build(ctx context.Context, dir, out string) (Result, error) {
+	return s.build(ctx, dir, "build", "-trimpath", "-o", out, ".")
+}
+
+// BuildTest собирает тестовый бинарник пакета из корня модуля dir (go test -c) в out.
+// Запускать его — через RunProgram: исходники, в том числе тесты, туда не попадают.
+func (s *Sandbox) BuildTest(ctx context.Context, dir, out string) (Result, error) {
+	return s.build(ctx, dir, "test", "-c", "-trimpath", "-o", out, ".")
+}
+
+func (s *Sandbox) build(ctx context.Context, dir string, args ...string) (Result, error) {
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); errors.Is(err, fs.ErrNotExist) {
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module main\n\ngo 1.21\n"), 0o600); err != nil {
+			return Result{}, err
+		}
+	}
+	return spawn(ctx, initSpec{
+		Mode: modeBuild,
+		Path: s.goBin,
+		Args: append([]string{"go"}, args...),
+		Dir:  dir,
+		Env: []string{
+			"PATH=" + filepath.Dir(s.goBin) + ":/usr/local/bin:/usr/bin:/bin",
+			"HOME=" + dir,
+			"GOCACHE=" + s.cfg.cacheDir(),
+			// модули не скачиваются (GOPROXY=off), доступна только стандартная библиотека
+			"GOPATH=" + filepath.Join(s.cfg.cacheDir(), "gopath"),
+			"GOFLAGS=-mod=mod",
+			"GOPROXY=off",
+			"GOTOOLCHAIN=local",
+			"CGO_ENABLED=0",
+		},
+		Limits: s.cfg.Build,
+	}, nil)
+}
+
+// Run запускает собранный бинарник в полностью изолированном окружении.
+func (s *Sandbox) Run(ctx context.Context, bin string, stdin io.Reader) (Result, error) {
+	return s.RunProgram(ctx, Program{Bin: bin}, stdin)
+}
+
+// RunProgram как Run, но с аргументами и собственными лимитами (например, для тестовых бинарников).
+func (s *Sandbox) RunProgram(ctx context.Context, p Program, stdin io.Reader) (Result, error) {
+	root, err := os.MkdirTemp("", "sandbox-root-*")
+	if err != nil {
+		return Result{}, err
+	}
+	defer os.RemoveAll(root)
+	limits := p.Limits
+	if limits == (Limits{}) {
+		limits = s.cfg.Run
+	}
+	return spawn(ctx, initSpec{
+		Mode:    modeRun,
+		Path:    "/" + progName,
+		Args:    append([]string{progName}, p.Args...),
+		Env:     []string{"GOMAXPROCS=2", "HOME=/tmp", "TMPDIR=/tmp", "PATH=/"},
+		Dir:     "/tmp",
+		Root:    root,
+		Bin:     p.Bin,
+		Scratch: s.cfg.ScratchBytes,
+		DropUID: os.Getuid() == 0,
+		Limits:  limits,
+	}, stdin)
+}
+
+// Exec запускает программу в отдельных неймспейсах без сети, под nobody (если сервис
+// работает от root) и с ограничениями cmd.Limits. Без cmd.Mounts корень не подменяется:
+// программе видна файловая система хоста с правами nobody — так работают интерпретаторы,
+// которым нужны их библиотеки. Изоляция слабее, чем у Run. С cmd.Mounts программа видит
+// только свой тулчейн и рабочий каталог.
+func (s *Sandbox) Exec(ctx context.Context, cmd Command, stdin io.Reader) (Result, error) {
+	path := cmd.Path
+	if !strings.Contains(path, "/") {
+		var err error
+		if path, err = exec.LookPath(path); err != nil {
+			return Result{}, err
+		}
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return Result{}, err
+	}
+	dropUID := os.Getuid() == 0
+	if dropUID {
+		if err := chownTree(cmd.Dir, sandboxID); err != nil {
+			return Result{}, err
+		}
+	}
+	spec := initSpec{
+		Mode:    modeExec,
+		Path:    path,
+		Args:    cmd.Args,
+		Env:     cmd.Env,
+		Dir:     cmd.Dir,
+		DropUID: dropUID,
+		Seccomp: cmd.Untrusted,
+		Limits:  cmd.Limits,
+	}
+	if len(cmd.Mounts) > 0 {
+		if spec.Dir, err = filepath.Abs(cmd.Dir); err != nil {
+			return Result{}, err
+		}
+		root, err := os.MkdirTemp("", "sandbox-root-*")
+		if err != nil {
+			return Result{}, err
+		}
+		defer os.RemoveAll(root)
+		spec.Root, spec.Mounts, spec.Scratch = root, cmd.Mounts, s.cfg.ScratchBytes
+	}
+	return spawn(ctx, spec, stdin)
+}
+
+func chownTree(dir string, id int) error {
+	return filepath.WalkDir(dir, func(p string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, id, id)
+	})
+}
+
+// RunGo собирает программу из одного файла main.go и запускает её.
+func (s *Sandbox) RunGo(ctx context.Context, code, stdin string) (Outcome, error) {
+	return s.RunFiles(ctx, map[string]string{"main.go": code}, stdin)
+}
+
+// RunFiles собирает проект из дерева файлов (путь через "/" → содержимое) и запускает его.
+// Пакет main должен лежать в корне; без go.mod модуль называется main.
+func (s *Sandbox) RunFiles(ctx context.Context, files map[string]string, stdin string) (Outcome, error) {
+	work, err := os.MkdirTemp("", "sandbox-go-*")
+	if err != nil {
+		return Outcome{}, err
+	}
+	defer os.RemoveAll(work)
+	src := filepath.Join(work, "src")
+	if err := WriteTree(src, files); err != nil {
+		return Outcome{}, err
+	}
+	bin := filepath.Join(work, progName)
+	build, err := s.Build(ctx, src, bin)
+	out := Outcome{Build: build}
+	if err != nil || !build.OK() {
+		return out, err
+	}
+	out.Compiled = true
+	out.Run, err = s.Run(ctx, bin, strings.NewReader(stdin))
+	return out, err
+}
+
+// spawn запускает вспомогательный процесс в новых неймспейсах и ждёт завершения программы.
+// Ошибки настройки вспомогательный процесс пишет в pipe (fd 3), закрываемый при успешном exec.
+func spawn(ctx context.Context, spec initSpec, stdin io.Reader) (Result, error) {
+	parent := ctx
+	if spec.Limits.WallTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, spec.Limits.WallTime)
+		defer cancel()
+	}
+	payload, err := json.Marshal(spec)
+	if err != nil {
+		return Result{}, err
+	}
+	reportR, reportW, err := os.Pipe()
+	if err != nil {
+		return Result{}, err
+	}
+	defer reportR.Close()
+
+	stdout := &CappedBuffer{Limit: spec.Limits.OutputBytes}
+	stderr := &CappedBuffer{Limit: spec.Limits.OutputBytes}
+	cmd := exec.CommandContext(ctx, "/proc/self/exe")
+	cmd.Args = []string{initArg0, string(payload)}
+	cmd.Env = []string{}
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.ExtraFiles = []*os.File{reportW}
+	cmd.SysProcAttr = sysProcAttr(spec.DropUID)
+
+	start := time.Now()
+	err = cmd.Start()
+	reportW.Close()
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrSetup, err)
+	}
+	setupMsg, _ := io.ReadAll(reportR)
+	waitErr := cmd.Wait()
+
+	res := Result{
+		Stdout:          stdout.String(),
+		Stderr:          stderr.String(),
+		Elapsed:         time.Since(start),
+		OutputTruncated: stdout.Truncated || stderr.Truncated,
+	}
+	if len(setupMsg) > 0 {
+		return res, fmt.Errorf("%w: %s", ErrSetup, setupMsg)
+	}
+	if parent.Err() != nil {
+		return res, parent.Err()
+	}
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return res, waitErr
+	}
+	ws, _ := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	switch {
+	case ws.Signaled():
+		res.ExitCode = -1
+		res.Signal = ws.Signal().String()
+		res.TimedOut = ws.Signal() == syscall.SIGXCPU
+	default:
+		res.ExitCode = ws.ExitStatus()
+	}
+	if ru, ok := cmd.ProcessState.SysUsage().(*syscall.Rusage); ok {
+		res.MemoryKB = ru.Maxrss
+	}
+	// Go-программы игнорируют SIGXCPU, и по жёсткому лимиту ядро присылает SIGKILL
+	cpu := cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()
+	if ctx.Err() != nil || (spec.Limits.CPUTime > 0 && cpu >= spec.Limits.CPUTime) {
+		res.TimedOut = true
+	}
+	return res, nil
+}
+
+func sysProcAttr(dropUID bool) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET |
+			syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		Pdeathsig:   syscall.SIGKILL,
+	}
+	if dropUID {
+		// от root можно отобразить ещё и nobody: программа пойдёт под отдельным пользователем
+		attr.UidMappings = append(attr.UidMappings, syscall.SysProcIDMap{ContainerID: sandboxID, HostID: sandboxID, Size: 1})
+		attr.GidMappings = append(attr.GidMappings, syscall.SysProcIDMap{ContainerID: sandboxID, HostID: sandboxID, Size: 1})
+		attr.GidMappingsEnableSetgroups = true
+	}
+	return attr
+}
@@ -0,0 +1,264 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	Init()
+	os.Exit(m.Run())
+}
+
+func newTestSandbox(t *testing.T) *Sandbox {
+	t.Helper()
+	if err := Supported(); err != nil {
+		t.Skip(err)
+	}
+	cfg := DefaultConfig()
+	cfg.CacheDir = filepath.Join(os.TempDir(), "learngo-sandbox-test-cache")
+	cfg.ScratchBytes = 4 << 20
+	cfg.Run.WallTime = 10 * time.Second
+	cfg.Run.CPUTime = 3 * time.Second
+	cfg.Run.FileSize = 8 << 20
+	cfg.Run.OutputBytes = 16 << 10
+	sb, err := New(cfg)
+	if err != nil {
+		t.Skip(err)
+	}
+	return sb
+}
+
+func runGo(t *testing.T, sb *Sandbox, code, stdin string) Result {
+	t.Helper()
+	out, err := sb.RunGo(context.Background(), code, stdin)
+	if err != nil {
+		t.Fatalf("RunGo: %v", err)
+	}
+	if !out.Compiled {
+		t.Fatalf("build failed: %s", out.Build.Stderr)
+	}
+	return out.Run
+}
+
+func TestRunEchoesStdin(t *testing.T) {
+	sb := newTestSandbox(t)
+	res := runGo(t, sb, `package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	b, _ := io.ReadAll(os.Stdin)
+	fmt.Printf("uid=%d in=%s", os.Getuid(), b)
+}
+`, "hello")
+	if !res.OK() || !strings.HasSuffix(res.Stdout, "in=hello") {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if os.Getuid() == 0 && !strings.HasPrefix(res.Stdout, "uid=65534") {
+		t.Fatalf("program must run as nobody: %q", res.Stdout)
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	sb := newTestSandbox(t)
+	out, err := sb.RunGo(context.Background(), "package main\n\nfunc main() { undefined() }\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Compiled || !strings.Contains(out.Build.Stderr, "undefined") {
+		t.Fatalf("expected compile error, got %+v", out)
+	}
+}
+
+func TestForkBombIsBlocked(t *testing.T) {
+	sb := newTestSandbox(t)
+	res := runGo(t, sb, `package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func main() {
+	started := 0
+	for i := 0; i < 1000; i++ {
+		if _, err := syscall.ForkExec("/prog", []string{"prog"}, nil); err == nil {
+			started++
+		}
+	}
+	_, _, errno := syscall.RawSyscall(syscall.SYS_CLONE, uintptr(syscall.SIGCHLD), 0, 0)
+	fmt.Printf("started=%d clone=%v", started, errno)
+	os.Exit(0)
+}
+`, "")
+	if res.Stdout != "started=0 clone=operation not permitted" {
+		t.Fatalf("fork must be denied: %+v", res)
+	}
+}
+
+func TestNetworkIsBlocked(t *testing.T) {
+	sb := newTestSandbox(t)
+	res := runGo(t, sb, `package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+func main() {
+	for _, addr := range []string{"1.1.1.1:53", "127.0.0.1:80"} {
+		if c, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+			c.Close()
+			fmt.Println("connected", addr)
+		}
+	}
+	if l, err := net.Listen("tcp", "127.0.0.1:0"); err == nil {
+		l.Close()
+		fmt.Println("listening")
+	}
+	fmt.Print("done")
+}
+`, "")
+	if res.Stdout != "done" {
+		t.Fatalf("network must be unavailable: %+v", res)
+	}
+}
+
+func TestDiskFillIsBounded(t *testing.T) {
+	sb := newTestSandbox(t)
+	res := runGo(t, sb, `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if err := os.WriteFile("/escape", []byte("x"), 0o644); err == nil {
+		fmt.Println("wrote outside scratch")
+	}
+	f, err := os.Create("/tmp/fill")
+	if err != nil {
+		panic(err)
+	}
+	chunk := make([]byte, 1<<20)
+	total := 0
+	for i := 0; i < 1024; i++ {
+		n, err := f.Write(chunk)
+		total += n
+		if err != nil {
+			break
+		}
+	}
+	fmt.Print(total)
+}
+`, "")
+	if !res.OK() || strings.Contains(res.Stdout, "outside") {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if n, err := strconv.Atoi(res.Stdout); err != nil || n > 4<<20 {
+		t.Fatalf("scratch must be limited to 4 MiB, wrote %s bytes", res.Stdout)
+	}
+}
+
+func TestTimeAndOutputLimits(t *testing.T) {
+	sb := newTestSandbox(t)
+	res := runGo(t, sb, `package main
+
+import "fmt"
+
+func main() {
+	for {
+		fmt.Println("spam spam spam spam")
+	}
+}
+`, "")
+	if !res.TimedOut || !res.OutputTruncated || len(res.Stdout) > 16<<10 {
+		t.Fatalf("expected time and output limits, got timedOut=%v truncated=%v len=%d", res.TimedOut, res.OutputTruncated, len(res.Stdout))
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	sb := newTestSandbox(t)
+	res := runGo(t, sb, `package main
+
+import "fmt"
+
+func main() {
+	var keep [][]byte
+	for i := 0; i < 64; i++ {
+		b := make([]byte, 16<<20)
+		for j := range b {
+			b[j] = 1
+		}
+		keep = append(keep, b)
+	}
+	fmt.Print(len(keep))
+}
+`, "")
+	if res.OK() || !strings.Contains(res.Stderr, "out of memory") {
+		t.Fatalf("1 GiB must not fit into the memory limit: %+v", res)
+	}
+}
+
+func TestRunFilesWithSubpackage(t *testing.T) {
+	sb := newTestSandbox(t)
+	out, err := sb.RunFiles(context.Background(), map[string]string{
+		"go.mod": "module demo\n\ngo 1.21\n",
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"demo/internal/greet"
+)
+
+func main() { fmt.Print(greet.Hello("gopher")) }
+`,
+		"internal/greet/greet.go": "package greet\n\nfunc Hello(name string) string { return \"hello, \" + name }\n",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Compiled || out.Run.Stdout != "hello, gopher" {
+		t.Fatalf("unexpected outcome: %+v", out)
+	}
+}
+
+func TestExecWithMountsHidesHost(t *testing.T) {
+	sb := newTestSandbox(t)
+	sh, err := filepath.EvalSymlinks("/bin/sh")
+	if err != nil {
+		t.Skip(err)
+	}
+	dir := t.TempDir()
+	res, err := sb.Exec(context.Background(), Command{
+		Path:   sh,
+		Args:   []string{"sh", "-c", "echo ok > out.txt; touch /usr/probe || echo read-only; cat /etc/passwd"},
+		Env:    []string{"PATH=/usr/bin:/bin"},
+		Dir:    dir,
+		Mounts: []string{"/usr", "/bin", "/lib", "/lib64"},
+		Limits: DefaultConfig().Build,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.OK() || strings.Contains(res.Stdout, "root:") || !strings.Contains(res.Stdout, "read-only") {
+		t.Fatalf("host files must not be visible or writable: %+v", res)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "out.txt")); err != nil || string(b) != "ok\n" {
+		t.Fatalf("work dir must be writable: %q, %v (%+v)", b, err, res)
+	}
+}
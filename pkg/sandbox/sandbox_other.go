@@ -0,0 +1,47 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"io"
+)
+
+// Sandbox на других ОС недоступна; New всегда возвращает ErrUnsupported.
+type Sandbox struct{}
+
+// Init ничего не делает вне Linux.
+func Init() {}
+
+// Supported вне Linux всегда возвращает ErrUnsupported.
+func Supported() error { return ErrUnsupported }
+
+func New(Config) (*Sandbox, error) { return nil, ErrUnsupported }
+
+func (s *Sandbox) Build(context.Context, string, string) (Result, error) {
+	return Result{}, ErrUnsupported
+}
+
+func (s *Sandbox) BuildTest(context.Context, string, string) (Result, error) {
+	return Result{}, ErrUnsupported
+}
+
+func (s *Sandbox) Run(context.Context, string, io.Reader) (Result, error) {
+	return Result{}, ErrUnsupported
+}
+
+func (s *Sandbox) RunProgram(context.Context, Program, io.Reader) (Result, error) {
+	return Result{}, ErrUnsupported
+}
+
+func (s *Sandbox) RunGo(context.Context, string, string) (Outcome, error) {
+	return Outcome{}, ErrUnsupported
+}
+
+func (s *Sandbox) RunFiles(context.Context, map[string]string, string) (Outcome, error) {
+	return Outcome{}, ErrUnsupported
+}
+
+func (s *Sandbox) Exec(context.Context, Command, io.Reader) (Result, error) {
+	return Result{}, ErrUnsupported
+}
@@ -0,0 +1,119 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Смещения полей struct seccomp_data; arg0 читаем младшим словом (little-endian).
+const (
+	seccompDataNr   = 0
+	seccompDataArch = 4
+	seccompDataArg0 = 16
+)
+
+// deniedSyscalls запрещены при запуске: сеть, администрирование, смена неймспейсов
+// и монтирований, отладка чужих процессов, загрузка модулей и eBPF.
+var deniedSyscalls = append([]uint32{
+	unix.SYS_SOCKET,
+	unix.SYS_PTRACE,
+	unix.SYS_PROCESS_VM_READV,
+	unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_MOUNT,
+	unix.SYS_UMOUNT2,
+	unix.SYS_PIVOT_ROOT,
+	unix.SYS_CHROOT,
+	unix.SYS_OPEN_TREE,
+	unix.SYS_MOVE_MOUNT,
+	unix.SYS_FSOPEN,
+	unix.SYS_FSCONFIG,
+	unix.SYS_FSMOUNT,
+	unix.SYS_FSPICK,
+	unix.SYS_MOUNT_SETATTR,
+	unix.SYS_SETNS,
+	unix.SYS_UNSHARE,
+	unix.SYS_OPEN_BY_HANDLE_AT,
+	unix.SYS_NAME_TO_HANDLE_AT,
+	unix.SYS_KEXEC_LOAD,
+	unix.SYS_KEXEC_FILE_LOAD,
+	unix.SYS_INIT_MODULE,
+	unix.SYS_FINIT_MODULE,
+	unix.SYS_DELETE_MODULE,
+	unix.SYS_REBOOT,
+	unix.SYS_SWAPON,
+	unix.SYS_SWAPOFF,
+	unix.SYS_ACCT,
+	unix.SYS_SYSLOG,
+	unix.SYS_SETTIMEOFDAY,
+	unix.SYS_CLOCK_SETTIME,
+	unix.SYS_ADJTIMEX,
+	unix.SYS_PERSONALITY,
+	unix.SYS_BPF,
+	unix.SYS_PERF_EVENT_OPEN,
+	unix.SYS_USERFAULTFD,
+	unix.SYS_KEYCTL,
+	unix.SYS_ADD_KEY,
+	unix.SYS_REQUEST_KEY,
+	unix.SYS_IO_URING_SETUP,
+	unix.SYS_IO_URING_ENTER,
+	unix.SYS_IO_URING_REGISTER,
+}, archDeniedSyscalls...)
+
+func stmt(code uint16, k uint32) unix.SockFilter {
+	return unix.SockFilter{Code: code, K: k}
+}
+
+func jump(code uint16, k uint32, jt, jf uint8) unix.SockFilter {
+	return unix.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+}
+
+// seccompFilter BPF-программа: чужая архитектура — kill, запрещённые вызовы — EPERM,
+// clone разрешён только для потоков (так Go-рантайм создаёт M), clone3 — ENOSYS,
+// чтобы рантайм откатился на clone. Всё остальное разрешено.
+func seccompFilter() []unix.SockFilter {
+	const (
+		ld   = unix.BPF_LD | unix.BPF_W | unix.BPF_ABS
+		jeq  = unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K
+		jge  = unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K
+		jset = unix.BPF_JMP | unix.BPF_JSET | unix.BPF_K
+		ret  = unix.BPF_RET | unix.BPF_K
+	)
+	eperm := uint32(unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM))
+	prog := []unix.SockFilter{
+		stmt(ld, seccompDataArch),
+		jump(jeq, seccompArch, 1, 0),
+		stmt(ret, unix.SECCOMP_RET_KILL_PROCESS),
+		stmt(ld, seccompDataNr),
+	}
+	if seccompX32Bit != 0 {
+		prog = append(prog, jump(jge, seccompX32Bit, 0, 1), stmt(ret, eperm))
+	}
+	for _, nr := range deniedSyscalls {
+		prog = append(prog, jump(jeq, nr, 0, 1), stmt(ret, eperm))
+	}
+	prog = append(prog,
+		jump(jeq, unix.SYS_CLONE3, 0, 1),
+		stmt(ret, unix.SECCOMP_RET_ERRNO|uint32(unix.ENOSYS)),
+		jump(jeq, unix.SYS_CLONE, 0, 3),
+		stmt(ld, seccompDataArg0),
+		jump(jset, unix.CLONE_THREAD, 1, 0),
+		stmt(ret, eperm),
+		stmt(ret, unix.SECCOMP_RET_ALLOW),
+	)
+	return prog
+}
+
+// installSeccomp ставит фильтр на текущий поток; он наследуется при exec.
+// Требует PR_SET_NO_NEW_PRIVS.
+func installSeccomp() error {
+	prog := seccompFilter()
+	fprog := unix.SockFprog{Len: uint16(len(prog)), Filter: &prog[0]}
+	if err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&fprog)), 0, 0); err != nil {
+		return fmt.Errorf("install seccomp filter: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package sandbox
+
+import "golang.org/x/sys/unix"
+
+const (
+	seccompArch = unix.AUDIT_ARCH_X86_64
+	// seccompX32Bit номера x32 ABI; такие вызовы не проходят через таблицу выше.
+	seccompX32Bit = 0x40000000
+)
+
+var archDeniedSyscalls = []uint32{
+	unix.SYS_FORK,
+	unix.SYS_VFORK,
+	unix.SYS_IOPL,
+	unix.SYS_IOPERM,
+	unix.SYS_MODIFY_LDT,
+}
@@ -0,0 +1,11 @@
+package sandbox
+
+import "golang.org/x/sys/unix"
+
+const (
+	seccompArch   = unix.AUDIT_ARCH_AARCH64
+	seccompX32Bit = 0
+)
+
+// fork и vfork на arm64 нет: всё идёт через clone.
+var archDeniedSyscalls []uint32
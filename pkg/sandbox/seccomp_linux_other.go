@@ -0,0 +1,11 @@
+//go:build linux && !amd64 && !arm64
+
+package sandbox
+
+// Для остальных архитектур фильтр не описан — песочница считается неподдерживаемой.
+const (
+	seccompArch   = 0
+	seccompX32Bit = 0
+)
+
+var archDeniedSyscalls []uint32
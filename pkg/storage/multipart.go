@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// CompletedPart загруженная клиентом часть multipart-загрузки (ETag из ответа S3 на PUT части).
+type CompletedPart struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+}
+
+// ObjectInfo метаданные загруженного объекта.
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+func (s *S3Client) core() minio.Core { return minio.Core{Client: s.client} }
+
+// NewMultipartUpload начинает multipart-загрузку и возвращает её uploadId.
+func (s *S3Client) NewMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	return s.core().NewMultipartUpload(ctx, s.bucket, key, minio.PutObjectOptions{ContentType: contentType})
+}
+
+// PresignUploadPart подписывает PUT одной части; клиент грузит её напрямую в S3.
+func (s *S3Client) PresignUploadPart(ctx context.Context, key, uploadID string, partNumber int, expiry time.Duration) (string, error) {
+	params := url.Values{}
+	params.Set("partNumber", strconv.Itoa(partNumber))
+	params.Set("uploadId", uploadID)
+	u, err := s.client.Presign(ctx, "PUT", s.bucket, key, expiry, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// CompleteMultipartUpload собирает объект из частей (в порядке номеров).
+func (s *S3Client) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	if len(parts) == 0 {
+		return fmt.Errorf("no parts to complete")
+	}
+	cp := make([]minio.CompletePart, 0, len(parts))
+	for _, p := range parts {
+		cp = append(cp, minio.CompletePart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	sort.Slice(cp, func(i, j int) bool { return cp[i].PartNumber < cp[j].PartNumber })
+	_, err := s.core().CompleteMultipartUpload(ctx, s.bucket, key, uploadID, cp, minio.PutObjectOptions{})
+	return err
+}
+
+// AbortMultipartUpload отменяет загрузку и освобождает уже загруженные части.
+func (s *S3Client) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	return s.core().AbortMultipartUpload(ctx, s.bucket, key, uploadID)
+}
+
+// PresignGet подписывает кратковременную ссылку на чтение приватного объекта.
+func (s *S3Client) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// PutObject загружает небольшой объект целиком (субтитры, превью).
+func (s *S3Client) PutObject(ctx context.Context, key, contentType string, data []byte) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// StatObject возвращает размер и тип объекта.
+func (s *S3Client) StatObject(ctx context.Context, key string) (ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Size: info.Size, ContentType: info.ContentType}, nil
+}
+
+// RemoveObject удаляет объект.
+func (s *S3Client) RemoveObject(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
@@ -30,6 +30,29 @@ type Config struct {
 	S3Bucket          string   `env:"S3_BUCKET"`
 	S3BaseURL         string   `env:"S3_BASE_URL" envDefault:"https://s3.twcstorage.ru"`
 
+	// Видеоуроки: размер части multipart-загрузки, лимит файла и время жизни подписанных ссылок
+	VideoPartSizeMB      int `env:"VIDEO_PART_SIZE_MB" envDefault:"16"`
+	VideoMaxSizeMB       int `env:"VIDEO_MAX_SIZE_MB" envDefault:"4096"`
+	VideoUploadURLTTLMin int `env:"VIDEO_UPLOAD_URL_TTL_MIN" envDefault:"60"`
+	VideoPlaybackTTLMin  int `env:"VIDEO_PLAYBACK_TTL_MIN" envDefault:"10"`
+
+	// Локализация контента
+	SupportedLocales []string `env:"SUPPORTED_LOCALES" envSeparator:"," envDefault:"ru,en"`
+	DefaultLocale    string   `env:"DEFAULT_LOCALE" envDefault:"ru"`
+
+	// Рендеринг Markdown теории (число закешированных документов)
+	MarkdownCacheSize int `env:"MARKDOWN_CACHE_SIZE" envDefault:"512"`
+
+	// Фоновые задачи: пересчёт счётчиков курсов
+	StatsRecalcIntervalMin int     `env:"STATS_RECALC_INTERVAL_MIN" envDefault:"15"`
+	StatsDebounceSec       int     `env:"STATS_DEBOUNCE_SEC" envDefault:"5"`
+	TrendingWindowDays     int     `env:"TRENDING_WINDOW_DAYS" envDefault:"14"`
+	TrendingHalfLifeHours  float64 `env:"TRENDING_HALF_LIFE_HOURS" envDefault:"72"`
+
+	// Фоновые задачи: поиск заимствований в решениях (порог сходства пары 0..1)
+	PlagiarismIntervalMin int     `env:"PLAGIARISM_INTERVAL_MIN" envDefault:"60"`
+	PlagiarismThreshold   float64 `env:"PLAGIARISM_THRESHOLD" envDefault:"0.5"`
+
 	// OpenAI / AI Provider
 	OpenAIAPIKey      string  `env:"OPENAI_API_KEY"`
 	OpenAIModel       string  `env:"OPENAI_MODEL" envDefault:"gpt-4o"`
@@ -38,10 +61,44 @@ type Config struct {
 	OpenAIBaseURL     string  `env:"OPENAI_BASE_URL" envDefault:"https://api.openai.com/v1"`
 
 	// Code Execution
-	CodeExecutionTimeout     int    `env:"CODE_EXECUTION_TIMEOUT" envDefault:"5000"`     // milliseconds
-	CodeExecutionMemoryLimit int    `env:"CODE_EXECUTION_MEMORY_LIMIT" envDefault:"128"` // MB
-	Judge0APIURL             string `env:"JUDGE0_API_URL" envDefault:"https://judge0.com/api/v1"`
-	Judge0APIKey             string `env:"JUDGE0_API_KEY"`
+	// CodeExecutors бэкенды в порядке предпочтения: judge0, queue, local; auto — все настроенные
+	// (локальный без песочницы — только если других нет). CodeExecutorRoutes — правила по языкам
+	// и курсам, формат в codeexec.ParseRules.
+	CodeExecutors                []string `env:"CODE_EXECUTOR" envSeparator:"," envDefault:"auto"`
+	CodeExecutorRoutes           string   `env:"CODE_EXECUTOR_ROUTES"`
+	CodeExecutorProbeSec         int      `env:"CODE_EXECUTOR_PROBE_SEC" envDefault:"30"`
+	CodeExecutorFailureThreshold int      `env:"CODE_EXECUTOR_FAILURE_THRESHOLD" envDefault:"3"`
+	CodeExecutorCooldownSec      int      `env:"CODE_EXECUTOR_COOLDOWN_SEC" envDefault:"30"`
+	CodeExecutionTimeout         int      `env:"CODE_EXECUTION_TIMEOUT" envDefault:"5000"`     // milliseconds
+	CodeExecutionMemoryLimit     int      `env:"CODE_EXECUTION_MEMORY_LIMIT" envDefault:"128"` // MB
+	Judge0APIURL                 string   `env:"JUDGE0_API_URL" envDefault:"https://judge0.com/api/v1"`
+	Judge0APIKey                 string   `env:"JUDGE0_API_KEY"`
+	// Judge0CallbackURL адрес PUT /internal/judge0/callbacks, доступный из Judge0: результаты приходят
+	// уведомлениями вместо частого опроса. Требует JUDGE0_CALLBACK_SECRET, общий для всех реплик:
+	// без него приложение не стартует.
+	Judge0CallbackURL    string `env:"JUDGE0_CALLBACK_URL"`
+	Judge0CallbackSecret string `env:"JUDGE0_CALLBACK_SECRET"`
+	// Кеш результатов выполнения (Redis, без него — в памяти процесса); CodeExecCacheSize — записей в памяти
+	CodeExecCacheEnabled bool `env:"CODE_EXEC_CACHE_ENABLED" envDefault:"true"`
+	CodeExecCacheTTLMin  int  `env:"CODE_EXEC_CACHE_TTL_MIN" envDefault:"60"`
+	CodeExecCacheSize    int  `env:"CODE_EXEC_CACHE_SIZE" envDefault:"1024"`
+	// CodeExecSandboxRequired без песочницы локальный исполнитель не запускается: приложение не стартует.
+	// Вне dev снимается только явным CODE_EXEC_SANDBOX_REQUIRED=false
+	CodeExecSandboxRequired bool `env:"CODE_EXEC_SANDBOX_REQUIRED" envDefault:"true"`
+	// Статический анализ решений на Go (go vet, gofmt, проверки идиоматичности); набор проверок задаётся в курсе
+	StaticAnalysisEnabled       bool `env:"STATIC_ANALYSIS_ENABLED" envDefault:"true"`
+	StaticAnalysisVetTimeoutSec int  `env:"STATIC_ANALYSIS_VET_TIMEOUT_SEC" envDefault:"10"`
+
+	// Автопроверка заданий через go test: таймаут прогона, число параллельных прогонов.
+	// Без песочницы проверка отключается; GRADER_SANDBOX_REQUIRED=false — только для dev
+	GraderEnabled         bool `env:"GRADER_ENABLED" envDefault:"true"`
+	GraderTimeoutSec      int  `env:"GRADER_TIMEOUT_SEC" envDefault:"30"`
+	GraderMaxConcurrent   int  `env:"GRADER_MAX_CONCURRENT" envDefault:"2"`
+	GraderSandboxRequired bool `env:"GRADER_SANDBOX_REQUIRED" envDefault:"true"`
+	// Бенчмарки заданий на скорость: раундов сравнения с эталоном, -benchtime и общий таймаут
+	GraderBenchCount      int    `env:"GRADER_BENCH_COUNT" envDefault:"5"`
+	GraderBenchTime       string `env:"GRADER_BENCH_TIME" envDefault:"100ms"`
+	GraderBenchTimeoutSec int    `env:"GRADER_BENCH_TIMEOUT_SEC" envDefault:"120"`
 
 	// Rate Limiting
 	RateLimitAI      int `env:"RATE_LIMIT_AI" envDefault:"60"`       // requests per hour
@@ -49,6 +106,11 @@ type Config struct {
 	RateLimitGlobal  int `env:"RATE_LIMIT_GLOBAL" envDefault:"1000"` // requests per hour
 }
 
+// Development окружение машины разработчика (APP_ENV=dev, local или development).
+func (c *Config) Development() bool {
+	return c.Env == "dev" || c.Env == "local" || c.Env == "development"
+}
+
 // LoadConfig загружает конфигурацию из окружения.
 func LoadConfig() (*Config, error) {
 	var cfg Config